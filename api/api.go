@@ -2,11 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
@@ -14,9 +16,11 @@ import (
 	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
 	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
 	parser "github.com/PaulMue0/efficient-routeplanning/internal/parser"
-	pathfinding "github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
 	preprocessed_graph "github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
 )
 
 var (
@@ -25,7 +29,11 @@ var (
 	cchNetwork      *graph.Graph
 	originalNetwork *graph.Graph    // Store original unmodified network
 	originalWeights map[edgeKey]int // Store original edge weights
+	history         *changeLog      // Edge update history of cchNetwork
 	mu              sync.RWMutex
+
+	// queryWorkspaces holds the search state of concurrent queries.
+	queryWorkspaces = pathfinding.NewWorkspacePool(0, queue.FourAry)
 )
 
 type edgeKey struct {
@@ -38,7 +46,7 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-User")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -66,10 +74,25 @@ func graphHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func loadAndPreprocess() {
-	name := "osm5.txt"
-	dataDir := "../../data/RoadNetworks"
-	fileSystem := os.DirFS(dataDir)
+// Config names the files the API server loads on startup.
+type Config struct {
+	Network  string // The road network in the text format
+	Ordering string // The KaHIP order of the CCH, empty to compute a nested dissection order
+	CH       string // A preprocessed CH, which is built from the network if it cannot be read
+	Addr     string // The address to listen on
+}
+
+// DefaultConfig is the configuration of StartApi, relative to cmd/efficient-routeplanning.
+var DefaultConfig = Config{
+	Network:  "../../data/RoadNetworks/osm5.txt",
+	Ordering: "../../data/KaHIP/osm5.ordering",
+	CH:       "../../data/preprocessed/ch_osm5.gob",
+	Addr:     ":8080",
+}
+
+func loadAndPreprocess(cfg Config) {
+	name := filepath.Base(cfg.Network)
+	fileSystem := os.DirFS(filepath.Dir(cfg.Network))
 
 	// Load original network for base graph endpoint
 	originalNetworkData, err := parser.NewNetworkFromFS(fileSystem, name)
@@ -94,12 +117,17 @@ func loadAndPreprocess() {
 			originalWeights[edgeKey{from, to}] = edge.Weight
 		}
 	}
+	history = newChangeLog(originalWeights)
 
 	// Preprocess CCH
 	cchInst := cch.NewCCH()
 	log.Println("Starting CCH preprocessing...")
 	start := time.Now()
-	err = cchInst.Preprocess(cchNetwork, "../../data/kaHIP/osm5.ordering")
+	if cfg.Ordering != "" {
+		err = cchInst.Preprocess(cchNetwork, cfg.Ordering)
+	} else {
+		err = cchInst.PreprocessWithOrder(cchNetwork, cch.NestedDissection(cchNetwork))
+	}
 	if err != nil {
 		log.Fatalf("CCH preprocessing failed: %v", err)
 	}
@@ -110,9 +138,12 @@ func loadAndPreprocess() {
 	cchInst.Customize(cchNetwork)
 
 	// Preprocess CH
-	chFilePath := "../../data/preprocessed/ch_osm5.gob"
-	log.Printf("Attempting to load preprocessed CH from %s", chFilePath)
-	chFile, err := preprocessed_graph.ReadCHFile(chFilePath)
+	chFilePath := cfg.CH
+	chFile, err := &preprocessed_graph.PreprocessedCHFile{}, errors.New("no CH file configured")
+	if chFilePath != "" {
+		log.Printf("Attempting to load preprocessed CH from %s", chFilePath)
+		chFile, err = preprocessed_graph.ReadCHFile(chFilePath)
+	}
 	if err == nil {
 		log.Printf("Successfully loaded preprocessed CH from %s", chFilePath)
 		chInstance = chFile.ToCH()
@@ -133,20 +164,34 @@ func loadAndPreprocess() {
 }
 
 func StartApi() {
-	loadAndPreprocess()
+	Serve(DefaultConfig)
+}
+
+// Serve loads the files of cfg and serves the API on cfg.Addr until the
+// server fails.
+func Serve(cfg Config) {
+	loadAndPreprocess(cfg)
 
 	// Apply CORS middleware to all handlers
 	http.HandleFunc("/api/cch", corsMiddleware(cchHandler))
 	http.HandleFunc("/api/ch", corsMiddleware(chHandler))
-	http.HandleFunc("/api/cch/query", corsMiddleware(cchQueryHandler))
-	http.HandleFunc("/api/ch/query", corsMiddleware(chQueryHandler))
-	http.HandleFunc("/api/ch/query/nounpack", corsMiddleware(chQueryNoUnpackHandler))
+	http.HandleFunc("/api/cch/query", corsMiddleware(queryHandler("CCH", routing.Options{}, cchEngine)))
+	http.HandleFunc("/api/ch/query", corsMiddleware(queryHandler("CH", routing.Options{}, chEngine)))
+	http.HandleFunc("/api/ch/query/nounpack", corsMiddleware(queryHandler("CH", routing.Options{NoUnpack: true}, chEngine)))
 	http.HandleFunc("/api/cch/update", corsMiddleware(cchUpdateHandler))
+	http.HandleFunc("/api/cch/history", corsMiddleware(historyHandler))
+	http.HandleFunc("/api/cch/history/undo", corsMiddleware(undoHandler))
+	http.HandleFunc("/api/cch/history/reset", corsMiddleware(resetHandler))
 	http.HandleFunc("/api/graph", corsMiddleware(graphHandler))
-	http.HandleFunc("/api/dijkstra/query", corsMiddleware(dijkstraQueryHandler))
-
-	log.Println("Starting API server on :8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	http.HandleFunc("/api/dijkstra/query", corsMiddleware(queryHandler("Dijkstra", routing.Options{}, dijkstraEngine)))
+	http.HandleFunc("/api/status", corsMiddleware(statusHandler))
+	http.HandleFunc("/api/pareto/query", corsMiddleware(paretoHandler))
+	http.HandleFunc("/api/route", corsMiddleware(routeHandler))
+	http.HandleFunc("/api/vrp", corsMiddleware(vrpHandler))
+	http.HandleFunc("/api/kshortest", corsMiddleware(kShortestHandler))
+
+	log.Printf("Starting API server on %s", cfg.Addr)
+	if err := http.ListenAndServe(cfg.Addr, nil); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -154,6 +199,9 @@ func StartApi() {
 func cchHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	mu.RLock()
+	defer mu.RUnlock()
+
 	if cchInstance == nil {
 		http.Error(w, "CCH not initialized", http.StatusInternalServerError)
 		return
@@ -168,6 +216,9 @@ func cchHandler(w http.ResponseWriter, r *http.Request) {
 func chHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	mu.RLock()
+	defer mu.RUnlock()
+
 	if chInstance == nil {
 		http.Error(w, "CH not initialized", http.StatusInternalServerError)
 		return
@@ -179,59 +230,6 @@ func chHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func dijkstraQueryHandler(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-	log.Printf("Dijkstra query: from=%s, to=%s", fromStr, toStr)
-
-	from, err := strconv.Atoi(fromStr)
-	if err != nil {
-		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
-		return
-	}
-
-	to, err := strconv.Atoi(toStr)
-	if err != nil {
-		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
-		return
-	}
-
-	mu.RLock()
-	defer mu.RUnlock()
-
-	if cchNetwork == nil {
-		log.Println("cchNetwork is nil")
-		http.Error(w, "Graph not initialized", http.StatusInternalServerError)
-		return
-	}
-
-	start := time.Now()
-	path, weight, _, err := pathfinding.DijkstraShortestPath(cchNetwork, graph.VertexId(from), graph.VertexId(to), math.MaxFloat64)
-	duration := time.Since(start)
-	queryTimeMs := float64(duration.Nanoseconds()) / 1e6
-
-	if err != nil {
-		http.Error(w, "Query failed: no path found", http.StatusNotFound)
-		log.Printf("Dijkstra query failed: %v", err)
-		return
-	}
-
-	var pathEdges []PathEdge
-	for i := 0; i < len(path)-1; i++ {
-		u := path[i]
-		v := path[i+1]
-		edge, ok := cchNetwork.Edges[u][v]
-		if ok {
-			pathEdges = append(pathEdges, PathEdge{From: u, To: v, Weight: float64(edge.Weight), IsShortcut: false})
-		} else {
-			log.Printf("Warning: No edge found between %d and %d in cchNetwork for Dijkstra", u, v)
-		}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(QueryResponse{Path: pathEdges, Weight: weight, QueryTimeMs: queryTimeMs})
-}
-
 type PathEdge struct {
 	From       graph.VertexId `json:"From"`
 	To         graph.VertexId `json:"To"`
@@ -243,174 +241,185 @@ type QueryResponse struct {
 	Path        []PathEdge `json:"path"`
 	Weight      float64    `json:"weight"`
 	QueryTimeMs float64    `json:"queryTimeMs"`
+	Version     int        `json:"version"` // History version of the weights the engine answered with
+	Stale       bool       `json:"stale"`   // The engine does not reflect the latest edge updates yet
 }
 
-func cchQueryHandler(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-	log.Printf("CCH query: from=%s, to=%s", fromStr, toStr)
+var errEngineNotInitialized = errors.New("engine not initialized")
 
-	from, err := strconv.Atoi(fromStr)
-	if err != nil {
-		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
-		return
-	}
+// engineSelector picks the router that answers a query, together with the
+// metric version it reflects. It is called with mu held for reading.
+type engineSelector func(r *http.Request) (routing.Router, EngineStatus, error)
 
-	to, err := strconv.Atoi(toStr)
-	if err != nil {
-		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
-		return
-	}
+// queryHandler returns a handler answering from/to queries with the router
+// picked by selectEngine.
+func queryHandler(name string, opts routing.Options, selectEngine engineSelector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fromStr := r.URL.Query().Get("from")
+		toStr := r.URL.Query().Get("to")
+		log.Printf("%s query: from=%s, to=%s", name, fromStr, toStr)
 
-	if cchInstance == nil {
-		log.Println("cchInstance is nil")
-		http.Error(w, "CCH not initialized", http.StatusInternalServerError)
-		return
-	}
-	log.Printf("cchInstance is not nil, UpwardsGraph has %d vertices", len(cchInstance.UpwardsGraph.Vertices))
+		from, err := strconv.Atoi(fromStr)
+		if err != nil {
+			http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
+			return
+		}
 
-	start := time.Now()
-	path, weight, _, err := cchInstance.Query(graph.VertexId(from), graph.VertexId(to))
-	duration := time.Since(start)
-	queryTimeMs := float64(duration.Nanoseconds()) / 1e6
+		to, err := strconv.Atoi(toStr)
+		if err != nil {
+			http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
+			return
+		}
 
-	if err != nil {
-		http.Error(w, "Query failed: no path found", http.StatusNotFound)
-		log.Printf("CCH query failed: %v", err)
-		return
-	}
+		mu.RLock()
+		defer mu.RUnlock()
 
-	var pathEdges []PathEdge
-	for i := 0; i < len(path)-1; i++ {
-		u := path[i]
-		v := path[i+1]
+		router, status, err := selectEngine(r)
+		if errors.Is(err, errEngineNotInitialized) {
+			http.Error(w, name+" not initialized", http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			log.Printf("%s query rejected: %v", name, err)
+			return
+		}
+
+		workspace := queryWorkspaces.Get()
+		defer queryWorkspaces.Put(workspace)
+		opts := opts
+		opts.Workspace = workspace
 
-		edge, ok := cchInstance.UpwardsGraph.Edges[u][v]
-		if !ok {
-			edge, ok = cchInstance.DownwardsGraph.Edges[u][v]
+		route, err := router.Route(graph.VertexId(from), graph.VertexId(to), opts)
+		if err != nil {
+			http.Error(w, "Query failed: no path found", http.StatusNotFound)
+			log.Printf("%s query failed: %v", name, err)
+			return
 		}
 
-		if ok {
-			pathEdges = append(pathEdges, PathEdge{From: u, To: v, Weight: float64(edge.Weight), IsShortcut: edge.IsShortcut})
-		} else {
-			log.Printf("Warning: No edge found between %d and %d in CCH graphs for unpacked path", u, v)
+		pathEdges := make([]PathEdge, 0, len(route.Edges))
+		for _, edge := range route.Edges {
+			pathEdges = append(pathEdges, PathEdge{From: edge.From, To: edge.To, Weight: float64(edge.Weight), IsShortcut: edge.IsShortcut})
 		}
-	}
+		queryTimeMs := float64(route.Stats.Duration.Nanoseconds()) / 1e6
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(QueryResponse{Path: pathEdges, Weight: weight, QueryTimeMs: queryTimeMs})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(QueryResponse{Path: pathEdges, Weight: route.Cost, QueryTimeMs: queryTimeMs, Version: status.Version, Stale: status.Stale})
+	}
 }
 
-func chQueryHandler(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-
-	from, err := strconv.Atoi(fromStr)
-	if err != nil {
-		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
-		return
+// dijkstraEngine answers queries with Dijkstra on the current network. Avoid
+// constraints are answered on a copy of the network.
+func dijkstraEngine(r *http.Request) (routing.Router, EngineStatus, error) {
+	if cchNetwork == nil {
+		return nil, EngineStatus{}, errEngineNotInitialized
 	}
-
-	to, err := strconv.Atoi(toStr)
+	constraints, err := parseConstraints(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
-		return
+		return nil, EngineStatus{}, err
+	}
+	if constraints.IsEmpty() {
+		return routing.NewDijkstraRouter(cchNetwork), currentStatus(), nil
 	}
+	return routing.NewDijkstraRouter(constraints.Apply(cchNetwork)), currentStatus(), nil
+}
 
+// chEngine answers queries with the served CH, which may be stale while it is rebuilt.
+func chEngine(r *http.Request) (routing.Router, EngineStatus, error) {
 	if chInstance == nil {
-		http.Error(w, "CH not initialized", http.StatusInternalServerError)
-		return
+		return nil, EngineStatus{}, errEngineNotInitialized
 	}
-
-	start := time.Now()
-	path, weight, _, err := chInstance.Query(graph.VertexId(from), graph.VertexId(to))
-	duration := time.Since(start)
-	queryTimeMs := float64(duration.Nanoseconds()) / 1e6
-
+	constraints, err := parseConstraints(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Query failed: no path found", http.StatusNotFound)
-		log.Printf("CH query failed: %v", err)
-		return
+		return nil, EngineStatus{}, err
 	}
-
-	var pathEdges []PathEdge
-	for i := 0; i < len(path)-1; i++ {
-		u := path[i]
-		v := path[i+1]
-
-		edge, ok := chInstance.UpwardsGraph.Edges[u][v]
-		if !ok {
-			edge, ok = chInstance.DownwardsGraph.Edges[u][v]
-		}
-
-		if ok {
-			pathEdges = append(pathEdges, PathEdge{From: u, To: v, Weight: float64(edge.Weight), IsShortcut: edge.IsShortcut})
-		} else {
-			log.Printf("Warning: No edge found between %d and %d in CH graphs for unpacked path", u, v)
-		}
+	if !constraints.IsEmpty() {
+		return nil, EngineStatus{}, errors.New("the CH does not support avoid constraints, use the CCH or Dijkstra")
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(QueryResponse{Path: pathEdges, Weight: weight, QueryTimeMs: queryTimeMs})
+	return routing.NewCHRouter(chInstance), chStatus(), nil
 }
 
-func chQueryNoUnpackHandler(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-
-	from, err := strconv.Atoi(fromStr)
-	if err != nil {
-		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
-		return
+// cchEngine answers queries without a version or avoid constraints with the
+// current CCH. Queries for a past version or with avoid constraints are
+// answered by a copy customized with the weights of that version, where the
+// avoided edges have infinite weight. Concurrent queries are not affected.
+func cchEngine(r *http.Request) (routing.Router, EngineStatus, error) {
+	if cchInstance == nil {
+		return nil, EngineStatus{}, errEngineNotInitialized
 	}
-
-	to, err := strconv.Atoi(toStr)
+	constraints, err := parseConstraints(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
-		return
+		return nil, EngineStatus{}, err
 	}
 
-	if chInstance == nil {
-		http.Error(w, "CH not initialized", http.StatusInternalServerError)
-		return
+	metric, status := cchNetwork, currentStatus()
+	if versionStr := r.URL.Query().Get("version"); versionStr != "" {
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, EngineStatus{}, fmt.Errorf("invalid 'version' parameter: %w", err)
+		}
+		if version != history.Version() {
+			metric, err = networkAtVersion(version)
+			if err != nil {
+				return nil, EngineStatus{}, fmt.Errorf("failed to build CCH for version %d: %w", version, err)
+			}
+			status = EngineStatus{Version: version}
+		}
+	}
+	if metric == cchNetwork && constraints.IsEmpty() {
+		return routing.NewCCHRouter(cchInstance), status, nil
 	}
 
-	start := time.Now()
-	path, weight, _, err := chInstance.QueryNoUnpack(graph.VertexId(from), graph.VertexId(to))
-	duration := time.Since(start)
-	queryTimeMs := float64(duration.Nanoseconds()) / 1e6
+	overlay := cchInstance.Clone()
+	if err := overlay.Customize(constraints.Apply(metric)); err != nil {
+		return nil, EngineStatus{}, fmt.Errorf("failed to customize CCH copy: %w", err)
+	}
+	return routing.NewCCHRouter(overlay), status, nil
+}
 
+// networkAtVersion returns a copy of the network with the edge weights as they
+// were at the given history version. The caller must hold mu.
+func networkAtVersion(version int) (*graph.Graph, error) {
+	weights, err := history.WeightsAt(version)
 	if err != nil {
-		http.Error(w, "Query failed: no path found", http.StatusNotFound)
-		log.Printf("CH query failed: %v", err)
-		return
+		return nil, err
 	}
 
-	var pathEdges []PathEdge
-	for i := 0; i < len(path)-1; i++ {
-		u := path[i]
-		v := path[i+1]
-
-		edge, ok := chInstance.UpwardsGraph.Edges[u][v]
-		if !ok {
-			edge, ok = chInstance.DownwardsGraph.Edges[u][v]
-		}
-
-		if ok {
-			pathEdges = append(pathEdges, PathEdge{From: u, To: v, Weight: float64(edge.Weight), IsShortcut: edge.IsShortcut})
-		} else {
-			log.Printf("Warning: No edge found between %d and %d in CH graphs", u, v)
-		}
+	g := originalNetwork.Clone()
+	for key, weight := range weights {
+		g.UpdateEdge(key.from, key.to, weight, false, -1)
+		g.UpdateEdge(key.to, key.from, weight, false, -1)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(QueryResponse{Path: pathEdges, Weight: weight, QueryTimeMs: queryTimeMs})
+	return g, nil
 }
 
 type EdgeUpdate struct {
 	From   graph.VertexId `json:"from"`
 	To     graph.VertexId `json:"to"`
 	Weight string         `json:"weight"`
+	User   string         `json:"user,omitempty"`
+}
+
+// requestUser returns the user responsible for a change. The user given in the
+// request body takes precedence over the X-User header.
+func requestUser(r *http.Request, bodyUser string) string {
+	if bodyUser != "" {
+		return bodyUser
+	}
+	if user := r.Header.Get("X-User"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// applyWeight sets the weight of an undirected edge in cchNetwork. The caller must hold mu.
+func applyWeight(key edgeKey, weight int) {
+	if err := cchNetwork.UpdateEdge(key.from, key.to, weight, false, 0); err != nil {
+		log.Printf("Failed to update edge from %d to %d: %v", key.from, key.to, err)
+	}
+	if err := cchNetwork.UpdateEdge(key.to, key.from, weight, false, 0); err != nil {
+		log.Printf("Failed to update edge from %d to %d: %v", key.to, key.from, err)
+	}
 }
 
 func cchUpdateHandler(w http.ResponseWriter, r *http.Request) {
@@ -441,13 +450,23 @@ func cchUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Graph not initialized", http.StatusInternalServerError)
 		return
 	}
+	if cchInstance == nil {
+		http.Error(w, "CCH not initialized", http.StatusInternalServerError)
+		return
+	}
 
+	var changes []EdgeChange
 	for _, update := range updates {
 		var actualWeight int
 
+		if _, ok := cchNetwork.Edges[update.From][update.To]; !ok {
+			log.Printf("Failed to update edge from %d to %d: %v", update.From, update.To, graph.ErrEdgeNotFound)
+			continue
+		}
+
 		// Handle blocking/unblocking edges
 		if update.Weight == "inf" {
-			actualWeight = math.MaxInt32 // Use MaxInt32 to avoid overflow issues
+			actualWeight = graph.InfWeight
 		} else if update.Weight == "restore" {
 			// Restore original weight
 			key := edgeKey{from: update.From, to: update.To}
@@ -467,25 +486,87 @@ func cchUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Update both directions (undirected graph)
-		err := cchNetwork.UpdateEdge(update.From, update.To, actualWeight, false, 0)
-		if err != nil {
-			log.Printf("Failed to update edge from %d to %d: %v", update.From, update.To, err)
-		}
+		changes = append(changes, history.Update(update.From, update.To, actualWeight, requestUser(r, update.User)))
+		applyWeight(edgeKey{update.From, update.To}, actualWeight)
+	}
 
-		err = cchNetwork.UpdateEdge(update.To, update.From, actualWeight, false, 0)
-		if err != nil {
-			log.Printf("Failed to update edge from %d to %d: %v", update.To, update.From, err)
-		}
+	// Re-customize CCH with updated weights and rebuild the CH in the background
+	metricChanged()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"status": "success", "version": history.Version(), "changes": changes})
+}
+
+// historyHandler lists all recorded edge changes together with the current version.
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if history == nil {
+		http.Error(w, "History not initialized", http.StatusInternalServerError)
+		return
 	}
 
-	if cchInstance == nil {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"version": history.Version(), "changes": history.Entries()})
+}
+
+// undoHandler reverts a single edge update identified by its version.
+func undoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		http.Error(w, "Invalid 'version' parameter", http.StatusBadRequest)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if history == nil || cchInstance == nil {
+		http.Error(w, "CCH not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	change, err := history.Undo(version, requestUser(r, ""))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Failed to undo version %d: %v", version, err)
+		return
+	}
+
+	applyWeight(undirectedKey(change.From, change.To), change.NewWeight)
+	metricChanged()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"status": "success", "version": history.Version(), "changes": []EdgeChange{change}})
+}
+
+// resetHandler reverts all active edge updates and restores the original weights.
+func resetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if history == nil || cchInstance == nil {
 		http.Error(w, "CCH not initialized", http.StatusInternalServerError)
 		return
 	}
 
-	// Re-customize CCH with updated weights
-	cchInstance.Customize(cchNetwork)
+	change, affected := history.Reset(requestUser(r, ""))
+	for _, key := range affected {
+		applyWeight(key, history.originalWeight(key))
+	}
+	metricChanged()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	json.NewEncoder(w).Encode(map[string]any{"status": "success", "version": history.Version(), "changes": []EdgeChange{change}})
 }
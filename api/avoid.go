@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/avoid"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// parseConstraints reads the avoid constraints of a query. All parameters are
+// optional and avoidBox and avoidPolygon may be given several times:
+//
+//	avoidBox=minLat,minLon,maxLat,maxLon
+//	avoidPolygon=lat,lon;lat,lon;lat,lon
+//	avoidEdges=from-to,from-to
+//	avoidClasses=motorway,trunk
+func parseConstraints(query url.Values) (avoid.Constraints, error) {
+	var constraints avoid.Constraints
+
+	for _, value := range query["avoidBox"] {
+		coordinates, err := parseCoordinates(value)
+		if err != nil || len(coordinates) != 2 {
+			return avoid.Constraints{}, fmt.Errorf("invalid 'avoidBox' parameter %q", value)
+		}
+		box, err := avoid.NewBoundingBox(coordinates[0], coordinates[1])
+		if err != nil {
+			return avoid.Constraints{}, fmt.Errorf("invalid 'avoidBox' parameter %q: %w", value, err)
+		}
+		constraints.Areas = append(constraints.Areas, box)
+	}
+
+	for _, value := range query["avoidPolygon"] {
+		coordinates, err := parseCoordinates(value)
+		if err != nil {
+			return avoid.Constraints{}, fmt.Errorf("invalid 'avoidPolygon' parameter %q: %w", value, err)
+		}
+		polygon, err := avoid.NewPolygon(coordinates)
+		if err != nil {
+			return avoid.Constraints{}, fmt.Errorf("invalid 'avoidPolygon' parameter %q: %w", value, err)
+		}
+		constraints.Areas = append(constraints.Areas, polygon)
+	}
+
+	if value := query.Get("avoidEdges"); value != "" {
+		for _, pair := range strings.Split(value, ",") {
+			fromStr, toStr, ok := strings.Cut(pair, "-")
+			from, fromErr := strconv.Atoi(fromStr)
+			to, toErr := strconv.Atoi(toStr)
+			if !ok || fromErr != nil || toErr != nil {
+				return avoid.Constraints{}, fmt.Errorf("invalid edge %q in 'avoidEdges' parameter", pair)
+			}
+			constraints.Edges = append(constraints.Edges, avoid.Edge{From: graph.VertexId(from), To: graph.VertexId(to)})
+		}
+	}
+
+	if value := query.Get("avoidClasses"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			class, ok := graph.ParseRoadClass(name)
+			if !ok {
+				return avoid.Constraints{}, fmt.Errorf("unknown road class %q in 'avoidClasses' parameter", name)
+			}
+			constraints.RoadClasses = append(constraints.RoadClasses, class)
+		}
+	}
+
+	return constraints, nil
+}
+
+// parseCoordinates parses a list of latitudes and longitudes separated by
+// commas or semicolons into points.
+func parseCoordinates(value string) ([]avoid.Point, error) {
+	numbers := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' })
+	if len(numbers)%2 != 0 {
+		return nil, errors.New("odd number of coordinates")
+	}
+
+	points := make([]avoid.Point, 0, len(numbers)/2)
+	for i := 0; i < len(numbers); i += 2 {
+		lat, err := strconv.ParseFloat(strings.TrimSpace(numbers[i]), 64)
+		if err != nil {
+			return nil, err
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(numbers[i+1]), 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, avoid.Point{Lat: lat, Lon: lon})
+	}
+	return points, nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/avoid"
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestParseConstraints(t *testing.T) {
+	query := url.Values{
+		"avoidBox":     {"48.0,9.0,48.1,9.1"},
+		"avoidPolygon": {"48.0,9.0;48.1,9.0;48.0,9.1"},
+		"avoidEdges":   {"1-2,3-4"},
+		"avoidClasses": {"motorway,trunk"},
+	}
+	constraints, err := parseConstraints(query)
+	if err != nil {
+		t.Fatalf("parseConstraints failed: %v", err)
+	}
+	if len(constraints.Areas) != 2 || len(constraints.Edges) != 2 || len(constraints.RoadClasses) != 2 {
+		t.Errorf("got %+v, want two areas, two edges and two road classes", constraints)
+	}
+	if constraints.Edges[1] != (avoid.Edge{From: 3, To: 4}) {
+		t.Errorf("got edge %+v want 3-4", constraints.Edges[1])
+	}
+
+	for name, query := range map[string]url.Values{
+		"box with three values": {"avoidBox": {"48.0,9.0,48.1"}},
+		"inverted box":          {"avoidBox": {"48.1,9.1,48.0,9.0"}},
+		"polygon with 2 points": {"avoidPolygon": {"48.0,9.0;48.1,9.0"}},
+		"malformed edge":        {"avoidEdges": {"1:2"}},
+		"unknown class":         {"avoidClasses": {"runway"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseConstraints(query); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestQueryAvoiding(t *testing.T) {
+	setupParetoNetwork(t)
+	c := cch.NewCCH()
+	if err := c.PreprocessWithOrder(cchNetwork, []graph.VertexId{0, 1, 2, 3}); err != nil {
+		t.Fatalf("preprocessing failed: %v", err)
+	}
+	if err := c.Customize(cchNetwork); err != nil {
+		t.Fatalf("customization failed: %v", err)
+	}
+	oldCCH := cchInstance
+	cchInstance = c
+	t.Cleanup(func() { cchInstance = oldCCH })
+
+	query := func(handler http.HandlerFunc, target string) QueryResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", target, rec.Code, rec.Body.String())
+		}
+		var response QueryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"CCH":      queryHandler("CCH", routing.Options{}, cchEngine),
+		"Dijkstra": queryHandler("Dijkstra", routing.Options{}, dijkstraEngine),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := query(handler, "/q?from=0&to=3").Weight; got != 2 {
+				t.Errorf("got weight %f without constraints, want 2", got)
+			}
+			// Vertex 1 lies inside the box, so the route has to pass 2.
+			if got := query(handler, "/q?from=0&to=3&avoidBox=48.005,8.99,48.02,9.01").Weight; got != 10 {
+				t.Errorf("got weight %f avoiding the box, want 10", got)
+			}
+			if got := query(handler, "/q?from=0&to=3&avoidEdges=3-1").Weight; got != 10 {
+				t.Errorf("got weight %f avoiding the edge, want 10", got)
+			}
+			// The constraints of one query do not change the served metric.
+			if got := query(handler, "/q?from=0&to=3").Weight; got != 2 {
+				t.Errorf("got weight %f after a constrained query, want 2", got)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler := queryHandler("CCH", routing.Options{}, cchEngine)
+	handler(rec, httptest.NewRequest(http.MethodGet, "/q?from=0&to=3&avoidEdges=0-1,0-2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d when every route is avoided, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var (
+	ErrUnknownVersion = errors.New("unknown history version")
+	ErrNotUndoable    = errors.New("change is not an active edge update")
+)
+
+// Actions recorded in the edge update history.
+const (
+	ActionUpdate = "update"
+	ActionUndo   = "undo"
+	ActionReset  = "reset"
+)
+
+// EdgeChange is a single entry of the edge update history. Every entry gets a
+// new version number, so the history can be replayed up to any version.
+// Undo entries reference the version they revert, reset entries revert every
+// update that was active at the time.
+type EdgeChange struct {
+	Version   int            `json:"version"`
+	Action    string         `json:"action"`
+	From      graph.VertexId `json:"from"`
+	To        graph.VertexId `json:"to"`
+	OldWeight int            `json:"oldWeight"`
+	NewWeight int            `json:"newWeight"`
+	Reverts   int            `json:"reverts,omitempty"`
+	User      string         `json:"user"`
+	Time      time.Time      `json:"time"`
+}
+
+// changeLog is the append-only edge update history of the served network.
+// The weight of an edge at a given version is the weight of the latest update
+// that is still active at that version, or the original weight otherwise.
+type changeLog struct {
+	entries  []EdgeChange
+	original map[edgeKey]int
+	// active holds the active update versions of every edge touched so far at
+	// the latest version, so updates do not replay the whole history.
+	active map[edgeKey][]int
+}
+
+func newChangeLog(original map[edgeKey]int) *changeLog {
+	return &changeLog{original: original, active: make(map[edgeKey][]int)}
+}
+
+// undirectedKey normalizes an edge so that both directions of an undirected
+// edge share the same history.
+func undirectedKey(from, to graph.VertexId) edgeKey {
+	if from > to {
+		from, to = to, from
+	}
+	return edgeKey{from, to}
+}
+
+// Version returns the latest version of the history. Version 0 is the
+// original network without any changes.
+func (l *changeLog) Version() int {
+	return len(l.entries)
+}
+
+// Entries returns a copy of all history entries in version order.
+func (l *changeLog) Entries() []EdgeChange {
+	entries := make([]EdgeChange, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// activeAt returns, for every edge touched up to the given version, the
+// versions of its updates that are still active. Only older versions are
+// replayed from the start of the history.
+func (l *changeLog) activeAt(version int) map[edgeKey][]int {
+	if version == l.Version() {
+		return l.active
+	}
+	active := make(map[edgeKey][]int)
+	for _, entry := range l.entries[:version] {
+		applyEntry(active, entry)
+	}
+	return active
+}
+
+// applyEntry updates the active update versions of every edge for entry.
+func applyEntry(active map[edgeKey][]int, entry EdgeChange) {
+	key := undirectedKey(entry.From, entry.To)
+	switch entry.Action {
+	case ActionUpdate:
+		active[key] = append(active[key], entry.Version)
+	case ActionUndo:
+		versions := active[key]
+		for i, v := range versions {
+			if v == entry.Reverts {
+				active[key] = append(versions[:i:i], versions[i+1:]...)
+				break
+			}
+		}
+	case ActionReset:
+		for k := range active {
+			active[k] = nil
+		}
+	}
+}
+
+// WeightsAt returns the weight of every edge that was changed up to the given
+// version. Edges that were never changed keep their original weight and are
+// not part of the result.
+func (l *changeLog) WeightsAt(version int) (map[edgeKey]int, error) {
+	if version < 0 || version > l.Version() {
+		return nil, ErrUnknownVersion
+	}
+
+	weights := make(map[edgeKey]int)
+	for key, versions := range l.activeAt(version) {
+		if len(versions) == 0 {
+			weights[key] = l.originalWeight(key)
+			continue
+		}
+		weights[key] = l.entries[versions[len(versions)-1]-1].NewWeight
+	}
+	return weights, nil
+}
+
+// currentWeight returns the weight of an edge at the latest version.
+func (l *changeLog) currentWeight(key edgeKey) int {
+	versions := l.active[key]
+	if len(versions) == 0 {
+		return l.originalWeight(key)
+	}
+	return l.entries[versions[len(versions)-1]-1].NewWeight
+}
+
+func (l *changeLog) originalWeight(key edgeKey) int {
+	if weight, ok := l.original[key]; ok {
+		return weight
+	}
+	return l.original[edgeKey{key.to, key.from}]
+}
+
+func (l *changeLog) append(entry EdgeChange) EdgeChange {
+	entry.Version = l.Version() + 1
+	entry.Time = time.Now()
+	l.entries = append(l.entries, entry)
+	applyEntry(l.active, entry)
+	return entry
+}
+
+// Update records a weight change of an edge.
+func (l *changeLog) Update(from, to graph.VertexId, weight int, user string) EdgeChange {
+	old := l.currentWeight(undirectedKey(from, to))
+	return l.append(EdgeChange{Action: ActionUpdate, From: from, To: to, OldWeight: old, NewWeight: weight, User: user})
+}
+
+// Undo reverts the update with the given version. The edge falls back to the
+// latest update that is still active, or to its original weight.
+func (l *changeLog) Undo(version int, user string) (EdgeChange, error) {
+	if version < 1 || version > l.Version() {
+		return EdgeChange{}, ErrUnknownVersion
+	}
+	target := l.entries[version-1]
+	if target.Action != ActionUpdate {
+		return EdgeChange{}, ErrNotUndoable
+	}
+
+	key := undirectedKey(target.From, target.To)
+	isActive := false
+	for _, v := range l.active[key] {
+		if v == version {
+			isActive = true
+			break
+		}
+	}
+	if !isActive {
+		return EdgeChange{}, ErrNotUndoable
+	}
+
+	old := l.currentWeight(key)
+	entry := l.append(EdgeChange{Action: ActionUndo, From: target.From, To: target.To, OldWeight: old, Reverts: version, User: user})
+	entry.NewWeight = l.currentWeight(key)
+	l.entries[entry.Version-1] = entry
+	return entry, nil
+}
+
+// Reset reverts all active updates and returns the edges that were affected.
+func (l *changeLog) Reset(user string) (EdgeChange, []edgeKey) {
+	var affected []edgeKey
+	for key, versions := range l.active {
+		if len(versions) > 0 {
+			affected = append(affected, key)
+		}
+	}
+	return l.append(EdgeChange{Action: ActionReset, User: user}), affected
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func newTestChangeLog() *changeLog {
+	return newChangeLog(map[edgeKey]int{
+		{0, 1}: 1, {1, 0}: 1,
+		{1, 2}: 5, {2, 1}: 5,
+	})
+}
+
+func assertWeightAt(t *testing.T, l *changeLog, version int, key edgeKey, want int) {
+	t.Helper()
+	weights, err := l.WeightsAt(version)
+	if err != nil {
+		t.Fatalf("WeightsAt(%d) failed: %v", version, err)
+	}
+	got, ok := weights[key]
+	if !ok {
+		got = l.originalWeight(key)
+	}
+	if got != want {
+		t.Errorf("weight of %v at version %d: got %d, want %d", key, version, got, want)
+	}
+}
+
+func TestChangeLogUpdate(t *testing.T) {
+	l := newTestChangeLog()
+
+	first := l.Update(0, 1, 10, "alice")
+	second := l.Update(1, 0, 20, "bob")
+
+	if first.Version != 1 || second.Version != 2 {
+		t.Errorf("expected versions 1 and 2, got %d and %d", first.Version, second.Version)
+	}
+	if first.OldWeight != 1 || second.OldWeight != 10 {
+		t.Errorf("expected old weights 1 and 10, got %d and %d", first.OldWeight, second.OldWeight)
+	}
+
+	key := undirectedKey(0, 1)
+	assertWeightAt(t, l, 0, key, 1)
+	assertWeightAt(t, l, 1, key, 10)
+	assertWeightAt(t, l, 2, key, 20)
+}
+
+func TestChangeLogUndo(t *testing.T) {
+	l := newTestChangeLog()
+	key := undirectedKey(0, 1)
+
+	l.Update(0, 1, 10, "alice")
+	l.Update(0, 1, 20, "alice")
+
+	// Undoing the older update keeps the newer one in effect.
+	change, err := l.Undo(1, "bob")
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if change.NewWeight != 20 {
+		t.Errorf("expected weight 20 after undoing version 1, got %d", change.NewWeight)
+	}
+
+	change, err = l.Undo(2, "bob")
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if change.NewWeight != 1 {
+		t.Errorf("expected original weight 1 after undoing all updates, got %d", change.NewWeight)
+	}
+
+	if _, err := l.Undo(2, "bob"); !errors.Is(err, ErrNotUndoable) {
+		t.Errorf("expected ErrNotUndoable when undoing twice, got %v", err)
+	}
+	if _, err := l.Undo(3, "bob"); !errors.Is(err, ErrNotUndoable) {
+		t.Errorf("expected ErrNotUndoable when undoing an undo, got %v", err)
+	}
+	if _, err := l.Undo(42, "bob"); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("expected ErrUnknownVersion, got %v", err)
+	}
+
+	// The history still knows the weights of past versions.
+	assertWeightAt(t, l, 2, key, 20)
+	assertWeightAt(t, l, 3, key, 20)
+	assertWeightAt(t, l, 4, key, 1)
+}
+
+func TestChangeLogReset(t *testing.T) {
+	l := newTestChangeLog()
+
+	l.Update(0, 1, 10, "alice")
+	l.Update(1, 2, 7, "alice")
+
+	_, affected := l.Reset("bob")
+	if len(affected) != 2 {
+		t.Errorf("expected 2 affected edges, got %d", len(affected))
+	}
+
+	assertWeightAt(t, l, 2, undirectedKey(1, 2), 7)
+	assertWeightAt(t, l, 3, undirectedKey(0, 1), 1)
+	assertWeightAt(t, l, 3, undirectedKey(1, 2), 5)
+
+	if _, err := l.Undo(1, "bob"); !errors.Is(err, ErrNotUndoable) {
+		t.Errorf("expected ErrNotUndoable for an update reverted by reset, got %v", err)
+	}
+	if _, err := l.WeightsAt(4); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("expected ErrUnknownVersion, got %v", err)
+	}
+}
+
+func TestChangeLogIncrementalMatchesReplay(t *testing.T) {
+	l := newTestChangeLog()
+	keys := []edgeKey{undirectedKey(0, 1), undirectedKey(1, 2)}
+	rng := rand.New(rand.NewSource(1))
+
+	// snapshots[v] holds the weights at version v as seen at the time.
+	snapshots := []map[edgeKey]int{{keys[0]: 1, keys[1]: 5}}
+	for range 200 {
+		switch op := rng.Intn(10); {
+		case op < 6:
+			key := keys[rng.Intn(len(keys))]
+			l.Update(key.from, key.to, 1+rng.Intn(50), "alice")
+		case op < 9:
+			if _, err := l.Undo(1+rng.Intn(l.Version()), "bob"); err != nil {
+				continue
+			}
+		default:
+			l.Reset("carol")
+		}
+		snapshot := make(map[edgeKey]int)
+		for _, key := range keys {
+			snapshot[key] = l.currentWeight(key)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	for version, snapshot := range snapshots {
+		for key, want := range snapshot {
+			assertWeightAt(t, l, version, key, want)
+		}
+	}
+}
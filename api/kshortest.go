@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// maxK is the largest number of paths a k-shortest paths query may ask for.
+const maxK = 20
+
+type KShortestPath struct {
+	Path   []PathEdge `json:"path"`
+	Weight float64    `json:"weight"`
+}
+
+type KShortestResponse struct {
+	Paths       []KShortestPath `json:"paths"`
+	QueryTimeMs float64         `json:"queryTimeMs"`
+	Version     int             `json:"version"`
+}
+
+// kShortestHandler returns the k shortest loopless paths between from and to,
+// e.g. /api/kshortest?from=1&to=5&k=3. With engine=cch, the default, spur
+// paths are answered by the CCH whenever its shortest path avoids the excluded
+// vertices and edges; engine=dijkstra uses Dijkstra only. Avoid constraints
+// are honored with Dijkstra on the constrained network.
+func kShortestHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
+		return
+	}
+	k := 3
+	if kStr := query.Get("k"); kStr != "" {
+		k, err = strconv.Atoi(kStr)
+		if err != nil || k < 1 || k > maxK {
+			http.Error(w, "Invalid 'k' parameter, expected 1 to "+strconv.Itoa(maxK), http.StatusBadRequest)
+			return
+		}
+	}
+	engine := query.Get("engine")
+	if engine != "" && engine != "cch" && engine != "dijkstra" {
+		http.Error(w, "Unknown 'engine' parameter", http.StatusBadRequest)
+		return
+	}
+	constraints, err := parseConstraints(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if cchNetwork == nil {
+		http.Error(w, "Network not initialized", http.StatusInternalServerError)
+		return
+	}
+	g := cchNetwork
+	var oracle pathfinding.SpurOracle
+	if !constraints.IsEmpty() {
+		g = constraints.Apply(cchNetwork)
+	} else if engine != "dijkstra" && cchInstance != nil {
+		oracle = routing.SpurOracle(routing.NewCCHRouter(cchInstance), g)
+	}
+
+	start := time.Now()
+	paths, err := pathfinding.KShortestPaths(g, graph.VertexId(from), graph.VertexId(to), k, oracle)
+	duration := time.Since(start)
+	if err != nil {
+		http.Error(w, "Query failed: no path found", http.StatusNotFound)
+		log.Printf("k-shortest paths query failed: %v", err)
+		return
+	}
+
+	response := KShortestResponse{
+		Paths:       make([]KShortestPath, 0, len(paths)),
+		QueryTimeMs: float64(duration.Nanoseconds()) / 1e6,
+		Version:     history.Version(),
+	}
+	for _, p := range paths {
+		edges := make([]PathEdge, 0, len(p.Path))
+		for i := 0; i+1 < len(p.Path); i++ {
+			u, v := p.Path[i], p.Path[i+1]
+			edges = append(edges, PathEdge{From: u, To: v, Weight: float64(g.Edges[u][v].Weight)})
+		}
+		response.Paths = append(response.Paths, KShortestPath{Path: edges, Weight: p.Cost})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKShortestHandler(t *testing.T) {
+	setupParetoNetwork(t)
+
+	rec := httptest.NewRecorder()
+	kShortestHandler(rec, httptest.NewRequest(http.MethodGet, "/api/kshortest?from=0&to=3&k=5&engine=dijkstra", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response KShortestResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Paths) != 2 {
+		t.Fatalf("got %d paths, want the two loopless paths: %+v", len(response.Paths), response.Paths)
+	}
+	if response.Paths[0].Weight != 2 || response.Paths[1].Weight != 10 {
+		t.Errorf("got weights %f and %f, want 2 and 10", response.Paths[0].Weight, response.Paths[1].Weight)
+	}
+
+	for _, target := range []string{
+		"/api/kshortest?from=0&to=3&k=0",
+		"/api/kshortest?from=0&to=3&k=100",
+		"/api/kshortest?from=x&to=3",
+		"/api/kshortest?from=0&to=3&engine=ch",
+	} {
+		rec := httptest.NewRecorder()
+		kShortestHandler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
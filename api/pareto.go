@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/criteria"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// paretoMaxLabels bounds the work of a single Pareto query.
+const paretoMaxLabels = 200000
+
+// The cost vectors combine the current weight of an edge with its length.
+// They are derived lazily and cached until the next edge update.
+var (
+	paretoMu      sync.Mutex
+	paretoCosts   *criteria.Costs
+	paretoVersion int
+)
+
+type ParetoRoute struct {
+	Path  []PathEdge `json:"path"`
+	Costs []int      `json:"costs"`
+}
+
+type ParetoResponse struct {
+	Criteria    []string      `json:"criteria"`
+	Routes      []ParetoRoute `json:"routes"`
+	QueryTimeMs float64       `json:"queryTimeMs"`
+	Version     int           `json:"version"`
+}
+
+// currentCosts returns the cost vectors of cchNetwork. The caller must hold mu for reading.
+func currentCosts() *criteria.Costs {
+	paretoMu.Lock()
+	defer paretoMu.Unlock()
+
+	if paretoCosts == nil || paretoVersion != history.Version() {
+		paretoCosts = criteria.DistanceCosts(cchNetwork)
+		paretoVersion = history.Version()
+	}
+	return paretoCosts
+}
+
+// paretoHandler returns all Pareto-optimal routes between from and to with
+// respect to travel time and distance.
+func paretoHandler(w http.ResponseWriter, r *http.Request) {
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+	log.Printf("Pareto query: from=%s, to=%s", fromStr, toStr)
+
+	from, err := strconv.Atoi(fromStr)
+	if err != nil {
+		http.Error(w, "Invalid 'from' parameter", http.StatusBadRequest)
+		return
+	}
+
+	to, err := strconv.Atoi(toStr)
+	if err != nil {
+		http.Error(w, "Invalid 'to' parameter", http.StatusBadRequest)
+		return
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if cchNetwork == nil {
+		http.Error(w, "Network not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	costs := currentCosts()
+	start := time.Now()
+	routes, _, err := pathfinding.ParetoShortestPaths(cchNetwork, costs, graph.VertexId(from), graph.VertexId(to), paretoMaxLabels)
+	queryTime := time.Since(start)
+	if errors.Is(err, pathfinding.ErrTooManyLabels) {
+		http.Error(w, "Query failed: Pareto set too large", http.StatusUnprocessableEntity)
+		log.Printf("Pareto query failed: %v", err)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Query failed: no path found", http.StatusNotFound)
+		log.Printf("Pareto query failed: %v", err)
+		return
+	}
+
+	response := ParetoResponse{
+		Criteria:    costs.Names(),
+		Routes:      make([]ParetoRoute, 0, len(routes)),
+		QueryTimeMs: float64(queryTime.Nanoseconds()) / 1e6,
+		Version:     history.Version(),
+	}
+	for _, route := range routes {
+		pathEdges := make([]PathEdge, 0, len(route.Path))
+		for i := 0; i+1 < len(route.Path); i++ {
+			edge := cchNetwork.Edges[route.Path[i]][route.Path[i+1]]
+			pathEdges = append(pathEdges, PathEdge{From: route.Path[i], To: route.Path[i+1], Weight: float64(edge.Weight)})
+		}
+		response.Routes = append(response.Routes, ParetoRoute{Path: pathEdges, Costs: route.Costs})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
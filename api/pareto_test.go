@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// setupParetoNetwork serves a network with a fast route 0 -> 1 -> 3 and a
+// shorter but slower route 0 -> 2 -> 3.
+func setupParetoNetwork(t *testing.T) {
+	t.Helper()
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0, Lat: 48.000, Lon: 9.000})
+	g.AddVertex(graph.Vertex{Id: 1, Lat: 48.010, Lon: 9.000})
+	g.AddVertex(graph.Vertex{Id: 2, Lat: 48.001, Lon: 9.001})
+	g.AddVertex(graph.Vertex{Id: 3, Lat: 48.000, Lon: 9.002})
+	weights := make(map[edgeKey]int)
+	for _, e := range [][3]int{{0, 1, 1}, {1, 3, 1}, {0, 2, 5}, {2, 3, 5}} {
+		from, to := graph.VertexId(e[0]), graph.VertexId(e[1])
+		g.AddEdge(from, to, e[2], false, -1)
+		g.AddEdge(to, from, e[2], false, -1)
+		weights[edgeKey{from, to}] = e[2]
+		weights[edgeKey{to, from}] = e[2]
+	}
+
+	oldNetwork, oldHistory, oldCosts := cchNetwork, history, paretoCosts
+	cchNetwork, history, paretoCosts = g, newChangeLog(weights), nil
+	t.Cleanup(func() {
+		cchNetwork, history, paretoCosts = oldNetwork, oldHistory, oldCosts
+	})
+}
+
+func TestParetoHandler(t *testing.T) {
+	setupParetoNetwork(t)
+
+	rec := httptest.NewRecorder()
+	paretoHandler(rec, httptest.NewRequest(http.MethodGet, "/api/pareto/query?from=0&to=3", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response ParetoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if want := []string{"time", "distance"}; !reflect.DeepEqual(response.Criteria, want) {
+		t.Errorf("got criteria %v, want %v", response.Criteria, want)
+	}
+	if len(response.Routes) != 2 {
+		t.Fatalf("got %d routes, want 2: %+v", len(response.Routes), response.Routes)
+	}
+	if fast := response.Routes[0]; fast.Costs[0] != 2 || fast.Path[0].To != 1 {
+		t.Errorf("got %+v as the fastest route, want the route through 1", fast)
+	}
+	if short := response.Routes[1]; short.Costs[0] != 10 || short.Path[0].To != 2 {
+		t.Errorf("got %+v as the shortest route, want the route through 2", short)
+	}
+	if response.Routes[1].Costs[1] >= response.Routes[0].Costs[1] {
+		t.Error("expected the slower route to be shorter")
+	}
+}
+
+func TestParetoHandlerErrors(t *testing.T) {
+	setupParetoNetwork(t)
+	cchNetwork.AddVertex(graph.Vertex{Id: 4})
+
+	for query, want := range map[string]int{
+		"from=a&to=3": http.StatusBadRequest,
+		"from=0&to=b": http.StatusBadRequest,
+		"from=0&to=4": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		paretoHandler(rec, httptest.NewRequest(http.MethodGet, "/api/pareto/query?"+query, nil))
+		if rec.Code != want {
+			t.Errorf("%s: got status %d, want %d", query, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// The CH has no customization phase, so a weight change requires a complete
+// re-preprocessing. It runs in the background on a snapshot of the network
+// while queries are still answered by the previous, stale CH. When it is done
+// the new CH is swapped in. Updates arriving during a rebuild are coalesced
+// into a single follow-up rebuild.
+var (
+	chVersion        int  // History version the served CH was built from
+	chRebuilding     bool // A background rebuild is running
+	chRebuildPending bool // Another rebuild is needed once the running one finishes
+
+	// buildCH preprocesses the CH of a rebuild. Tests replace it to control
+	// when a rebuild finishes.
+	buildCH = func(g *graph.Graph) *ch.ContractionHierarchies {
+		chInst := ch.NewContractionHierarchies()
+		chInst.Preprocess(g)
+		return chInst
+	}
+)
+
+// EngineStatus describes which metric version an engine currently answers queries with.
+type EngineStatus struct {
+	Version    int  `json:"version"`
+	Stale      bool `json:"stale"`
+	Rebuilding bool `json:"rebuilding,omitempty"`
+}
+
+// scheduleCHRebuild rebuilds the CH for the current metric in the background.
+// The caller must hold mu for writing.
+func scheduleCHRebuild() {
+	if chRebuilding {
+		chRebuildPending = true
+		return
+	}
+	chRebuilding = true
+	go rebuildCH(cchNetwork.Clone(), history.Version())
+}
+
+// rebuildCH preprocesses a new CH on a snapshot of the network and swaps it in.
+func rebuildCH(g *graph.Graph, version int) {
+	log.Printf("Rebuilding CH for version %d in the background...", version)
+	start := time.Now()
+	chInst := buildCH(g)
+	log.Printf("Finished CH rebuild for version %d in %s", version, time.Since(start))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	chInstance = chInst
+	chVersion = version
+	chRebuilding = false
+	if chRebuildPending {
+		chRebuildPending = false
+		scheduleCHRebuild()
+	}
+}
+
+// metricChanged makes all engines pick up the current weights of cchNetwork.
+// Dijkstra works on cchNetwork directly, the CCH is re-customized immediately
+// and the CH is rebuilt in the background. The caller must hold mu for writing.
+func metricChanged() {
+	if err := cchInstance.Customize(cchNetwork); err != nil {
+		log.Printf("Failed to customize CCH: %v", err)
+	}
+	scheduleCHRebuild()
+}
+
+// chStatus returns the status of the served CH. The caller must hold mu.
+func chStatus() EngineStatus {
+	return EngineStatus{
+		Version:    chVersion,
+		Stale:      chVersion != history.Version(),
+		Rebuilding: chRebuilding,
+	}
+}
+
+// currentStatus returns the status of an engine that always reflects the
+// latest metric. The caller must hold mu.
+func currentStatus() EngineStatus {
+	return EngineStatus{Version: history.Version()}
+}
+
+// statusHandler reports the metric version of every engine.
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if history == nil {
+		http.Error(w, "History not initialized", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"version":  history.Version(),
+		"dijkstra": currentStatus(),
+		"cch":      currentStatus(),
+		"ch":       chStatus(),
+	})
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// setupRebuildNetwork serves the network of setupParetoNetwork with a CCH, a
+// CH of version 0 and a CH build that blocks until the test releases it. Every
+// started build sends the number of builds so far on the returned channel.
+func setupRebuildNetwork(t *testing.T) (started <-chan int, release chan<- struct{}) {
+	t.Helper()
+	setupParetoNetwork(t)
+
+	cchInst := cch.NewCCH()
+	if err := cchInst.PreprocessWithOrder(cchNetwork, []graph.VertexId{0, 1, 2, 3}); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	if err := cchInst.Customize(cchNetwork); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+	chInst := ch.NewContractionHierarchies()
+	chInst.Preprocess(cchNetwork.Clone())
+
+	startedBuilds, releaseBuild := make(chan int, 10), make(chan struct{})
+	builds := 0
+	oldCCH, oldCH, oldBuild := cchInstance, chInstance, buildCH
+	oldVersion, oldRebuilding, oldPending := chVersion, chRebuilding, chRebuildPending
+	cchInstance, chInstance, chVersion, chRebuilding, chRebuildPending = cchInst, chInst, 0, false, false
+	buildCH = func(g *graph.Graph) *ch.ContractionHierarchies {
+		builds++
+		startedBuilds <- builds
+		<-releaseBuild
+		return oldBuild(g)
+	}
+	t.Cleanup(func() {
+		cchInstance, chInstance, buildCH = oldCCH, oldCH, oldBuild
+		chVersion, chRebuilding, chRebuildPending = oldVersion, oldRebuilding, oldPending
+	})
+	return startedBuilds, releaseBuild
+}
+
+func postUpdate(t *testing.T, from, to graph.VertexId, weight any) {
+	t.Helper()
+	body := fmt.Sprintf(`[{"from": %d, "to": %d, "weight": "%v"}]`, from, to, weight)
+	rec := httptest.NewRecorder()
+	cchUpdateHandler(rec, httptest.NewRequest(http.MethodPost, "/api/cch/update", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update %d -> %d: got status %d: %s", from, to, rec.Code, rec.Body.String())
+	}
+}
+
+func assertCHStatus(t *testing.T, want EngineStatus) {
+	t.Helper()
+	mu.RLock()
+	got, pending := chStatus(), chRebuildPending
+	mu.RUnlock()
+	if got != want {
+		t.Errorf("got CH status %+v (pending %t), want %+v", got, pending, want)
+	}
+}
+
+// waitForRebuild waits until no CH rebuild is running.
+func waitForRebuild(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mu.RLock()
+		rebuilding := chRebuilding
+		mu.RUnlock()
+		if !rebuilding {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the CH rebuild did not finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queryCH answers a query with the served CH and checks the reported status.
+func queryCH(t *testing.T, from, to graph.VertexId, wantStatus EngineStatus) float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	target := fmt.Sprintf("/api/ch/query?from=%d&to=%d", from, to)
+	queryHandler("CH", routing.Options{}, chEngine)(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: got status %d: %s", target, rec.Code, rec.Body.String())
+	}
+	var response QueryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Version != wantStatus.Version || response.Stale != wantStatus.Stale {
+		t.Errorf("%s: answered by version %d (stale %t), want %d (stale %t)",
+			target, response.Version, response.Stale, wantStatus.Version, wantStatus.Stale)
+	}
+	return response.Weight
+}
+
+func TestCHRebuild(t *testing.T) {
+	started, release := setupRebuildNetwork(t)
+
+	// The first update starts a rebuild for version 1. Until it is done the CH
+	// of version 0 keeps answering with the old weights.
+	postUpdate(t, 0, 1, 10)
+	if build := <-started; build != 1 {
+		t.Fatalf("got build %d, want 1", build)
+	}
+	assertCHStatus(t, EngineStatus{Version: 0, Stale: true, Rebuilding: true})
+	if got := queryCH(t, 0, 3, EngineStatus{Version: 0, Stale: true}); got != 2 {
+		t.Errorf("stale CH: got distance %v, want 2", got)
+	}
+
+	// Two updates during the rebuild only mark a follow-up rebuild.
+	postUpdate(t, 1, 3, 10)
+	postUpdate(t, 0, 2, 2)
+	select {
+	case build := <-started:
+		t.Fatalf("build %d started while another one was running", build)
+	default:
+	}
+	mu.RLock()
+	pending := chRebuildPending
+	mu.RUnlock()
+	if !pending {
+		t.Error("expected a follow-up rebuild to be pending")
+	}
+
+	rec := httptest.NewRecorder()
+	statusHandler(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	var status struct {
+		Version int          `json:"version"`
+		CCH     EngineStatus `json:"cch"`
+		CH      EngineStatus `json:"ch"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status.Version != 3 || status.CCH != (EngineStatus{Version: 3}) || status.CH != (EngineStatus{Version: 0, Stale: true, Rebuilding: true}) {
+		t.Errorf("got status %+v", status)
+	}
+
+	// Finishing the first rebuild swaps in version 1 and starts exactly one
+	// follow-up rebuild for the latest version.
+	release <- struct{}{}
+	if build := <-started; build != 2 {
+		t.Fatalf("got build %d, want 2", build)
+	}
+	assertCHStatus(t, EngineStatus{Version: 1, Stale: true, Rebuilding: true})
+	if got := queryCH(t, 0, 3, EngineStatus{Version: 1, Stale: true}); got != 10 {
+		t.Errorf("CH of version 1: got distance %v, want 10", got)
+	}
+
+	release <- struct{}{}
+	waitForRebuild(t)
+	select {
+	case build := <-started:
+		t.Fatalf("unexpected build %d after the follow-up rebuild", build)
+	default:
+	}
+
+	assertCHStatus(t, EngineStatus{Version: 3})
+	for _, pair := range [][2]graph.VertexId{{0, 3}, {1, 2}, {3, 0}} {
+		want := queryCH(t, pair[0], pair[1], EngineStatus{Version: 3})
+		rec := httptest.NewRecorder()
+		target := fmt.Sprintf("/api/dijkstra/query?from=%d&to=%d", pair[0], pair[1])
+		queryHandler("Dijkstra", routing.Options{}, dijkstraEngine)(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var response QueryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if want != response.Weight {
+			t.Errorf("%d -> %d: CH distance %v, Dijkstra %v", pair[0], pair[1], want, response.Weight)
+		}
+	}
+	if got := queryCH(t, 0, 3, EngineStatus{Version: 3}); got != 7 {
+		t.Errorf("rebuilt CH: got distance %v, want 7", got)
+	}
+}
+
+func TestBlockEdge(t *testing.T) {
+	started, release := setupRebuildNetwork(t)
+
+	// Blocking 0 - 1 forces every engine onto the route over 2.
+	postUpdate(t, 0, 1, "inf")
+	<-started
+	release <- struct{}{}
+	waitForRebuild(t)
+
+	if w := cchNetwork.Edges[0][1].Weight; w != graph.InfWeight {
+		t.Errorf("got weight %d for the blocked edge, want InfWeight", w)
+	}
+	if entries := history.Entries(); len(entries) != 1 || entries[0].NewWeight != graph.InfWeight {
+		t.Errorf("got history %+v, want one entry with InfWeight", entries)
+	}
+	for name, engine := range map[string]engineSelector{"Dijkstra": dijkstraEngine, "CH": chEngine, "CCH": cchEngine} {
+		rec := httptest.NewRecorder()
+		queryHandler(name, routing.Options{}, engine)(rec, httptest.NewRequest(http.MethodGet, "/api/query?from=0&to=3", nil))
+		var response QueryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", name, err)
+		}
+		if response.Weight != 10 {
+			t.Errorf("%s: got distance %v around the blocked edge, want 10", name, response.Weight)
+		}
+	}
+}
+
+func TestUpdateWithoutCCH(t *testing.T) {
+	setupParetoNetwork(t)
+	oldCCH := cchInstance
+	cchInstance = nil
+	t.Cleanup(func() { cchInstance = oldCCH })
+
+	rec := httptest.NewRecorder()
+	body := `[{"from": 0, "to": 1, "weight": "10"}]`
+	cchUpdateHandler(rec, httptest.NewRequest(http.MethodPost, "/api/cch/update", strings.NewReader(body)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if history.Version() != 0 || cchNetwork.Edges[0][1].Weight != 1 {
+		t.Errorf("a failed update changed the history (version %d) or the network (weight %d)",
+			history.Version(), cchNetwork.Edges[0][1].Weight)
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	"github.com/PaulMue0/efficient-routeplanning/internal/tour"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// routeEngines are the engines a multi-stop route can be answered with.
+var routeEngines = map[string]engineSelector{
+	"cch":      cchEngine,
+	"ch":       chEngine,
+	"dijkstra": dijkstraEngine,
+}
+
+// LegResponse is the route between two consecutive waypoints.
+type LegResponse struct {
+	From   graph.VertexId `json:"from"`
+	To     graph.VertexId `json:"to"`
+	Weight float64        `json:"weight"`
+	Path   []PathEdge     `json:"path"`
+}
+
+type RouteResponse struct {
+	Waypoints   []graph.VertexId `json:"waypoints"` // Waypoints in visiting order
+	Order       []int            `json:"order"`     // Index of every visited waypoint in the request
+	Legs        []LegResponse    `json:"legs"`
+	Weight      float64          `json:"weight"`
+	QueryTimeMs float64          `json:"queryTimeMs"`
+	Version     int              `json:"version"`
+	Stale       bool             `json:"stale"`
+}
+
+// routeHandler answers routes through an ordered list of waypoints, e.g.
+// /api/route?waypoints=1,5,9&engine=cch&optimize=true. With optimize=true the
+// intermediate waypoints are reordered to minimize the total weight while the
+// first and last waypoint stay in place. The engine defaults to the CCH and
+// its parameters, e.g. avoid constraints, are honored for every leg.
+func routeHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var waypoints []graph.VertexId
+	for _, field := range strings.Split(query.Get("waypoints"), ",") {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			http.Error(w, "Invalid 'waypoints' parameter", http.StatusBadRequest)
+			return
+		}
+		waypoints = append(waypoints, graph.VertexId(id))
+	}
+
+	engine := query.Get("engine")
+	if engine == "" {
+		engine = "cch"
+	}
+	selectEngine, ok := routeEngines[engine]
+	if !ok {
+		http.Error(w, "Unknown 'engine' parameter", http.StatusBadRequest)
+		return
+	}
+	plan := tour.Plan
+	if optimize, _ := strconv.ParseBool(query.Get("optimize")); optimize {
+		plan = tour.PlanOptimized
+	}
+	log.Printf("Route query: engine=%s, waypoints=%v", engine, waypoints)
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	router, status, err := selectEngine(r)
+	if errors.Is(err, errEngineNotInitialized) {
+		http.Error(w, engine+" not initialized", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workspace := queryWorkspaces.Get()
+	defer queryWorkspaces.Put(workspace)
+
+	t, err := plan(router, waypoints, routing.Options{Workspace: workspace})
+	if errors.Is(err, tour.ErrTooFewWaypoints) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Query failed: no route through all waypoints", http.StatusNotFound)
+		log.Printf("Route query failed: %v", err)
+		return
+	}
+
+	response := RouteResponse{
+		Waypoints:   t.Waypoints,
+		Order:       t.Order,
+		Legs:        make([]LegResponse, 0, len(t.Legs)),
+		Weight:      t.Cost,
+		QueryTimeMs: float64(t.Stats.Duration.Nanoseconds()) / 1e6,
+		Version:     status.Version,
+		Stale:       status.Stale,
+	}
+	for _, leg := range t.Legs {
+		path := make([]PathEdge, 0, len(leg.Route.Edges))
+		for _, edge := range leg.Route.Edges {
+			path = append(path, PathEdge{From: edge.From, To: edge.To, Weight: float64(edge.Weight), IsShortcut: edge.IsShortcut})
+		}
+		response.Legs = append(response.Legs, LegResponse{From: leg.From, To: leg.To, Weight: leg.Route.Cost, Path: path})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestRouteHandler(t *testing.T) {
+	setupParetoNetwork(t)
+
+	testCases := []struct {
+		target        string
+		wantWaypoints []graph.VertexId
+		wantLegs      []float64
+	}{
+		{"/api/route?engine=dijkstra&waypoints=0,3,2", []graph.VertexId{0, 3, 2}, []float64{2, 5}},
+		{"/api/route?engine=dijkstra&waypoints=0,3,1,2&optimize=true", []graph.VertexId{0, 1, 3, 2}, []float64{1, 1, 5}},
+	}
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		routeHandler(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", tc.target, rec.Code, rec.Body.String())
+		}
+
+		var response RouteResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		legs := make([]float64, 0, len(response.Legs))
+		for _, leg := range response.Legs {
+			legs = append(legs, leg.Weight)
+		}
+		if !reflect.DeepEqual(response.Waypoints, tc.wantWaypoints) || !reflect.DeepEqual(legs, tc.wantLegs) {
+			t.Errorf("%s: got waypoints %v with legs %v, want %v with legs %v",
+				tc.target, response.Waypoints, legs, tc.wantWaypoints, tc.wantLegs)
+		}
+	}
+
+	for _, target := range []string{
+		"/api/route?engine=dijkstra&waypoints=0",
+		"/api/route?engine=dijkstra&waypoints=0,x",
+		"/api/route?engine=flight&waypoints=0,3",
+	} {
+		rec := httptest.NewRecorder()
+		routeHandler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
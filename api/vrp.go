@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/vrp"
+)
+
+// defaultVRPTimeLimit bounds the local search of requests without a time limit.
+const defaultVRPTimeLimit = 2 * time.Second
+
+// maxVRPTimeLimit is the longest time limit a request may ask for.
+const maxVRPTimeLimit = 30 * time.Second
+
+type VRPRequest struct {
+	vrp.Problem
+	TimeLimitMs int `json:"timeLimitMs"`
+}
+
+type VRPResponse struct {
+	vrp.Solution
+	SolveTimeMs float64 `json:"solveTimeMs"`
+	Version     int     `json:"version"`
+	Stale       bool    `json:"stale"`
+}
+
+// vrpHandler assigns the stops of a posted vehicle routing problem to its
+// vehicles. The distance matrix is computed with the engine given by the
+// engine parameter, the CCH by default, while holding the read lock; the
+// solver itself runs without the lock, so updates are not blocked by it.
+func vrpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request VRPRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding VRP request: %v", err)
+		return
+	}
+	if err := request.Problem.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	timeLimit := defaultVRPTimeLimit
+	if request.TimeLimitMs > 0 {
+		timeLimit = min(time.Duration(request.TimeLimitMs)*time.Millisecond, maxVRPTimeLimit)
+	}
+
+	engine := r.URL.Query().Get("engine")
+	if engine == "" {
+		engine = "cch"
+	}
+	selectEngine, ok := routeEngines[engine]
+	if !ok {
+		http.Error(w, "Unknown 'engine' parameter", http.StatusBadRequest)
+		return
+	}
+
+	mu.RLock()
+	router, status, err := selectEngine(r)
+	var matrix [][]float64
+	if err == nil {
+		matrix, err = request.Problem.Matrix(router)
+	}
+	mu.RUnlock()
+	if errors.Is(err, errEngineNotInitialized) {
+		http.Error(w, engine+" not initialized", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("VRP matrix failed: %v", err)
+		return
+	}
+
+	start := time.Now()
+	solution, err := vrp.Solve(request.Problem, matrix, vrp.Options{TimeLimit: timeLimit})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("VRP solved: %d stops, %d vehicles, cost %f, %d unassigned",
+		len(request.Stops), len(request.Vehicles), solution.Cost, len(solution.Unassigned))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VRPResponse{
+		Solution:    solution,
+		SolveTimeMs: float64(time.Since(start).Nanoseconds()) / 1e6,
+		Version:     status.Version,
+		Stale:       status.Stale,
+	})
+}
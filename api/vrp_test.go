@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVRPHandler(t *testing.T) {
+	setupParetoNetwork(t)
+
+	body := `{
+		"vehicles": [{"depot": 0, "capacity": 2}],
+		"stops": [{"vertex": 3, "demand": 1}, {"vertex": 1, "demand": 1}, {"vertex": 2, "demand": 5}],
+		"timeLimitMs": 100
+	}`
+	rec := httptest.NewRecorder()
+	vrpHandler(rec, httptest.NewRequest(http.MethodPost, "/api/vrp?engine=dijkstra", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response VRPResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Routes) != 1 || len(response.Routes[0].Stops) != 2 || response.Cost != 4 {
+		t.Errorf("got %+v, want one route serving two stops with cost 4", response.Solution)
+	}
+	if len(response.Unassigned) != 1 || response.Unassigned[0] != 2 {
+		t.Errorf("got unassigned %v, want the stop exceeding the capacity", response.Unassigned)
+	}
+
+	for name, request := range map[string]*http.Request{
+		"method":      httptest.NewRequest(http.MethodGet, "/api/vrp", nil),
+		"body":        httptest.NewRequest(http.MethodPost, "/api/vrp", strings.NewReader("{")),
+		"no vehicles": httptest.NewRequest(http.MethodPost, "/api/vrp", strings.NewReader(`{"stops": []}`)),
+	} {
+		rec := httptest.NewRecorder()
+		vrpHandler(rec, request)
+		if rec.Code < 400 {
+			t.Errorf("%s: got status %d, want an error", name, rec.Code)
+		}
+	}
+}
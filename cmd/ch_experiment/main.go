@@ -10,8 +10,10 @@ import (
 )
 
 func main() {
-	experiment := flag.String("experiment", "ch", "The experiment to run (ch, query, cch_preprocess, cch_customization or cch_query)")
+	experiment := flag.String("experiment", "ch", "The experiment to run (ch, query, cch_preprocess, cch_customization, cch_query, hub_labels, tnr, arcflags, ch_order or benchmark)")
+	seed := flag.Int64("seed", experiments.Seed, "Seed of the random queries and weights")
 	flag.Parse()
+	experiments.Seed = *seed
 
 	switch *experiment {
 	case "ch":
@@ -24,8 +26,18 @@ func main() {
 		experiments.RunCCHCustomizationExperiment()
 	case "cch_query":
 		experiments.RunCCHQueryExperiment()
+	case "hub_labels":
+		experiments.RunHubLabelExperiment()
+	case "tnr":
+		experiments.RunTNRExperiment()
+	case "arcflags":
+		experiments.RunArcFlagsExperiment()
+	case "ch_order":
+		experiments.RunCHOrderExperiment()
+	case "benchmark":
+		experiments.RunBenchmarkExperiment()
 	default:
-		fmt.Println("Invalid experiment specified. Use 'ch', 'query', 'cch_preprocess', 'cch_customization' or 'cch_query'.")
+		fmt.Println("Invalid experiment specified. Use 'ch', 'query', 'cch_preprocess', 'cch_customization', 'cch_query', 'hub_labels', 'tnr', 'arcflags', 'ch_order' or 'benchmark'.")
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/bench"
+)
+
+func runBench(args []string) error {
+	flags, asJSON := newFlagSet("bench", "bench (-graph <network.txt> | -ch <file.gob> | -cch <file.gob>) [-queries 1000 | -ranks 100 | -query-file <file>] [-seed 1]")
+	engineFlags := addEngineFlags(flags)
+	numQueries := flags.Int("queries", 1000, "The number of random queries")
+	ranks := flags.Int("ranks", 0, "Run Dijkstra rank queries from this many random sources instead of random queries; needs -graph")
+	seed := flags.Int64("seed", 1, "The seed of the queries")
+	warmup := flags.Int("warmup", 100, "The number of queries run before measuring")
+	queryFile := flags.String("query-file", "", "Read the queries from this file, or write the drawn queries to it if it does not exist")
+	csvPath := flags.String("csv", "", "Also write the results as CSV to this file")
+	flags.Parse(args)
+	if *numQueries <= 0 || *ranks < 0 || *warmup < 0 {
+		return usagef("-queries must be positive, -ranks and -warmup not negative")
+	}
+
+	e, err := engineFlags.load()
+	if err != nil {
+		return err
+	}
+	if *ranks > 0 && e.network == nil {
+		return usagef("-ranks needs -graph")
+	}
+
+	queries, err := benchQueries(e, *queryFile, func() []bench.Query {
+		if *ranks > 0 {
+			return bench.RankQueries(e.network, *ranks, *seed)
+		}
+		return bench.RandomQueries(e.searchGraph, *numQueries, *seed)
+	})
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries to run")
+	}
+
+	m := bench.Measure(e.name, e.router, queries, bench.Options{Warmup: *warmup})
+	results := append([]bench.Result{m.Summary()}, m.ByRank()...)
+	graphName := cmp.Or(*engineFlags.graphPath, *engineFlags.chPath, *engineFlags.cchPath)
+	querySet := *queryFile
+	if querySet == "" {
+		querySet = "random"
+		if *ranks > 0 {
+			querySet = "rank"
+		}
+	}
+	for i := range results {
+		results[i].Graph, results[i].QuerySet = graphName, querySet
+	}
+	if *csvPath != "" {
+		if err := createFile(*csvPath, func(w io.Writer) error { return bench.WriteCSV(w, results) }); err != nil {
+			return err
+		}
+	}
+
+	return output(*asJSON, results, func(w io.Writer) {
+		fmt.Fprintf(w, "%-5s %8s %12s %10s %10s %10s %10s %12s %12s\n",
+			"Rank", "Queries", "Unreachable", "Mean(ms)", "P50(ms)", "P95(ms)", "P99(ms)", "NodesPopped", "Allocs/Query")
+		for _, r := range results {
+			rank := "all"
+			if r.Rank != bench.RandomRank {
+				rank = fmt.Sprint(r.Rank)
+			}
+			fmt.Fprintf(w, "%-5s %8d %12d %10.4f %10.4f %10.4f %10.4f %12.1f %12.1f\n",
+				rank, r.Queries, r.Unreachable, r.MeanMs, r.P50Ms, r.P95Ms, r.P99Ms, r.AvgNodesPopped, r.AllocsPerQuery)
+		}
+		fmt.Fprintf(w, "%s, peak heap %.1f MB\n", e.name, float64(m.PeakHeapBytes)/(1<<20))
+	})
+}
+
+// benchQueries reads the queries from path if it exists. Otherwise it draws
+// them with generate and, if path is set, writes them there.
+func benchQueries(e *engine, path string, generate func() []bench.Query) ([]bench.Query, error) {
+	if path != "" {
+		file, err := os.Open(path)
+		if err == nil {
+			defer file.Close()
+			return bench.ReadQueries(e.searchGraph, file)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	queries := generate()
+	if path != "" {
+		if err := createFile(path, func(w io.Writer) error { return bench.WriteQueries(w, queries) }); err != nil {
+			return nil, err
+		}
+	}
+	return queries, nil
+}
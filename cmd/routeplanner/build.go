@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/vehicle"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+type importResult struct {
+	Input    string `json:"input"`
+	Output   string `json:"output"`
+	Vertices int    `json:"vertices"`
+	Edges    int    `json:"edges"`
+	Turns    string `json:"turns,omitempty"`
+	// ForbiddenTurns counts the turns forbidden by restriction relations.
+	ForbiddenTurns int `json:"forbiddenTurns,omitempty"`
+}
+
+func runImport(args []string) error {
+	flags, asJSON := newFlagSet("import", "import -osm <file.osm> -out <network.txt> [-turns <turns.txt>]")
+	osmPath := flags.String("osm", "", "The OSM XML file")
+	outPath := flags.String("out", "", "The road network in the text format")
+	turnsPath := flags.String("turns", "", "Write the turn restrictions of the restriction relations as a turn table")
+	flags.Parse(args)
+	if err := required("osm", *osmPath, "out", *outPath); err != nil {
+		return err
+	}
+
+	file, err := os.Open(*osmPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	network, table, _, err := parser.ImportOSMWithRestrictions(file)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", *osmPath, err)
+	}
+	if err := createFile(*outPath, func(w io.Writer) error { return parser.WriteNetwork(w, network.Network) }); err != nil {
+		return err
+	}
+
+	result := importResult{Input: *osmPath, Output: *outPath, Vertices: network.NumNodes, Edges: network.NumEdges}
+	if *turnsPath != "" {
+		if err := createFile(*turnsPath, func(w io.Writer) error { return parser.WriteTurnTable(w, network.Network, table) }); err != nil {
+			return err
+		}
+		result.Turns, result.ForbiddenTurns = *turnsPath, len(table.Entries(network.Network))
+	}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Imported %d vertices and %d edges into %s\n", result.Vertices, result.Edges, result.Output)
+		if result.Turns != "" {
+			fmt.Fprintf(w, "Wrote %d forbidden turns to %s\n", result.ForbiddenTurns, result.Turns)
+		}
+	})
+}
+
+type orderResult struct {
+	Graph      string  `json:"graph"`
+	Output     string  `json:"output"`
+	Vertices   int     `json:"vertices"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+func runOrder(args []string) error {
+	flags, asJSON := newFlagSet("order", "order -graph <network.txt> -out <file.ordering>")
+	graphPath := flags.String("graph", "", "The road network in the text format")
+	outPath := flags.String("out", "", "The nested dissection order in the KaHIP format")
+	flags.Parse(args)
+	if err := required("graph", *graphPath, "out", *outPath); err != nil {
+		return err
+	}
+
+	network, err := loadNetwork(*graphPath)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	order := cch.NestedDissection(network.Network)
+	duration := time.Since(start)
+	if err := createFile(*outPath, func(w io.Writer) error { return parser.WriteOrdering(w, network.Network, order) }); err != nil {
+		return err
+	}
+
+	result := orderResult{Graph: *graphPath, Output: *outPath, Vertices: len(order), DurationMs: milliseconds(duration)}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Ordered %d vertices in %s, written to %s\n", result.Vertices, duration, result.Output)
+	})
+}
+
+type preprocessResult struct {
+	Kind       string  `json:"kind"`
+	Graph      string  `json:"graph"`
+	Ordering   string  `json:"ordering,omitempty"`
+	Output     string  `json:"output"`
+	Vertices   int     `json:"vertices"`
+	Shortcuts  int     `json:"shortcuts"`
+	CoreSize   int     `json:"coreSize,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+func runPreprocess(args []string) error {
+	if len(args) == 0 || (args[0] != "ch" && args[0] != "cch") {
+		return usagef("preprocess needs the kind of hierarchy, ch or cch")
+	}
+	kind := args[0]
+
+	flags, asJSON := newFlagSet("preprocess "+kind, "preprocess ch|cch -graph <network.txt> -out <file.gob> [-ordering <file.ordering>]")
+	graphPath := flags.String("graph", "", "The road network in the text format")
+	outPath := flags.String("out", "", "The preprocessed hierarchy")
+	orderingPath := flags.String("ordering", "", "A contraction order in the KaHIP format; a CH contracts greedily without one, a CCH uses a computed nested dissection order")
+	coreSize := flags.Int("core-size", 0, "CH only: stop contracting once at most this many vertices are left")
+	coreDegree := flags.Float64("core-degree", 0, "CH only: stop contracting once the average degree reaches this value")
+	flags.Parse(args[1:])
+	if err := required("graph", *graphPath, "out", *outPath); err != nil {
+		return err
+	}
+	if kind == "cch" && (*coreSize != 0 || *coreDegree != 0) {
+		return usagef("a CCH has no core")
+	}
+
+	network, err := loadNetwork(*graphPath)
+	if err != nil {
+		return err
+	}
+	g := network.Network
+
+	var order []graph.VertexId
+	if *orderingPath != "" {
+		if order, err = readOrdering(g, *orderingPath); err != nil {
+			return err
+		}
+	}
+
+	result := preprocessResult{Kind: kind, Graph: *graphPath, Ordering: *orderingPath, Output: *outPath, Vertices: len(g.Vertices)}
+	start := time.Now()
+	if kind == "ch" {
+		c := ch.NewContractionHierarchies(ch.WithDeterminism(), ch.WithCore(*coreSize, *coreDegree))
+		if order != nil {
+			err = c.PreprocessWithOrder(g, order)
+		} else {
+			c.Preprocess(g)
+		}
+		if err != nil {
+			return fmt.Errorf("CH preprocessing failed: %w", err)
+		}
+		result.DurationMs = milliseconds(time.Since(start))
+		result.Shortcuts = c.NumShortcutsAdded
+		if c.HasCore() {
+			result.CoreSize = len(c.Core.Vertices)
+		}
+		err = preprocessed_graph.FromCH(c).WriteCH(*outPath)
+	} else {
+		if order == nil {
+			order = cch.NestedDissection(g)
+		}
+		c := cch.NewCCH()
+		if err := c.PreprocessWithOrder(g, order); err != nil {
+			return fmt.Errorf("CCH preprocessing failed: %w", err)
+		}
+		result.DurationMs = milliseconds(time.Since(start))
+		result.Shortcuts = c.ShortcutsAdded
+		err = preprocessed_graph.FromCCH(c).Write(*outPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", *outPath, err)
+	}
+
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Preprocessed %s of %d vertices with %d shortcuts in %.3f ms, written to %s\n",
+			kind, result.Vertices, result.Shortcuts, result.DurationMs, result.Output)
+		if result.CoreSize > 0 {
+			fmt.Fprintf(w, "Core of %d vertices\n", result.CoreSize)
+		}
+	})
+}
+
+type customizeResult struct {
+	Graph      string  `json:"graph"`
+	CCH        string  `json:"cch"`
+	Profile    string  `json:"profile,omitempty"`
+	Output     string  `json:"output"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+func runCustomize(args []string) error {
+	flags, asJSON := newFlagSet("customize", "customize -graph <network.txt> -cch <file.gob> -out <file.gob> [-profile car]")
+	graphPath := flags.String("graph", "", "The road network the CCH was preprocessed for")
+	cchPath := flags.String("cch", "", "The CCH written by preprocess cch")
+	outPath := flags.String("out", "", "The customized CCH")
+	profile := flags.String("profile", "", fmt.Sprintf("Customize with the travel times of a vehicle profile (one of %v) instead of the weights of the network", vehicle.Names()))
+	flags.Parse(args)
+	if err := required("graph", *graphPath, "cch", *cchPath, "out", *outPath); err != nil {
+		return err
+	}
+
+	network, err := loadNetwork(*graphPath)
+	if err != nil {
+		return err
+	}
+	file, err := preprocessed_graph.ReadCCH(*cchPath)
+	if err != nil {
+		return fmt.Errorf("failed to read CCH %s: %w", *cchPath, err)
+	}
+	c := file.ToCCH()
+
+	start := time.Now()
+	if *profile != "" {
+		p, err := vehicle.ByName(*profile)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		err = c.CustomizeProfile(network.Network, p)
+	} else {
+		err = c.Customize(network.Network)
+	}
+	if err != nil {
+		return fmt.Errorf("CCH customization failed: %w", err)
+	}
+	duration := time.Since(start)
+	if err := preprocessed_graph.FromCCH(c).Write(*outPath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *outPath, err)
+	}
+
+	result := customizeResult{Graph: *graphPath, CCH: *cchPath, Profile: *profile, Output: *outPath, DurationMs: milliseconds(duration)}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Customized %s in %s, written to %s\n", result.CCH, duration, result.Output)
+	})
+}
+
+type exportResult struct {
+	Graph  string `json:"graph"`
+	Format string `json:"format"`
+	Output string `json:"output"`
+}
+
+func runExport(args []string) error {
+	flags, asJSON := newFlagSet("export", "export -graph <network.txt> -format network|json|metis|ordering -out <file> [-ch <file.gob> | -cch <file.gob>]")
+	graphPath := flags.String("graph", "", "The road network in the text format")
+	format := flags.String("format", "", "network, json, metis (the input of KaHIP) or ordering (the contraction order of -ch or -cch)")
+	outPath := flags.String("out", "", "The output file")
+	chPath := flags.String("ch", "", "The CH whose order is exported with -format ordering")
+	cchPath := flags.String("cch", "", "The CCH whose order is exported with -format ordering")
+	flags.Parse(args)
+	if err := required("graph", *graphPath, "format", *format, "out", *outPath); err != nil {
+		return err
+	}
+	if (*format == "ordering") != (*chPath != "" || *cchPath != "") || (*chPath != "" && *cchPath != "") {
+		return usagef("-format ordering needs exactly one of -ch and -cch, the other formats neither")
+	}
+
+	network, err := loadNetwork(*graphPath)
+	if err != nil {
+		return err
+	}
+	g := network.Network
+
+	var write func(io.Writer) error
+	switch *format {
+	case "network":
+		write = func(w io.Writer) error { return parser.WriteNetwork(w, g) }
+	case "json":
+		write = func(w io.Writer) error {
+			data, err := parser.ToJSON(g)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		}
+	case "metis":
+		write = func(w io.Writer) error { return parser.ToMetis(g, w) }
+	case "ordering":
+		var order []graph.VertexId
+		if *chPath != "" {
+			file, err := preprocessed_graph.ReadCHFile(*chPath)
+			if err != nil {
+				return fmt.Errorf("failed to read CH %s: %w", *chPath, err)
+			}
+			order = file.ToCH().FullOrder()
+		} else {
+			file, err := preprocessed_graph.ReadCCH(*cchPath)
+			if err != nil {
+				return fmt.Errorf("failed to read CCH %s: %w", *cchPath, err)
+			}
+			order = file.ToCCH().ContractionOrder
+		}
+		write = func(w io.Writer) error { return parser.WriteOrdering(w, g, order) }
+	default:
+		return usagef("unknown format %q", *format)
+	}
+	if err := createFile(*outPath, write); err != nil {
+		return err
+	}
+
+	result := exportResult{Graph: *graphPath, Format: *format, Output: *outPath}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Exported %s as %s to %s\n", result.Graph, result.Format, result.Output)
+	})
+}
+
+// readOrdering reads the contraction order of g in the KaHIP format at path.
+func readOrdering(g *graph.Graph, path string) ([]graph.VertexId, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	order, err := parser.ReadOrdering(g, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ordering %s: %w", path, err)
+	}
+	return order, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/generate"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+type generateResult struct {
+	Type     string `json:"type"`
+	Output   string `json:"output"`
+	Vertices int    `json:"vertices"`
+	Edges    int    `json:"edges"`
+}
+
+func runGenerate(args []string) error {
+	flags, asJSON := newFlagSet("generate", "generate -type grid|geometric|delaunay|roadlike -out <network.txt> [-rows <n> -cols <n> | -n <n>]")
+	kind := flags.String("type", "", "grid, geometric, delaunay or roadlike")
+	outPath := flags.String("out", "", "The road network in the text format")
+	rows := flags.Int("rows", 100, "The number of rows of grid, delaunay and roadlike")
+	cols := flags.Int("cols", 100, "The number of columns of grid, delaunay and roadlike")
+	n := flags.Int("n", 10000, "The number of vertices of geometric")
+	spacing := flags.Float64("spacing", 100, "The distance between grid rows and columns in meters")
+	radius := flags.Float64("radius", 0, "The connection radius of geometric in meters (default: two average vertex distances)")
+	seed := flags.Int64("seed", 1, "The seed of the generator")
+	flags.Parse(args)
+	if err := required("type", *kind, "out", *outPath); err != nil {
+		return err
+	}
+	if *rows < 1 || *cols < 1 || *n < 1 || *spacing <= 0 {
+		return usagef("-rows, -cols, -n and -spacing must be positive")
+	}
+	if *radius < 0 {
+		return usagef("-radius must not be negative")
+	}
+
+	// The text format has no weights, so the weight function does not matter.
+	opts := generate.Options{Seed: *seed}
+	var g *graph.Graph
+	switch *kind {
+	case "grid":
+		g = generate.Grid(*rows, *cols, *spacing, opts)
+	case "geometric":
+		side := *spacing * math.Sqrt(float64(*n))
+		if *radius == 0 {
+			*radius = 2 * *spacing
+		}
+		g = generate.RandomGeometric(*n, side, *radius, opts)
+	case "delaunay":
+		g = generate.Delaunay(*rows, *cols, *spacing, opts)
+	case "roadlike":
+		g = generate.RoadLike(*rows, *cols, *spacing, opts)
+	default:
+		return usagef("unknown type %q", *kind)
+	}
+	if err := createFile(*outPath, func(w io.Writer) error { return parser.WriteNetwork(w, g) }); err != nil {
+		return err
+	}
+
+	result := generateResult{Type: *kind, Output: *outPath, Vertices: len(g.Vertices)}
+	// Every edge exists in both directions and is written once.
+	for _, edges := range g.Edges {
+		result.Edges += len(edges)
+	}
+	result.Edges /= 2
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "Generated a %s graph with %d vertices and %d edges into %s\n", result.Type, result.Vertices, result.Edges, result.Output)
+	})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// routeplanner runs the steps of the routing pipeline on explicit input and
+// output files. Every subcommand prints a human readable summary, or JSON
+// with -json:
+//
+//	go run ./cmd/routeplanner import -osm map.osm -out data/RoadNetworks/map.txt
+//	go run ./cmd/routeplanner order -graph data/RoadNetworks/osm1.txt -out osm1.ordering
+//	go run ./cmd/routeplanner preprocess ch -graph data/RoadNetworks/osm1.txt -out ch_osm1.gob
+//	go run ./cmd/routeplanner preprocess cch -graph data/RoadNetworks/osm1.txt -ordering osm1.ordering -out cch_osm1.gob
+//	go run ./cmd/routeplanner customize -graph data/RoadNetworks/osm1.txt -cch cch_osm1.gob -out cch_osm1_car.gob -profile car
+//	go run ./cmd/routeplanner query -ch ch_osm1.gob -source 1 -target 5 -json
+//	go run ./cmd/routeplanner matrix -cch cch_osm1_car.gob -sources 1,2,3 -targets 4,5
+//	go run ./cmd/routeplanner vrp -graph data/RoadNetworks/osm1.txt -cch cch_osm1.gob -problem problem.json
+//	go run ./cmd/routeplanner verify -graph data/RoadNetworks/osm1.txt -ch ch_osm1.gob
+//	go run ./cmd/routeplanner bench -graph data/RoadNetworks/osm1.txt -ch ch_osm1.gob -queries 1000
+//	go run ./cmd/routeplanner serve -graph data/RoadNetworks/osm5.txt -ordering data/KaHIP/osm5.ordering -addr :8080
+//	go run ./cmd/routeplanner export -graph data/RoadNetworks/osm1.txt -format metis -out osm1.graph
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(os.Args[2:])
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(os.Stderr, "%v\nRun 'routeplanner %s -h' for usage.\n", err, os.Args[1])
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands map[string]command
+
+// stdout receives the results of all commands. Tests replace it to read them.
+var stdout io.Writer = os.Stdout
+
+func init() {
+	// Assigned in init because help refers to commands.
+	commands = map[string]command{
+		"import":     {"Convert an OSM XML file into the road network text format", runImport},
+		"generate":   {"Write a synthetic road network in the text format", runGenerate},
+		"order":      {"Compute a nested dissection order of a road network", runOrder},
+		"preprocess": {"Build a CH or the topology of a CCH", runPreprocess},
+		"customize":  {"Apply the weights of a road network or a vehicle profile to a CCH", runCustomize},
+		"query":      {"Compute a shortest path", runQuery},
+		"matrix":     {"Compute a many-to-many distance matrix", runMatrix},
+		"vrp":        {"Solve a vehicle routing problem", runVRP},
+		"verify":     {"Check a CH or CCH against Dijkstra", runVerify},
+		"bench":      {"Time random queries", runBench},
+		"serve":      {"Serve the HTTP API", runServe},
+		"export":     {"Write a road network or contraction order in another format", runExport},
+		"help":       {"Print this help", func([]string) error { usage(); return nil }},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: routeplanner <command> [flags]\n\nCommands:")
+	for _, name := range []string{"import", "generate", "order", "preprocess", "customize", "query", "matrix", "vrp", "verify", "bench", "serve", "export", "help"} {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, commands[name].summary)
+	}
+}
+
+// usageError is returned by a command whose arguments are incomplete or
+// inconsistent. main exits with status 2 for it.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// newFlagSet returns the flags of a command together with its -json flag.
+func newFlagSet(name, synopsis string) (*flag.FlagSet, *bool) {
+	flags := newPlainFlagSet(name, synopsis)
+	return flags, flags.Bool("json", false, "Print the result as JSON")
+}
+
+// newPlainFlagSet returns the flags of a command without output.
+func newPlainFlagSet(name, synopsis string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: routeplanner %s\n\nFlags:\n", synopsis)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// required returns a usage error naming the first flag without a value.
+// nameValues alternates flag names and values.
+func required(nameValues ...string) error {
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		if nameValues[i+1] == "" {
+			return usagef("missing -%s", nameValues[i])
+		}
+	}
+	return nil
+}
+
+// loadNetwork reads a road network in the text format.
+func loadNetwork(path string) (graph.RoadNetwork, error) {
+	network, err := parser.NewNetworkFromFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+	if err != nil {
+		return graph.RoadNetwork{}, fmt.Errorf("failed to load graph %s: %w", path, err)
+	}
+	return network, nil
+}
+
+// createFile creates path for writing and calls write with it.
+func createFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// parseIds parses a comma separated list of vertex ids.
+func parseIds(list string) ([]graph.VertexId, error) {
+	var ids []graph.VertexId
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, usagef("invalid vertex id %q", field)
+		}
+		ids = append(ids, graph.VertexId(id))
+	}
+	return ids, nil
+}
+
+// milliseconds converts d for the JSON output.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e6
+}
+
+// output prints result as indented JSON if asJSON is set and calls text
+// otherwise.
+func output(asJSON bool, result any, text func(w io.Writer)) error {
+	if !asJSON {
+		text(stdout)
+		return nil
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// junction has two routes from node 2 to node 4: the short one turns left at
+// node 1, which a no_left_turn forbids, the long one passes nodes 6 and 7.
+const junction = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="1" lat="48.0000" lon="9.0000"/>
+  <node id="2" lat="47.9990" lon="9.0000"/>
+  <node id="3" lat="48.0000" lon="9.0010"/>
+  <node id="4" lat="48.0000" lon="8.9990"/>
+  <node id="6" lat="47.9990" lon="8.9980"/>
+  <node id="7" lat="48.0000" lon="8.9980"/>
+  <way id="10">
+    <nd ref="2"/>
+    <nd ref="1"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="11">
+    <nd ref="3"/>
+    <nd ref="1"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="12">
+    <nd ref="1"/>
+    <nd ref="4"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="14">
+    <nd ref="2"/>
+    <nd ref="6"/>
+    <nd ref="7"/>
+    <nd ref="4"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <relation id="1">
+    <member type="way" ref="10" role="from"/>
+    <member type="node" ref="1" role="via"/>
+    <member type="way" ref="12" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="no_left_turn"/>
+  </relation>
+</osm>`
+
+// run runs a command with -json and decodes its result into result.
+func run(t *testing.T, result any, name string, args ...string) {
+	t.Helper()
+	var buffer bytes.Buffer
+	old := stdout
+	stdout = &buffer
+	defer func() { stdout = old }()
+
+	if err := commands[name].run(append(args, "-json")); err != nil {
+		t.Fatalf("%s %v: %v", name, args, err)
+	}
+	if err := json.Unmarshal(buffer.Bytes(), result); err != nil {
+		t.Fatalf("%s %v: failed to decode %q: %v", name, args, buffer.String(), err)
+	}
+}
+
+// vertexAt returns the id of the vertex of g at the given coordinates.
+func vertexAt(t *testing.T, g *graph.Graph, lat, lon float64) string {
+	t.Helper()
+	for id, v := range g.Vertices {
+		if v.Lat == lat && v.Lon == lon {
+			return strconv.Itoa(int(id))
+		}
+	}
+	t.Fatalf("no vertex at %v, %v", lat, lon)
+	return ""
+}
+
+func TestPipeline(t *testing.T) {
+	dir := t.TempDir()
+	osmPath := filepath.Join(dir, "junction.osm")
+	networkPath := filepath.Join(dir, "junction.txt")
+	turnsPath := filepath.Join(dir, "junction.turns")
+	chPath := filepath.Join(dir, "ch.gob")
+	if err := os.WriteFile(osmPath, []byte(junction), 0644); err != nil {
+		t.Fatalf("failed to write OSM file: %v", err)
+	}
+
+	var imported importResult
+	run(t, &imported, "import", "-osm", osmPath, "-out", networkPath, "-turns", turnsPath)
+	if imported.Vertices != 6 || imported.ForbiddenTurns != 1 {
+		t.Errorf("imported %d vertices and %d forbidden turns, want 6 and 1", imported.Vertices, imported.ForbiddenTurns)
+	}
+
+	var preprocessed preprocessResult
+	run(t, &preprocessed, "preprocess", "ch", "-graph", networkPath, "-out", chPath)
+	if preprocessed.Vertices != 6 || preprocessed.CoreSize != 0 {
+		t.Errorf("preprocessed %d vertices with a core of %d, want 6 and none", preprocessed.Vertices, preprocessed.CoreSize)
+	}
+
+	network, err := loadNetwork(networkPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := vertexAt(t, network.Network, 47.999, 9.0)
+	target := vertexAt(t, network.Network, 48.0, 8.999)
+	junction := vertexAt(t, network.Network, 48.0, 9.0)
+
+	var dijkstra, contracted, turnAware queryResult
+	run(t, &dijkstra, "query", "-graph", networkPath, "-source", source, "-target", target)
+	run(t, &contracted, "query", "-ch", chPath, "-source", source, "-target", target)
+	run(t, &turnAware, "query", "-graph", networkPath, "-turns", turnsPath, "-source", source, "-target", target)
+
+	if dijkstra.Router != "Dijkstra" || contracted.Router != "CH" || turnAware.Router != "TurnDijkstra" {
+		t.Errorf("got routers %s, %s and %s", dijkstra.Router, contracted.Router, turnAware.Router)
+	}
+	if contracted.Cost != dijkstra.Cost {
+		t.Errorf("CH cost %v, Dijkstra cost %v", contracted.Cost, dijkstra.Cost)
+	}
+	if len(contracted.Path) != 3 || strconv.Itoa(int(contracted.Path[1])) != junction {
+		t.Errorf("got CH path %v, want the left turn at %s", contracted.Path, junction)
+	}
+	if turnAware.Cost <= dijkstra.Cost || len(turnAware.Path) != 4 {
+		t.Errorf("got turn aware path %v of cost %v, want the detour around the forbidden turn", turnAware.Path, turnAware.Cost)
+	}
+
+	// The stops at the junction and at the target fit into one vehicle.
+	problemPath := filepath.Join(dir, "problem.json")
+	problem := `{"vehicles": [{"depot": ` + source + `, "capacity": 2}], "stops": [{"vertex": ` + junction + `, "demand": 1}, {"vertex": ` + target + `, "demand": 1}]}`
+	if err := os.WriteFile(problemPath, []byte(problem), 0644); err != nil {
+		t.Fatalf("failed to write problem: %v", err)
+	}
+	var solved vrpResult
+	run(t, &solved, "vrp", "-ch", chPath, "-problem", problemPath, "-time-limit", "0")
+	if len(solved.Routes) != 1 || len(solved.Routes[0].Stops) != 2 || len(solved.Unassigned) != 0 || solved.Cost != 2*dijkstra.Cost {
+		t.Errorf("got solution %+v, want both stops on one route of cost %v", solved, 2*dijkstra.Cost)
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/api"
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	"github.com/PaulMue0/efficient-routeplanning/internal/verify"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// engineFlags select the router of the query commands: a CH, a CCH or plain
+// Dijkstra on the network, or with -turns a turn aware CCH or Dijkstra.
+type engineFlags struct {
+	graphPath *string
+	chPath    *string
+	cchPath   *string
+	turnsPath *string
+}
+
+func addEngineFlags(flags *flag.FlagSet) engineFlags {
+	return engineFlags{
+		graphPath: flags.String("graph", "", "The road network in the text format; queried with Dijkstra without -ch and -cch, customizes -cch otherwise"),
+		chPath:    flags.String("ch", "", "A CH written by preprocess ch"),
+		cchPath:   flags.String("cch", "", "A CCH written by customize, or by preprocess cch together with -graph"),
+		turnsPath: flags.String("turns", "", "A turn table written by import -turns; needs -graph and queries a turn aware CCH with -cch, turn aware Dijkstra otherwise"),
+	}
+}
+
+// engine is a loaded router together with the graph its queries run on.
+type engine struct {
+	name      string
+	router    routing.Router
+	network   *graph.Graph // Nil unless -graph was given
+	hierarchy *verify.Hierarchy
+	// searchGraph contains every vertex that can be queried.
+	searchGraph *graph.Graph
+	vertices    []graph.VertexId
+}
+
+func (f engineFlags) load() (*engine, error) {
+	if *f.chPath != "" && *f.cchPath != "" {
+		return nil, usagef("-ch and -cch are mutually exclusive")
+	}
+	if *f.chPath == "" && *f.cchPath == "" && *f.graphPath == "" {
+		return nil, usagef("missing -graph, -ch or -cch")
+	}
+	if *f.turnsPath != "" && (*f.graphPath == "" || *f.chPath != "") {
+		return nil, usagef("-turns needs -graph and works with -cch or Dijkstra, not with -ch")
+	}
+
+	e := &engine{}
+	if *f.graphPath != "" {
+		network, err := loadNetwork(*f.graphPath)
+		if err != nil {
+			return nil, err
+		}
+		e.network = network.Network
+	}
+
+	switch {
+	case *f.turnsPath != "":
+		table, err := parser.NewTurnTableFromFS(os.DirFS(filepath.Dir(*f.turnsPath)), filepath.Base(*f.turnsPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read turn table %s: %w", *f.turnsPath, err)
+		}
+		e.searchGraph = e.network
+		if *f.cchPath == "" {
+			e.name, e.router = "TurnDijkstra", routing.NewTurnDijkstraRouter(e.network, table)
+			break
+		}
+		file, err := preprocessed_graph.ReadCCH(*f.cchPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CCH %s: %w", *f.cchPath, err)
+		}
+		c, err := cch.NewTurnCCH(e.network, file.ToCCH().ContractionOrder)
+		if err != nil {
+			return nil, fmt.Errorf("turn CCH preprocessing failed: %w", err)
+		}
+		if err := c.Customize(e.network, table); err != nil {
+			return nil, fmt.Errorf("turn CCH customization failed: %w", err)
+		}
+		e.name, e.router = "TurnCCH", routing.NewTurnCCHRouter(c, e.network)
+	case *f.chPath != "":
+		file, err := preprocessed_graph.ReadCHFile(*f.chPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CH %s: %w", *f.chPath, err)
+		}
+		c := file.ToCH()
+		h := verify.FromCH(c)
+		e.name, e.router, e.hierarchy, e.searchGraph = "CH", routing.NewCHRouter(c), &h, c.UpwardsGraph
+	case *f.cchPath != "":
+		file, err := preprocessed_graph.ReadCCH(*f.cchPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CCH %s: %w", *f.cchPath, err)
+		}
+		c := file.ToCCH()
+		if e.network != nil {
+			if err := c.Customize(e.network); err != nil {
+				return nil, fmt.Errorf("CCH customization failed: %w", err)
+			}
+		}
+		h := verify.FromCCH(c)
+		e.name, e.router, e.hierarchy, e.searchGraph = "CCH", routing.NewCCHRouter(c), &h, c.UpwardsGraph
+	default:
+		e.name, e.router, e.searchGraph = "Dijkstra", routing.NewDijkstraRouter(e.network), e.network
+	}
+
+	e.vertices = make([]graph.VertexId, 0, len(e.searchGraph.Vertices))
+	for id := range e.searchGraph.Vertices {
+		e.vertices = append(e.vertices, id)
+	}
+	sort.Slice(e.vertices, func(i, j int) bool { return e.vertices[i] < e.vertices[j] })
+	return e, nil
+}
+
+// checkVertices returns an error for the first of ids that is not a vertex of
+// the search graph.
+func (e *engine) checkVertices(ids ...graph.VertexId) error {
+	for _, id := range ids {
+		i := sort.Search(len(e.vertices), func(i int) bool { return e.vertices[i] >= id })
+		if i == len(e.vertices) || e.vertices[i] != id {
+			return fmt.Errorf("unknown vertex %d", id)
+		}
+	}
+	return nil
+}
+
+type queryResult struct {
+	Router      string           `json:"router"`
+	Source      graph.VertexId   `json:"source"`
+	Target      graph.VertexId   `json:"target"`
+	Path        []graph.VertexId `json:"path"`
+	Cost        float64          `json:"cost"`
+	NodesPopped int              `json:"nodesPopped"`
+	DurationMs  float64          `json:"durationMs"`
+}
+
+func runQuery(args []string) error {
+	flags, asJSON := newFlagSet("query", "query (-graph <network.txt> [-turns <turns.txt>] | -ch <file.gob> | -cch <file.gob>) -source <id> -target <id>")
+	engineFlags := addEngineFlags(flags)
+	source := flags.Int("source", -1, "The source vertex")
+	target := flags.Int("target", -1, "The target vertex")
+	flags.Parse(args)
+	if *source < 0 || *target < 0 {
+		return usagef("missing -source or -target")
+	}
+
+	e, err := engineFlags.load()
+	if err != nil {
+		return err
+	}
+	if err := e.checkVertices(graph.VertexId(*source), graph.VertexId(*target)); err != nil {
+		return err
+	}
+	route, err := e.router.Route(graph.VertexId(*source), graph.VertexId(*target), routing.Options{})
+	if err != nil {
+		return fmt.Errorf("query %d -> %d failed: %w", *source, *target, err)
+	}
+
+	result := queryResult{
+		Router:      e.name,
+		Source:      route.Source,
+		Target:      route.Target,
+		Path:        route.Path,
+		Cost:        route.Cost,
+		NodesPopped: route.Stats.NodesPopped,
+		DurationMs:  milliseconds(route.Stats.Duration),
+	}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "%s %d -> %d: cost %v, %d vertices, %d nodes popped in %s\n",
+			result.Router, result.Source, result.Target, result.Cost, len(result.Path), result.NodesPopped, route.Stats.Duration)
+		for i, v := range result.Path {
+			if i > 0 {
+				fmt.Fprint(w, " ")
+			}
+			fmt.Fprintf(w, "%d", v)
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+type matrixResult struct {
+	Router     string           `json:"router"`
+	Sources    []graph.VertexId `json:"sources"`
+	Targets    []graph.VertexId `json:"targets"`
+	Distances  [][]*float64     `json:"distances"` // Null for unreachable targets
+	DurationMs float64          `json:"durationMs"`
+}
+
+func runMatrix(args []string) error {
+	flags, asJSON := newFlagSet("matrix", "matrix (-graph <network.txt> | -ch <file.gob> | -cch <file.gob>) -sources <id,...> -targets <id,...>")
+	engineFlags := addEngineFlags(flags)
+	sourceList := flags.String("sources", "", "Comma separated source vertices")
+	targetList := flags.String("targets", "", "Comma separated target vertices, the sources if empty")
+	flags.Parse(args)
+	if err := required("sources", *sourceList); err != nil {
+		return err
+	}
+	if *targetList == "" {
+		targetList = sourceList
+	}
+	sources, err := parseIds(*sourceList)
+	if err != nil {
+		return err
+	}
+	targets, err := parseIds(*targetList)
+	if err != nil {
+		return err
+	}
+
+	e, err := engineFlags.load()
+	if err != nil {
+		return err
+	}
+	if err := e.checkVertices(slices.Concat(sources, targets)...); err != nil {
+		return err
+	}
+	start := time.Now()
+	matrix, err := routing.DistanceMatrix(e.router, sources, targets)
+	if err != nil {
+		return fmt.Errorf("failed to compute distance matrix: %w", err)
+	}
+	duration := time.Since(start)
+
+	result := matrixResult{Router: e.name, Sources: sources, Targets: targets, DurationMs: milliseconds(duration)}
+	for _, row := range matrix {
+		distances := make([]*float64, len(row))
+		for j := range row {
+			if !math.IsInf(row[j], 1) {
+				distances[j] = &row[j]
+			}
+		}
+		result.Distances = append(result.Distances, distances)
+	}
+	return output(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "%10s", "")
+		for _, t := range targets {
+			fmt.Fprintf(w, " %10d", t)
+		}
+		fmt.Fprintln(w)
+		for i, s := range sources {
+			fmt.Fprintf(w, "%10d", s)
+			for _, d := range matrix[i] {
+				fmt.Fprintf(w, " %10v", d)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s: %dx%d matrix in %s\n", result.Router, len(sources), len(targets), duration)
+	})
+}
+
+type verifyResult struct {
+	Hierarchy  string           `json:"hierarchy"`
+	OK         bool             `json:"ok"`
+	Vertices   int              `json:"vertices"`
+	Edges      int              `json:"edges"`
+	Queries    int              `json:"queries"`
+	AllPairs   bool             `json:"allPairs"`
+	Violations []verifyMessage  `json:"violations"`
+	Mismatches []verifyMismatch `json:"mismatches"`
+}
+
+type verifyMessage struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+type verifyMismatch struct {
+	Source graph.VertexId `json:"source"`
+	Target graph.VertexId `json:"target"`
+	Reason string         `json:"reason"`
+}
+
+// errVerificationFailed makes main exit with status 1 after the report.
+var errVerificationFailed = errors.New("verification failed")
+
+func runVerify(args []string) error {
+	flags, asJSON := newFlagSet("verify", "verify -graph <network.txt> (-ch <file.gob> | -cch <file.gob>)")
+	engineFlags := addEngineFlags(flags)
+	pairs := flags.Int("pairs", 1000, "The number of random source-target pairs")
+	seed := flags.Int64("seed", 1, "The seed of the random pairs")
+	allPairsLimit := flags.Int("all-pairs-limit", 200, "Compare all pairs on networks with at most this many vertices")
+	flags.Parse(args)
+	if err := required("graph", *engineFlags.graphPath); err != nil {
+		return err
+	}
+	if (*engineFlags.chPath == "") == (*engineFlags.cchPath == "") {
+		return usagef("specify exactly one of -ch and -cch")
+	}
+	if *engineFlags.turnsPath != "" {
+		return usagef("verify does not support -turns")
+	}
+
+	e, err := engineFlags.load()
+	if err != nil {
+		return err
+	}
+	report := verify.Run(e.network, *e.hierarchy, verify.Options{Pairs: *pairs, Seed: *seed, AllPairsLimit: *allPairsLimit})
+
+	if *asJSON {
+		result := verifyResult{
+			Hierarchy:  report.Hierarchy,
+			OK:         report.OK(),
+			Vertices:   report.Vertices,
+			Edges:      report.Edges,
+			Queries:    report.Queries,
+			AllPairs:   report.AllPairs,
+			Violations: []verifyMessage{},
+			Mismatches: []verifyMismatch{},
+		}
+		for _, v := range report.Violations {
+			result.Violations = append(result.Violations, verifyMessage{Check: v.Check, Message: v.Message})
+		}
+		for _, m := range report.Mismatches {
+			result.Mismatches = append(result.Mismatches, verifyMismatch{Source: m.Source, Target: m.Target, Reason: m.Reason})
+		}
+		if err := output(true, result, nil); err != nil {
+			return err
+		}
+	} else if err := report.Write(stdout); err != nil {
+		return err
+	}
+	if !report.OK() {
+		return errVerificationFailed
+	}
+	return nil
+}
+
+func runServe(args []string) error {
+	flags := newPlainFlagSet("serve", "serve -graph <network.txt> [-ordering <file.ordering>] [-ch <file.gob>] [-addr :8080]")
+	graphPath := flags.String("graph", "", "The road network in the text format")
+	orderingPath := flags.String("ordering", "", "The order of the CCH in the KaHIP format, a nested dissection order is computed if empty")
+	chPath := flags.String("ch", "", "A CH written by preprocess ch, the CH is preprocessed on startup if empty")
+	addr := flags.String("addr", ":8080", "The address to listen on")
+	flags.Parse(args)
+	if err := required("graph", *graphPath); err != nil {
+		return err
+	}
+
+	api.Serve(api.Config{Network: *graphPath, Ordering: *orderingPath, CH: *chPath, Addr: *addr})
+	return nil
+}
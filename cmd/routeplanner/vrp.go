@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/vrp"
+)
+
+type vrpResult struct {
+	Router     string      `json:"router"`
+	Problem    string      `json:"problem"`
+	Routes     []vrp.Route `json:"routes"`
+	Unassigned []int       `json:"unassigned"`
+	Cost       float64     `json:"cost"`
+	MatrixMs   float64     `json:"matrixMs"`
+	SolveMs    float64     `json:"solveMs"`
+}
+
+func runVRP(args []string) error {
+	flags, asJSON := newFlagSet("vrp", "vrp (-graph <network.txt> | -ch <file.gob> | -cch <file.gob>) -problem <problem.json> [-time-limit 5s]")
+	engineFlags := addEngineFlags(flags)
+	problemPath := flags.String("problem", "", "The vehicle routing problem as JSON, in the format accepted by /api/vrp")
+	timeLimit := flags.Duration("time-limit", 5*time.Second, "The time limit of the local search, 0 for none")
+	flags.Parse(args)
+	if err := required("problem", *problemPath); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*problemPath)
+	if err != nil {
+		return err
+	}
+	var problem vrp.Problem
+	if err := json.Unmarshal(data, &problem); err != nil {
+		return fmt.Errorf("failed to decode problem %s: %w", *problemPath, err)
+	}
+
+	e, err := engineFlags.load()
+	if err != nil {
+		return err
+	}
+	if err := e.checkVertices(problem.Locations()...); err != nil {
+		return err
+	}
+	start := time.Now()
+	matrix, err := problem.Matrix(e.router)
+	if err != nil {
+		return fmt.Errorf("failed to compute distance matrix: %w", err)
+	}
+	matrixTime := time.Since(start)
+
+	start = time.Now()
+	solution, err := vrp.Solve(problem, matrix, vrp.Options{TimeLimit: *timeLimit})
+	if err != nil {
+		return fmt.Errorf("failed to solve problem: %w", err)
+	}
+	solveTime := time.Since(start)
+
+	result := vrpResult{
+		Router:     e.name,
+		Problem:    *problemPath,
+		Routes:     solution.Routes,
+		Unassigned: solution.Unassigned,
+		Cost:       solution.Cost,
+		MatrixMs:   milliseconds(matrixTime),
+		SolveMs:    milliseconds(solveTime),
+	}
+	return output(*asJSON, result, func(w io.Writer) {
+		for _, route := range result.Routes {
+			fmt.Fprintf(w, "Vehicle %d: cost %v, load %d, stops %v\n", route.Vehicle, route.Cost, route.Load, route.Stops)
+		}
+		if len(result.Unassigned) > 0 {
+			fmt.Fprintf(w, "Unassigned stops: %v\n", result.Unassigned)
+		}
+		fmt.Fprintf(w, "%s: cost %v, %dx%d matrix in %s, solved in %s\n",
+			result.Router, result.Cost, len(matrix), len(matrix), matrixTime, solveTime)
+	})
+}
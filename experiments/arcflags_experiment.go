@@ -0,0 +1,188 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/arcflags"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+type ArcFlagsExperimentResult struct {
+	GraphName               string
+	NumRegions              int
+	Partitioner             string
+	FlagTime                time.Duration
+	CHFlagTime              time.Duration
+	FlagDensity             float64
+	CHFlagDensity           float64
+	AvgDijkstraTime         time.Duration
+	AvgArcFlagTime          time.Duration
+	AvgCHTime               time.Duration
+	AvgCHArcFlagTime        time.Duration
+	AvgDijkstraNodesPopped  int
+	AvgArcFlagNodesPopped   int
+	AvgCHNodesPopped        int
+	AvgCHArcFlagNodesPopped int
+	Mismatches              int
+}
+
+func RunArcFlagsExperiment() {
+	preprocessedDir := "./data/preprocessed"
+	roadNetworksDir := "./data/RoadNetworks"
+	partitionsDir := "./data/KaHIP"
+	resultsPath := "./arcflags_experiment_results.csv"
+	numRegions := 32
+	numQueries := 100
+
+	files, err := os.ReadDir(preprocessedDir)
+	if err != nil {
+		log.Fatalf("failed to read preprocessed directory: %v", err)
+	}
+
+	var results []ArcFlagsExperimentResult
+
+	r := newRand()
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "ch_osm") && strings.HasSuffix(file.Name(), ".gob") {
+			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "ch_"), ".gob") + ".txt"
+			log.Printf("Processing graph: %s", graphName)
+
+			// Load original graph
+			originalNetwork, err := parser.NewNetworkFromFS(os.DirFS(roadNetworksDir), graphName)
+			if err != nil {
+				log.Printf("failed to load original graph %s: %v", graphName, err)
+				continue
+			}
+			g := originalNetwork.Network
+
+			// Load CH graph
+			preprocessedFile, err := preprocessed_graph.ReadCHFile(filepath.Join(preprocessedDir, file.Name()))
+			if err != nil {
+				log.Printf("failed to read preprocessed graph for %s: %v", graphName, err)
+				continue
+			}
+			chInstance := preprocessedFile.ToCH()
+
+			partitionPath := filepath.Join(partitionsDir, strings.TrimSuffix(graphName, ".txt")+".partition")
+			partition, partitioner, err := loadPartition(g, partitionPath, numRegions)
+			if err != nil {
+				log.Printf("failed to partition %s: %v", graphName, err)
+				continue
+			}
+
+			start := time.Now()
+			flags := arcflags.Compute(g, partition)
+			flagTime := time.Since(start)
+
+			start = time.Now()
+			chFlags, err := arcflags.NewCHArcFlags(chInstance, partition)
+			chFlagTime := time.Since(start)
+			if err != nil {
+				log.Printf("failed to compute CH arc flags for %s: %v", graphName, err)
+				continue
+			}
+
+			vertices := sortedVertices(g)
+			if len(vertices) < 2 {
+				log.Printf("not enough vertices in graph %s to perform queries", graphName)
+				continue
+			}
+
+			plain := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewDijkstraRouter(g), routing.NewArcFlagRouter(g, flags))
+			hierarchical := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewCHRouter(chInstance), routing.NewCHArcFlagRouter(chFlags))
+
+			results = append(results, ArcFlagsExperimentResult{
+				GraphName:               graphName,
+				NumRegions:              partition.NumRegions,
+				Partitioner:             partitioner,
+				FlagTime:                flagTime,
+				CHFlagTime:              chFlagTime,
+				FlagDensity:             flags.Density(),
+				CHFlagDensity:           chFlags.Flags.Density(),
+				AvgDijkstraTime:         plain.AvgBaselineTime,
+				AvgArcFlagTime:          plain.AvgCandidateTime,
+				AvgCHTime:               hierarchical.AvgBaselineTime,
+				AvgCHArcFlagTime:        hierarchical.AvgCandidateTime,
+				AvgDijkstraNodesPopped:  plain.AvgBaselineNodesPopped,
+				AvgArcFlagNodesPopped:   plain.AvgCandidateNodesPopped,
+				AvgCHNodesPopped:        hierarchical.AvgBaselineNodesPopped,
+				AvgCHArcFlagNodesPopped: hierarchical.AvgCandidateNodesPopped,
+				Mismatches:              plain.Mismatches + hierarchical.Mismatches,
+			})
+
+			log.Printf("Finished processing %s", graphName)
+		}
+	}
+
+	// Write results to CSV
+	csvFile, err := os.Create(resultsPath)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	defer writer.Flush()
+
+	headers := []string{"Graph", "Regions", "Partitioner", "FlagTime(ms)", "CHFlagTime(ms)", "FlagDensity", "CHFlagDensity",
+		"AvgDijkstraTime(ms)", "AvgArcFlagTime(ms)", "AvgCHTime(ms)", "AvgCHArcFlagTime(ms)",
+		"AvgDijkstraNodesPopped", "AvgArcFlagNodesPopped", "AvgCHNodesPopped", "AvgCHArcFlagNodesPopped", "Mismatches"}
+	writer.Write(headers)
+
+	for _, result := range results {
+		row := []string{
+			result.GraphName,
+			strconv.Itoa(result.NumRegions),
+			result.Partitioner,
+			fmt.Sprintf("%.3f", float64(result.FlagTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.CHFlagTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.4f", result.FlagDensity),
+			fmt.Sprintf("%.4f", result.CHFlagDensity),
+			fmt.Sprintf("%.3f", float64(result.AvgDijkstraTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgArcFlagTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgCHTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgCHArcFlagTime.Nanoseconds())/1e6),
+			strconv.Itoa(result.AvgDijkstraNodesPopped),
+			strconv.Itoa(result.AvgArcFlagNodesPopped),
+			strconv.Itoa(result.AvgCHNodesPopped),
+			strconv.Itoa(result.AvgCHArcFlagNodesPopped),
+			strconv.Itoa(result.Mismatches),
+		}
+		writer.Write(row)
+	}
+
+	log.Printf("Arc-flags experiment results written to %s", resultsPath)
+}
+
+// loadPartition reads a KaHIP partition of g if one exists at path and falls
+// back to the built-in coordinate bisection with numRegions regions.
+func loadPartition(g *graph.Graph, path string, numRegions int) (arcflags.Partition, string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		partition, err := arcflags.KDPartition(g, min(numRegions, len(g.Vertices)))
+		return partition, "kd", err
+	}
+	if err != nil {
+		return arcflags.Partition{}, "", err
+	}
+	defer file.Close()
+
+	regions, err := parser.ReadMetisPartition(g, file)
+	if err != nil {
+		return arcflags.Partition{}, "", err
+	}
+	partition, err := arcflags.NewPartition(g, regions)
+	return partition, "kahip", err
+}
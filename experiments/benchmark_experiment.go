@@ -0,0 +1,159 @@
+package experiments
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/bench"
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// RunBenchmarkExperiment measures Dijkstra, CH and CCH on identical query
+// sets: uniformly random pairs and Dijkstra rank queries. The query sets are
+// stored in data/queries on the first run and read from there afterwards, so
+// later runs and other tools measure the same queries. Besides the summary of
+// every query set, the results contain the latencies per Dijkstra rank.
+func RunBenchmarkExperiment() {
+	dataDir := "./data/RoadNetworks"
+	orderingDir := "./data/KaHIP"
+	queryDir := "./data/queries"
+	resultsPath := "./benchmark_experiment_results"
+	numRandomQueries := 1000
+	numRankSources := 100
+	opts := bench.Options{Warmup: 100}
+
+	if err := os.MkdirAll(queryDir, 0755); err != nil {
+		log.Fatalf("failed to create query directory: %v", err)
+	}
+
+	files, err := os.ReadDir(dataDir)
+	if err != nil {
+		log.Fatalf("failed to read data directory: %v", err)
+	}
+
+	var results []bench.Result
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), "osm") || !strings.HasSuffix(file.Name(), ".txt") {
+			continue
+		}
+		graphName := file.Name()
+		baseName := strings.TrimSuffix(graphName, ".txt")
+		log.Printf("Processing graph: %s", graphName)
+
+		network, err := parser.NewNetworkFromFS(os.DirFS(dataDir), graphName)
+		if err != nil {
+			log.Printf("failed to load graph %s: %v", graphName, err)
+			continue
+		}
+		g := network.Network
+
+		orderingPath := filepath.Join(orderingDir, baseName+".ordering")
+		if _, err := os.Stat(orderingPath); os.IsNotExist(err) {
+			log.Printf("ordering file not found for %s, skipping", graphName)
+			continue
+		}
+		c := cch.NewCCH()
+		if err := c.Preprocess(g, orderingPath); err != nil {
+			log.Printf("CCH preprocessing failed for %s: %v", graphName, err)
+			continue
+		}
+		if err := c.Customize(g); err != nil {
+			log.Printf("CCH customization failed for %s: %v", graphName, err)
+			continue
+		}
+		hierarchy := ch.NewContractionHierarchies(ch.WithDeterminism())
+		hierarchy.Preprocess(g.Clone())
+
+		querySets := []struct {
+			name     string
+			generate func() []bench.Query
+		}{
+			{"random", func() []bench.Query { return bench.RandomQueries(g, numRandomQueries, Seed) }},
+			{"rank", func() []bench.Query { return bench.RankQueries(g, numRankSources, Seed) }},
+		}
+		for _, set := range querySets {
+			queries, err := loadOrGenerateQueries(g, filepath.Join(queryDir, baseName+"_"+set.name+".queries"), set.generate)
+			if err != nil {
+				log.Printf("failed to prepare %s queries for %s: %v", set.name, graphName, err)
+				continue
+			}
+
+			dijkstra := bench.Measure("Dijkstra", routing.NewDijkstraRouter(g), queries, opts)
+			measurements := []*bench.Measurement{
+				dijkstra,
+				bench.Measure("CH", routing.NewCHRouter(hierarchy), queries, opts),
+				bench.Measure("CCH", routing.NewCCHRouter(c), queries, opts),
+			}
+			for _, m := range measurements {
+				if mismatches := bench.Mismatches(dijkstra, m); mismatches > 0 {
+					log.Printf("%s: %d distance mismatches with Dijkstra on %s", m.Router, mismatches, graphName)
+				}
+				rows := []bench.Result{m.Summary()}
+				if set.name == "rank" {
+					rows = append(rows, m.ByRank()...)
+				}
+				for _, row := range rows {
+					row.Graph, row.QuerySet = graphName, set.name
+					results = append(results, row)
+				}
+			}
+		}
+
+		log.Printf("Finished processing %s", graphName)
+	}
+
+	if err := writeResults(resultsPath+".csv", results, bench.WriteCSV); err != nil {
+		log.Fatalf("failed to write results: %v", err)
+	}
+	if err := writeResults(resultsPath+".json", results, bench.WriteJSON); err != nil {
+		log.Fatalf("failed to write results: %v", err)
+	}
+	log.Printf("Benchmark results written to %s.csv and %s.json", resultsPath, resultsPath)
+}
+
+// loadOrGenerateQueries reads the queries at path, or generates them and
+// stores them at path if the file does not exist yet.
+func loadOrGenerateQueries(g *graph.Graph, path string, generate func() []bench.Query) ([]bench.Query, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		log.Printf("Reading queries from %s", path)
+		return bench.ReadQueries(g, file)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	queries := generate()
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	if err := bench.WriteQueries(out, queries); err != nil {
+		return nil, err
+	}
+	log.Printf("Wrote %d queries to %s", len(queries), path)
+	return queries, nil
+}
+
+func writeResults(path string, results []bench.Result, write func(io.Writer, []bench.Result) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, results); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
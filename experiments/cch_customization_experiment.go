@@ -4,9 +4,11 @@ import (
 	"encoding/csv"
 	"fmt"
 	"log"
+	"maps"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,6 +36,7 @@ func RunCCHCustomizationExperiment() {
 
 	var results []CCHCustomizationExperimentResult
 
+	r := newRand()
 	for _, file := range files {
 		if !file.IsDir() && strings.HasPrefix(file.Name(), "cch_osm") && strings.HasSuffix(file.Name(), ".gob") {
 			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "cch_"), ".gob") + ".txt"
@@ -69,7 +72,7 @@ func RunCCHCustomizationExperiment() {
 			var totalRandomTime time.Duration
 			for i := 0; i < numRandomRuns; i++ {
 				// Create a graph with random weights
-				randomWeightGraph := createRandomWeightGraph(originalNetwork.Network)
+				randomWeightGraph := createRandomWeightGraph(r, originalNetwork.Network)
 
 				// Reload CCH instance to have a fresh start
 				cchInstance := preprocessedFile.ToCCH()
@@ -121,16 +124,17 @@ func RunCCHCustomizationExperiment() {
 	log.Printf("CCH customization experiment results written to %s", resultsPath)
 }
 
-func createRandomWeightGraph(original *graph.Graph) *graph.Graph {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func createRandomWeightGraph(r *rand.Rand, original *graph.Graph) *graph.Graph {
 	randomGraph := graph.NewGraph()
 
 	for _, vertex := range original.Vertices {
 		randomGraph.AddVertex(vertex)
 	}
 
-	for u, edges := range original.Edges {
-		for v := range edges {
+	// Draw the weights in the order of the vertex ids to make them reproducible
+	for _, u := range sortedVertices(original) {
+		targets := slices.Sorted(maps.Keys(original.Edges[u]))
+		for _, v := range targets {
 			if u < v { // Add each edge only once
 				randomWeight := r.Intn(1000) + 1 // [1, 1000]
 				randomGraph.AddEdge(u, v, randomWeight, false, -1)
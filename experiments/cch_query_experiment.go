@@ -4,7 +4,6 @@ import (
 	"encoding/csv"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,9 +11,8 @@ import (
 	"time"
 
 	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
-	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
 	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
-	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
 )
 
 type CCHQueryExperimentResult struct {
@@ -37,6 +35,7 @@ func RunCCHQueryExperiment() {
 
 	var results []CCHQueryExperimentResult
 
+	r := newRand()
 	for _, file := range files {
 		if !file.IsDir() && strings.HasPrefix(file.Name(), "cch_osm") && strings.HasSuffix(file.Name(), ".gob") {
 			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "cch_"), ".gob") + ".txt"
@@ -67,54 +66,20 @@ func RunCCHQueryExperiment() {
 			}
 
 			// Get vertices for random queries
-			var vertices []graph.VertexId
-			for _, v := range originalNetwork.Network.Vertices {
-				vertices = append(vertices, v.Id)
-			}
+			vertices := sortedVertices(originalNetwork.Network)
 			if len(vertices) < 2 {
 				log.Printf("not enough vertices in graph %s to perform queries", graphName)
 				continue
 			}
 
-			var totalDijkstraTime time.Duration
-			var totalCCHQueryTime time.Duration
-			mismatches := 0
-
-			for i := 0; i < numQueries; i++ {
-				source, target := selectRandomNodes(vertices)
-
-				// Run Dijkstra on original graph
-				start := time.Now()
-				_, dijkstraDist, _, err := pathfinding.DijkstraShortestPath(originalNetwork.Network, source, target, math.Inf(1))
-				dijkstraTime := time.Since(start)
-				if err != nil {
-					log.Printf("Dijkstra failed for %v to %v on %s: %v", source, target, graphName, err)
-					continue
-				}
-				totalDijkstraTime += dijkstraTime
-
-				// Run CCH Query
-				start = time.Now()
-				_, cchDist, _, err := cchInstance.Query(source, target)
-				cchTime := time.Since(start)
-				if err != nil {
-					log.Printf("CCH query failed for %v to %v on %s: %v", source, target, graphName, err)
-					continue
-				}
-				totalCCHQueryTime += cchTime
-
-				// Compare distances
-				if math.Abs(dijkstraDist-cchDist) > 1e-6 { // Using a tolerance for float comparison
-					log.Printf("Distance mismatch for %v to %v on %s: Dijkstra=%.2f, CCH=%.2f", source, target, graphName, dijkstraDist, cchDist)
-					mismatches++
-				}
-			}
+			comparison := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewDijkstraRouter(originalNetwork.Network), routing.NewCCHRouter(cchInstance))
 
 			result := CCHQueryExperimentResult{
 				GraphName:       graphName,
-				AvgDijkstraTime: totalDijkstraTime / time.Duration(numQueries),
-				AvgCCHQueryTime: totalCCHQueryTime / time.Duration(numQueries),
-				Mismatches:      mismatches,
+				AvgDijkstraTime: comparison.AvgBaselineTime,
+				AvgCCHQueryTime: comparison.AvgCandidateTime,
+				Mismatches:      comparison.Mismatches,
 			}
 			results = append(results, result)
 
@@ -52,6 +52,7 @@ func RunCHExperiment() {
 
 			ch.ShortcutsAdded = 0 // Reset shortcut counter
 			chInstance := ch.NewContractionHierarchies()
+			chInstance.Deterministic = true
 
 			start := time.Now()
 			chInstance.Preprocess(network.Network)
@@ -0,0 +1,181 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+type CHOrderExperimentResult struct {
+	GraphName            string
+	GreedyTime           time.Duration
+	NestedDissectionTime time.Duration
+	RebuildTime          time.Duration
+	GreedyShortcuts      int
+	NDShortcuts          int
+	AvgGreedyTime        time.Duration
+	AvgNDTime            time.Duration
+	AvgGreedyNodesPopped int
+	AvgNDNodesPopped     int
+	Mismatches           int
+}
+
+// RunCHOrderExperiment compares CHs built with the greedy priority order to
+// CHs built with the nested dissection order of data/KaHIP, and measures how
+// fast a CH is rebuilt from the exported greedy order.
+func RunCHOrderExperiment() {
+	dataDir := "./data/RoadNetworks"
+	orderingDir := "./data/KaHIP"
+	preprocessedPath := "./data/preprocessed"
+	resultsPath := "./ch_order_experiment_results.csv"
+	numQueries := 100
+
+	if _, err := os.Stat(preprocessedPath); os.IsNotExist(err) {
+		os.MkdirAll(preprocessedPath, 0755)
+	}
+
+	files, err := os.ReadDir(dataDir)
+	if err != nil {
+		log.Fatalf("failed to read data directory: %v", err)
+	}
+
+	var results []CHOrderExperimentResult
+
+	r := newRand()
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "osm") && strings.HasSuffix(file.Name(), ".txt") {
+			graphName := file.Name()
+			log.Printf("Processing graph: %s", graphName)
+
+			network, err := parser.NewNetworkFromFS(os.DirFS(dataDir), graphName)
+			if err != nil {
+				log.Printf("failed to load graph %s: %v", graphName, err)
+				continue
+			}
+			g := network.Network
+
+			nestedDissection, err := readOrdering(g, filepath.Join(orderingDir, strings.TrimSuffix(graphName, ".txt")+".ordering"))
+			if os.IsNotExist(err) {
+				log.Printf("ordering file not found for %s, skipping", graphName)
+				continue
+			}
+			if err != nil {
+				log.Printf("failed to read ordering for %s: %v", graphName, err)
+				continue
+			}
+
+			greedy := ch.NewContractionHierarchies(ch.WithDeterminism())
+			start := time.Now()
+			greedy.Preprocess(g.Clone())
+			greedyTime := time.Since(start)
+
+			nd := ch.NewContractionHierarchies(ch.WithDeterminism())
+			start = time.Now()
+			if err := nd.PreprocessWithOrder(g.Clone(), nestedDissection); err != nil {
+				log.Printf("CH preprocessing with nested dissection order failed for %s: %v", graphName, err)
+				continue
+			}
+			ndTime := time.Since(start)
+
+			// Export the greedy order and rebuild the CH from it, as for a new metric.
+			orderPath := filepath.Join(preprocessedPath, "ch_"+strings.TrimSuffix(graphName, ".txt")+".ordering")
+			if err := writeOrdering(g, orderPath, greedy.ContractionOrder); err != nil {
+				log.Printf("failed to write greedy order for %s: %v", graphName, err)
+			}
+			rebuilt := ch.NewContractionHierarchies(ch.WithDeterminism())
+			start = time.Now()
+			if err := rebuilt.PreprocessWithOrder(g.Clone(), greedy.ContractionOrder); err != nil {
+				log.Printf("CH rebuild with greedy order failed for %s: %v", graphName, err)
+				continue
+			}
+			rebuildTime := time.Since(start)
+
+			vertices := sortedVertices(g)
+			if len(vertices) < 2 {
+				log.Printf("not enough vertices in graph %s to perform queries", graphName)
+				continue
+			}
+			comparison := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewCHRouter(greedy), routing.NewCHRouter(nd))
+
+			results = append(results, CHOrderExperimentResult{
+				GraphName:            graphName,
+				GreedyTime:           greedyTime,
+				NestedDissectionTime: ndTime,
+				RebuildTime:          rebuildTime,
+				GreedyShortcuts:      greedy.NumShortcutsAdded,
+				NDShortcuts:          nd.NumShortcutsAdded,
+				AvgGreedyTime:        comparison.AvgBaselineTime,
+				AvgNDTime:            comparison.AvgCandidateTime,
+				AvgGreedyNodesPopped: comparison.AvgBaselineNodesPopped,
+				AvgNDNodesPopped:     comparison.AvgCandidateNodesPopped,
+				Mismatches:           comparison.Mismatches,
+			})
+
+			log.Printf("Finished processing %s", graphName)
+		}
+	}
+
+	// Write results to CSV
+	csvFile, err := os.Create(resultsPath)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	defer writer.Flush()
+
+	headers := []string{"Graph", "GreedyTime(ms)", "NestedDissectionTime(ms)", "RebuildTime(ms)", "GreedyShortcuts", "NDShortcuts",
+		"AvgGreedyTime(ms)", "AvgNDTime(ms)", "AvgGreedyNodesPopped", "AvgNDNodesPopped", "Mismatches"}
+	writer.Write(headers)
+
+	for _, result := range results {
+		row := []string{
+			result.GraphName,
+			fmt.Sprintf("%.3f", float64(result.GreedyTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.NestedDissectionTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.RebuildTime.Nanoseconds())/1e6),
+			strconv.Itoa(result.GreedyShortcuts),
+			strconv.Itoa(result.NDShortcuts),
+			fmt.Sprintf("%.3f", float64(result.AvgGreedyTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgNDTime.Nanoseconds())/1e6),
+			strconv.Itoa(result.AvgGreedyNodesPopped),
+			strconv.Itoa(result.AvgNDNodesPopped),
+			strconv.Itoa(result.Mismatches),
+		}
+		writer.Write(row)
+	}
+
+	log.Printf("CH order experiment results written to %s", resultsPath)
+}
+
+// readOrdering reads the node ordering of g at path.
+func readOrdering(g *graph.Graph, path string) ([]graph.VertexId, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parser.ReadOrdering(g, file)
+}
+
+// writeOrdering writes order as a node ordering of g to path.
+func writeOrdering(g *graph.Graph, path string, order []graph.VertexId) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return parser.WriteOrdering(file, g, order)
+}
@@ -0,0 +1,155 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+)
+
+type HubLabelExperimentResult struct {
+	GraphName               string
+	BuildTime               time.Duration
+	AvgLabelSize            float64
+	CompressedBytes         int
+	AvgCHQueryTime          time.Duration
+	AvgHubLabelQueryTime    time.Duration
+	AvgCHDistanceTime       time.Duration
+	AvgHubLabelDistanceTime time.Duration
+	AvgCompressedTime       time.Duration
+	Mismatches              int
+}
+
+func RunHubLabelExperiment() {
+	preprocessedDir := "./data/preprocessed"
+	resultsPath := "./hub_label_experiment_results.csv"
+	numQueries := 1000
+
+	files, err := os.ReadDir(preprocessedDir)
+	if err != nil {
+		log.Fatalf("failed to read preprocessed directory: %v", err)
+	}
+
+	var results []HubLabelExperimentResult
+
+	r := newRand()
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "ch_osm") && strings.HasSuffix(file.Name(), ".gob") {
+			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "ch_"), ".gob") + ".txt"
+			log.Printf("Processing graph: %s", graphName)
+
+			// Load CH graph
+			preprocessedFile, err := preprocessed_graph.ReadCHFile(filepath.Join(preprocessedDir, file.Name()))
+			if err != nil {
+				log.Printf("failed to read preprocessed graph for %s: %v", graphName, err)
+				continue
+			}
+			chInstance := preprocessedFile.ToCH()
+			vertices := chInstance.ContractionOrder
+			if len(vertices) < 2 {
+				log.Printf("not enough vertices in graph %s to perform queries", graphName)
+				continue
+			}
+
+			// Build and save hub labels
+			start := time.Now()
+			labels, err := hublabel.Build(chInstance)
+			buildTime := time.Since(start)
+			if err != nil {
+				log.Printf("failed to build hub labels for %s: %v", graphName, err)
+				continue
+			}
+			compressed := labels.Compress()
+
+			outputPath := filepath.Join(preprocessedDir, "hl_"+strings.TrimSuffix(graphName, ".txt")+".gob")
+			if err := preprocessed_graph.FromHubLabels(labels).WriteHubLabels(outputPath); err != nil {
+				log.Printf("failed to write hub labels for %s: %v", graphName, err)
+			}
+
+			// Full queries with path unpacking
+			comparison := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewCHRouter(chInstance), routing.NewHubLabelRouter(labels, chInstance))
+
+			// Distance-only queries, as used for distance matrices
+			var chTime, labelTime, compressedTime time.Duration
+			mismatches := comparison.Mismatches
+			for i := 0; i < numQueries; i++ {
+				source, target := selectRandomNodes(r, vertices)
+
+				start := time.Now()
+				_, want, _, chErr := chInstance.QueryNoUnpack(source, target)
+				chTime += time.Since(start)
+
+				start = time.Now()
+				got, err := labels.Distance(source, target)
+				labelTime += time.Since(start)
+
+				start = time.Now()
+				gotCompressed, compressedErr := compressed.Distance(source, target)
+				compressedTime += time.Since(start)
+
+				allFailed := chErr != nil && err != nil && compressedErr != nil
+				allSucceeded := chErr == nil && err == nil && compressedErr == nil
+				if !allFailed && (!allSucceeded || got != want || gotCompressed != want) {
+					log.Printf("Distance mismatch for %v to %v on %s: ch=%.2f, labels=%.2f, compressed=%.2f", source, target, graphName, want, got, gotCompressed)
+					mismatches++
+				}
+			}
+
+			results = append(results, HubLabelExperimentResult{
+				GraphName:               graphName,
+				BuildTime:               buildTime,
+				AvgLabelSize:            labels.AverageLabelSize(),
+				CompressedBytes:         compressed.Size(),
+				AvgCHQueryTime:          comparison.AvgBaselineTime,
+				AvgHubLabelQueryTime:    comparison.AvgCandidateTime,
+				AvgCHDistanceTime:       chTime / time.Duration(numQueries),
+				AvgHubLabelDistanceTime: labelTime / time.Duration(numQueries),
+				AvgCompressedTime:       compressedTime / time.Duration(numQueries),
+				Mismatches:              mismatches,
+			})
+
+			log.Printf("Finished processing %s", graphName)
+		}
+	}
+
+	// Write results to CSV
+	csvFile, err := os.Create(resultsPath)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	defer writer.Flush()
+
+	headers := []string{"Graph", "BuildTime(ms)", "AvgLabelSize", "CompressedBytes", "AvgCHQueryTime(ms)", "AvgHubLabelQueryTime(ms)",
+		"AvgCHDistanceTime(us)", "AvgHubLabelDistanceTime(us)", "AvgCompressedDistanceTime(us)", "Mismatches"}
+	writer.Write(headers)
+
+	for _, result := range results {
+		row := []string{
+			result.GraphName,
+			fmt.Sprintf("%.3f", float64(result.BuildTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.2f", result.AvgLabelSize),
+			strconv.Itoa(result.CompressedBytes),
+			fmt.Sprintf("%.3f", float64(result.AvgCHQueryTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgHubLabelQueryTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.3f", float64(result.AvgCHDistanceTime.Nanoseconds())/1e3),
+			fmt.Sprintf("%.3f", float64(result.AvgHubLabelDistanceTime.Nanoseconds())/1e3),
+			fmt.Sprintf("%.3f", float64(result.AvgCompressedTime.Nanoseconds())/1e3),
+			strconv.Itoa(result.Mismatches),
+		}
+		writer.Write(row)
+	}
+
+	log.Printf("Hub label experiment results written to %s", resultsPath)
+}
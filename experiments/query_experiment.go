@@ -8,23 +8,24 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
-	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
 	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
 type QueryExperimentResult struct {
-	GraphName            string
-	AvgDijkstraTime      time.Duration
-	AvgCHDijkstraTime    time.Duration
+	GraphName              string
+	AvgDijkstraTime        time.Duration
+	AvgCHDijkstraTime      time.Duration
 	AvgDijkstraNodesPopped int
-	AvgCHNodesPopped     int
-	Mismatches           int
+	AvgCHNodesPopped       int
+	Mismatches             int
 }
 
 func RunQueryExperiment() {
@@ -40,6 +41,7 @@ func RunQueryExperiment() {
 
 	var results []QueryExperimentResult
 
+	r := newRand()
 	for _, file := range files {
 		if !file.IsDir() && strings.HasPrefix(file.Name(), "ch_osm") && strings.HasSuffix(file.Name(), ".gob") {
 			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "ch_"), ".gob") + ".txt"
@@ -63,60 +65,22 @@ func RunQueryExperiment() {
 			chInstance := preprocessedFile.ToCH()
 
 			// Get vertices for random queries
-			var vertices []graph.VertexId
-			for _, v := range originalNetwork.Network.Vertices {
-				vertices = append(vertices, v.Id)
-			}
+			vertices := sortedVertices(originalNetwork.Network)
 			if len(vertices) < 2 {
 				log.Printf("not enough vertices in graph %s to perform queries", graphName)
 				continue
 			}
 
-			var totalDijkstraTime time.Duration
-			var totalCHDijkstraTime time.Duration
-			var totalDijkstraNodesPopped int
-			var totalCHNodesPopped int
-			mismatches := 0
-
-			for i := 0; i < numQueries; i++ {
-				source, target := selectRandomNodes(vertices)
-
-				// Run Dijkstra on original graph
-				start := time.Now()
-				_, dijkstraDist, dijkstraNodesPopped, err := pathfinding.DijkstraShortestPath(originalNetwork.Network, source, target, math.Inf(1))
-				dijkstraTime := time.Since(start)
-				if err != nil {
-					log.Printf("Dijkstra failed for %s to %s on %s: %v", source, target, graphName, err)
-					continue
-				}
-				totalDijkstraTime += dijkstraTime
-				totalDijkstraNodesPopped += dijkstraNodesPopped
-
-				// Run CH-Dijkstra on CH graph
-				start = time.Now()
-				_, chDist, chNodesPopped, err := chInstance.Query(source, target)
-				chTime := time.Since(start)
-				if err != nil {
-					log.Printf("CH-Dijkstra failed for %s to %s on %s: %v", source, target, graphName, err)
-					continue
-				}
-				totalCHDijkstraTime += chTime
-				totalCHNodesPopped += chNodesPopped
-
-				// Compare distances
-				if math.Abs(dijkstraDist-chDist) > 1e-6 { // Using a tolerance for float comparison
-					log.Printf("Distance mismatch for %s to %s on %s: Dijkstra=%.2f, CH=%.2f", source, target, graphName, dijkstraDist, chDist)
-					mismatches++
-				}
-			}
+			comparison := compareRouters(r, graphName, vertices, numQueries,
+				routing.NewDijkstraRouter(originalNetwork.Network), routing.NewCHRouter(chInstance))
 
 			result := QueryExperimentResult{
-				GraphName:            graphName,
-				AvgDijkstraTime:      totalDijkstraTime / time.Duration(numQueries),
-				AvgCHDijkstraTime:    totalCHDijkstraTime / time.Duration(numQueries),
-				AvgDijkstraNodesPopped: totalDijkstraNodesPopped / numQueries,
-				AvgCHNodesPopped:     totalCHNodesPopped / numQueries,
-				Mismatches:           mismatches,
+				GraphName:              graphName,
+				AvgDijkstraTime:        comparison.AvgBaselineTime,
+				AvgCHDijkstraTime:      comparison.AvgCandidateTime,
+				AvgDijkstraNodesPopped: comparison.AvgBaselineNodesPopped,
+				AvgCHNodesPopped:       comparison.AvgCandidateNodesPopped,
+				Mismatches:             comparison.Mismatches,
 			}
 			results = append(results, result)
 
@@ -152,8 +116,81 @@ func RunQueryExperiment() {
 	log.Printf("Query experiment results written to %s", resultsPath)
 }
 
-func selectRandomNodes(nodes []graph.VertexId) (graph.VertexId, graph.VertexId) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+// queryComparison holds the averaged results of answering the same random
+// queries with a baseline router and a candidate router.
+type queryComparison struct {
+	AvgBaselineTime         time.Duration
+	AvgCandidateTime        time.Duration
+	AvgBaselineNodesPopped  int
+	AvgCandidateNodesPopped int
+	Mismatches              int
+}
+
+// compareRouters runs numQueries random queries on both routers and counts the
+// queries for which the candidate returns a different distance than the baseline.
+func compareRouters(r *rand.Rand, graphName string, vertices []graph.VertexId, numQueries int, baseline, candidate routing.Router) queryComparison {
+	var totalBaselineTime, totalCandidateTime time.Duration
+	var totalBaselineNodesPopped, totalCandidateNodesPopped int
+	mismatches := 0
+
+	for i := 0; i < numQueries; i++ {
+		source, target := selectRandomNodes(r, vertices)
+
+		baselineRoute, err := baseline.Route(source, target, routing.Options{})
+		if err != nil {
+			log.Printf("Baseline query failed for %v to %v on %s: %v", source, target, graphName, err)
+			continue
+		}
+		totalBaselineTime += baselineRoute.Stats.Duration
+		totalBaselineNodesPopped += baselineRoute.Stats.NodesPopped
+
+		candidateRoute, err := candidate.Route(source, target, routing.Options{})
+		if err != nil {
+			log.Printf("Candidate query failed for %v to %v on %s: %v", source, target, graphName, err)
+			continue
+		}
+		totalCandidateTime += candidateRoute.Stats.Duration
+		totalCandidateNodesPopped += candidateRoute.Stats.NodesPopped
+
+		// Compare distances
+		if math.Abs(baselineRoute.Cost-candidateRoute.Cost) > 1e-6 { // Using a tolerance for float comparison
+			log.Printf("Distance mismatch for %v to %v on %s: baseline=%.2f, candidate=%.2f", source, target, graphName, baselineRoute.Cost, candidateRoute.Cost)
+			mismatches++
+		}
+	}
+
+	return queryComparison{
+		AvgBaselineTime:         totalBaselineTime / time.Duration(numQueries),
+		AvgCandidateTime:        totalCandidateTime / time.Duration(numQueries),
+		AvgBaselineNodesPopped:  totalBaselineNodesPopped / numQueries,
+		AvgCandidateNodesPopped: totalCandidateNodesPopped / numQueries,
+		Mismatches:              mismatches,
+	}
+}
+
+// Seed seeds the random queries and weights of the experiments, so that two
+// runs on the same preprocessed files use the same queries.
+var Seed int64 = 1
+
+// newRand returns a random source seeded with Seed. Every experiment run
+// creates its own, so the pairs it draws do not depend on the experiments
+// that ran before it in the same process.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(Seed))
+}
+
+// sortedVertices returns the vertex ids of g in ascending order, so random
+// picks from them do not depend on the map order.
+func sortedVertices(g *graph.Graph) []graph.VertexId {
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+	return vertices
+}
+
+func selectRandomNodes(r *rand.Rand, nodes []graph.VertexId) (graph.VertexId, graph.VertexId) {
 	sourceIndex := r.Intn(len(nodes))
 	targetIndex := r.Intn(len(nodes))
 	for sourceIndex == targetIndex {
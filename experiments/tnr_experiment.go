@@ -0,0 +1,152 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	"github.com/PaulMue0/efficient-routeplanning/internal/tnr"
+)
+
+type TNRExperimentResult struct {
+	GraphName        string
+	TransitNodes     int
+	BuildTime        time.Duration
+	AvgAccessNodes   float64
+	LocalQueries     int
+	AvgCHTime        time.Duration
+	AvgTNRTime       time.Duration
+	AvgTNRGlobalTime time.Duration
+	AvgCHGlobalTime  time.Duration
+	Mismatches       int
+}
+
+func RunTNRExperiment() {
+	preprocessedDir := "./data/preprocessed"
+	resultsPath := "./tnr_experiment_results.csv"
+	numQueries := 1000
+
+	files, err := os.ReadDir(preprocessedDir)
+	if err != nil {
+		log.Fatalf("failed to read preprocessed directory: %v", err)
+	}
+
+	var results []TNRExperimentResult
+
+	r := newRand()
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "ch_osm") && strings.HasSuffix(file.Name(), ".gob") {
+			graphName := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "ch_"), ".gob") + ".txt"
+			log.Printf("Processing graph: %s", graphName)
+
+			// Load CH graph
+			preprocessedFile, err := preprocessed_graph.ReadCHFile(filepath.Join(preprocessedDir, file.Name()))
+			if err != nil {
+				log.Printf("failed to read preprocessed graph for %s: %v", graphName, err)
+				continue
+			}
+			chInstance := preprocessedFile.ToCH()
+			vertices := chInstance.ContractionOrder
+			if len(vertices) < 2 {
+				log.Printf("not enough vertices in graph %s to perform queries", graphName)
+				continue
+			}
+
+			// The number of transit nodes grows with the square root of the
+			// network size, as proposed for CH-based transit node routing.
+			for _, factor := range []float64{1, 4} {
+				numTransit := min(len(vertices), int(factor*math.Sqrt(float64(len(vertices)))))
+
+				start := time.Now()
+				transit, err := tnr.Build(chInstance, numTransit)
+				buildTime := time.Since(start)
+				if err != nil {
+					log.Printf("failed to build transit node routing for %s: %v", graphName, err)
+					continue
+				}
+
+				result := TNRExperimentResult{
+					GraphName:      graphName,
+					TransitNodes:   numTransit,
+					BuildTime:      buildTime,
+					AvgAccessNodes: transit.AverageAccessNodes(),
+				}
+				var chTime, tnrTime, chGlobalTime, tnrGlobalTime time.Duration
+				for i := 0; i < numQueries; i++ {
+					source, target := selectRandomNodes(r, vertices)
+
+					start := time.Now()
+					_, want, _, chErr := chInstance.QueryNoUnpack(source, target)
+					chDuration := time.Since(start)
+
+					start = time.Now()
+					got, local, err := transit.Distance(source, target)
+					tnrDuration := time.Since(start)
+
+					chTime += chDuration
+					tnrTime += tnrDuration
+					if local {
+						result.LocalQueries++
+					} else {
+						chGlobalTime += chDuration
+						tnrGlobalTime += tnrDuration
+					}
+
+					if (chErr == nil) != (err == nil) || (err == nil && got != want) {
+						log.Printf("Distance mismatch for %v to %v on %s: ch=%.2f, tnr=%.2f", source, target, graphName, want, got)
+						result.Mismatches++
+					}
+				}
+
+				result.AvgCHTime = chTime / time.Duration(numQueries)
+				result.AvgTNRTime = tnrTime / time.Duration(numQueries)
+				if global := numQueries - result.LocalQueries; global > 0 {
+					result.AvgCHGlobalTime = chGlobalTime / time.Duration(global)
+					result.AvgTNRGlobalTime = tnrGlobalTime / time.Duration(global)
+				}
+				results = append(results, result)
+			}
+
+			log.Printf("Finished processing %s", graphName)
+		}
+	}
+
+	// Write results to CSV
+	csvFile, err := os.Create(resultsPath)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	defer writer.Flush()
+
+	headers := []string{"Graph", "TransitNodes", "BuildTime(ms)", "AvgAccessNodes", "LocalQueries(%)",
+		"AvgCHTime(us)", "AvgTNRTime(us)", "AvgCHGlobalTime(us)", "AvgTNRGlobalTime(us)", "Mismatches"}
+	writer.Write(headers)
+
+	for _, result := range results {
+		row := []string{
+			result.GraphName,
+			strconv.Itoa(result.TransitNodes),
+			fmt.Sprintf("%.3f", float64(result.BuildTime.Nanoseconds())/1e6),
+			fmt.Sprintf("%.2f", result.AvgAccessNodes),
+			fmt.Sprintf("%.1f", 100*float64(result.LocalQueries)/float64(numQueries)),
+			fmt.Sprintf("%.3f", float64(result.AvgCHTime.Nanoseconds())/1e3),
+			fmt.Sprintf("%.3f", float64(result.AvgTNRTime.Nanoseconds())/1e3),
+			fmt.Sprintf("%.3f", float64(result.AvgCHGlobalTime.Nanoseconds())/1e3),
+			fmt.Sprintf("%.3f", float64(result.AvgTNRGlobalTime.Nanoseconds())/1e3),
+			strconv.Itoa(result.Mismatches),
+		}
+		writer.Write(row)
+	}
+
+	log.Printf("Transit node routing experiment results written to %s", resultsPath)
+}
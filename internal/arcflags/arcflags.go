@@ -0,0 +1,307 @@
+// Package arcflags implements arc-flag preprocessing for goal-directed
+// search. The graph is partitioned into regions and every edge gets one flag
+// per region, set if the edge lies on a shortest path into that region. A
+// query only follows edges flagged for the region of its target. Flags can
+// be computed for the road network itself, for a pruned Dijkstra search, or
+// for the search graph of a contraction hierarchy, for a CH query that is
+// additionally goal-directed.
+package arcflags
+
+import (
+	"container/heap"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+// Flags holds the arc flags of all edges of a graph. It implements
+// pathfinding.ArcFlags.
+type Flags struct {
+	Partition Partition
+	words     int
+	arcs      map[graph.VertexId]map[graph.VertexId]int
+	bits      []uint64
+}
+
+// Region implements pathfinding.ArcFlags.
+func (f *Flags) Region(v graph.VertexId) (int, bool) {
+	region, ok := f.Partition.Region[v]
+	return region, ok
+}
+
+// HasFlag implements pathfinding.ArcFlags.
+func (f *Flags) HasFlag(from, to graph.VertexId, region int) bool {
+	i, ok := f.arcs[from][to]
+	if !ok {
+		return false
+	}
+	return f.bits[i*f.words+region/64]&(1<<(region%64)) != 0
+}
+
+// Density returns the average share of regions flagged per edge.
+func (f *Flags) Density() float64 {
+	if len(f.bits) == 0 || f.Partition.NumRegions == 0 {
+		return 0
+	}
+	set := 0
+	for _, word := range f.bits {
+		set += bits.OnesCount64(word)
+	}
+	return float64(set) / float64(len(f.bits)/f.words*f.Partition.NumRegions)
+}
+
+// Compute computes the arc flags of g for the partition p. Within a region
+// all edges are flagged. For every boundary vertex b of a region, i.e. a
+// vertex with an incoming edge from another region, a backward Dijkstra
+// search flags all edges on shortest paths to b.
+func Compute(g *graph.Graph, p Partition) *Flags {
+	s := newSearchGraph(p)
+	for u, edges := range g.Edges {
+		for v, edge := range edges {
+			s.addArc(u, v, edge.Weight, false)
+		}
+	}
+	return s.compute()
+}
+
+// ComputeCH computes the arc flags of the upward and downward graphs of c for
+// the partition p, as used by pathfinding.CHArcFlagShortestPath. The backward
+// searches only follow up-down paths, so an edge is flagged if it lies on a
+// shortest up-down path into the region. A core CH is rejected with
+// ch.ErrHasCore, since up-down paths do not cover paths through the core.
+func ComputeCH(c *ch.ContractionHierarchies, p Partition) (*Flags, error) {
+	if err := c.RequireNoCore(); err != nil {
+		return nil, err
+	}
+	s := newSearchGraph(p)
+	for u, edges := range c.UpwardsGraph.Edges {
+		for v, edge := range edges {
+			s.addArc(u, v, edge.Weight, false)
+		}
+	}
+	for u, edges := range c.DownwardsGraph.Edges {
+		for v, edge := range edges {
+			s.addArc(u, v, edge.Weight, true)
+		}
+	}
+	return s.compute(), nil
+}
+
+// Phases of the backward search. An up-down path is in the up phase until it
+// takes its first downward edge.
+const (
+	phaseUp = iota
+	phaseDown
+)
+
+type arc struct {
+	from, to int
+	weight   int
+	down     bool
+}
+
+// searchGraph is a dense copy of the graph to flag. Arcs are grouped by their
+// head for the backward searches.
+type searchGraph struct {
+	partition Partition
+	ids       []graph.VertexId
+	index     map[graph.VertexId]int
+	arcs      []arc
+	incoming  [][]int
+}
+
+func newSearchGraph(p Partition) *searchGraph {
+	return &searchGraph{partition: p, index: make(map[graph.VertexId]int)}
+}
+
+func (s *searchGraph) vertex(v graph.VertexId) int {
+	i, ok := s.index[v]
+	if !ok {
+		i = len(s.ids)
+		s.index[v] = i
+		s.ids = append(s.ids, v)
+		s.incoming = append(s.incoming, nil)
+	}
+	return i
+}
+
+func (s *searchGraph) addArc(u, v graph.VertexId, weight int, down bool) {
+	if weight == graph.InfWeight {
+		return
+	}
+	from, to := s.vertex(u), s.vertex(v)
+	s.incoming[to] = append(s.incoming[to], len(s.arcs))
+	s.arcs = append(s.arcs, arc{from: from, to: to, weight: weight, down: down})
+}
+
+func (s *searchGraph) region(i int) int {
+	region, ok := s.partition.Region[s.ids[i]]
+	if !ok {
+		return -1
+	}
+	return region
+}
+
+func (s *searchGraph) compute() *Flags {
+	f := &Flags{
+		Partition: s.partition,
+		words:     (s.partition.NumRegions + 63) / 64,
+		arcs:      make(map[graph.VertexId]map[graph.VertexId]int),
+	}
+	f.bits = make([]uint64, len(s.arcs)*f.words)
+	for i, a := range s.arcs {
+		from, to := s.ids[a.from], s.ids[a.to]
+		if f.arcs[from] == nil {
+			f.arcs[from] = make(map[graph.VertexId]int)
+		}
+		f.arcs[from][to] = i
+	}
+
+	boundaries := make([][]int, s.partition.NumRegions)
+	for i := range s.ids {
+		region := s.region(i)
+		if region < 0 {
+			continue
+		}
+		for _, a := range s.incoming[i] {
+			if s.region(s.arcs[a].from) != region {
+				boundaries[region] = append(boundaries[region], i)
+				break
+			}
+		}
+	}
+
+	regions := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			distances := make([]int, 2*len(s.ids))
+			for region := range regions {
+				for _, b := range boundaries[region] {
+					s.backwardSearch(b, distances)
+					s.flagTightArcs(f, region, distances)
+				}
+			}
+		}()
+	}
+	for region := range s.partition.NumRegions {
+		regions <- region
+	}
+	close(regions)
+	wg.Wait()
+
+	for i, a := range s.arcs {
+		if region := s.region(a.to); region >= 0 && region == s.region(a.from) {
+			f.bits[i*f.words+region/64] |= 1 << (region % 64)
+		}
+	}
+	return f
+}
+
+// backwardSearch computes the distance of every state (vertex, phase) to the
+// boundary vertex b, -1 if b is not reachable. States are indexed by
+// 2*vertex+phase.
+func (s *searchGraph) backwardSearch(b int, distances []int) {
+	for i := range distances {
+		distances[i] = -1
+	}
+	distances[2*b+phaseUp], distances[2*b+phaseDown] = 0, 0
+
+	queue := collection.NewPriorityQueue[int]()
+	queue.PushWithPriority(2*b+phaseUp, 0)
+	queue.PushWithPriority(2*b+phaseDown, 0)
+	settled := make([]bool, len(distances))
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[int])
+		state := queue.GetValue(item)
+		if settled[state] {
+			continue
+		}
+		settled[state] = true
+		vertex, phase, dist := state/2, state%2, distances[state]
+
+		for _, i := range s.incoming[vertex] {
+			a := s.arcs[i]
+			if a.down != (phase == phaseDown) {
+				continue
+			}
+			// An up-phase path may take a downward edge, a down-phase path
+			// can only have taken downward edges before.
+			predecessors := []int{2*a.from + phaseUp}
+			if a.down {
+				predecessors = append(predecessors, 2*a.from+phaseDown)
+			}
+			for _, previous := range predecessors {
+				if d := dist + a.weight; !settled[previous] && (distances[previous] < 0 || d < distances[previous]) {
+					distances[previous] = d
+					queue.PushWithPriority(previous, float64(d))
+				}
+			}
+		}
+	}
+}
+
+// flagTightArcs flags every arc that lies on a shortest path to the boundary
+// vertex the distances were computed for.
+func (s *searchGraph) flagTightArcs(f *Flags, region int, distances []int) {
+	mask := uint64(1) << (region % 64)
+	for i, a := range s.arcs {
+		phase := phaseUp
+		if a.down {
+			phase = phaseDown
+		}
+		head := distances[2*a.to+phase]
+		if head < 0 {
+			continue
+		}
+		d := head + a.weight
+		if distances[2*a.from+phaseUp] == d || (a.down && distances[2*a.from+phaseDown] == d) {
+			atomic.OrUint64(&f.bits[i*f.words+region/64], mask)
+		}
+	}
+}
+
+// CHArcFlags combines a contraction hierarchy with arc flags on its search
+// graph.
+type CHArcFlags struct {
+	CH    *ch.ContractionHierarchies
+	Flags *Flags
+}
+
+// NewCHArcFlags computes the arc flags of c for the partition p.
+func NewCHArcFlags(c *ch.ContractionHierarchies, p Partition) (*CHArcFlags, error) {
+	flags, err := ComputeCH(c, p)
+	if err != nil {
+		return nil, err
+	}
+	return &CHArcFlags{CH: c, Flags: flags}, nil
+}
+
+// Query finds the shortest path from source to target with the arc-flag
+// pruned up-down search and unpacks all shortcuts.
+func (a *CHArcFlags) Query(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	path, weight, nodesPopped, err := a.QueryNoUnpack(source, target)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	path, err = a.CH.UnpackPath(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return path, weight, nodesPopped, nil
+}
+
+// QueryNoUnpack finds the shortest path from source to target with the
+// arc-flag pruned up-down search and keeps all shortcuts.
+func (a *CHArcFlags) QueryNoUnpack(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	return pathfinding.CHArcFlagShortestPath(a.CH.UpwardsGraph, a.CH.DownwardsGraph, a.Flags, source, target)
+}
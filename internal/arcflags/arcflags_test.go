@@ -0,0 +1,154 @@
+package arcflags
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func loadOsm1(t *testing.T) *graph.Graph {
+	t.Helper()
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	return network.Network
+}
+
+func TestKDPartition(t *testing.T) {
+	g := loadOsm1(t)
+	p, err := KDPartition(g, 6)
+	if err != nil {
+		t.Fatalf("KDPartition failed: %v", err)
+	}
+
+	sizes := make([]int, p.NumRegions)
+	for v := range g.Vertices {
+		region, ok := p.Region[v]
+		if !ok || region < 0 || region >= p.NumRegions {
+			t.Fatalf("vertex %d has region %d, %v", v, region, ok)
+		}
+		sizes[region]++
+	}
+	for region, size := range sizes {
+		if want := len(g.Vertices) / p.NumRegions; size < want-1 || size > want+1 {
+			t.Errorf("region %d has %d vertices, want about %d", region, size, want)
+		}
+	}
+
+	for _, numRegions := range []int{0, len(g.Vertices) + 1} {
+		if _, err := KDPartition(g, numRegions); !errors.Is(err, ErrInvalidPartition) {
+			t.Errorf("KDPartition(%d) error = %v, want ErrInvalidPartition", numRegions, err)
+		}
+	}
+}
+
+func TestNewPartition(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0})
+	g.AddVertex(graph.Vertex{Id: 1})
+
+	p, err := NewPartition(g, map[graph.VertexId]int{0: 0, 1: 3})
+	if err != nil || p.NumRegions != 4 {
+		t.Errorf("NewPartition = %d regions, %v, want 4 regions", p.NumRegions, err)
+	}
+	if _, err := NewPartition(g, map[graph.VertexId]int{0: 0}); !errors.Is(err, ErrInvalidPartition) {
+		t.Errorf("missing region error = %v, want ErrInvalidPartition", err)
+	}
+	if _, err := NewPartition(g, map[graph.VertexId]int{0: 0, 1: -1}); !errors.Is(err, ErrInvalidPartition) {
+		t.Errorf("negative region error = %v, want ErrInvalidPartition", err)
+	}
+}
+
+func TestArcFlagQueries(t *testing.T) {
+	g := loadOsm1(t)
+	p, err := KDPartition(g, 8)
+	if err != nil {
+		t.Fatalf("KDPartition failed: %v", err)
+	}
+	flags := Compute(g, p)
+	c := ch.NewContractionHierarchies()
+	c.Preprocess(g.Clone())
+	chFlags, err := NewCHArcFlags(c, p)
+	if err != nil {
+		t.Fatalf("NewCHArcFlags failed: %v", err)
+	}
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	r := rand.New(rand.NewSource(1))
+	var dijkstraPopped, arcFlagPopped int
+	for i := 0; i < 300; i++ {
+		s, d := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+		_, want, popped, wantErr := pathfinding.DijkstraShortestPath(g, s, d, math.Inf(1))
+		dijkstraPopped += popped
+
+		path, got, popped, err := pathfinding.ArcFlagDijkstraShortestPath(g, flags, s, d)
+		arcFlagPopped += popped
+		if wantErr != nil {
+			if !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+				t.Errorf("arc-flag Dijkstra %d -> %d error = %v, want ErrTargetNotReachable", s, d, err)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("arc-flag Dijkstra %d -> %d = %v, %v, want %v", s, d, got, err, want)
+		}
+		assertPathWeight(t, g, path, s, d, want)
+
+		path, got, _, err = chFlags.Query(s, d)
+		if err != nil || got != want {
+			t.Errorf("CH arc-flag query %d -> %d = %v, %v, want %v", s, d, got, err, want)
+		}
+		assertPathWeight(t, g, path, s, d, want)
+	}
+
+	if arcFlagPopped >= dijkstraPopped {
+		t.Errorf("arc-flag Dijkstra popped %d nodes, Dijkstra %d", arcFlagPopped, dijkstraPopped)
+	}
+	if density := flags.Density(); density <= 0 || density >= 1 {
+		t.Errorf("Density() = %v, want a share strictly between 0 and 1", density)
+	}
+}
+
+func TestCHArcFlagsCoreCH(t *testing.T) {
+	g := loadOsm1(t)
+	p, err := KDPartition(g, 8)
+	if err != nil {
+		t.Fatalf("KDPartition failed: %v", err)
+	}
+	c := ch.NewContractionHierarchies(ch.WithCore(100, 0))
+	c.Preprocess(g.Clone())
+	if _, err := NewCHArcFlags(c, p); !errors.Is(err, ch.ErrHasCore) {
+		t.Errorf("NewCHArcFlags error = %v, want ch.ErrHasCore", err)
+	}
+}
+
+func assertPathWeight(t *testing.T, g *graph.Graph, path []graph.VertexId, source, target graph.VertexId, want float64) {
+	t.Helper()
+	if len(path) == 0 || path[0] != source || path[len(path)-1] != target {
+		t.Errorf("path %v does not lead from %d to %d", path, source, target)
+		return
+	}
+	sum := 0
+	for i := 0; i+1 < len(path); i++ {
+		edge, ok := g.Edges[path[i]][path[i+1]]
+		if !ok {
+			t.Errorf("path %v uses missing edge %d -> %d", path, path[i], path[i+1])
+			return
+		}
+		sum += edge.Weight
+	}
+	if float64(sum) != want {
+		t.Errorf("path %v has weight %d, want %v", path, sum, want)
+	}
+}
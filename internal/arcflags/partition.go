@@ -0,0 +1,81 @@
+package arcflags
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var ErrInvalidPartition = errors.New("invalid partition")
+
+// Partition assigns every vertex of a graph to one of NumRegions regions,
+// numbered from zero.
+type Partition struct {
+	NumRegions int
+	Region     map[graph.VertexId]int
+}
+
+// NewPartition validates a region assignment, e.g. one read with
+// parser.ReadMetisPartition, and determines the number of regions.
+func NewPartition(g *graph.Graph, regions map[graph.VertexId]int) (Partition, error) {
+	p := Partition{Region: regions}
+	for v := range g.Vertices {
+		region, ok := regions[v]
+		if !ok {
+			return Partition{}, fmt.Errorf("%w: vertex %d has no region", ErrInvalidPartition, v)
+		}
+		if region < 0 {
+			return Partition{}, fmt.Errorf("%w: vertex %d has negative region %d", ErrInvalidPartition, v, region)
+		}
+		p.NumRegions = max(p.NumRegions, region+1)
+	}
+	return p, nil
+}
+
+// KDPartition splits the vertices of g into numRegions regions of about
+// equal size by recursive coordinate bisection: every step sorts the vertices
+// along the coordinate with the larger extent and cuts them in proportion to
+// the number of regions on either side.
+func KDPartition(g *graph.Graph, numRegions int) (Partition, error) {
+	if numRegions < 1 || numRegions > max(1, len(g.Vertices)) {
+		return Partition{}, fmt.Errorf("%w: %d regions for %d vertices", ErrInvalidPartition, numRegions, len(g.Vertices))
+	}
+
+	vertices := make([]graph.Vertex, 0, len(g.Vertices))
+	for _, v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i].Id < vertices[j].Id })
+
+	p := Partition{NumRegions: numRegions, Region: make(map[graph.VertexId]int, len(vertices))}
+	bisect(vertices, 0, numRegions, p.Region)
+	return p, nil
+}
+
+func bisect(vertices []graph.Vertex, firstRegion, numRegions int, regions map[graph.VertexId]int) {
+	if numRegions == 1 {
+		for _, v := range vertices {
+			regions[v.Id] = firstRegion
+		}
+		return
+	}
+
+	minLat, maxLat := vertices[0].Lat, vertices[0].Lat
+	minLon, maxLon := vertices[0].Lon, vertices[0].Lon
+	for _, v := range vertices {
+		minLat, maxLat = min(minLat, v.Lat), max(maxLat, v.Lat)
+		minLon, maxLon = min(minLon, v.Lon), max(maxLon, v.Lon)
+	}
+	coordinate := func(v graph.Vertex) float64 { return v.Lat }
+	if maxLon-minLon > maxLat-minLat {
+		coordinate = func(v graph.Vertex) float64 { return v.Lon }
+	}
+	sort.SliceStable(vertices, func(i, j int) bool { return coordinate(vertices[i]) < coordinate(vertices[j]) })
+
+	leftRegions := numRegions / 2
+	cut := len(vertices) * leftRegions / numRegions
+	bisect(vertices[:cut], firstRegion, leftRegions, regions)
+	bisect(vertices[cut:], firstRegion+leftRegions, numRegions-leftRegions, regions)
+}
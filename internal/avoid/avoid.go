@@ -0,0 +1,138 @@
+// Package avoid describes per-query constraints such as areas, edges or road
+// classes a route must not use. Constraints are applied to a copy of the
+// metric, so a single query can be answered with them without changing the
+// weights other queries see.
+package avoid
+
+import (
+	"errors"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrInvalidArea is returned for polygons with fewer than three corners and
+// bounding boxes whose minimum exceeds their maximum.
+var ErrInvalidArea = errors.New("invalid avoid area")
+
+// Point is a geographic coordinate.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Area is a region of the map.
+type Area interface {
+	Contains(p Point) bool
+}
+
+// BoundingBox is the rectangle between two corners.
+type BoundingBox struct {
+	Min Point
+	Max Point
+}
+
+// NewBoundingBox returns the bounding box between min and max.
+func NewBoundingBox(min, max Point) (BoundingBox, error) {
+	if min.Lat > max.Lat || min.Lon > max.Lon {
+		return BoundingBox{}, ErrInvalidArea
+	}
+	return BoundingBox{Min: min, Max: max}, nil
+}
+
+// Contains reports whether p lies inside the box or on its border.
+func (b BoundingBox) Contains(p Point) bool {
+	return p.Lat >= b.Min.Lat && p.Lat <= b.Max.Lat && p.Lon >= b.Min.Lon && p.Lon <= b.Max.Lon
+}
+
+// Polygon is a simple polygon given by its corners. The last corner is
+// connected to the first one.
+type Polygon []Point
+
+// NewPolygon returns the polygon with the given corners.
+func NewPolygon(corners []Point) (Polygon, error) {
+	if len(corners) < 3 {
+		return nil, ErrInvalidArea
+	}
+	return Polygon(corners), nil
+}
+
+// Contains reports whether p lies inside the polygon using the even-odd rule.
+// Latitude and longitude are treated as plane coordinates, which is accurate
+// enough for the areas of a city or a region.
+func (poly Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Lat > p.Lat) == (b.Lat > p.Lat) {
+			continue
+		}
+		crossing := a.Lon + (p.Lat-a.Lat)/(b.Lat-a.Lat)*(b.Lon-a.Lon)
+		if p.Lon < crossing {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Edge is an edge to avoid. Both directions of the road are avoided.
+type Edge struct {
+	From graph.VertexId
+	To   graph.VertexId
+}
+
+// Constraints lists what a route must not use. An edge is avoided if one of
+// its end points lies in one of the areas, if it is listed in Edges or if its
+// road class is listed in RoadClasses. The zero value avoids nothing.
+type Constraints struct {
+	Areas       []Area
+	Edges       []Edge
+	RoadClasses []graph.RoadClass
+}
+
+// IsEmpty reports whether the constraints avoid nothing.
+func (c Constraints) IsEmpty() bool {
+	return len(c.Areas) == 0 && len(c.Edges) == 0 && len(c.RoadClasses) == 0
+}
+
+// Apply returns a copy of g in which every avoided edge has graph.InfWeight.
+// The topology is unchanged, so the result can be used to customize a copy of
+// a CCH built for g.
+func (c Constraints) Apply(g *graph.Graph) *graph.Graph {
+	metric := g.Clone()
+	if c.IsEmpty() {
+		return metric
+	}
+
+	avoidedVertices := make(map[graph.VertexId]bool)
+	if len(c.Areas) > 0 {
+		for id, v := range g.Vertices {
+			point := Point{Lat: v.Lat, Lon: v.Lon}
+			for _, area := range c.Areas {
+				if area.Contains(point) {
+					avoidedVertices[id] = true
+					break
+				}
+			}
+		}
+	}
+	avoidedEdges := make(map[Edge]bool, 2*len(c.Edges))
+	for _, e := range c.Edges {
+		avoidedEdges[e] = true
+		avoidedEdges[Edge{From: e.To, To: e.From}] = true
+	}
+	avoidedClasses := make(map[graph.RoadClass]bool, len(c.RoadClasses))
+	for _, class := range c.RoadClasses {
+		avoidedClasses[class] = true
+	}
+
+	for from, targets := range metric.Edges {
+		for to, edge := range targets {
+			if avoidedVertices[from] || avoidedVertices[to] ||
+				avoidedEdges[Edge{From: from, To: to}] || avoidedClasses[edge.Attributes.RoadClass] {
+				edge.Weight = graph.InfWeight
+				targets[to] = edge
+			}
+		}
+	}
+	return metric
+}
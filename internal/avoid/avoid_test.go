@@ -0,0 +1,93 @@
+package avoid
+
+import (
+	"errors"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestPolygonContains(t *testing.T) {
+	// An L-shaped polygon: the upper right quarter of the square is missing.
+	polygon, err := NewPolygon([]Point{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}})
+	if err != nil {
+		t.Fatalf("NewPolygon failed: %v", err)
+	}
+	testCases := []struct {
+		point Point
+		want  bool
+	}{
+		{Point{0.5, 0.5}, true},
+		{Point{1.5, 0.5}, true},
+		{Point{0.5, 1.5}, true},
+		{Point{1.5, 1.5}, false},
+		{Point{3, 0.5}, false},
+		{Point{-1, -1}, false},
+	}
+	for _, tc := range testCases {
+		if got := polygon.Contains(tc.point); got != tc.want {
+			t.Errorf("Contains(%v) = %t, want %t", tc.point, got, tc.want)
+		}
+	}
+
+	if _, err := NewPolygon([]Point{{0, 0}, {1, 1}}); !errors.Is(err, ErrInvalidArea) {
+		t.Errorf("got %v want %v", err, ErrInvalidArea)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	box, err := NewBoundingBox(Point{48, 9}, Point{49, 10})
+	if err != nil {
+		t.Fatalf("NewBoundingBox failed: %v", err)
+	}
+	if !box.Contains(Point{48.5, 9.5}) || !box.Contains(Point{48, 10}) || box.Contains(Point{47.9, 9.5}) {
+		t.Error("Contains does not match the box")
+	}
+	if _, err := NewBoundingBox(Point{49, 9}, Point{48, 10}); !errors.Is(err, ErrInvalidArea) {
+		t.Errorf("got %v want %v", err, ErrInvalidArea)
+	}
+}
+
+func TestApply(t *testing.T) {
+	g := graph.NewGraph()
+	for i, lat := range []float64{48.0, 48.1, 48.2, 48.3} {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i), Lat: lat, Lon: 9.0})
+	}
+	for _, e := range [][2]graph.VertexId{{0, 1}, {1, 2}, {2, 3}} {
+		g.AddEdge(e[0], e[1], 1, false, -1)
+		g.AddEdge(e[1], e[0], 1, false, -1)
+	}
+	g.SetAttributes(2, 3, graph.Attributes{RoadClass: graph.RoadClassMotorway})
+
+	box, _ := NewBoundingBox(Point{48.05, 8.9}, Point{48.15, 9.1})
+	metric := Constraints{
+		Areas:       []Area{box},
+		RoadClasses: []graph.RoadClass{graph.RoadClassMotorway},
+	}.Apply(g)
+
+	testCases := []struct {
+		from, to graph.VertexId
+		want     int
+	}{
+		{0, 1, graph.InfWeight},
+		{2, 1, graph.InfWeight},
+		{2, 3, graph.InfWeight},
+		{3, 2, 1},
+	}
+	for _, tc := range testCases {
+		if got := metric.Edges[tc.from][tc.to].Weight; got != tc.want {
+			t.Errorf("edge %d->%d: got weight %d, want %d", tc.from, tc.to, got, tc.want)
+		}
+	}
+	if g.Edges[0][1].Weight != 1 {
+		t.Error("Apply changed the original graph")
+	}
+
+	metric = Constraints{Edges: []Edge{{From: 3, To: 2}}}.Apply(g)
+	if metric.Edges[2][3].Weight != graph.InfWeight || metric.Edges[3][2].Weight != graph.InfWeight {
+		t.Error("both directions of an avoided edge should have infinite weight")
+	}
+	if metric.Edges[0][1].Weight != 1 {
+		t.Error("edges that are not avoided should keep their weight")
+	}
+}
@@ -0,0 +1,191 @@
+package bench
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+)
+
+// heapMetric is the runtime metric sampled for the peak heap size: the bytes
+// occupied by live and not yet swept objects.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// Options controls a measurement.
+type Options struct {
+	// Warmup is the number of queries run before measuring, taken cyclically
+	// from the query set. They fill caches and let the workspace pool grow.
+	Warmup int
+}
+
+// Sample is the outcome of one measured query.
+type Sample struct {
+	Query       Query
+	Duration    time.Duration
+	NodesPopped int
+	Cost        float64 // +Inf if the target is unreachable
+}
+
+// Measurement holds the samples of one router on one query set.
+type Measurement struct {
+	Router  string
+	Samples []Sample
+	// Mallocs and AllocBytes are the heap allocations of all measured queries.
+	Mallocs    uint64
+	AllocBytes uint64
+	// PeakHeapBytes is the largest heap size observed after a query,
+	// including the data of the router itself.
+	PeakHeapBytes uint64
+}
+
+// Measure runs the queries one after another on r and records the latency of
+// each. Latencies are wall clock times around Route. The heap size is sampled
+// after every query outside of the timed section. Queries that fail, e.g.
+// because the target is unreachable, are recorded with an infinite cost.
+func Measure(name string, r routing.Router, queries []Query, opts Options) *Measurement {
+	for i := 0; i < opts.Warmup && len(queries) > 0; i++ {
+		q := queries[i%len(queries)]
+		r.Route(q.Source, q.Target, routing.Options{})
+	}
+	runtime.GC()
+
+	m := &Measurement{Router: name, Samples: make([]Sample, 0, len(queries))}
+	heap := []metrics.Sample{{Name: heapMetric}}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for _, q := range queries {
+		start := time.Now()
+		route, err := r.Route(q.Source, q.Target, routing.Options{})
+		duration := time.Since(start)
+
+		sample := Sample{Query: q, Duration: duration, Cost: math.Inf(1)}
+		if err == nil {
+			sample.Cost = route.Cost
+			sample.NodesPopped = route.Stats.NodesPopped
+		}
+		m.Samples = append(m.Samples, sample)
+
+		metrics.Read(heap)
+		if heap[0].Value.Kind() == metrics.KindUint64 {
+			m.PeakHeapBytes = max(m.PeakHeapBytes, heap[0].Value.Uint64())
+		}
+	}
+	runtime.ReadMemStats(&after)
+	m.Mallocs = after.Mallocs - before.Mallocs
+	m.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	return m
+}
+
+// Result summarizes the samples of a measurement, or the samples of one rank.
+type Result struct {
+	Graph          string  `json:"graph"`
+	Router         string  `json:"router"`
+	QuerySet       string  `json:"querySet"`
+	Rank           int     `json:"rank"` // RandomRank for a summary over all queries
+	Queries        int     `json:"queries"`
+	Unreachable    int     `json:"unreachable"`
+	MeanMs         float64 `json:"meanMs"`
+	P50Ms          float64 `json:"p50Ms"`
+	P95Ms          float64 `json:"p95Ms"`
+	P99Ms          float64 `json:"p99Ms"`
+	MaxMs          float64 `json:"maxMs"`
+	AvgNodesPopped float64 `json:"avgNodesPopped"`
+	AllocsPerQuery float64 `json:"allocsPerQuery"`
+	BytesPerQuery  float64 `json:"bytesPerQuery"`
+	PeakHeapBytes  uint64  `json:"peakHeapBytes"`
+}
+
+// Summary summarizes all samples of m. Graph and QuerySet are left for the
+// caller to fill in.
+func (m *Measurement) Summary() Result {
+	return m.summarize(m.Samples, RandomRank)
+}
+
+// ByRank summarizes the samples of every Dijkstra rank in ascending order of
+// rank. Random queries are left out.
+func (m *Measurement) ByRank() []Result {
+	byRank := make(map[int][]Sample)
+	for _, s := range m.Samples {
+		if s.Query.Rank != RandomRank {
+			byRank[s.Query.Rank] = append(byRank[s.Query.Rank], s)
+		}
+	}
+	ranks := make([]int, 0, len(byRank))
+	for rank := range byRank {
+		ranks = append(ranks, rank)
+	}
+	slices.Sort(ranks)
+
+	results := make([]Result, 0, len(ranks))
+	for _, rank := range ranks {
+		results = append(results, m.summarize(byRank[rank], rank))
+	}
+	return results
+}
+
+func (m *Measurement) summarize(samples []Sample, rank int) Result {
+	result := Result{Router: m.Router, Rank: rank, Queries: len(samples), PeakHeapBytes: m.PeakHeapBytes}
+	if len(samples) == 0 {
+		return result
+	}
+
+	durations := make([]time.Duration, 0, len(samples))
+	var total time.Duration
+	nodesPopped, reached := 0, 0
+	for _, s := range samples {
+		durations = append(durations, s.Duration)
+		total += s.Duration
+		if math.IsInf(s.Cost, 1) {
+			result.Unreachable++
+			continue
+		}
+		nodesPopped += s.NodesPopped
+		reached++
+	}
+	slices.Sort(durations)
+
+	result.MeanMs = milliseconds(total) / float64(len(samples))
+	result.P50Ms = milliseconds(Percentile(durations, 50))
+	result.P95Ms = milliseconds(Percentile(durations, 95))
+	result.P99Ms = milliseconds(Percentile(durations, 99))
+	result.MaxMs = milliseconds(durations[len(durations)-1])
+	if reached > 0 {
+		result.AvgNodesPopped = float64(nodesPopped) / float64(reached)
+	}
+	// Allocations are only known for the whole measurement and are spread
+	// evenly over its queries.
+	result.AllocsPerQuery = float64(m.Mallocs) / float64(len(m.Samples))
+	result.BytesPerQuery = float64(m.AllocBytes) / float64(len(m.Samples))
+	return result
+}
+
+// Percentile returns the p-th percentile of the sorted durations by the
+// nearest rank method, i.e. the smallest duration that is at least as large
+// as p percent of all durations.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// Mismatches counts the queries whose cost differs between two measurements
+// of the same query set.
+func Mismatches(a, b *Measurement) int {
+	mismatches := 0
+	for i := range min(len(a.Samples), len(b.Samples)) {
+		ca, cb := a.Samples[i].Cost, b.Samples[i].Cost
+		if ca != cb && math.Abs(ca-cb) > 1e-6 {
+			mismatches++
+		}
+	}
+	return mismatches
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e6
+}
@@ -0,0 +1,162 @@
+package bench
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func loadOsm1(t *testing.T) *graph.Graph {
+	t.Helper()
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	return network.Network
+}
+
+func TestRandomQueries(t *testing.T) {
+	g := loadOsm1(t)
+
+	queries := RandomQueries(g, 100, 7)
+	if len(queries) != 100 {
+		t.Fatalf("got %d queries, want 100", len(queries))
+	}
+	for _, q := range queries {
+		if q.Source == q.Target || q.Rank != RandomRank {
+			t.Errorf("invalid random query %+v", q)
+		}
+	}
+	if again := RandomQueries(g, 100, 7); !reflect.DeepEqual(queries, again) {
+		t.Error("the same seed gave different queries")
+	}
+	if other := RandomQueries(g, 100, 8); reflect.DeepEqual(queries, other) {
+		t.Error("different seeds gave the same queries")
+	}
+}
+
+func TestRankQueries(t *testing.T) {
+	g := loadOsm1(t)
+
+	queries := RankQueries(g, 5, 1)
+	if len(queries) == 0 {
+		t.Fatal("got no queries")
+	}
+	for _, q := range queries {
+		// The target of rank i has exactly 2^i vertices settled before it,
+		// up to ties in the distance.
+		distances := pathfinding.UpwardSearch(g, nil, q.Source, nil)
+		closer := 0
+		for _, d := range distances {
+			if d < distances[q.Target] {
+				closer++
+			}
+		}
+		if closer > 1<<q.Rank {
+			t.Errorf("query %+v: %d vertices are closer than the target", q, closer)
+		}
+	}
+	if again := RankQueries(g, 5, 1); !reflect.DeepEqual(queries, again) {
+		t.Error("the same seed gave different queries")
+	}
+}
+
+func TestQueriesRoundTrip(t *testing.T) {
+	g := loadOsm1(t)
+	queries := append(RandomQueries(g, 10, 1), RankQueries(g, 2, 1)...)
+
+	var buf bytes.Buffer
+	if err := WriteQueries(&buf, queries); err != nil {
+		t.Fatalf("WriteQueries failed: %v", err)
+	}
+	got, err := ReadQueries(g, &buf)
+	if err != nil {
+		t.Fatalf("ReadQueries failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, queries) {
+		t.Errorf("ReadQueries = %v, want %v", got, queries)
+	}
+
+	for name, content := range map[string]string{
+		"fields":  "1 2\n",
+		"number":  "1 x -1\n",
+		"unknown": "1 100000 -1\n",
+	} {
+		if _, err := ReadQueries(g, strings.NewReader(content)); !errors.Is(err, ErrInvalidQueries) {
+			t.Errorf("%s: got error %v, want ErrInvalidQueries", name, err)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 100; i++ {
+		durations = append(durations, time.Duration(i))
+	}
+	for p, want := range map[float64]time.Duration{0: 1, 50: 50, 95: 95, 99: 99, 100: 100} {
+		if got := Percentile(durations, p); got != want {
+			t.Errorf("Percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if got := Percentile(durations[:1], 99); got != 1 {
+		t.Errorf("Percentile of one duration = %v, want 1", got)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	g := loadOsm1(t)
+	c := ch.NewContractionHierarchies(ch.WithDeterminism())
+	c.Preprocess(g.Clone())
+
+	queries := append(RandomQueries(g, 50, 1), RankQueries(g, 3, 1)...)
+	dijkstra := Measure("Dijkstra", routing.NewDijkstraRouter(g), queries, Options{Warmup: 10})
+	hierarchy := Measure("CH", routing.NewCHRouter(c), queries, Options{Warmup: 10})
+
+	if n := Mismatches(dijkstra, hierarchy); n != 0 {
+		t.Errorf("%d mismatches between Dijkstra and CH", n)
+	}
+
+	summary := hierarchy.Summary()
+	if summary.Queries != len(queries) || summary.Router != "CH" || summary.Rank != RandomRank {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if !(summary.P50Ms <= summary.P95Ms && summary.P95Ms <= summary.P99Ms && summary.P99Ms <= summary.MaxMs) {
+		t.Errorf("percentiles out of order: %+v", summary)
+	}
+	if summary.PeakHeapBytes == 0 {
+		t.Error("no peak heap size measured")
+	}
+
+	byRank := dijkstra.ByRank()
+	if len(byRank) == 0 {
+		t.Fatal("no results by rank")
+	}
+	total := 0
+	for i, r := range byRank {
+		if i > 0 && r.Rank <= byRank[i-1].Rank {
+			t.Errorf("ranks not ascending: %d after %d", r.Rank, byRank[i-1].Rank)
+		}
+		total += r.Queries
+	}
+	if total != len(queries)-50 {
+		t.Errorf("got %d rank queries, want %d", total, len(queries)-50)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, append([]Result{summary}, byRank...)); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(byRank)+2 {
+		t.Errorf("WriteCSV wrote %d lines, want %d", lines, len(byRank)+2)
+	}
+}
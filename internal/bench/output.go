@@ -0,0 +1,54 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader names the columns of WriteCSV in the style of the other result
+// files, so results/plot_results.py can read them with pandas.
+var csvHeader = []string{"Graph", "Router", "QuerySet", "Rank", "Queries", "Unreachable",
+	"MeanTime(ms)", "P50Time(ms)", "P95Time(ms)", "P99Time(ms)", "MaxTime(ms)",
+	"AvgNodesPopped", "AllocsPerQuery", "BytesPerQuery", "PeakHeap(MB)"}
+
+// WriteCSV writes one row per result.
+func WriteCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := []string{
+			r.Graph,
+			r.Router,
+			r.QuerySet,
+			strconv.Itoa(r.Rank),
+			strconv.Itoa(r.Queries),
+			strconv.Itoa(r.Unreachable),
+			fmt.Sprintf("%.4f", r.MeanMs),
+			fmt.Sprintf("%.4f", r.P50Ms),
+			fmt.Sprintf("%.4f", r.P95Ms),
+			fmt.Sprintf("%.4f", r.P99Ms),
+			fmt.Sprintf("%.4f", r.MaxMs),
+			fmt.Sprintf("%.1f", r.AvgNodesPopped),
+			fmt.Sprintf("%.1f", r.AllocsPerQuery),
+			fmt.Sprintf("%.1f", r.BytesPerQuery),
+			fmt.Sprintf("%.2f", float64(r.PeakHeapBytes)/(1<<20)),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
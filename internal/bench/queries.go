@@ -0,0 +1,180 @@
+package bench
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var ErrInvalidQueries = errors.New("invalid query file")
+
+// RandomRank marks a query whose target was drawn uniformly at random.
+const RandomRank = -1
+
+// Query is a source-target pair. Rank is i for a query whose target is the
+// 2^i-th vertex settled by a Dijkstra search from the source, and RandomRank
+// for a random pair.
+type Query struct {
+	Source graph.VertexId `json:"source"`
+	Target graph.VertexId `json:"target"`
+	Rank   int            `json:"rank"`
+}
+
+// RandomQueries draws n pairs of distinct vertices of g uniformly at random.
+// The pairs only depend on seed and the vertex ids of g.
+func RandomQueries(g *graph.Graph, n int, seed int64) []Query {
+	vertices := sortedVertices(g)
+	if len(vertices) < 2 {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(seed))
+	queries := make([]Query, 0, n)
+	for range n {
+		source := rng.Intn(len(vertices))
+		target := rng.Intn(len(vertices) - 1)
+		if target >= source {
+			target++
+		}
+		queries = append(queries, Query{Source: vertices[source], Target: vertices[target], Rank: RandomRank})
+	}
+	return queries
+}
+
+// RankQueries draws sources random sources and runs a complete Dijkstra search
+// from each. For every i with 2^i below the number of settled vertices, the
+// 2^i-th settled vertex becomes the target of a query of rank i, so the
+// queries cover short to long distances evenly. The queries are ordered by
+// source, then by rank.
+func RankQueries(g *graph.Graph, sources int, seed int64) []Query {
+	vertices := sortedVertices(g)
+	if len(vertices) == 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(seed))
+	var queries []Query
+	for range sources {
+		source := vertices[rng.Intn(len(vertices))]
+		settled := settleOrder(g, source)
+		for rank, i := 0, 1; i < len(settled); rank, i = rank+1, i*2 {
+			queries = append(queries, Query{Source: source, Target: settled[i], Rank: rank})
+		}
+	}
+	return queries
+}
+
+// WriteQueries writes one query per line as "source target rank".
+func WriteQueries(w io.Writer, queries []Query) error {
+	bw := bufio.NewWriter(w)
+	for _, q := range queries {
+		if _, err := fmt.Fprintf(bw, "%d %d %d\n", q.Source, q.Target, q.Rank); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadQueries reads queries written by WriteQueries. Empty lines and lines
+// starting with # are skipped. Every vertex must be part of g.
+func ReadQueries(g *graph.Graph, r io.Reader) ([]Query, error) {
+	var queries []Query
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: line %d: expected 3 fields, got %d", ErrInvalidQueries, line, len(fields))
+		}
+		var values [3]int
+		for i, field := range fields {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidQueries, line, err)
+			}
+			values[i] = value
+		}
+		q := Query{Source: graph.VertexId(values[0]), Target: graph.VertexId(values[1]), Rank: values[2]}
+		for _, v := range []graph.VertexId{q.Source, q.Target} {
+			if _, ok := g.Vertices[v]; !ok {
+				return nil, fmt.Errorf("%w: line %d: vertex %d is not part of the graph", ErrInvalidQueries, line, v)
+			}
+		}
+		queries = append(queries, q)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// settleOrder returns the vertices in the order a Dijkstra search from source
+// settles them. Vertices at the same distance are settled by ascending id, so
+// the order does not depend on the map order of the edges.
+func settleOrder(g *graph.Graph, source graph.VertexId) []graph.VertexId {
+	distances := map[graph.VertexId]float64{source: 0}
+	settled := make(map[graph.VertexId]bool)
+	var order []graph.VertexId
+	queue := &settleQueue{{source, 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(settleItem)
+		if settled[item.vertex] {
+			continue
+		}
+		settled[item.vertex] = true
+		order = append(order, item.vertex)
+		for target, edge := range g.Edges[item.vertex] {
+			if edge.Weight == graph.InfWeight || settled[target] {
+				continue
+			}
+			distance := item.distance + float64(edge.Weight)
+			if old, ok := distances[target]; !ok || distance < old {
+				distances[target] = distance
+				heap.Push(queue, settleItem{target, distance})
+			}
+		}
+	}
+	return order
+}
+
+type settleItem struct {
+	vertex   graph.VertexId
+	distance float64
+}
+
+// settleQueue is a min-heap of settleItems ordered by distance, then by id.
+type settleQueue []settleItem
+
+func (q settleQueue) Len() int { return len(q) }
+func (q settleQueue) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	return q[i].vertex < q[j].vertex
+}
+func (q settleQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *settleQueue) Push(x any)   { *q = append(*q, x.(settleItem)) }
+func (q *settleQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func sortedVertices(g *graph.Graph) []graph.VertexId {
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+	return vertices
+}
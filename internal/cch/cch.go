@@ -21,13 +21,13 @@ import graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 //	ContractionOrder  []graph.VertexId
 
 type CCH struct {
-	UpwardsGraph      *graph.Graph
-	DownwardsGraph    *graph.Graph
-	ContractionOrder  []graph.VertexId
-	ContractionMap    map[graph.VertexId]int
-	ShortcutsAdded    int
-	TotalTriangles    int
-	MaxTriangles      int
+	UpwardsGraph     *graph.Graph
+	DownwardsGraph   *graph.Graph
+	ContractionOrder []graph.VertexId
+	ContractionMap   map[graph.VertexId]int
+	ShortcutsAdded   int
+	TotalTriangles   int
+	MaxTriangles     int
 }
 
 func NewCCH() *CCH {
@@ -81,3 +81,16 @@ func NewCCH() *CCH {
 // -------------------- Query phase ---------------------------------
 //
 // Use Elimination Tree for optimization
+
+// Clone returns a deep copy of the CCH. The clone can be customized with a
+// different metric without affecting the original instance.
+func (c *CCH) Clone() *CCH {
+	co := make([]graph.VertexId, len(c.ContractionOrder))
+	copy(co, c.ContractionOrder)
+	cm := make(map[graph.VertexId]int, len(c.ContractionMap))
+	for id, rank := range c.ContractionMap {
+		cm[id] = rank
+	}
+
+	return &CCH{c.UpwardsGraph.Clone(), c.DownwardsGraph.Clone(), co, cm, c.ShortcutsAdded, c.TotalTriangles, c.MaxTriangles}
+}
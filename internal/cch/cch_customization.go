@@ -2,9 +2,10 @@ package cch
 
 import (
 	"fmt"
-	"math"
 	"sort"
 
+	"github.com/PaulMue0/efficient-routeplanning/internal/vehicle"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/criteria"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
@@ -19,32 +20,38 @@ func (cch *CCH) Customize(originalGraph *graph.Graph) error {
 	return nil
 }
 
+// CustomizeWeightedSum customizes the CCH with a linear combination of the
+// cost vectors of originalGraph, e.g. 0.7 * time + 0.3 * distance. Every
+// choice of coefficients is a different metric on the same topology.
+func (cch *CCH) CustomizeWeightedSum(originalGraph *graph.Graph, costs *criteria.Costs, coefficients []float64) error {
+	weighted, err := costs.WeightedSum(originalGraph, coefficients)
+	if err != nil {
+		return fmt.Errorf("failed to combine criteria: %w", err)
+	}
+	return cch.Customize(weighted)
+}
+
+// CustomizeProfile customizes the CCH with the travel times of a vehicle
+// profile on originalGraph. Edges the vehicle may not use get an infinite
+// weight, so a single CCH serves all profiles.
+func (cch *CCH) CustomizeProfile(originalGraph *graph.Graph, profile vehicle.Profile) error {
+	return cch.Customize(profile.Metric(originalGraph))
+}
+
+// Respecting resets the weights of the CCH to the metric of originalGraph. The
+// upward edge (v, w) gets the weight of the original edge v -> w and the downward
+// edge (w, v) the weight of w -> v, so directed metrics are supported. Edges
+// without an original counterpart become shortcuts of infinite weight.
 func (cch *CCH) Respecting(originalGraph *graph.Graph) error {
 	for v := range cch.UpwardsGraph.Vertices {
 		for w, edge := range cch.UpwardsGraph.Edges[v] {
-			originalEdge, exists := originalGraph.Edges[v][w]
-
-			var (
-				newWeight  int
-				isShortcut bool
-				viaNode    graph.VertexId
-			)
-
-			if exists {
-				newWeight = originalEdge.Weight
-				isShortcut = false
-				viaNode = -1
-			} else {
-				newWeight = int(math.Inf(1))
-				isShortcut = true
-				viaNode = edge.Via
-			}
-
-			if err := cch.UpwardsGraph.UpdateEdge(v, w, newWeight, isShortcut, viaNode); err != nil {
+			upWeight, upShortcut, upVia := respectingWeight(originalGraph, v, w, edge.Via)
+			if err := cch.UpwardsGraph.UpdateEdge(v, w, upWeight, upShortcut, upVia); err != nil {
 				return fmt.Errorf("failed to update upwards graph for edge %d->%d: %w", v, w, err)
 			}
 
-			if err := cch.DownwardsGraph.UpdateEdge(w, v, newWeight, isShortcut, viaNode); err != nil {
+			downWeight, downShortcut, downVia := respectingWeight(originalGraph, w, v, edge.Via)
+			if err := cch.DownwardsGraph.UpdateEdge(w, v, downWeight, downShortcut, downVia); err != nil {
 				return fmt.Errorf("failed to update downwards graph for edge %d->%d: %w", w, v, err)
 			}
 		}
@@ -52,6 +59,14 @@ func (cch *CCH) Respecting(originalGraph *graph.Graph) error {
 	return nil
 }
 
+// respectingWeight returns the initial weight of the CCH edge from -> to.
+func respectingWeight(originalGraph *graph.Graph, from, to, via graph.VertexId) (int, bool, graph.VertexId) {
+	if originalEdge, exists := originalGraph.Edges[from][to]; exists {
+		return originalEdge.Weight, false, -1
+	}
+	return graph.InfWeight, true, via
+}
+
 func (cch *CCH) basicCustomization() error {
 	if cch == nil {
 		return fmt.Errorf("cch is nil")
@@ -113,18 +128,20 @@ func (cch *CCH) basicCustomization() error {
 					return fmt.Errorf("missing edge (%d, %d) in downwards graph", w.Id, v.Id)
 				}
 
-				newUpwardsWeight := min(existingUpEdge.Weight, edgeVU.Weight+edgeUW.Weight)
-				newDownwardsWeight := min(existingDownEdge.Weight, edgeUV.Weight+edgeWU.Weight)
-
-				if newUpwardsWeight >= existingUpEdge.Weight || newDownwardsWeight >= existingDownEdge.Weight {
-					continue
-				}
+				// Weights may still be infinite, so the triangle is relaxed
+				// with the overflow-safe helpers of the graph package.
+				newUpwardsWeight := graph.AddWeights(edgeVU.Weight, edgeUW.Weight)
+				newDownwardsWeight := graph.AddWeights(edgeUV.Weight, edgeWU.Weight)
 
-				if err := cch.UpwardsGraph.UpdateEdge(v.Id, w.Id, newUpwardsWeight, true, uId); err != nil {
-					return fmt.Errorf("failed to update upwards edge (%d, %d): %w", v.Id, w.Id, err)
+				if graph.LessWeight(newUpwardsWeight, existingUpEdge.Weight) {
+					if err := cch.UpwardsGraph.UpdateEdge(v.Id, w.Id, newUpwardsWeight, true, uId); err != nil {
+						return fmt.Errorf("failed to update upwards edge (%d, %d): %w", v.Id, w.Id, err)
+					}
 				}
-				if err := cch.DownwardsGraph.UpdateEdge(w.Id, v.Id, newDownwardsWeight, true, uId); err != nil {
-					return fmt.Errorf("failed to update downwards edge (%d, %d): %w", w.Id, v.Id, err)
+				if graph.LessWeight(newDownwardsWeight, existingDownEdge.Weight) {
+					if err := cch.DownwardsGraph.UpdateEdge(w.Id, v.Id, newDownwardsWeight, true, uId); err != nil {
+						return fmt.Errorf("failed to update downwards edge (%d, %d): %w", w.Id, v.Id, err)
+					}
 				}
 			}
 		}
@@ -2,9 +2,11 @@ package cch
 
 import (
 	"fmt"
-	"math"
+	"reflect"
 	"testing"
 
+	"github.com/PaulMue0/efficient-routeplanning/internal/vehicle"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/criteria"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
@@ -93,7 +95,7 @@ func TestRespecting(t *testing.T) {
 				assertEdgeWeight(t, cch, 0, 1, 1)
 				// This shortcut (1->3 via 0) is created during preprocess.
 				// Respecting() resets its weight to infinity before customization.
-				assertShortcut(t, cch, 1, 3, 0, int(math.Inf(1)))
+				assertShortcut(t, cch, 1, 3, 0, graph.InfWeight)
 			},
 		},
 	}
@@ -225,3 +227,80 @@ func TestBasicCustomization(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomizeWeightedSum(t *testing.T) {
+	// Two routes from 1 to 3: through 2 it is fast but long, through 4 slow but short.
+	g := buildGraph([]graph.VertexId{1, 2, 3, 4}, [][3]int{{1, 2, 1}, {2, 3, 1}, {1, 4, 5}, {4, 3, 5}})
+	costs := criteria.NewCosts("time", "distance")
+	for _, e := range [][4]int{{1, 2, 1, 100}, {2, 3, 1, 100}, {1, 4, 5, 10}, {4, 3, 5, 10}} {
+		costs.Set(graph.VertexId(e[0]), graph.VertexId(e[1]), criteria.Vector{e[2], e[3]})
+		costs.Set(graph.VertexId(e[1]), graph.VertexId(e[0]), criteria.Vector{e[2], e[3]})
+	}
+	cch := preprocessCCH(t, g, "4\n1 1\n2 2\n3 4\n4 3\n")
+
+	testCases := []struct {
+		coefficients []float64
+		wantPath     []graph.VertexId
+		wantWeight   float64
+	}{
+		{[]float64{1, 0}, []graph.VertexId{1, 2, 3}, 2},
+		{[]float64{0, 1}, []graph.VertexId{1, 4, 3}, 20},
+		{[]float64{1, 0.1}, []graph.VertexId{1, 4, 3}, 12},
+	}
+	for _, tc := range testCases {
+		if err := cch.CustomizeWeightedSum(g, costs, tc.coefficients); err != nil {
+			t.Fatalf("CustomizeWeightedSum failed: %v", err)
+		}
+		path, weight, _, err := cch.Query(1, 3)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if weight != tc.wantWeight || !reflect.DeepEqual(path, tc.wantPath) {
+			t.Errorf("coefficients %v: got %v with weight %f, want %v with weight %f",
+				tc.coefficients, path, weight, tc.wantPath, tc.wantWeight)
+		}
+	}
+
+	if err := cch.CustomizeWeightedSum(g, costs, []float64{1}); err == nil {
+		t.Error("expected an error for a wrong number of coefficients")
+	}
+}
+
+func TestCustomizeProfile(t *testing.T) {
+	// From 1 to 3 a residential road through 2 is open to everyone, while the
+	// shorter footway through 4 is closed to cars.
+	g := buildGraph([]graph.VertexId{1, 2, 3, 4}, [][3]int{{1, 2, 1}, {2, 3, 1}, {1, 4, 1}, {4, 3, 1}})
+	residential := graph.Attributes{RoadClass: graph.RoadClassResidential, Length: 500}
+	footway := graph.Attributes{RoadClass: graph.RoadClassFootway, Length: 100, Restricted: graph.DefaultRestrictions(graph.RoadClassFootway)}
+	for _, e := range [][2]graph.VertexId{{1, 2}, {2, 3}, {1, 4}, {4, 3}} {
+		attributes := residential
+		if e[0] == 4 || e[1] == 4 {
+			attributes = footway
+		}
+		g.SetAttributes(e[0], e[1], attributes)
+		g.SetAttributes(e[1], e[0], attributes)
+	}
+	cch := preprocessCCH(t, g, "4\n1 1\n2 2\n3 4\n4 3\n")
+
+	testCases := []struct {
+		profile    vehicle.Profile
+		wantPath   []graph.VertexId
+		wantWeight float64
+	}{
+		{vehicle.Car, []graph.VertexId{1, 2, 3}, 120000},
+		{vehicle.Pedestrian, []graph.VertexId{1, 4, 3}, 144000},
+	}
+	for _, tc := range testCases {
+		if err := cch.CustomizeProfile(g, tc.profile); err != nil {
+			t.Fatalf("CustomizeProfile failed: %v", err)
+		}
+		path, weight, _, err := cch.Query(1, 3)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if weight != tc.wantWeight || !reflect.DeepEqual(path, tc.wantPath) {
+			t.Errorf("%s: got %v with weight %f, want %v with weight %f",
+				tc.profile.Name, path, weight, tc.wantPath, tc.wantWeight)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package cch
+
+import (
+	"sort"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ndLeafSize is the number of vertices below which NestedDissection stops
+// splitting and ranks the remaining vertices by degree.
+const ndLeafSize = 16
+
+// NestedDissection computes a contraction order for PreprocessWithOrder
+// without KaHIP. It bisects the vertices at the median latitude and at the
+// median longitude, takes the smaller boundary of either cut as separator and
+// ranks the separator above both halves, which are ordered recursively. The
+// orders of KaHIP have smaller separators, but this one needs nothing but the
+// coordinates of the vertices and is deterministic.
+func NestedDissection(g *graph.Graph) []graph.VertexId {
+	neighbors := make(map[graph.VertexId][]graph.VertexId, len(g.Vertices))
+	for u, edges := range g.Edges {
+		for v := range edges {
+			if u == v {
+				continue
+			}
+			neighbors[u] = append(neighbors[u], v)
+			neighbors[v] = append(neighbors[v], u)
+		}
+	}
+
+	vertices := make([]graph.Vertex, 0, len(g.Vertices))
+	for _, v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i].Id < vertices[j].Id })
+
+	d := dissection{
+		neighbors: neighbors,
+		side:      make(map[graph.VertexId]int, len(vertices)),
+		order:     make([]graph.VertexId, 0, len(vertices)),
+	}
+	d.dissect(vertices)
+	return d.order
+}
+
+type dissection struct {
+	neighbors map[graph.VertexId][]graph.VertexId
+	// side labels the vertices of the current bisection. Every bisection uses
+	// fresh labels, so vertices outside of it never match.
+	side   map[graph.VertexId]int
+	labels int
+	order  []graph.VertexId
+}
+
+func (d *dissection) dissect(vertices []graph.Vertex) {
+	if len(vertices) <= ndLeafSize {
+		sort.SliceStable(vertices, func(i, j int) bool {
+			return len(d.neighbors[vertices[i].Id]) < len(d.neighbors[vertices[j].Id])
+		})
+		for _, v := range vertices {
+			d.order = append(d.order, v.Id)
+		}
+		return
+	}
+
+	// Bisect along both coordinates and keep the smaller separator.
+	var bestLeft, bestRight, bestSeparator []graph.Vertex
+	for i, coordinate := range []func(graph.Vertex) float64{
+		func(v graph.Vertex) float64 { return v.Lat },
+		func(v graph.Vertex) float64 { return v.Lon },
+	} {
+		sorted := append([]graph.Vertex(nil), vertices...)
+		sort.SliceStable(sorted, func(i, j int) bool { return coordinate(sorted[i]) < coordinate(sorted[j]) })
+		left, right, separator := d.bisect(sorted)
+		if i == 0 || len(separator) < len(bestSeparator) {
+			bestLeft, bestRight, bestSeparator = left, right, separator
+		}
+	}
+
+	d.dissect(bestLeft)
+	d.dissect(bestRight)
+	for _, v := range bestSeparator {
+		d.order = append(d.order, v.Id)
+	}
+}
+
+// bisect cuts the sorted vertices in the middle and moves the boundary
+// vertices of the side with fewer of them into the separator.
+func (d *dissection) bisect(vertices []graph.Vertex) (left, right, separator []graph.Vertex) {
+	cut := len(vertices) / 2
+	leftLabel, rightLabel := d.labels+1, d.labels+2
+	d.labels += 2
+	for i, v := range vertices {
+		if i < cut {
+			d.side[v.Id] = leftLabel
+		} else {
+			d.side[v.Id] = rightLabel
+		}
+	}
+
+	leftRest, leftBoundary := d.split(vertices[:cut], rightLabel)
+	rightRest, rightBoundary := d.split(vertices[cut:], leftLabel)
+	if len(rightBoundary) < len(leftBoundary) {
+		return vertices[:cut], rightRest, rightBoundary
+	}
+	return leftRest, vertices[cut:], leftBoundary
+}
+
+// split divides vertices into those without and those with a neighbor labeled
+// other. The vertices are not modified.
+func (d *dissection) split(vertices []graph.Vertex, other int) (rest, boundary []graph.Vertex) {
+	for _, v := range vertices {
+		onBoundary := false
+		for _, w := range d.neighbors[v.Id] {
+			if d.side[w] == other {
+				onBoundary = true
+				break
+			}
+		}
+		if onBoundary {
+			boundary = append(boundary, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return rest, boundary
+}
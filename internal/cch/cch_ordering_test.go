@@ -0,0 +1,80 @@
+package cch
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/generate"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestNestedDissection(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+
+	order := NestedDissection(g)
+	if len(order) != len(g.Vertices) {
+		t.Fatalf("got %d vertices in the order, want %d", len(order), len(g.Vertices))
+	}
+
+	c := NewCCH()
+	if err := c.PreprocessWithOrder(g, order); err != nil {
+		t.Fatalf("PreprocessWithOrder failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+	t.Logf("%d shortcuts", c.ShortcutsAdded)
+
+	vertices := order
+	rng := rand.New(rand.NewSource(1))
+	for range 200 {
+		s, target := vertices[rng.Intn(len(vertices))], vertices[rng.Intn(len(vertices))]
+		_, want, _, wantErr := pathfinding.DijkstraShortestPath(g, s, target, math.Inf(1))
+		_, got, _, err := c.Query(s, target)
+		if (wantErr == nil) != (err == nil) {
+			t.Fatalf("%d -> %d: got error %v, Dijkstra %v", s, target, err, wantErr)
+		}
+		if err == nil && got != want {
+			t.Errorf("%d -> %d: got %v, want %v", s, target, got, want)
+		}
+	}
+
+	again := NestedDissection(g)
+	for i := range order {
+		if order[i] != again[i] {
+			t.Fatalf("order differs at rank %d: %d vs %d", i, order[i], again[i])
+		}
+	}
+}
+
+func TestNestedDissectionRoadLike(t *testing.T) {
+	g := generate.RoadLike(40, 50, 100, generate.Options{Seed: 1, Weight: generate.TravelTime()})
+	c := NewCCH()
+	if err := c.PreprocessWithOrder(g, NestedDissection(g)); err != nil {
+		t.Fatalf("PreprocessWithOrder failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for range 100 {
+		s, target := graph.VertexId(rng.Intn(len(g.Vertices))), graph.VertexId(rng.Intn(len(g.Vertices)))
+		_, want, _, wantErr := pathfinding.DijkstraShortestPath(g, s, target, math.Inf(1))
+		_, got, _, err := c.Query(s, target)
+		if wantErr != nil || err != nil {
+			t.Fatalf("%d -> %d: got error %v, Dijkstra %v", s, target, err, wantErr)
+		}
+		if got != want {
+			t.Errorf("%d -> %d: got %v, want %v", s, target, got, want)
+		}
+	}
+}
@@ -1,14 +1,10 @@
 package cch
 
 import (
-	"bufio"
 	"fmt"
-	"math"
 	"os"
-	"sort"
-	"strconv"
-	"strings"
 
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
@@ -19,6 +15,37 @@ func (c *CCH) Preprocess(g *graph.Graph, orderingFilePath string) error {
 		return fmt.Errorf("failed to initialize contraction: %w", err)
 	}
 
+	return c.buildTopology(g)
+}
+
+// PreprocessWithOrder runs the metric independent preprocessing with an order
+// computed in memory instead of a KaHIP ordering file. order lists every
+// vertex of g exactly once, from the lowest to the highest rank.
+func (c *CCH) PreprocessWithOrder(g *graph.Graph, order []graph.VertexId) error {
+	if len(order) != len(g.Vertices) {
+		return fmt.Errorf("mismatch in node count: graph has %d nodes, but order has %d entries",
+			len(g.Vertices), len(order))
+	}
+
+	contractionMap := make(map[graph.VertexId]int, len(order))
+	for i, id := range order {
+		if _, ok := g.Vertices[id]; !ok {
+			return fmt.Errorf("vertex %d of the order is not part of the graph", id)
+		}
+		if _, seen := contractionMap[id]; seen {
+			return fmt.Errorf("vertex %d appears more than once in the order", id)
+		}
+		contractionMap[id] = i
+	}
+
+	c.ContractionOrder = append([]graph.VertexId(nil), order...)
+	c.ContractionMap = contractionMap
+	return c.buildTopology(g)
+}
+
+// buildTopology creates the upwards and downwards graphs for the contraction
+// order stored in c and inserts all shortcuts.
+func (c *CCH) buildTopology(g *graph.Graph) error {
 	if err := c.initializeGraphsWithVertices(g); err != nil {
 		return fmt.Errorf("failed to initialize graphs with vertices: %w", err)
 	}
@@ -122,11 +149,11 @@ func (c *CCH) addShortcuts() error {
 				if !exists {
 					c.ShortcutsAdded++
 					// The weight is set to infinity to be updated later by metric-dependent steps.
-					if err := c.UpwardsGraph.AddEdge(start, end, int(math.Inf(1)), true, id); err != nil {
+					if err := c.UpwardsGraph.AddEdge(start, end, graph.InfWeight, true, id); err != nil {
 						return fmt.Errorf("failed to add shortcut (%d -> %d) to upwards graph: %w", start, end, err)
 					}
 					// The DownwardsGraph is the reverse of the UpwardsGraph.
-					if err := c.DownwardsGraph.AddEdge(end, start, int(math.Inf(1)), true, id); err != nil {
+					if err := c.DownwardsGraph.AddEdge(end, start, graph.InfWeight, true, id); err != nil {
 						return fmt.Errorf("failed to add shortcut (%d -> %d) to downwards graph: %w", end, start, err)
 					}
 				}
@@ -137,79 +164,19 @@ func (c *CCH) addShortcuts() error {
 }
 
 func (c *CCH) initializeContraction(g *graph.Graph, orderingFilePath string) error {
-	// Re-create the mapping from METIS's 1-based ID to the original graph's VertexId.
-	nodeIDs := make([]graph.VertexId, 0, len(g.Vertices))
-	for id := range g.Vertices {
-		nodeIDs = append(nodeIDs, id)
-	}
-	sort.Slice(nodeIDs, func(i, j int) bool {
-		return nodeIDs[i] < nodeIDs[j]
-	})
-
-	metisIdToGraphId := make(map[int]graph.VertexId, len(nodeIDs))
-	for i, id := range nodeIDs {
-		metisIdToGraphId[i+1] = id
-	}
-
 	file, err := os.Open(orderingFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to open ordering file: %w", err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	type metisPair struct {
-		id   int
-		rank int
-	}
-	pairs := []metisPair{}
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) == 2 {
-			rank, err := strconv.Atoi(parts[0])
-			if err != nil {
-				return fmt.Errorf("invalid rank in ordering file: %w", err)
-			}
-			id, err := strconv.Atoi(parts[1])
-			if err != nil {
-				return fmt.Errorf("METIS ID %d from file not found in graph mapping", id)
-			}
-			pairs = append(pairs, metisPair{id, rank})
-		}
-	}
-
-	sort.Slice(pairs, func(i, j int) bool {
-		return pairs[i].rank < pairs[j].rank
-	})
-
-	metisOrdering := make([]int, 0, len(g.Vertices))
-	for _, p := range pairs {
-		metisOrdering = append(metisOrdering, p.id)
-	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading ordering file: %w", err)
-	}
-
-	if len(metisOrdering) != len(g.Vertices) {
-		return fmt.Errorf("mismatch in node count: graph has %d nodes, but ordering file has %d entries",
-			len(g.Vertices), len(metisOrdering))
-	}
-
-	originalNodeOrdering := make([]graph.VertexId, len(metisOrdering))
-	for i, metisID := range metisOrdering {
-		originalID, ok := metisIdToGraphId[metisID]
-		if !ok {
-			return fmt.Errorf("METIS ID %d from file not found in graph mapping", metisID)
-		}
-		originalNodeOrdering[i] = originalID
+	contractionOrder, err := parser.ReadOrdering(g, file)
+	if err != nil {
+		return err
 	}
 
-	contractionOrder := originalNodeOrdering
 	contractionMap := make(map[graph.VertexId]int)
-	for i, nodeID := range originalNodeOrdering {
+	for i, nodeID := range contractionOrder {
 		contractionMap[nodeID] = i
 	}
 
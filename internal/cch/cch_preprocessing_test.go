@@ -220,3 +220,28 @@ func TestCCHPreprocess(t *testing.T) {
 		t.Errorf("Expected shortcut 1->2 to be via node 0, but got %d", edge.Via)
 	}
 }
+
+func TestPreprocessWithOrder(t *testing.T) {
+	g := buildGraph([]graph.VertexId{1, 2, 3}, [][3]int{{1, 2, 1}, {2, 3, 1}})
+
+	c := NewCCH()
+	if err := c.PreprocessWithOrder(g, []graph.VertexId{2, 1, 3}); err != nil {
+		t.Fatalf("PreprocessWithOrder failed: %v", err)
+	}
+	if c.ContractionMap[2] != 0 || c.ContractionMap[3] != 2 {
+		t.Errorf("got contraction map %v", c.ContractionMap)
+	}
+	if _, ok := c.UpwardsGraph.Edges[1][3]; !ok {
+		t.Error("expected a shortcut 1 -> 3 after contracting 2 first")
+	}
+
+	for name, order := range map[string][]graph.VertexId{
+		"too short": {1, 2},
+		"duplicate": {1, 2, 2},
+		"unknown":   {1, 2, 4},
+	} {
+		if err := NewCCH().PreprocessWithOrder(g, order); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
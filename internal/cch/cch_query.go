@@ -11,7 +11,13 @@ import (
 // It performs a bidirectional Dijkstra search on the CCH and then unpacks
 // the resulting path to resolve any shortcuts.
 func (cch *CCH) Query(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
-	path, weight, nodesPopped, err := pathfinding.BiDirectionalDijkstraShortestPath(cch.UpwardsGraph, cch.DownwardsGraph, source, target)
+	return cch.QueryWith(nil, source, target)
+}
+
+// QueryWith answers the same query as Query with the search state kept in w,
+// which may be nil.
+func (cch *CCH) QueryWith(w *pathfinding.Workspace, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	path, weight, nodesPopped, err := w.BiDirectionalDijkstraShortestPath(cch.UpwardsGraph, cch.DownwardsGraph, source, target)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bidirectional Dijkstra failed: %w", err)
 	}
@@ -28,6 +34,27 @@ func (cch *CCH) Query(source, target graph.VertexId) ([]graph.VertexId, float64,
 	return unpackedPath, weight, nodesPopped, nil
 }
 
+// QueryNoUnpack finds the shortest path between source and target using the CCH
+// and returns the path on the CCH graphs without unpacking any shortcuts.
+func (cch *CCH) QueryNoUnpack(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	return cch.QueryNoUnpackWith(nil, source, target)
+}
+
+// QueryNoUnpackWith answers the same query as QueryNoUnpack with the search
+// state kept in w, which may be nil.
+func (cch *CCH) QueryNoUnpackWith(w *pathfinding.Workspace, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	path, weight, nodesPopped, err := w.BiDirectionalDijkstraShortestPath(cch.UpwardsGraph, cch.DownwardsGraph, source, target)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("bidirectional Dijkstra failed: %w", err)
+	}
+
+	if len(path) == 0 {
+		return []graph.VertexId{}, weight, 0, nil
+	}
+
+	return path, weight, nodesPopped, nil
+}
+
 // unpackPath takes a path containing shortcuts and expands them into the original edges.
 func (cch *CCH) unpackPath(path []graph.VertexId) ([]graph.VertexId, error) {
 	if len(path) < 2 {
@@ -3,6 +3,7 @@ package cch
 import (
 	"errors"
 	"log"
+	"math"
 	"os"
 	"reflect"
 	"slices"
@@ -29,7 +30,7 @@ func TestOsm1Query(t *testing.T) {
 		cchInst := NewCCH()
 		log.Println("Starting CCH preprocessing...")
 		start := time.Now()
-		err = cchInst.Preprocess(network.Network, "../../data/kaHIP/osm1.ordering")
+		err = cchInst.Preprocess(network.Network, "../../data/KaHIP/osm1.ordering")
 		if err != nil {
 			log.Fatalf("CCH preprocessing failed: %v", err)
 		}
@@ -41,8 +42,12 @@ func TestOsm1Query(t *testing.T) {
 		if slices.Equal(path, []graph.VertexId{1, 2, 3, 4, 5}) {
 			t.Errorf("wrong path: Got %q Expected: %q", path, []graph.VertexId{1, 2, 3, 4, 5})
 		}
-		if dist != 5 {
-			t.Errorf("wrong path length. Got %f Expected %f", dist, 5.0)
+		_, wantDist, _, dijkstraErr := pathfinding.DijkstraShortestPath(network.Network, 1, 5, math.Inf(1))
+		if dijkstraErr != nil {
+			t.Fatalf("Dijkstra failed: %v", dijkstraErr)
+		}
+		if dist != wantDist {
+			t.Errorf("wrong path length. Got %f Expected %f", dist, wantDist)
 		}
 		if err != nil {
 
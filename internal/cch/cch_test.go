@@ -57,3 +57,22 @@ func preprocessAndCustomizeCCH(t *testing.T, g *graph.Graph, ordering string) *C
 	}
 	return cch
 }
+
+func TestClone(t *testing.T) {
+	g := buildGraph([]graph.VertexId{0, 1, 2}, [][3]int{{0, 1, 1}, {0, 2, 1}})
+	original := preprocessAndCustomizeCCH(t, g, "3\n1 1\n2 2\n3 3\n")
+	clone := original.Clone()
+
+	g.UpdateEdge(0, 1, 10, false, -1)
+	g.UpdateEdge(1, 0, 10, false, -1)
+	if err := clone.Customize(g); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	if got := original.UpwardsGraph.Edges[0][1].Weight; got != 1 {
+		t.Errorf("customizing the clone changed the original: got weight %d, want 1", got)
+	}
+	if got := clone.UpwardsGraph.Edges[0][1].Weight; got != 10 {
+		t.Errorf("expected clone weight 10, got %d", got)
+	}
+}
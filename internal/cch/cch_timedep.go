@@ -0,0 +1,267 @@
+package cch
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/ttf"
+)
+
+// ErrNoProfiles is returned when a time-dependent customization is run without profiles.
+var ErrNoProfiles = errors.New("time-dependent customization requires profiles")
+
+// TimeDependentCCH customizes a CCH with travel time functions instead of
+// static weights. Every edge of the CCH gets the exact travel time function of
+// the fastest path it represents, so a query computes the earliest arrival
+// for any departure time.
+//
+// The functions of the upwards and downwards edges are stored next to the
+// CCH, which keeps the static weights of the CCH untouched.
+type TimeDependentCCH struct {
+	CCH      *CCH
+	original *graph.Graph
+	profiles *ttf.Profiles
+	// up[v][w] is the function of the upwards edge v -> w, down[w][v] the
+	// function of the downwards edge w -> v. nil means not traversable.
+	up   map[graph.VertexId]map[graph.VertexId]*ttf.TTF
+	down map[graph.VertexId]map[graph.VertexId]*ttf.TTF
+}
+
+// NewTimeDependentCCH wraps the preprocessed CCH c. It must be customized
+// before it can be queried.
+func NewTimeDependentCCH(c *CCH) *TimeDependentCCH {
+	return &TimeDependentCCH{CCH: c}
+}
+
+// Customize computes the travel time functions of all CCH edges for the
+// graph g, whose edges without a profile keep their static weight.
+func (t *TimeDependentCCH) Customize(g *graph.Graph, profiles *ttf.Profiles) error {
+	if profiles == nil {
+		return ErrNoProfiles
+	}
+
+	up := make(map[graph.VertexId]map[graph.VertexId]*ttf.TTF)
+	down := make(map[graph.VertexId]map[graph.VertexId]*ttf.TTF)
+	for v, targets := range t.CCH.UpwardsGraph.Edges {
+		for w := range targets {
+			if up[v] == nil {
+				up[v] = make(map[graph.VertexId]*ttf.TTF)
+			}
+			if down[w] == nil {
+				down[w] = make(map[graph.VertexId]*ttf.TTF)
+			}
+			up[v][w], _ = profiles.Function(g, v, w)
+			down[w][v], _ = profiles.Function(g, w, v)
+		}
+	}
+
+	// Lower triangles are processed bottom-up exactly like in the basic
+	// customization, but with linked and minimized functions.
+	for _, u := range t.CCH.ContractionOrder {
+		neighbors := make([]graph.VertexId, 0, len(t.CCH.UpwardsGraph.Edges[u]))
+		for v := range t.CCH.UpwardsGraph.Edges[u] {
+			neighbors = append(neighbors, v)
+		}
+		sort.Slice(neighbors, func(i, j int) bool {
+			return t.CCH.ContractionMap[neighbors[i]] < t.CCH.ContractionMap[neighbors[j]]
+		})
+
+		for i, v := range neighbors {
+			for _, w := range neighbors[i+1:] {
+				if _, ok := t.CCH.UpwardsGraph.Edges[v][w]; !ok {
+					return fmt.Errorf("missing edge (%d, %d) in upwards graph", v, w)
+				}
+				up[v][w] = minimumTTF(up[v][w], linkTTF(down[v][u], up[u][w]))
+				down[w][v] = minimumTTF(down[w][v], linkTTF(down[w][u], up[u][v]))
+			}
+		}
+	}
+
+	t.original = g
+	t.profiles = profiles
+	t.up = up
+	t.down = down
+	return nil
+}
+
+// Query computes the earliest arrival at target when leaving source at
+// departure. It returns the unpacked path and the arrival time.
+func (t *TimeDependentCCH) Query(source, target graph.VertexId, departure float64) ([]graph.VertexId, float64, int, error) {
+	if t.up == nil {
+		return nil, 0, 0, fmt.Errorf("time-dependent CCH is not customized")
+	}
+	for _, v := range []graph.VertexId{source, target} {
+		if _, ok := t.CCH.UpwardsGraph.Vertices[v]; !ok {
+			return nil, 0, 0, fmt.Errorf("vertex %d not found", v)
+		}
+	}
+
+	arrivals := map[graph.VertexId]float64{source: departure}
+	preds := make(map[graph.VertexId]graph.VertexId)
+	nodesPopped := 0
+
+	// Upwards from the source, every vertex of its search space gets its
+	// exact earliest arrival time.
+	nodesPopped += t.search(arrivals, preds, []graph.VertexId{source}, t.up, nil)
+
+	// Downwards only the vertices from which the target can be reached
+	// matter. These are the vertices reachable upwards from the target.
+	reachesTarget := map[graph.VertexId]bool{target: true}
+	stack := []graph.VertexId{target}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for w := range t.CCH.UpwardsGraph.Edges[v] {
+			if !reachesTarget[w] {
+				reachesTarget[w] = true
+				stack = append(stack, w)
+			}
+		}
+	}
+	var starts []graph.VertexId
+	for v := range arrivals {
+		if reachesTarget[v] {
+			starts = append(starts, v)
+		}
+	}
+	nodesPopped += t.search(arrivals, preds, starts, t.down, reachesTarget)
+
+	arrival, ok := arrivals[target]
+	if !ok {
+		return nil, 0, nodesPopped, pathfinding.ErrTargetNotReachable
+	}
+
+	path := []graph.VertexId{target}
+	for current := target; current != source; {
+		current = preds[current]
+		path = append(path, current)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	unpacked := []graph.VertexId{source}
+	at := departure
+	for i := 0; i+1 < len(path); i++ {
+		segment, err := t.unpackEdge(path[i], path[i+1], at)
+		if err != nil {
+			return nil, 0, nodesPopped, fmt.Errorf("failed to unpack path: %w", err)
+		}
+		at += t.function(path[i], path[i+1]).Eval(at)
+		unpacked = append(unpacked, segment[1:]...)
+	}
+
+	return unpacked, arrival, nodesPopped, nil
+}
+
+// search runs a time-dependent Dijkstra on edges, starting at starts with
+// their current arrival times. If allowed is set, only vertices contained in
+// it are entered. It returns the number of popped vertices.
+func (t *TimeDependentCCH) search(arrivals map[graph.VertexId]float64, preds map[graph.VertexId]graph.VertexId,
+	starts []graph.VertexId, edges map[graph.VertexId]map[graph.VertexId]*ttf.TTF, allowed map[graph.VertexId]bool) int {
+	queue := collection.NewPriorityQueue[graph.VertexId]()
+	for _, v := range starts {
+		queue.PushWithPriority(v, arrivals[v])
+	}
+
+	visited := make(map[graph.VertexId]bool)
+	nodesPopped := 0
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
+		nodesPopped++
+		v := queue.GetValue(item)
+		arrival := queue.GetPriority(item)
+		if visited[v] {
+			continue
+		}
+		visited[v] = true
+
+		for w, f := range edges[v] {
+			if f == nil || visited[w] || (allowed != nil && !allowed[w]) {
+				continue
+			}
+			newArrival := f.Arrival(arrival)
+			if old, ok := arrivals[w]; !ok || newArrival < old {
+				arrivals[w] = newArrival
+				preds[w] = v
+				queue.PushWithPriority(w, newArrival)
+			}
+		}
+	}
+	return nodesPopped
+}
+
+// function returns the travel time function of the CCH edge u -> v.
+func (t *TimeDependentCCH) function(u, v graph.VertexId) *ttf.TTF {
+	if t.CCH.ContractionMap[u] < t.CCH.ContractionMap[v] {
+		return t.up[u][v]
+	}
+	return t.down[u][v]
+}
+
+// unpackEdge unpacks the CCH edge u -> v entered at time at. Which path a
+// shortcut represents depends on the time, so the lower triangle whose linked
+// function matches the shortcut at that time is followed.
+func (t *TimeDependentCCH) unpackEdge(u, v graph.VertexId, at float64) ([]graph.VertexId, error) {
+	f := t.function(u, v)
+	if f == nil {
+		return nil, fmt.Errorf("no traversable edge between %d and %d", u, v)
+	}
+	want := f.Eval(at)
+	tolerance := 1e-6 * math.Max(1, want)
+
+	if original, ok := t.profiles.Function(t.original, u, v); ok && math.Abs(original.Eval(at)-want) <= tolerance {
+		return []graph.VertexId{u, v}, nil
+	}
+
+	// Candidates for the middle vertex are the lower neighbors of u that are
+	// also lower neighbors of v.
+	for m, first := range t.down[u] {
+		second, ok := t.up[m][v]
+		if first == nil || !ok || second == nil {
+			continue
+		}
+		d := first.Eval(at)
+		if math.Abs(d+second.Eval(at+d)-want) > tolerance {
+			continue
+		}
+
+		path1, err := t.unpackEdge(u, m, at)
+		if err != nil {
+			return nil, err
+		}
+		path2, err := t.unpackEdge(m, v, at+d)
+		if err != nil {
+			return nil, err
+		}
+		return append(path1, path2[1:]...), nil
+	}
+
+	return nil, fmt.Errorf("no path found for edge %d -> %d at time %f", u, v, at)
+}
+
+// linkTTF links two functions, where nil stands for a non-traversable edge.
+func linkTTF(f, g *ttf.TTF) *ttf.TTF {
+	if f == nil || g == nil {
+		return nil
+	}
+	return ttf.Link(f, g)
+}
+
+// minimumTTF returns the pointwise minimum, where nil stands for a
+// non-traversable edge.
+func minimumTTF(f, g *ttf.TTF) *ttf.TTF {
+	if f == nil {
+		return g
+	}
+	if g == nil {
+		return f
+	}
+	return ttf.Minimum(f, g)
+}
@@ -0,0 +1,141 @@
+package cch
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/ttf"
+)
+
+func TestTimeDependentCCHRushHour(t *testing.T) {
+	// A highway 1 -> 2 -> 3 congested between 100 and 200 and a side road 1 -> 4 -> 3.
+	g := buildGraph([]graph.VertexId{1, 2, 3, 4}, [][3]int{{1, 2, 10}, {2, 3, 10}, {1, 4, 15}, {4, 3, 15}})
+	rushHour, err := ttf.New(400, []ttf.Point{{At: 0, Duration: 10}, {At: 90, Duration: 10}, {At: 100, Duration: 60}, {At: 190, Duration: 60}, {At: 250, Duration: 10}})
+	if err != nil {
+		t.Fatalf("ttf.New failed: %v", err)
+	}
+	profiles := ttf.NewProfiles(400)
+	if err := profiles.Set(1, 2, rushHour); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	td := NewTimeDependentCCH(preprocessCCH(t, g, "4\n1 1\n2 2\n3 4\n4 3\n"))
+	if _, _, _, err := td.Query(1, 3, 0); err == nil {
+		t.Error("expected an error before customization")
+	}
+	if err := td.Customize(g, nil); err == nil {
+		t.Error("expected an error without profiles")
+	}
+	if err := td.Customize(g, profiles); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	testCases := []struct {
+		departure   float64
+		wantPath    []graph.VertexId
+		wantArrival float64
+	}{
+		{0, []graph.VertexId{1, 2, 3}, 20},
+		{150, []graph.VertexId{1, 4, 3}, 180},
+	}
+	for _, tc := range testCases {
+		path, arrival, _, err := td.Query(1, 3, tc.departure)
+		if err != nil {
+			t.Fatalf("departure %f: Query failed: %v", tc.departure, err)
+		}
+		if arrival != tc.wantArrival {
+			t.Errorf("departure %f: got arrival %f, want %f", tc.departure, arrival, tc.wantArrival)
+		}
+		if !reflect.DeepEqual(path, tc.wantPath) {
+			t.Errorf("departure %f: got path %v, want %v", tc.departure, path, tc.wantPath)
+		}
+	}
+}
+
+func TestTimeDependentCCHOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+
+	c := NewCCH()
+	if err := c.Preprocess(g, "../../data/KaHIP/osm1.ordering"); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+
+	// Give every edge a daily profile of 24 samples, some of them congested.
+	const interval = 100.0
+	rng := rand.New(rand.NewSource(7))
+	profiles := ttf.NewProfiles(24 * interval)
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		vertices = append(vertices, id)
+	}
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i] < vertices[j] })
+	for _, from := range vertices {
+		for to := range g.Edges[from] {
+			samples := make([]float64, 24)
+			base := 10 + rng.Float64()*30
+			for i := range samples {
+				samples[i] = base
+				if i >= 7 && i <= 9 {
+					samples[i] += rng.Float64() * 60
+				}
+			}
+			f, err := ttf.FromSamples(interval, samples)
+			if err != nil {
+				t.Fatalf("FromSamples failed: %v", err)
+			}
+			if err := profiles.Set(from, to, f); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+		}
+	}
+
+	td := NewTimeDependentCCH(c)
+	if err := td.Customize(g, profiles); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	for i := 0; i < 30; i++ {
+		source := vertices[(i*37)%len(vertices)]
+		target := vertices[(i*101+13)%len(vertices)]
+		departure := float64(i) * 97
+
+		_, want, _, wantErr := pathfinding.TimeDependentDijkstraShortestPath(g, profiles, source, target, departure)
+		path, got, _, err := td.Query(source, target, departure)
+		if wantErr != nil {
+			if err == nil {
+				t.Errorf("%d -> %d: expected an error", source, target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d -> %d: Query failed: %v", source, target, err)
+		}
+		if math.Abs(got-want) > 1e-6 {
+			t.Errorf("%d -> %d at %f: got arrival %f, want %f", source, target, departure, got, want)
+		}
+
+		// Driving the unpacked path must reproduce the arrival time.
+		at := departure
+		for j := 0; j+1 < len(path); j++ {
+			f, ok := profiles.Function(g, path[j], path[j+1])
+			if !ok {
+				t.Fatalf("%d -> %d: path %v uses a missing edge", source, target, path)
+			}
+			at = f.Arrival(at)
+		}
+		if math.Abs(at-want) > 1e-6 {
+			t.Errorf("%d -> %d: unpacked path arrives at %f, want %f", source, target, at, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cch
+
+import (
+	"fmt"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// TurnCCH is a CCH on the edge-based expansion of a road network. Its
+// topology contains every possible turn, so turn costs and turn restrictions
+// are part of the metric and can be changed by a customization just like
+// edge weights.
+type TurnCCH struct {
+	CCH      *CCH
+	Expanded *turns.ExpandedGraph
+}
+
+// NewTurnCCH runs the metric independent preprocessing of a turn aware CCH
+// for g. order is a contraction order of g from the lowest to the highest
+// rank; the order of the expansion is derived from it.
+func NewTurnCCH(g *graph.Graph, order []graph.VertexId) (*TurnCCH, error) {
+	expanded := turns.Expand(g, nil)
+
+	c := NewCCH()
+	if err := c.PreprocessWithOrder(expanded.Graph, expanded.Order(order)); err != nil {
+		return nil, fmt.Errorf("failed to preprocess expanded graph: %w", err)
+	}
+	return &TurnCCH{CCH: c, Expanded: expanded}, nil
+}
+
+// Customize applies the edge weights of g and the turn costs of table. g must
+// have the topology the TurnCCH was built for. A nil table allows all turns.
+func (t *TurnCCH) Customize(g *graph.Graph, table *turns.Table) error {
+	expanded := turns.Expand(g, table)
+	if len(expanded.Graph.Vertices) != len(t.Expanded.Graph.Vertices) {
+		return fmt.Errorf("graph has %d arcs and vertices, but the CCH was built for %d",
+			len(expanded.Graph.Vertices), len(t.Expanded.Graph.Vertices))
+	}
+
+	if err := t.CCH.Customize(expanded.Graph); err != nil {
+		return fmt.Errorf("failed to customize expanded graph: %w", err)
+	}
+	t.Expanded = expanded
+	return nil
+}
+
+// Query finds the shortest path between source and target that respects the
+// turn costs of the last customization. The path is given in vertices of the
+// original graph.
+func (t *TurnCCH) Query(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	from, ok := t.Expanded.Source(source)
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("source vertex %d not found", source)
+	}
+	to, ok := t.Expanded.Sink(target)
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("target vertex %d not found", target)
+	}
+	if source == target {
+		return []graph.VertexId{source}, 0, 0, nil
+	}
+
+	path, weight, nodesPopped, err := t.CCH.Query(from, to)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return t.Expanded.OriginalPath(path), weight, nodesPopped, nil
+}
@@ -0,0 +1,160 @@
+package cch
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// buildIntersection builds a crossing at vertex 4 with the arms 1 (north),
+// 3 (west), 5 (east) and 7 (south). The north and west arms are also connected
+// by a slow road through 0.
+func buildIntersection() *graph.Graph {
+	return buildGraph(
+		[]graph.VertexId{0, 1, 3, 4, 5, 7},
+		[][3]int{{0, 1, 3}, {0, 3, 3}, {1, 4, 2}, {3, 4, 1}, {4, 5, 1}, {4, 7, 1}},
+	)
+}
+
+func TestTurnCCHIntersection(t *testing.T) {
+	g := buildIntersection()
+	turnCCH, err := NewTurnCCH(g, []graph.VertexId{5, 7, 0, 3, 1, 4})
+	if err != nil {
+		t.Fatalf("NewTurnCCH failed: %v", err)
+	}
+
+	noLeftTurn := turns.NewTable()
+	noLeftTurn.Forbid(7, 4, 3)
+	noUTurns := turns.NewTable()
+	noUTurns.Forbid(7, 4, 3)
+	noUTurns.ForbidUTurns()
+	withCost := turns.NewTable()
+	withCost.SetCost(7, 4, 3, 5)
+
+	testCases := []struct {
+		name     string
+		table    *turns.Table
+		wantPath []graph.VertexId
+		wantCost float64
+	}{
+		{"no restrictions", nil, []graph.VertexId{7, 4, 3}, 2},
+		{"turn cost", withCost, []graph.VertexId{7, 4, 5, 4, 3}, 4},
+		{"no left turn", noLeftTurn, []graph.VertexId{7, 4, 5, 4, 3}, 4},
+		{"no left turn and no U-turns", noUTurns, []graph.VertexId{7, 4, 1, 0, 3}, 9},
+	}
+
+	// The same TurnCCH is customized again for every table.
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := turnCCH.Customize(g, tc.table); err != nil {
+				t.Fatalf("Customize failed: %v", err)
+			}
+			path, cost, _, err := turnCCH.Query(7, 3)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if cost != tc.wantCost {
+				t.Errorf("got cost %f, want %f", cost, tc.wantCost)
+			}
+			if !reflect.DeepEqual(path, tc.wantPath) {
+				t.Errorf("got path %v, want %v", path, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestTurnCCHUnreachable(t *testing.T) {
+	g := buildIntersection()
+	turnCCH, err := NewTurnCCH(g, []graph.VertexId{5, 7, 0, 3, 1, 4})
+	if err != nil {
+		t.Fatalf("NewTurnCCH failed: %v", err)
+	}
+
+	table := turns.NewTable()
+	table.ForbidUTurns()
+	for _, to := range []graph.VertexId{1, 3, 7} {
+		table.Forbid(5, 4, to)
+	}
+	if err := turnCCH.Customize(g, table); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	if _, _, _, err := turnCCH.Query(5, 3); !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+	if _, _, _, err := turnCCH.Query(5, 42); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestTurnCCHOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+
+	plain := NewCCH()
+	if err := plain.Preprocess(g, "../../data/KaHIP/osm1.ordering"); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	turnCCH, err := NewTurnCCH(g, plain.ContractionOrder)
+	if err != nil {
+		t.Fatalf("NewTurnCCH failed: %v", err)
+	}
+
+	// Forbid one turn at every crossing and make U-turns expensive.
+	table := turns.NewTable()
+	table.SetUTurnCost(3)
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		vertices = append(vertices, id)
+	}
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i] < vertices[j] })
+	for _, via := range vertices {
+		if len(g.Edges[via]) < 3 {
+			continue
+		}
+		neighbors := make([]graph.VertexId, 0, len(g.Edges[via]))
+		for n := range g.Edges[via] {
+			neighbors = append(neighbors, n)
+		}
+		sort.Slice(neighbors, func(i, j int) bool { return neighbors[i] < neighbors[j] })
+		table.Forbid(neighbors[0], via, neighbors[1])
+		table.SetCost(neighbors[1], via, neighbors[2], 2)
+	}
+
+	if err := turnCCH.Customize(g, table); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	for i := 0; i < 40; i++ {
+		source := vertices[(i*37)%len(vertices)]
+		target := vertices[(i*101+13)%len(vertices)]
+
+		_, want, _, wantErr := pathfinding.TurnAwareDijkstraShortestPath(g, table.Cost, source, target)
+		path, got, _, err := turnCCH.Query(source, target)
+		if wantErr != nil {
+			if !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+				t.Errorf("%d -> %d: expected ErrTargetNotReachable, got %v", source, target, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d -> %d: Query failed: %v", source, target, err)
+		}
+		if got != want {
+			t.Errorf("%d -> %d: got cost %f, want %f", source, target, got, want)
+		}
+		if len(path) == 0 || path[0] != source || path[len(path)-1] != target {
+			t.Errorf("%d -> %d: got path %v", source, target, path)
+		}
+	}
+}
@@ -1,16 +1,25 @@
 package ch
 
 import (
+	"cmp"
 	"container/heap"
+	"errors"
 	"fmt"
+	"runtime"
 	"slices"
 	"sync"
+	"sync/atomic"
 
 	pathfinding "github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
 )
 
+// ErrInvalidOrder is returned by PreprocessWithOrder if the order is not a
+// permutation of the vertices of the graph.
+var ErrInvalidOrder = errors.New("invalid contraction order")
+
 // ShortcutsAdded is a global counter for the number of shortcuts added during preprocessing.
 // It is used for experimental analysis.
 var ShortcutsAdded = 0
@@ -18,25 +27,72 @@ var ShortcutsAdded = 0
 // ContractionHierarchies represents the data structure for contraction hierarchies.
 // It contains the original graph, the upward and downward graphs built during preprocessing,
 // the contraction order of vertices, and a priority queue for selecting vertices to contract.
+// If Deterministic is set, neighbors are visited and priorities are updated in the order of
+// their ids, so the contraction order, the shortcuts and the files written with
+// preprocessed_graph.FromCH are the same in every run and for every number of Workers.
+// The priority of a vertex is the weighted sum of PriorityTerms, and witness searches
+// stop early as configured by WitnessLimits and WitnessStages. If CoreSize or CoreDegree
+// is set, Preprocess stops early and keeps the uncontracted vertices as Core, which
+// queries search with a bidirectional Dijkstra; see WithCore.
 type ContractionHierarchies struct {
 	NumShortcutsAdded int
 	ContractionOrder  []graph.VertexId
 	Priorities        *collection.PriorityQueue[graph.VertexId]
 	UpwardsGraph      *graph.Graph
 	DownwardsGraph    *graph.Graph
-	shortcutCache     map[graph.VertexId]int // Cache for shortcuts computation
+	WitnessQueue      queue.Kind                    // Priority queue of the witness searches
+	Deterministic     bool                          // Reproducible preprocessing
+	Workers           int                           // Parallel witness searches, GOMAXPROCS if zero
+	PriorityTerms     []WeightedTerm                // DefaultPriority if nil
+	WitnessLimits     pathfinding.WitnessLimits     // Limits of the witness searches
+	WitnessStages     []WitnessStage                // Limits by average degree, see WithStagedWitnessLimits
+	CoreSize          int                           // Stop contracting once at most CoreSize vertices are left
+	CoreDegree        float64                       // Stop contracting once the average degree reaches CoreDegree
+	Core              *graph.Graph                  // Uncontracted vertices, nil if all were contracted
+	shortcutCache     map[graph.VertexId]simulation // Cache for shortcuts computation
 	cacheMu           sync.RWMutex
+
+	// State of the priority terms and witness stages during preprocessing.
+	deletedNeighbors map[graph.VertexId]int
+	depths           map[graph.VertexId]int
+	originalEdges    map[edgeKey]int // Original edges of a shortcut, 1 for original edges
+	numArcs          int             // Arcs of the graph left to contract
+	limits           pathfinding.WitnessLimits
 }
 
-// NewContractionHierarchies creates and initializes a new ContractionHierarchies struct.
-func NewContractionHierarchies() *ContractionHierarchies {
-	co := make([]graph.VertexId, 0)
-	ed := collection.NewPriorityQueue[graph.VertexId]()
-	ug := graph.NewGraph()
-	dg := graph.NewGraph()
-	cache := make(map[graph.VertexId]int)
+// simulation is the outcome of contracting a vertex without changing the graph.
+type simulation struct {
+	shortcuts     int
+	originalEdges int // Original edges represented by the shortcuts
+}
 
-	return &ContractionHierarchies{0, co, ed, ug, dg, cache, sync.RWMutex{}}
+// edgeKey identifies an undirected edge.
+type edgeKey struct {
+	u, v graph.VertexId
+}
+
+func newEdgeKey(u, v graph.VertexId) edgeKey {
+	return edgeKey{min(u, v), max(u, v)}
+}
+
+// NewContractionHierarchies creates and initializes a new ContractionHierarchies struct.
+func NewContractionHierarchies(opts ...Option) *ContractionHierarchies {
+	c := &ContractionHierarchies{
+		ContractionOrder: make([]graph.VertexId, 0),
+		Priorities:       collection.NewPriorityQueue[graph.VertexId](),
+		UpwardsGraph:     graph.NewGraph(),
+		DownwardsGraph:   graph.NewGraph(),
+		WitnessQueue:     queue.FourAry,
+		shortcutCache:    make(map[graph.VertexId]simulation),
+		deletedNeighbors: make(map[graph.VertexId]int),
+		depths:           make(map[graph.VertexId]int),
+		originalEdges:    make(map[edgeKey]int),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.limits = c.WitnessLimits
+	return c
 }
 
 // Preprocess prepares the graph for fast queries by contracting vertices in an optimized order.
@@ -46,6 +102,11 @@ func (c *ContractionHierarchies) Preprocess(g *graph.Graph) {
 	c.InitializePriority(g)
 
 	for len(g.Vertices) > 0 {
+		if c.reachedCore(g) {
+			c.keepCore(g)
+			return
+		}
+		c.updateLimits(g)
 		independentSet := c.findIndependentSet(g, batchSize)
 
 		if len(independentSet) == 0 {
@@ -65,7 +126,7 @@ func (c *ContractionHierarchies) Preprocess(g *graph.Graph) {
 		allNeighbors := make(map[graph.VertexId]struct{})
 		neighborsMap := make(map[graph.VertexId][]graph.Vertex)
 		for _, v := range independentSet {
-			neighbors, _ := g.Neighbors(v)
+			neighbors := c.neighbors(g, v)
 			neighborsMap[v] = neighbors
 			for _, neighbor := range neighbors {
 				allNeighbors[neighbor.Id] = struct{}{}
@@ -89,6 +150,81 @@ func (c *ContractionHierarchies) Preprocess(g *graph.Graph) {
 	}
 }
 
+// PreprocessWithOrder contracts the vertices of g in the given order instead of
+// the one chosen by the priority, e.g. a nested dissection order read with
+// parser.ReadOrdering or the ContractionOrder of an earlier run on a graph with
+// other weights. order lists every vertex of g exactly once, from the lowest
+// to the highest rank. Witness searches still prune superfluous shortcuts, and
+// runs of consecutive vertices that are independent are contracted in parallel.
+// With WithCore the contraction stops early as in Preprocess, and the rest of
+// the order forms the core.
+func (c *ContractionHierarchies) PreprocessWithOrder(g *graph.Graph, order []graph.VertexId) error {
+	const batchSize = 128
+	if len(order) != len(g.Vertices) {
+		return fmt.Errorf("%w: graph has %d vertices, but order has %d entries", ErrInvalidOrder, len(g.Vertices), len(order))
+	}
+	seen := make(map[graph.VertexId]struct{}, len(order))
+	for _, v := range order {
+		if _, ok := g.Vertices[v]; !ok {
+			return fmt.Errorf("%w: vertex %d is not part of the graph", ErrInvalidOrder, v)
+		}
+		if _, ok := seen[v]; ok {
+			return fmt.Errorf("%w: vertex %d appears more than once", ErrInvalidOrder, v)
+		}
+		seen[v] = struct{}{}
+	}
+
+	c.countArcs(g)
+	for len(order) > 0 {
+		if c.reachedCore(g) {
+			c.keepCore(g)
+			return nil
+		}
+		c.updateLimits(g)
+		batch := c.orderedIndependentSet(g, order, batchSize)
+		order = order[len(batch):]
+
+		neighborsMap := make(map[graph.VertexId][]graph.Vertex, len(batch))
+		for _, v := range batch {
+			neighborsMap[v] = c.neighbors(g, v)
+		}
+		c.contractBatch(g, batch, neighborsMap)
+	}
+	return nil
+}
+
+// orderedIndependentSet returns the longest prefix of order, up to batchSize
+// vertices, whose vertices are independent as in findIndependentSet.
+func (c *ContractionHierarchies) orderedIndependentSet(g *graph.Graph, order []graph.VertexId, batchSize int) []graph.VertexId {
+	nodesInSet := make(map[graph.VertexId]struct{})
+	neighborsOfSet := make(map[graph.VertexId]struct{})
+
+	n := 0
+	for ; n < len(order) && n < batchSize; n++ {
+		v := order[n]
+		if _, exists := neighborsOfSet[v]; exists {
+			break
+		}
+		independent := true
+		for neighbor := range g.Edges[v] {
+			_, inSet := nodesInSet[neighbor]
+			_, nextToSet := neighborsOfSet[neighbor]
+			if inSet || nextToSet {
+				independent = false
+				break
+			}
+		}
+		if !independent {
+			break
+		}
+		nodesInSet[v] = struct{}{}
+		for neighbor := range g.Edges[v] {
+			neighborsOfSet[neighbor] = struct{}{}
+		}
+	}
+	return order[:max(n, 1)]
+}
+
 // findIndependentSet selects a set of vertices that can be contracted in parallel without causing conflicts.
 // Vertices are considered independent if they are not adjacent and do not share any common neighbors.
 // It prioritizes vertices with a lower contraction priority (e.g., smaller edge difference).
@@ -160,42 +296,39 @@ func (c *ContractionHierarchies) contractBatch(
 	type shortcut struct {
 		from, to, via graph.VertexId
 		weight        int
+		originalEdges int
 	}
 
-	var (
-		wg        sync.WaitGroup
-		mu        sync.Mutex
-		shortcuts []shortcut
-	)
-
 	// --- Phase 1: find shortcuts in parallel ---
-	for _, v := range vertices {
-		wg.Add(1)
-		go func(vertexId graph.VertexId) {
-			defer wg.Done()
-			neighbors := neighborsMap[vertexId]
-			incidentEdges := g.Edges[vertexId]
-
-			for i := 0; i < len(neighbors)-1; i++ {
-				u := neighbors[i]
-				for j := i + 1; j < len(neighbors); j++ {
-					w := neighbors[j]
-
-					costViaV := float64(incidentEdges[u.Id].Weight) + float64(incidentEdges[w.Id].Weight)
-
-					// Use optimized witness search instead of two Dijkstra calls
-					if !pathfinding.WitnessSearch(g, u.Id, w.Id, costViaV, vertexId) {
-						mu.Lock()
-						shortcuts = append(shortcuts, shortcut{
-							from: u.Id, to: w.Id, via: vertexId, weight: int(costViaV),
-						})
-						mu.Unlock()
-					}
+	// Every vertex collects its shortcuts separately, so they are applied in
+	// the order of the batch.
+	found := make([][]shortcut, len(vertices))
+	c.parallelFor(len(vertices), func(k int) {
+		vertexId := vertices[k]
+		neighbors := neighborsMap[vertexId]
+		incidentEdges := g.Edges[vertexId]
+
+		for i := 0; i < len(neighbors)-1; i++ {
+			u := neighbors[i]
+			for j := i + 1; j < len(neighbors); j++ {
+				w := neighbors[j]
+				if closedVia(incidentEdges, u.Id, w.Id) {
+					continue
+				}
+
+				costViaV := float64(incidentEdges[u.Id].Weight) + float64(incidentEdges[w.Id].Weight)
+
+				// Use optimized witness search instead of two Dijkstra calls
+				if !c.witnessSearch(g, u.Id, w.Id, costViaV, vertexId) {
+					found[k] = append(found[k], shortcut{
+						from: u.Id, to: w.Id, via: vertexId, weight: int(costViaV),
+						originalEdges: c.originals(vertexId, u.Id) + c.originals(vertexId, w.Id),
+					})
 				}
 			}
-		}(v)
-	}
-	wg.Wait()
+		}
+	})
+	shortcuts := slices.Concat(found...)
 
 	// --- Phase 2: apply graph modifications sequentially ---
 	for _, sc := range shortcuts {
@@ -212,14 +345,18 @@ func (c *ContractionHierarchies) contractBatch(
 			if cost < existingEdge.Weight {
 				g.UpdateEdge(sc.from, sc.to, cost, true, sc.via)
 				g.UpdateEdge(sc.to, sc.from, cost, true, sc.via)
+				c.originalEdges[newEdgeKey(sc.from, sc.to)] = sc.originalEdges
 			}
 		} else {
 			g.AddEdge(sc.to, sc.from, cost, true, sc.via)
+			c.originalEdges[newEdgeKey(sc.from, sc.to)] = sc.originalEdges
+			c.numArcs += 2
 		}
 	}
 
 	// --- Phase 3: contract vertices ---
 	for _, v := range vertices {
+		c.markContracted(g, v)
 		c.ContractionOrder = append(c.ContractionOrder, v)
 		c.InsertInUpwardsOrDownwardsGraph(g, v)
 		if err := g.RemoveVertex(v); err != nil {
@@ -231,32 +368,58 @@ func (c *ContractionHierarchies) contractBatch(
 // InitializePriority computes the initial priority for every vertex in the graph and
 // populates the priority queue - now parallelized for better performance.
 func (c *ContractionHierarchies) InitializePriority(g *graph.Graph) {
-	var wg sync.WaitGroup
-	results := make(chan struct {
-		id       graph.VertexId
-		priority float64
-	}, len(g.Vertices))
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	if c.Deterministic {
+		slices.Sort(vertices)
+	}
 
-	for _, v := range g.Vertices {
-		wg.Add(1)
-		go func(vertex graph.VertexId) {
-			defer wg.Done()
-			priority := c.Priority(g, vertex)
-			results <- struct {
-				id       graph.VertexId
-				priority float64
-			}{id: vertex, priority: priority}
-		}(v.Id)
+	c.countArcs(g)
+	c.updateLimits(g)
+
+	for i, priority := range c.priorities(g, vertices) {
+		c.Priorities.PushWithPriority(vertices[i], priority)
+	}
+}
+
+// countArcs initializes the number of arcs of the graph left to contract.
+func (c *ContractionHierarchies) countArcs(g *graph.Graph) {
+	c.numArcs = 0
+	for _, edges := range g.Edges {
+		c.numArcs += len(edges)
+	}
+}
+
+// updateLimits selects the witness search limits for the average degree of g.
+func (c *ContractionHierarchies) updateLimits(g *graph.Graph) {
+	averageDegree := 0.0
+	if len(g.Vertices) > 0 {
+		averageDegree = float64(c.numArcs) / float64(len(g.Vertices))
 	}
+	c.limits = c.limitsFor(averageDegree)
+}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// markContracted updates the state of the priority terms before v and its
+// edges are removed from g.
+func (c *ContractionHierarchies) markContracted(g *graph.Graph, v graph.VertexId) {
+	for neighbor := range g.Edges[v] {
+		c.deletedNeighbors[neighbor]++
+		c.depths[neighbor] = max(c.depths[neighbor], c.depths[v]+1)
+		delete(c.originalEdges, newEdgeKey(v, neighbor))
+	}
+	c.numArcs -= 2 * len(g.Edges[v])
+	delete(c.deletedNeighbors, v)
+	delete(c.depths, v)
+}
 
-	for r := range results {
-		c.Priorities.PushWithPriority(r.id, r.priority)
+// originals returns the number of original edges the edge between u and v represents.
+func (c *ContractionHierarchies) originals(u, v graph.VertexId) int {
+	if n, ok := c.originalEdges[newEdgeKey(u, v)]; ok {
+		return n
 	}
+	return 1
 }
 
 // recomputeBatchNeighborPriorities recalculates priorities for all neighbors
@@ -264,65 +427,66 @@ func (c *ContractionHierarchies) InitializePriority(g *graph.Graph) {
 func (c *ContractionHierarchies) recomputeBatchNeighborPriorities(
 	g *graph.Graph, allNeighbors map[graph.VertexId]struct{},
 ) {
-	var wg sync.WaitGroup
-	results := make(chan struct {
-		id       graph.VertexId
-		priority float64
-	}, len(allNeighbors))
-
+	vertices := make([]graph.VertexId, 0, len(allNeighbors))
 	for neighborId := range allNeighbors {
 		if _, ok := g.Vertices[neighborId]; !ok {
 			continue // skip already contracted
 		}
-		wg.Add(1)
-		go func(n graph.VertexId) {
-			defer wg.Done()
-			prio := c.Priority(g, n)
-			results <- struct {
-				id       graph.VertexId
-				priority float64
-			}{id: n, priority: prio}
-		}(neighborId)
+		vertices = append(vertices, neighborId)
+	}
+	if c.Deterministic {
+		slices.Sort(vertices)
 	}
 
-	wg.Wait()
-	close(results)
-
-	for r := range results {
-		c.Priorities.UpdatePriority(r.id, r.priority)
+	for i, priority := range c.priorities(g, vertices) {
+		c.Priorities.UpdatePriority(vertices[i], priority)
 	}
 }
 
 // recomputeNeighborPriorities recalculates priorities for a set of neighbor vertices
 // in parallel and applies updates to the priority queue sequentially.
 func (c *ContractionHierarchies) recomputeNeighborPriorities(g *graph.Graph, neighbors map[graph.VertexId]struct{}) {
-	var wg sync.WaitGroup
-	results := make(chan struct {
-		id       graph.VertexId
-		priority float64
-	}, len(neighbors))
+	c.recomputeBatchNeighborPriorities(g, neighbors)
+}
 
-	for neighborId := range neighbors {
-		if _, ok := g.Vertices[neighborId]; !ok {
-			continue // skip already removed vertices
-		}
+// priorities computes the priorities of the given vertices in parallel.
+func (c *ContractionHierarchies) priorities(g *graph.Graph, vertices []graph.VertexId) []float64 {
+	priorities := make([]float64, len(vertices))
+	c.parallelFor(len(vertices), func(i int) {
+		priorities[i] = c.Priority(g, vertices[i])
+	})
+	return priorities
+}
+
+// parallelFor calls fn for 0 <= i < n on c.Workers goroutines.
+func (c *ContractionHierarchies) parallelFor(n int, fn func(i int)) {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n)
+
+	var wg sync.WaitGroup
+	var next atomic.Int64
+	for range workers {
 		wg.Add(1)
-		go func(n graph.VertexId) {
+		go func() {
 			defer wg.Done()
-			prio := c.Priority(g, n)
-			results <- struct {
-				id       graph.VertexId
-				priority float64
-			}{id: n, priority: prio}
-		}(neighborId)
+			for i := int(next.Add(1)) - 1; i < n; i = int(next.Add(1)) - 1 {
+				fn(i)
+			}
+		}()
 	}
-
 	wg.Wait()
-	close(results)
+}
 
-	for r := range results {
-		c.Priorities.UpdatePriority(r.id, r.priority)
+// neighbors returns the neighbors of v in g, ordered by id if c is deterministic.
+func (c *ContractionHierarchies) neighbors(g *graph.Graph, v graph.VertexId) []graph.Vertex {
+	neighbors, _ := g.Neighbors(v)
+	if c.Deterministic {
+		slices.SortFunc(neighbors, func(a, b graph.Vertex) int { return cmp.Compare(a.Id, b.Id) })
 	}
+	return neighbors
 }
 
 // Contract contracts a single vertex v. This involves adding shortcuts between its neighbors
@@ -330,6 +494,7 @@ func (c *ContractionHierarchies) recomputeNeighborPriorities(g *graph.Graph, nei
 // The contracted vertex is added to the contraction order.
 func (c *ContractionHierarchies) Contract(g *graph.Graph, v graph.VertexId) {
 	c.Shortcuts(g, v, true)
+	c.markContracted(g, v)
 	c.ContractionOrder = append(c.ContractionOrder, v)
 	c.InsertInUpwardsOrDownwardsGraph(g, v)
 
@@ -373,85 +538,145 @@ func (c *ContractionHierarchies) InsertInUpwardsOrDownwardsGraph(g *graph.Graph,
 // If the insertFlag is true, it adds the necessary shortcuts to the graph.
 // Optimized to use witness search and caching.
 func (c *ContractionHierarchies) Shortcuts(g *graph.Graph, v graph.VertexId, insertFlag bool) int {
-	// Check cache first if not inserting
 	if !insertFlag {
-		c.cacheMu.RLock()
-		if cached, exists := c.shortcutCache[v]; exists {
-			c.cacheMu.RUnlock()
-			return cached
-		}
-		c.cacheMu.RUnlock()
+		return c.simulate(g, v).shortcuts
 	}
 
 	shortcutsFound := 0
-	neighbors, _ := g.Neighbors(v)
+	neighbors := c.neighbors(g, v)
 	incidentEdges := g.Edges[v]
 
 	for i := 0; i < len(neighbors)-1; i++ {
 		u := neighbors[i]
 		for j := i + 1; j < len(neighbors); j++ {
 			w := neighbors[j]
+			if closedVia(incidentEdges, u.Id, w.Id) {
+				continue
+			}
 
 			costViaV := float64(incidentEdges[u.Id].Weight) + float64(incidentEdges[w.Id].Weight)
 
 			// Use optimized witness search
-			if !pathfinding.WitnessSearch(g, u.Id, w.Id, costViaV, v) {
+			if !c.witnessSearch(g, u.Id, w.Id, costViaV, v) {
 				shortcutsFound++
-				if insertFlag {
-					c.NumShortcutsAdded++
-					ShortcutsAdded++
-					cost := int(costViaV)
-					addErr := g.AddEdge(u.Id, w.Id, cost, true, v)
-					if addErr == graph.ErrEdgeAlreadyExists {
-						g.UpdateEdge(u.Id, w.Id, cost, true, v)
-						g.UpdateEdge(w.Id, u.Id, cost, true, v)
-					} else {
-						g.AddEdge(w.Id, u.Id, cost, true, v)
-					}
+				c.NumShortcutsAdded++
+				ShortcutsAdded++
+				cost := int(costViaV)
+				addErr := g.AddEdge(u.Id, w.Id, cost, true, v)
+				if addErr == graph.ErrEdgeAlreadyExists {
+					g.UpdateEdge(u.Id, w.Id, cost, true, v)
+					g.UpdateEdge(w.Id, u.Id, cost, true, v)
+				} else {
+					g.AddEdge(w.Id, u.Id, cost, true, v)
+					c.numArcs += 2
 				}
+				c.originalEdges[newEdgeKey(u.Id, w.Id)] = c.originals(v, u.Id) + c.originals(v, w.Id)
 			}
 		}
 	}
 
-	// Cache result if not inserting
-	if !insertFlag {
-		c.cacheMu.Lock()
-		c.shortcutCache[v] = shortcutsFound
-		c.cacheMu.Unlock()
+	return shortcutsFound
+}
+
+// closedVia reports whether the path u - v - w uses an edge of infinite weight.
+// Such a path is never shortest, so it needs no shortcut.
+func closedVia(incidentEdges map[graph.VertexId]graph.Edge, u, w graph.VertexId) bool {
+	return incidentEdges[u].Weight == graph.InfWeight || incidentEdges[w].Weight == graph.InfWeight
+}
+
+// simulate finds the shortcuts contracting v would add, using the cache if possible.
+func (c *ContractionHierarchies) simulate(g *graph.Graph, v graph.VertexId) simulation {
+	c.cacheMu.RLock()
+	if cached, exists := c.shortcutCache[v]; exists {
+		c.cacheMu.RUnlock()
+		return cached
 	}
+	c.cacheMu.RUnlock()
 
-	return shortcutsFound
+	var sim simulation
+	neighbors := c.neighbors(g, v)
+	incidentEdges := g.Edges[v]
+
+	for i := 0; i < len(neighbors)-1; i++ {
+		u := neighbors[i]
+		for j := i + 1; j < len(neighbors); j++ {
+			w := neighbors[j]
+			if closedVia(incidentEdges, u.Id, w.Id) {
+				continue
+			}
+
+			costViaV := float64(incidentEdges[u.Id].Weight) + float64(incidentEdges[w.Id].Weight)
+			if !c.witnessSearch(g, u.Id, w.Id, costViaV, v) {
+				sim.shortcuts++
+				sim.originalEdges += c.originals(v, u.Id) + c.originals(v, w.Id)
+			}
+		}
+	}
+
+	c.cacheMu.Lock()
+	c.shortcutCache[v] = sim
+	c.cacheMu.Unlock()
+
+	return sim
 }
 
 // Priority calculates the contraction priority for a vertex v. The priority is a heuristic
-// used to decide the order of contraction. It is typically based on the edge difference,
-// which is the number of shortcuts added minus the number of edges removed.
-// Enhanced with additional factors for better performance.
+// used to decide the order of contraction: the sum of the PriorityTerms of v, each
+// multiplied by its weight.
 func (c *ContractionHierarchies) Priority(g *graph.Graph, v graph.VertexId) float64 {
 	degree, _ := g.Degree(v)
-	shortcuts := c.Shortcuts(g, v, false)
-	ed := shortcuts - degree
+	sim := c.simulate(g, v)
 
-	// Add factor for contracted neighbors to prefer vertices with contracted neighbors
-	contractedNeighbors := 0
-	for neighbor := range g.Edges[v] {
-		if slices.Contains(c.ContractionOrder, neighbor) {
-			contractedNeighbors++
-		}
+	terms := c.PriorityTerms
+	if terms == nil {
+		terms = DefaultPriority
 	}
 
-	priority := float64(ed) +
-		(float64(shortcuts) / (float64(degree) + 1.0)) +
-		float64(contractedNeighbors)*0.5
+	priority := 0.0
+	for _, term := range terms {
+		var value float64
+		switch term.Term {
+		case EdgeDifference:
+			value = float64(sim.shortcuts - degree)
+		case ShortcutRatio:
+			value = float64(sim.shortcuts) / (float64(degree) + 1.0)
+		case DeletedNeighbors:
+			value = float64(c.deletedNeighbors[v])
+		case SearchSpaceDepth:
+			value = float64(c.depths[v])
+		case OriginalEdges:
+			removed := 0
+			for neighbor := range g.Edges[v] {
+				removed += c.originals(v, neighbor)
+			}
+			value = float64(sim.originalEdges - removed)
+		}
+		priority += term.Weight * value
+	}
 
 	return priority
 }
 
+// witnessSearch runs a witness search with a workspace whose queue is of
+// kind c.WitnessQueue, within the limits of the current stage.
+func (c *ContractionHierarchies) witnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64, ignoredNode graph.VertexId) bool {
+	pool := pathfinding.SharedWorkspacePool(c.WitnessQueue)
+	w := pool.Get()
+	defer pool.Put(w)
+	return w.LimitedWitnessSearch(g, source, target, bound, ignoredNode, c.limits)
+}
+
 // Query finds the shortest path between a source and a target vertex using the preprocessed
 // contraction hierarchy. It performs a bidirectional Dijkstra search on the upward and downward
 // graphs and then unpacks the resulting path to resolve any shortcuts.
 func (c *ContractionHierarchies) Query(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
-	path, weight, nodesPopped, err := pathfinding.BiDirectionalDijkstraShortestPath(c.UpwardsGraph, c.DownwardsGraph, source, target)
+	return c.QueryWith(nil, source, target)
+}
+
+// QueryWith answers the same query as Query with the search state kept in w,
+// which may be nil.
+func (c *ContractionHierarchies) QueryWith(w *pathfinding.Workspace, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	path, weight, nodesPopped, err := c.search(w, source, target)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bidirectional Dijkstra failed: %w", err)
 	}
@@ -472,7 +697,13 @@ func (c *ContractionHierarchies) Query(source, target graph.VertexId) ([]graph.V
 // contraction hierarchy. It performs a bidirectional Dijkstra search on the upward and downward
 // graphs and returns the resulting path without unpacking any shortcuts.
 func (c *ContractionHierarchies) QueryNoUnpack(source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
-	path, weight, nodesPopped, err := pathfinding.BiDirectionalDijkstraShortestPath(c.UpwardsGraph, c.DownwardsGraph, source, target)
+	return c.QueryNoUnpackWith(nil, source, target)
+}
+
+// QueryNoUnpackWith answers the same query as QueryNoUnpack with the search
+// state kept in w, which may be nil.
+func (c *ContractionHierarchies) QueryNoUnpackWith(w *pathfinding.Workspace, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	path, weight, nodesPopped, err := c.search(w, source, target)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("bidirectional Dijkstra failed: %w", err)
 	}
@@ -484,6 +715,12 @@ func (c *ContractionHierarchies) QueryNoUnpack(source, target graph.VertexId) ([
 	return path, weight, nodesPopped, nil
 }
 
+// UnpackPath replaces every shortcut of a path on the upward and downward graphs
+// by the original edges it represents.
+func (c *ContractionHierarchies) UnpackPath(path []graph.VertexId) ([]graph.VertexId, error) {
+	return c.unpackPath(path)
+}
+
 // unpackPath reconstructs the full shortest path from a path that may contain shortcuts.
 // It iterates through the path segments and recursively unpacks any shortcut edges.
 func (c *ContractionHierarchies) unpackPath(path []graph.VertexId) ([]graph.VertexId, error) {
@@ -514,6 +751,9 @@ func (c *ContractionHierarchies) unpackEdge(u, v graph.VertexId) ([]graph.Vertex
 	edge, ok = c.UpwardsGraph.Edges[u][v]
 	if !ok {
 		edge, ok = c.DownwardsGraph.Edges[u][v]
+		if !ok && c.Core != nil {
+			edge, ok = c.Core.Edges[u][v]
+		}
 		if !ok {
 			return nil, fmt.Errorf("no edge found between %d and %d in CH graphs", u, v)
 		}
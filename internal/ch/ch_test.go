@@ -2,14 +2,22 @@ package ch
 
 import (
 	"container/heap"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/PaulMue0/efficient-routeplanning/internal/generate"
 	parser "github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
 )
 
 // --- Test Helper Functions ---
@@ -376,3 +384,334 @@ func BenchmarkOsm1(b *testing.B) {
 
 	}
 }
+
+// BenchmarkQueues compares the priority queues in the witness searches of the
+// preprocessing and in the queries on the road networks.
+func BenchmarkQueues(b *testing.B) {
+	for _, name := range []string{"osm1.txt", "osm3.txt"} {
+		network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), name)
+		if err != nil {
+			b.Skipf("failed to load %s: %v", name, err)
+		}
+		prefix := strings.TrimSuffix(name, ".txt")
+
+		for _, kind := range queue.Kinds() {
+			b.Run(prefix+"/preprocess/"+kind.String(), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					ch := NewContractionHierarchies()
+					ch.WitnessQueue = kind
+					ch.Preprocess(network.Network.Clone())
+				}
+			})
+		}
+
+		ch := NewContractionHierarchies()
+		ch.Preprocess(network.Network.Clone())
+		vertices := make([]graph.VertexId, 0, len(network.Network.Vertices))
+		for v := range network.Network.Vertices {
+			vertices = append(vertices, v)
+		}
+		slices.Sort(vertices)
+
+		for _, kind := range queue.Kinds() {
+			b.Run(prefix+"/query/"+kind.String(), func(b *testing.B) {
+				w := pathfinding.NewWorkspace(len(vertices), kind)
+				r := rand.New(rand.NewSource(1))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ch.QueryNoUnpackWith(w, vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))])
+				}
+			})
+		}
+	}
+}
+
+func TestPriorityTerms(t *testing.T) {
+	// A star with center 3 and leaves 0, 1 and 2.
+	g := graph.NewGraph()
+	for i := 0; i <= 3; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	for i := 0; i < 3; i++ {
+		g.AddEdge(graph.VertexId(i), 3, 1, false, -1)
+		g.AddEdge(3, graph.VertexId(i), 1, false, -1)
+	}
+
+	// Contracting the center adds three shortcuts of two original edges each.
+	c := NewContractionHierarchies(WithPriority(WeightedTerm{OriginalEdges, 1}))
+	if got := c.Priority(g, 3); got != 3 {
+		t.Errorf("original edges of the center = %v, want 3", got)
+	}
+
+	c.Contract(g, 3)
+	tests := []struct {
+		term PriorityTerm
+		want float64
+	}{
+		{EdgeDifference, -4},  // no shortcut, two edges
+		{ShortcutRatio, 0},    // no shortcut
+		{DeletedNeighbors, 2}, // the center
+		{SearchSpaceDepth, 2}, // above the center
+		{OriginalEdges, -8},   // two shortcuts of two edges each
+	}
+	for _, tt := range tests {
+		c.PriorityTerms = []WeightedTerm{{tt.term, 2}}
+		if got := c.Priority(g, 0); got != tt.want {
+			t.Errorf("term %d of leaf 0 = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+// TestWitnessLimits checks that limited witness searches only add shortcuts
+// and keep the query results exact.
+func TestWitnessLimits(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Skipf("failed to load osm1: %v", err)
+	}
+	g := network.Network
+
+	exact := NewContractionHierarchies(WithDeterminism())
+	exact.Preprocess(g.Clone())
+
+	options := map[string][]Option{
+		"hops":     {WithWitnessLimits(pathfinding.WitnessLimits{MaxHops: 1})},
+		"settled":  {WithWitnessLimits(pathfinding.WitnessLimits{MaxSettled: 5})},
+		"staged":   {WithStagedWitnessLimits(StagedHopLimits...)},
+		"priority": {WithPriority(WeightedTerm{EdgeDifference, 1}, WeightedTerm{SearchSpaceDepth, 1}, WeightedTerm{OriginalEdges, 0.5})},
+	}
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+
+	for name, opts := range options {
+		t.Run(name, func(t *testing.T) {
+			c := NewContractionHierarchies(append(opts, WithDeterminism())...)
+			c.Preprocess(g.Clone())
+			if name != "priority" && c.NumShortcutsAdded < exact.NumShortcutsAdded {
+				t.Errorf("limited searches added %d shortcuts, unlimited ones %d", c.NumShortcutsAdded, exact.NumShortcutsAdded)
+			}
+
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 50; i++ {
+				s, d := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+				_, want, _, wantErr := exact.QueryNoUnpack(s, d)
+				_, got, _, err := c.QueryNoUnpack(s, d)
+				if got != want || (err == nil) != (wantErr == nil) {
+					t.Errorf("query %d -> %d = %v, %v, want %v, %v", s, d, got, err, want, wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestPreprocessWithOrder(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Skipf("failed to load osm1: %v", err)
+	}
+	g := network.Network
+	greedy := NewContractionHierarchies(WithDeterminism())
+	greedy.Preprocess(g.Clone())
+
+	file, err := os.Open("../../data/KaHIP/osm1.ordering")
+	if err != nil {
+		t.Skipf("failed to open osm1.ordering: %v", err)
+	}
+	defer file.Close()
+	nestedDissection, err := parser.ReadOrdering(g, file)
+	if err != nil {
+		t.Fatalf("ReadOrdering failed: %v", err)
+	}
+
+	// The greedy order is reused for a graph with other weights.
+	reweighted := g.Clone()
+	for u, edges := range reweighted.Edges {
+		for v, edge := range edges {
+			reweighted.UpdateEdge(u, v, edge.Weight*(1+int(u+v)%3), false, -1)
+		}
+	}
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+
+	for name, tc := range map[string]struct {
+		g     *graph.Graph
+		order []graph.VertexId
+	}{
+		"greedy":            {g, greedy.ContractionOrder},
+		"nested dissection": {g, nestedDissection},
+		"reweighted":        {reweighted, greedy.ContractionOrder},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := NewContractionHierarchies(WithDeterminism())
+			if err := c.PreprocessWithOrder(tc.g.Clone(), tc.order); err != nil {
+				t.Fatalf("PreprocessWithOrder failed: %v", err)
+			}
+			if !slices.Equal(c.ContractionOrder, tc.order) {
+				t.Errorf("contraction order differs from the given one")
+			}
+
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 50; i++ {
+				s, d := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+				_, want, _, wantErr := pathfinding.DijkstraShortestPath(tc.g, s, d, math.Inf(1))
+				_, got, _, err := c.QueryNoUnpack(s, d)
+				if (wantErr == nil && got != want) || (err == nil) != (wantErr == nil) {
+					t.Errorf("query %d -> %d = %v, %v, want %v, %v", s, d, got, err, want, wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestPreprocessWithInvalidOrder(t *testing.T) {
+	for name, order := range map[string][]graph.VertexId{
+		"too short": {0, 1, 2},
+		"unknown":   {0, 1, 2, 3, 4, 5, 6, 8},
+		"duplicate": {0, 1, 2, 3, 4, 5, 6, 6},
+	} {
+		c := NewContractionHierarchies()
+		if err := c.PreprocessWithOrder(createGraphFromSlidedeck(), order); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: PreprocessWithOrder error = %v, want ErrInvalidOrder", name, err)
+		}
+	}
+}
+
+func TestClosedEdges(t *testing.T) {
+	// The edge 0 - 2 is closed, so 0 -> 2 must take the detour over 1.
+	build := func() *graph.Graph {
+		g := graph.NewGraph()
+		for v := graph.VertexId(0); v < 3; v++ {
+			g.AddVertex(graph.Vertex{Id: v})
+		}
+		for _, e := range []struct {
+			u, v   graph.VertexId
+			weight int
+		}{{0, 1, 1}, {1, 2, 1}, {0, 2, graph.InfWeight}} {
+			g.AddEdge(e.u, e.v, e.weight, false, -1)
+			g.AddEdge(e.v, e.u, e.weight, false, -1)
+		}
+		return g
+	}
+
+	for _, order := range [][]graph.VertexId{{0, 1, 2}, {1, 0, 2}, {2, 1, 0}} {
+		c := NewContractionHierarchies()
+		if err := c.PreprocessWithOrder(build(), order); err != nil {
+			t.Fatalf("order %v: PreprocessWithOrder failed: %v", order, err)
+		}
+		for _, pair := range [][2]graph.VertexId{{0, 2}, {2, 0}} {
+			path, got, _, err := c.Query(pair[0], pair[1])
+			if err != nil || got != 2 || len(path) != 3 {
+				t.Errorf("order %v: query %d -> %d = %v, %v, %v, want the path over 1 of weight 2", order, pair[0], pair[1], path, got, err)
+			}
+		}
+	}
+}
+
+func TestCoreCH(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Skipf("failed to load osm1: %v", err)
+	}
+	g := network.Network
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+
+	greedy := NewContractionHierarchies(WithDeterminism())
+	greedy.Preprocess(g.Clone())
+
+	for name, tc := range map[string]struct {
+		opt   Option
+		order []graph.VertexId
+	}{
+		"size":          {WithCore(100, 0), nil},
+		"degree":        {WithCore(0, 3), nil},
+		"size, ordered": {WithCore(100, 0), greedy.ContractionOrder},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := NewContractionHierarchies(tc.opt, WithDeterminism())
+			if tc.order != nil {
+				if err := c.PreprocessWithOrder(g.Clone(), tc.order); err != nil {
+					t.Fatalf("PreprocessWithOrder failed: %v", err)
+				}
+				if !slices.Equal(c.ContractionOrder, tc.order[:len(c.ContractionOrder)]) {
+					t.Error("contraction order is no prefix of the given one")
+				}
+			} else {
+				c.Preprocess(g.Clone())
+			}
+			if !c.HasCore() {
+				t.Fatal("preprocessing left no core")
+			}
+			if got := len(c.ContractionOrder) + len(c.Core.Vertices); got != len(g.Vertices) {
+				t.Errorf("%d contracted and core vertices, want %d", got, len(g.Vertices))
+			}
+			order := c.FullOrder()
+			assertIsPermutation(t, order, g.Vertices)
+			if !slices.Equal(order[:len(c.ContractionOrder)], c.ContractionOrder) {
+				t.Error("full order does not start with the contraction order")
+			}
+
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 50; i++ {
+				s, d := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+				_, want, _, wantErr := pathfinding.DijkstraShortestPath(g, s, d, math.Inf(1))
+				path, got, _, err := c.Query(s, d)
+				if (err == nil) != (wantErr == nil) || (err == nil && got != want) {
+					t.Fatalf("query %d -> %d = %v, %v, want %v, %v", s, d, got, err, want, wantErr)
+				}
+				if err != nil {
+					continue
+				}
+
+				// The unpacked path must consist of original edges.
+				length := 0
+				for j := 0; j+1 < len(path); j++ {
+					edge, ok := g.Edges[path[j]][path[j+1]]
+					if !ok {
+						t.Fatalf("query %d -> %d: no edge %d -> %d", s, d, path[j], path[j+1])
+					}
+					length += edge.Weight
+				}
+				if float64(length) != want {
+					t.Errorf("query %d -> %d: path has length %d, want %v", s, d, length, want)
+				}
+			}
+		})
+	}
+}
+
+func FuzzQueryRandomGeometric(f *testing.F) {
+	f.Add(int64(1), uint8(60), uint8(10))
+	f.Add(int64(2), uint8(200), uint8(1))
+	f.Add(int64(3), uint8(30), uint8(100))
+	f.Fuzz(func(t *testing.T, seed int64, n, maxWeight uint8) {
+		// A radius of about two average vertex distances leaves some
+		// vertices isolated, so unreachable targets are covered as well.
+		g := generate.RandomGeometric(int(n), 1000, 2000/math.Sqrt(float64(n)+1), generate.Options{
+			Seed:   seed,
+			Weight: generate.Uniform(1, int(maxWeight)+1),
+		})
+		c := NewContractionHierarchies(WithDeterminism())
+		c.Preprocess(g.Clone())
+
+		r := rand.New(rand.NewSource(seed))
+		for i := 0; i < 20 && len(g.Vertices) > 0; i++ {
+			s, d := graph.VertexId(r.Intn(len(g.Vertices))), graph.VertexId(r.Intn(len(g.Vertices)))
+			_, want, _, wantErr := pathfinding.DijkstraShortestPath(g, s, d, math.Inf(1))
+			_, got, _, err := c.Query(s, d)
+			if (err == nil) != (wantErr == nil) || (err == nil && got != want) {
+				t.Fatalf("query %d -> %d = %v, %v, want %v, %v", s, d, got, err, want, wantErr)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package ch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	pathfinding "github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrHasCore is returned by techniques that need every vertex in the
+// contraction order, such as hub labels, when given a core CH.
+var ErrHasCore = errors.New("contraction hierarchy has a core")
+
+// HasCore reports whether preprocessing stopped before contracting every
+// vertex and left a core.
+func (c *ContractionHierarchies) HasCore() bool {
+	return c.Core != nil
+}
+
+// RequireNoCore returns an error wrapping ErrHasCore if c has a core.
+func (c *ContractionHierarchies) RequireNoCore() error {
+	if c.Core != nil {
+		return fmt.Errorf("%w of %d vertices", ErrHasCore, len(c.Core.Vertices))
+	}
+	return nil
+}
+
+// FullOrder returns every vertex from the lowest to the highest rank: the
+// contraction order followed by the core vertices, sorted by id. Core
+// vertices rank above all contracted ones, so the result is a valid
+// contraction order of the whole graph.
+func (c *ContractionHierarchies) FullOrder() []graph.VertexId {
+	order := slices.Clone(c.ContractionOrder)
+	if c.Core == nil {
+		return order
+	}
+	core := make([]graph.VertexId, 0, len(c.Core.Vertices))
+	for v := range c.Core.Vertices {
+		core = append(core, v)
+	}
+	slices.Sort(core)
+	return append(order, core...)
+}
+
+// reachedCore reports whether the graph left to contract is small or dense
+// enough to become the core.
+func (c *ContractionHierarchies) reachedCore(g *graph.Graph) bool {
+	if len(g.Vertices) <= c.CoreSize {
+		return true
+	}
+	return c.CoreDegree > 0 && float64(c.numArcs)/float64(len(g.Vertices)) >= c.CoreDegree
+}
+
+// keepCore stores the vertices left in g, with their edges and shortcuts, as
+// the core. Core vertices rank above all contracted ones, so the upward and
+// downward graphs already hold the edges into the core.
+func (c *ContractionHierarchies) keepCore(g *graph.Graph) {
+	c.Core = g.Clone()
+	for _, v := range g.Vertices {
+		c.UpwardsGraph.AddVertex(v)
+		c.DownwardsGraph.AddVertex(v)
+	}
+}
+
+// search runs the bidirectional upward search of a query, through the core if
+// there is one.
+func (c *ContractionHierarchies) search(w *pathfinding.Workspace, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	if c.Core != nil {
+		return w.CoreShortestPath(c.UpwardsGraph, c.DownwardsGraph, c.Core, source, target)
+	}
+	return w.BiDirectionalDijkstraShortestPath(c.UpwardsGraph, c.DownwardsGraph, source, target)
+}
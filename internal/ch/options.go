@@ -0,0 +1,131 @@
+package ch
+
+import (
+	"math"
+
+	pathfinding "github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
+)
+
+// PriorityTerm is one measure of how expensive it is to contract a vertex now.
+// The priority of a vertex is the weighted sum of the terms of a
+// ContractionHierarchies, and vertices with a small priority are contracted first.
+type PriorityTerm int
+
+const (
+	// EdgeDifference is the number of shortcuts the contraction adds minus the
+	// number of edges it removes.
+	EdgeDifference PriorityTerm = iota
+	// ShortcutRatio is the number of shortcuts divided by the degree plus one.
+	ShortcutRatio
+	// DeletedNeighbors is the number of neighbors contracted so far. It spreads
+	// the contraction uniformly over the graph.
+	DeletedNeighbors
+	// SearchSpaceDepth is one more than the largest depth of a contracted
+	// neighbor, i.e. the number of edges of the longest upward path ending in
+	// the vertex. It keeps the upward search spaces shallow.
+	SearchSpaceDepth
+	// OriginalEdges is the number of original edges the shortcuts represent
+	// minus the number the removed edges represent. It keeps shortcuts from
+	// growing long, which makes unpacking them cheaper.
+	OriginalEdges
+)
+
+// WeightedTerm is a priority term with its weight.
+type WeightedTerm struct {
+	Term   PriorityTerm
+	Weight float64
+}
+
+// DefaultPriority are the priority terms used if none are configured.
+var DefaultPriority = []WeightedTerm{
+	{EdgeDifference, 1},
+	{ShortcutRatio, 1},
+	{DeletedNeighbors, 0.5},
+}
+
+// WitnessStage applies witness search limits while the average degree of the
+// graph that is left to contract is at most MaxAverageDegree.
+type WitnessStage struct {
+	MaxAverageDegree float64
+	Limits           pathfinding.WitnessLimits
+}
+
+// StagedHopLimits raise the hop limit of the witness searches as the
+// remaining graph gets denser: one hop up to an average degree of 3.3, two
+// hops up to 10 and five hops above, as proposed by Geisberger et al.
+var StagedHopLimits = []WitnessStage{
+	{3.3, pathfinding.WitnessLimits{MaxHops: 1}},
+	{10, pathfinding.WitnessLimits{MaxHops: 2}},
+	{math.Inf(1), pathfinding.WitnessLimits{MaxHops: 5}},
+}
+
+// Option configures a ContractionHierarchies created by NewContractionHierarchies.
+type Option func(*ContractionHierarchies)
+
+// WithPriority replaces DefaultPriority with the given terms.
+func WithPriority(terms ...WeightedTerm) Option {
+	return func(c *ContractionHierarchies) {
+		c.PriorityTerms = terms
+	}
+}
+
+// WithWitnessLimits limits every witness search. Limited searches miss some
+// witnesses, which makes preprocessing faster but adds superfluous shortcuts.
+func WithWitnessLimits(limits pathfinding.WitnessLimits) Option {
+	return func(c *ContractionHierarchies) {
+		c.WitnessLimits = limits
+	}
+}
+
+// WithStagedWitnessLimits picks the witness search limits by the average
+// degree of the remaining graph. The first stage whose MaxAverageDegree is
+// not exceeded applies; above all stages the limits of WithWitnessLimits do.
+func WithStagedWitnessLimits(stages ...WitnessStage) Option {
+	return func(c *ContractionHierarchies) {
+		c.WitnessStages = stages
+	}
+}
+
+// WithCore stops the contraction once at most size vertices are left or their
+// average degree reaches averageDegree, whichever comes first. A zero value
+// disables the respective bound. The remaining vertices form the core, which
+// needs no preprocessing and suits metrics that change often; queries search
+// it with a bidirectional Dijkstra after the upward searches reach it.
+func WithCore(size int, averageDegree float64) Option {
+	return func(c *ContractionHierarchies) {
+		c.CoreSize = size
+		c.CoreDegree = averageDegree
+	}
+}
+
+// WithWitnessQueue sets the priority queue of the witness searches.
+func WithWitnessQueue(kind queue.Kind) Option {
+	return func(c *ContractionHierarchies) {
+		c.WitnessQueue = kind
+	}
+}
+
+// WithDeterminism makes preprocessing reproducible, see ContractionHierarchies.
+func WithDeterminism() Option {
+	return func(c *ContractionHierarchies) {
+		c.Deterministic = true
+	}
+}
+
+// WithWorkers sets the number of parallel witness searches.
+func WithWorkers(workers int) Option {
+	return func(c *ContractionHierarchies) {
+		c.Workers = workers
+	}
+}
+
+// limitsFor returns the witness search limits at the given average degree.
+func (c *ContractionHierarchies) limitsFor(averageDegree float64) pathfinding.WitnessLimits {
+	for _, stage := range c.WitnessStages {
+		if averageDegree <= stage.MaxAverageDegree {
+			return stage.Limits
+		}
+	}
+	return c.WitnessLimits
+}
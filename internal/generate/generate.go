@@ -0,0 +1,151 @@
+// Package generate builds synthetic road networks of any size for tests,
+// fuzzing and scaling studies: grids, random geometric graphs, Delaunay-like
+// triangulations and road-like graphs with a hierarchy of street, arterial
+// and motorway edges.
+//
+// Every generator is deterministic: the same arguments and Options.Seed give
+// the same graph. Vertices are numbered from 0 and carry coordinates near
+// Options.Origin, edges exist in both directions with the same weight and
+// carry the attributes of their road class, so vehicle profiles apply to them
+// as to imported networks.
+package generate
+
+import (
+	"math"
+	"math/rand"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// metersPerDegree is the length of one degree of latitude.
+const metersPerDegree = 6371000.0 * math.Pi / 180
+
+// DefaultOrigin is the south-west corner of generated graphs if Options.Origin
+// is not set.
+var DefaultOrigin = graph.Vertex{Lat: 48.78, Lon: 9.18}
+
+// WeightFunc returns the weight of an edge from its attributes. It is called
+// once per pair of adjacent vertices, in a deterministic order, and its result
+// is clamped to at least 1. rng is separate from the source of the topology,
+// so the weights do not change the vertices and edges of a graph.
+type WeightFunc func(rng *rand.Rand, a graph.Attributes) int
+
+// Options are shared by all generators.
+type Options struct {
+	Seed int64
+	// Weight assigns the edge weights. Nil uses Length.
+	Weight WeightFunc
+	// Origin is the coordinate of the south-west corner. The zero value uses
+	// DefaultOrigin.
+	Origin graph.Vertex
+}
+
+// Length weighs an edge with its length in meters.
+func Length() WeightFunc {
+	return func(_ *rand.Rand, a graph.Attributes) int {
+		return int(math.Round(a.Length))
+	}
+}
+
+// TravelTime weighs an edge with the time in milliseconds to drive it at its
+// speed limit, or at 50 km/h if it is unknown. This makes the road hierarchy
+// of RoadLike visible to shortest paths.
+func TravelTime() WeightFunc {
+	return func(_ *rand.Rand, a graph.Attributes) int {
+		speed := float64(a.MaxSpeed)
+		if speed <= 0 {
+			speed = 50
+		}
+		return int(math.Round(a.Length / (speed / 3.6) * 1000))
+	}
+}
+
+// Uniform draws every weight uniformly from [lo, hi] regardless of the
+// geometry, so shortest paths no longer follow the coordinates.
+func Uniform(lo, hi int) WeightFunc {
+	return func(rng *rand.Rand, _ graph.Attributes) int {
+		return lo + rng.Intn(hi-lo+1)
+	}
+}
+
+// Constant gives every edge the same weight, which makes many shortest paths
+// tie.
+func Constant(weight int) WeightFunc {
+	return func(*rand.Rand, graph.Attributes) int {
+		return weight
+	}
+}
+
+// NoisyLength multiplies the length of every edge with a factor drawn
+// uniformly from [1, 1+spread], e.g. to model congestion.
+func NoisyLength(spread float64) WeightFunc {
+	return func(rng *rand.Rand, a graph.Attributes) int {
+		return int(math.Round(a.Length * (1 + spread*rng.Float64())))
+	}
+}
+
+// builder places vertices in a plane measured in meters and adds undirected
+// edges with attributes and weights.
+type builder struct {
+	g         *graph.Graph
+	rng       *rand.Rand // Coordinates and topology
+	weightRng *rand.Rand
+	weight    WeightFunc
+	origin    graph.Vertex
+}
+
+func newBuilder(opts Options) *builder {
+	b := &builder{
+		g:         graph.NewGraph(),
+		rng:       rand.New(rand.NewSource(opts.Seed)),
+		weightRng: rand.New(rand.NewSource(opts.Seed + 1)),
+		weight:    opts.Weight,
+		origin:    opts.Origin,
+	}
+	if b.weight == nil {
+		b.weight = Length()
+	}
+	if b.origin == (graph.Vertex{}) {
+		b.origin = DefaultOrigin
+	}
+	return b
+}
+
+// addVertex adds the vertex x meters east and y meters north of the origin
+// with the next free id.
+func (b *builder) addVertex(x, y float64) graph.VertexId {
+	id := graph.VertexId(len(b.g.Vertices))
+	b.g.AddVertex(graph.Vertex{
+		Id:  id,
+		Lat: b.origin.Lat + y/metersPerDegree,
+		Lon: b.origin.Lon + x/(metersPerDegree*math.Cos(b.origin.Lat*math.Pi/180)),
+	})
+	return id
+}
+
+// addEdge connects u and v in both directions as a road of the given class
+// and speed limit. It does nothing if the two are already adjacent.
+func (b *builder) addEdge(u, v graph.VertexId, class graph.RoadClass, maxSpeed int) {
+	if u == v {
+		return
+	}
+	if _, exists := b.g.Edges[u][v]; exists {
+		return
+	}
+	attributes := graph.Attributes{
+		RoadClass:  class,
+		MaxSpeed:   maxSpeed,
+		Length:     graph.Distance(b.g.Vertices[u], b.g.Vertices[v]),
+		Restricted: graph.DefaultRestrictions(class),
+	}
+	weight := max(1, b.weight(b.weightRng, attributes))
+	b.g.AddEdge(u, v, weight, false, -1)
+	b.g.AddEdge(v, u, weight, false, -1)
+	b.g.SetAttributes(u, v, attributes)
+	b.g.SetAttributes(v, u, attributes)
+}
+
+// jitter returns a random offset in [-spread, spread].
+func (b *builder) jitter(spread float64) float64 {
+	return (2*b.rng.Float64() - 1) * spread
+}
@@ -0,0 +1,196 @@
+package generate
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// numUndirectedEdges counts every pair of adjacent vertices once and checks
+// that every edge has a reverse edge of the same weight.
+func numUndirectedEdges(t *testing.T, g *graph.Graph) int {
+	t.Helper()
+	n := 0
+	for u, edges := range g.Edges {
+		for v, e := range edges {
+			reverse, ok := g.Edges[v][u]
+			if !ok || reverse.Weight != e.Weight {
+				t.Fatalf("edge %d -> %d has no reverse edge of weight %d", u, v, e.Weight)
+			}
+			if e.Weight < 1 {
+				t.Fatalf("edge %d -> %d has weight %d", u, v, e.Weight)
+			}
+			if u < v {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// connected reports whether every vertex is reachable from vertex 0.
+func connected(g *graph.Graph) bool {
+	return len(pathfinding.UpwardSearch(g, nil, 0, nil)) == len(g.Vertices)
+}
+
+func TestGrid(t *testing.T) {
+	g := Grid(4, 5, 100, Options{})
+	if len(g.Vertices) != 20 {
+		t.Errorf("got %d vertices, want 20", len(g.Vertices))
+	}
+	if n := numUndirectedEdges(t, g); n != 4*4+3*5 {
+		t.Errorf("got %d edges, want %d", n, 4*4+3*5)
+	}
+	if e := g.Edges[0][1]; math.Abs(e.Attributes.Length-100) > 0.5 || e.Weight != 100 {
+		t.Errorf("edge 0 -> 1 has length %v and weight %d, want 100", e.Attributes.Length, e.Weight)
+	}
+	if g.Vertices[0] != (graph.Vertex{Id: 0, Lat: DefaultOrigin.Lat, Lon: DefaultOrigin.Lon}) {
+		t.Errorf("vertex 0 is at %v, want the origin", g.Vertices[0])
+	}
+	if g.Vertices[5].Lat <= g.Vertices[0].Lat || g.Vertices[1].Lon <= g.Vertices[0].Lon {
+		t.Error("rows do not grow north or columns do not grow east")
+	}
+}
+
+func TestDeterminism(t *testing.T) {
+	generators := map[string]func(Options) *graph.Graph{
+		"RandomGeometric": func(o Options) *graph.Graph { return RandomGeometric(200, 1000, 120, o) },
+		"Delaunay":        func(o Options) *graph.Graph { return Delaunay(10, 12, 100, o) },
+		"RoadLike":        func(o Options) *graph.Graph { return RoadLike(40, 40, 100, o) },
+	}
+	for name, generate := range generators {
+		a, b := generate(Options{Seed: 3}), generate(Options{Seed: 3})
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: the same seed gave different graphs", name)
+		}
+		if reflect.DeepEqual(a, generate(Options{Seed: 4})) {
+			t.Errorf("%s: different seeds gave the same graph", name)
+		}
+
+		// The weights must not change the topology.
+		weighted := generate(Options{Seed: 3, Weight: Uniform(1, 10)})
+		if !reflect.DeepEqual(a.Vertices, weighted.Vertices) || numUndirectedEdges(t, a) != numUndirectedEdges(t, weighted) {
+			t.Errorf("%s: the weight function changed the topology", name)
+		}
+	}
+}
+
+func TestRandomGeometric(t *testing.T) {
+	const radius = 150.0
+	g := RandomGeometric(150, 1000, radius, Options{Seed: 1})
+
+	// Compare with all pairs. The lengths are great-circle distances of the
+	// planar coordinates, which differ slightly, so pairs close to the radius
+	// are left out.
+	for u := range g.Vertices {
+		for v := range g.Vertices {
+			if u == v {
+				continue
+			}
+			d := graph.Distance(g.Vertices[u], g.Vertices[v])
+			_, adjacent := g.Edges[u][v]
+			if d < radius*0.99 && !adjacent {
+				t.Errorf("%d and %d are %v m apart but not adjacent", u, v, d)
+			}
+			if d > radius*1.01 && adjacent {
+				t.Errorf("%d and %d are %v m apart but adjacent", u, v, d)
+			}
+		}
+	}
+}
+
+func TestDelaunay(t *testing.T) {
+	const rows, cols = 8, 9
+	g := Delaunay(rows, cols, 100, Options{Seed: 2})
+	want := rows*(cols-1) + cols*(rows-1) + (rows-1)*(cols-1)
+	if n := numUndirectedEdges(t, g); n != want {
+		t.Errorf("got %d edges, want %d", n, want)
+	}
+
+	// No two edges may cross.
+	type segment struct{ a, b graph.Vertex }
+	var segments []segment
+	for u, edges := range g.Edges {
+		for v := range edges {
+			if u < v {
+				segments = append(segments, segment{g.Vertices[u], g.Vertices[v]})
+			}
+		}
+	}
+	orientation := func(p, q, r graph.Vertex) float64 {
+		return (q.Lon-p.Lon)*(r.Lat-p.Lat) - (q.Lat-p.Lat)*(r.Lon-p.Lon)
+	}
+	for i, s := range segments {
+		for _, o := range segments[i+1:] {
+			if s.a == o.a || s.a == o.b || s.b == o.a || s.b == o.b {
+				continue
+			}
+			if orientation(s.a, s.b, o.a)*orientation(s.a, s.b, o.b) < 0 && orientation(o.a, o.b, s.a)*orientation(o.a, o.b, s.b) < 0 {
+				t.Fatalf("edges %d-%d and %d-%d cross", s.a.Id, s.b.Id, o.a.Id, o.b.Id)
+			}
+		}
+	}
+}
+
+func TestRoadLike(t *testing.T) {
+	const rows, cols = 65, 65
+	g := RoadLike(rows, cols, 100, Options{Seed: 1, Weight: TravelTime()})
+	numUndirectedEdges(t, g)
+	if !connected(g) {
+		t.Fatal("the graph is not connected")
+	}
+
+	classes := make(map[graph.RoadClass]int)
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			classes[e.Attributes.RoadClass]++
+		}
+	}
+	for _, class := range []graph.RoadClass{graph.RoadClassResidential, graph.RoadClassPrimary, graph.RoadClassMotorway} {
+		if classes[class] == 0 {
+			t.Errorf("no %s edges", class)
+		}
+	}
+
+	// A path between opposite corners should use the motorways.
+	path, _, _, err := pathfinding.DijkstraShortestPath(g, 0, rows*cols-1, math.Inf(1))
+	if err != nil {
+		t.Fatalf("Dijkstra failed: %v", err)
+	}
+	motorway := false
+	for i := 1; i < len(path); i++ {
+		motorway = motorway || g.Edges[path[i-1]][path[i]].Attributes.RoadClass == graph.RoadClassMotorway
+	}
+	if !motorway {
+		t.Error("the corner to corner path uses no motorway")
+	}
+}
+
+func TestWeightFuncs(t *testing.T) {
+	g := Grid(5, 5, 100, Options{Weight: Uniform(3, 7)})
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Weight < 3 || e.Weight > 7 {
+				t.Errorf("uniform weight %d outside of [3, 7]", e.Weight)
+			}
+		}
+	}
+
+	g = Grid(2, 2, 100, Options{Weight: Constant(0)})
+	if w := g.Edges[0][1].Weight; w != 1 {
+		t.Errorf("got weight %d for Constant(0), want it clamped to 1", w)
+	}
+
+	g = Grid(1, 2, 100, Options{Weight: TravelTime()})
+	if w := g.Edges[0][1].Weight; w != 7200 {
+		t.Errorf("got %d ms for 100 m at 50 km/h, want 7200", w)
+	}
+
+	g = Grid(1, 2, 100, Options{Weight: NoisyLength(0.5)})
+	if w := g.Edges[0][1].Weight; w < 100 || w > 150 {
+		t.Errorf("got noisy length %d, want it in [100, 150]", w)
+	}
+}
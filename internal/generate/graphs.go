@@ -0,0 +1,182 @@
+package generate
+
+import (
+	"slices"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// Grid returns a rows x cols grid whose vertices are spacing meters apart and
+// connected to their horizontal and vertical neighbors. The vertex in row r
+// and column c has the id r*cols+c.
+func Grid(rows, cols int, spacing float64, opts Options) *graph.Graph {
+	b := newBuilder(opts)
+	for r := range rows {
+		for c := range cols {
+			b.addVertex(float64(c)*spacing, float64(r)*spacing)
+		}
+	}
+	for r := range rows {
+		for c := range cols {
+			id := graph.VertexId(r*cols + c)
+			if c+1 < cols {
+				b.addEdge(id, id+1, graph.RoadClassResidential, 50)
+			}
+			if r+1 < rows {
+				b.addEdge(id, id+graph.VertexId(cols), graph.RoadClassResidential, 50)
+			}
+		}
+	}
+	return b.g
+}
+
+// RandomGeometric places n vertices uniformly at random in a square with the
+// given side length in meters and connects every two vertices that are at
+// most radius meters apart. The graph is disconnected if the radius is small
+// compared to side/sqrt(n).
+func RandomGeometric(n int, side, radius float64, opts Options) *graph.Graph {
+	b := newBuilder(opts)
+	xs, ys := make([]float64, n), make([]float64, n)
+	for i := range n {
+		xs[i], ys[i] = b.rng.Float64()*side, b.rng.Float64()*side
+		b.addVertex(xs[i], ys[i])
+	}
+	if radius <= 0 {
+		return b.g
+	}
+
+	// Bin the vertices into cells of the radius, so only the vertices of
+	// neighboring cells need to be compared.
+	type cell struct{ x, y int }
+	cellOf := func(i int) cell { return cell{int(xs[i] / radius), int(ys[i] / radius)} }
+	cells := make(map[cell][]int)
+	for i := range n {
+		cells[cellOf(i)] = append(cells[cellOf(i)], i)
+	}
+
+	for i := range n {
+		home := cellOf(i)
+		var neighbors []int
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, j := range cells[cell{home.x + dx, home.y + dy}] {
+					if j > i && (xs[i]-xs[j])*(xs[i]-xs[j])+(ys[i]-ys[j])*(ys[i]-ys[j]) <= radius*radius {
+						neighbors = append(neighbors, j)
+					}
+				}
+			}
+		}
+		slices.Sort(neighbors)
+		for _, j := range neighbors {
+			b.addEdge(graph.VertexId(i), graph.VertexId(j), graph.RoadClassUnclassified, 50)
+		}
+	}
+	return b.g
+}
+
+// Delaunay returns a planar triangulation of a rows x cols grid whose
+// vertices are moved by up to a quarter of the spacing in either direction.
+// Every grid cell stays convex and is split along its shorter diagonal, as a
+// Delaunay triangulation would, so all inner faces are triangles. The ids are
+// those of Grid.
+func Delaunay(rows, cols int, spacing float64, opts Options) *graph.Graph {
+	b := newBuilder(opts)
+	xs, ys := make([]float64, rows*cols), make([]float64, rows*cols)
+	for r := range rows {
+		for c := range cols {
+			i := r*cols + c
+			xs[i] = float64(c)*spacing + b.jitter(spacing/4)
+			ys[i] = float64(r)*spacing + b.jitter(spacing/4)
+			b.addVertex(xs[i], ys[i])
+		}
+	}
+	squaredDistance := func(i, j int) float64 {
+		return (xs[i]-xs[j])*(xs[i]-xs[j]) + (ys[i]-ys[j])*(ys[i]-ys[j])
+	}
+
+	for r := range rows {
+		for c := range cols {
+			i := r*cols + c
+			if c+1 < cols {
+				b.addEdge(graph.VertexId(i), graph.VertexId(i+1), graph.RoadClassResidential, 50)
+			}
+			if r+1 < rows {
+				b.addEdge(graph.VertexId(i), graph.VertexId(i+cols), graph.RoadClassResidential, 50)
+			}
+			if c+1 < cols && r+1 < rows {
+				if squaredDistance(i, i+cols+1) <= squaredDistance(i+1, i+cols) {
+					b.addEdge(graph.VertexId(i), graph.VertexId(i+cols+1), graph.RoadClassResidential, 50)
+				} else {
+					b.addEdge(graph.VertexId(i+1), graph.VertexId(i+cols), graph.RoadClassResidential, 50)
+				}
+			}
+		}
+	}
+	return b.g
+}
+
+const (
+	// arterialEvery is the distance in grid steps between the arterial roads
+	// of RoadLike, motorwayEvery the distance between motorway junctions.
+	arterialEvery = 8
+	motorwayEvery = 32
+	// dropProbability is the share of residential cross streets RoadLike
+	// leaves out.
+	dropProbability = 0.25
+)
+
+// RoadLike returns a road network with three levels on a rows x cols grid of
+// slightly moved vertices. Residential streets at 30 km/h connect all grid
+// neighbors, except for a quarter of the horizontal ones. Every 8th row and
+// column is a primary road at 60 km/h, and junctions on every 32nd row and
+// column are linked by motorways at 120 km/h that have no exits in between.
+// Vertical streets are never left out, so every vertex reaches the primary
+// road in row 0 and the graph is connected. With TravelTime weights, long
+// shortest paths climb the hierarchy as on real roads. The ids are those of
+// Grid.
+func RoadLike(rows, cols int, spacing float64, opts Options) *graph.Graph {
+	b := newBuilder(opts)
+	for r := range rows {
+		for c := range cols {
+			b.addVertex(float64(c)*spacing+b.jitter(spacing/5), float64(r)*spacing+b.jitter(spacing/5))
+		}
+	}
+
+	street := func(arterial bool) (graph.RoadClass, int) {
+		if arterial {
+			return graph.RoadClassPrimary, 60
+		}
+		return graph.RoadClassResidential, 30
+	}
+	for r := range rows {
+		for c := range cols {
+			id := graph.VertexId(r*cols + c)
+			if c+1 < cols {
+				// Draw for every cross street, so the drops do not depend on the
+				// position of the arterials.
+				dropped := b.rng.Float64() < dropProbability
+				if r%arterialEvery == 0 || !dropped {
+					class, speed := street(r%arterialEvery == 0)
+					b.addEdge(id, id+1, class, speed)
+				}
+			}
+			if r+1 < rows {
+				class, speed := street(c%arterialEvery == 0)
+				b.addEdge(id, id+graph.VertexId(cols), class, speed)
+			}
+		}
+	}
+
+	for r := 0; r < rows; r += motorwayEvery {
+		for c := 0; c < cols; c += motorwayEvery {
+			id := graph.VertexId(r*cols + c)
+			if c+motorwayEvery < cols {
+				b.addEdge(id, id+motorwayEvery, graph.RoadClassMotorway, 120)
+			}
+			if r+motorwayEvery < rows {
+				b.addEdge(id, id+graph.VertexId(motorwayEvery*cols), graph.RoadClassMotorway, 120)
+			}
+		}
+	}
+	return b.g
+}
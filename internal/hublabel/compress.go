@@ -0,0 +1,178 @@
+package hublabel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var ErrCorruptLabel = errors.New("corrupt compressed hub label")
+
+// PackedLabels stores the labels of one direction back to back in a single
+// byte slice. The label of the vertex with rank r is Data[Offsets[r]:Offsets[r+1]].
+// Each entry is encoded as three unsigned varints: the hub rank as delta to
+// the previous hub, starting at r, the distance, and the rank of the parent
+// as delta to r.
+type PackedLabels struct {
+	Data    []byte
+	Offsets []uint32
+}
+
+// CompressedLabels is a lossless, compact encoding of Labels. Distance queries
+// decode the two labels on the fly; paths require decompressing first.
+type CompressedLabels struct {
+	Order    []graph.VertexId
+	Forward  PackedLabels
+	Backward PackedLabels
+	rank     map[graph.VertexId]int32
+}
+
+// NewCompressedLabels assembles compressed labels from their parts, e.g.
+// after reading them from disk.
+func NewCompressedLabels(order []graph.VertexId, forward, backward PackedLabels) (*CompressedLabels, error) {
+	if len(forward.Offsets) != len(order)+1 || len(backward.Offsets) != len(order)+1 {
+		return nil, fmt.Errorf("%w: %d vertices but %d forward and %d backward offsets",
+			ErrCorruptLabel, len(order), len(forward.Offsets), len(backward.Offsets))
+	}
+	c := &CompressedLabels{Order: order, Forward: forward, Backward: backward, rank: make(map[graph.VertexId]int32, len(order))}
+	for r, v := range order {
+		c.rank[v] = int32(r)
+	}
+	return c, nil
+}
+
+// Compress encodes the labels.
+func (l *Labels) Compress() *CompressedLabels {
+	c, _ := NewCompressedLabels(l.Order, l.pack(l.Forward), l.pack(l.Backward))
+	return c
+}
+
+func (l *Labels) pack(labels []Label) PackedLabels {
+	p := PackedLabels{Offsets: make([]uint32, 0, len(labels)+1)}
+	for r, label := range labels {
+		p.Offsets = append(p.Offsets, uint32(len(p.Data)))
+		previous := int32(r)
+		for i, hub := range label.Hubs {
+			p.Data = binary.AppendUvarint(p.Data, uint64(hub-previous))
+			p.Data = binary.AppendUvarint(p.Data, uint64(label.Dists[i]))
+			p.Data = binary.AppendUvarint(p.Data, uint64(l.Rank[label.Parents[i]]-int32(r)))
+			previous = hub
+		}
+	}
+	p.Offsets = append(p.Offsets, uint32(len(p.Data)))
+	return p
+}
+
+// Decompress decodes the labels, including the parents needed for Path.
+func (c *CompressedLabels) Decompress() (*Labels, error) {
+	l := newLabels(c.Order)
+	for r := range c.Order {
+		var err error
+		if l.Forward[r], err = c.unpack(c.Forward, int32(r)); err != nil {
+			return nil, err
+		}
+		if l.Backward[r], err = c.unpack(c.Backward, int32(r)); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (c *CompressedLabels) unpack(p PackedLabels, r int32) (Label, error) {
+	var label Label
+	reader := newEntryReader(p, r)
+	for reader.next() {
+		if int(reader.parent) >= len(c.Order) {
+			return Label{}, fmt.Errorf("%w: parent rank %d out of range", ErrCorruptLabel, reader.parent)
+		}
+		label.Hubs = append(label.Hubs, reader.hub)
+		label.Dists = append(label.Dists, reader.dist)
+		label.Parents = append(label.Parents, c.Order[reader.parent])
+	}
+	if reader.err != nil {
+		return Label{}, fmt.Errorf("label of vertex %d: %w", c.Order[r], reader.err)
+	}
+	return label, nil
+}
+
+// Distance returns the shortest path distance from source to target.
+func (c *CompressedLabels) Distance(source, target graph.VertexId) (float64, error) {
+	s, ok := c.rank[source]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVertex, source)
+	}
+	t, ok := c.rank[target]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVertex, target)
+	}
+
+	forward, backward := newEntryReader(c.Forward, s), newEntryReader(c.Backward, t)
+	best, found := 0, false
+	hasForward, hasBackward := forward.next(), backward.next()
+	for hasForward && hasBackward {
+		switch {
+		case forward.hub < backward.hub:
+			hasForward = forward.next()
+		case forward.hub > backward.hub:
+			hasBackward = backward.next()
+		default:
+			if d := forward.dist + backward.dist; !found || d < best {
+				best, found = d, true
+			}
+			hasForward, hasBackward = forward.next(), backward.next()
+		}
+	}
+	if forward.err != nil {
+		return 0, forward.err
+	}
+	if backward.err != nil {
+		return 0, backward.err
+	}
+	if !found {
+		return 0, pathfinding.ErrTargetNotReachable
+	}
+	return float64(best), nil
+}
+
+// Size returns the number of bytes used by the encoded labels.
+func (c *CompressedLabels) Size() int {
+	return len(c.Forward.Data) + len(c.Backward.Data) + 4*(len(c.Forward.Offsets)+len(c.Backward.Offsets))
+}
+
+// entryReader decodes the entries of a single packed label.
+type entryReader struct {
+	data   []byte
+	rank   int32
+	hub    int32
+	dist   int
+	parent int32
+	err    error
+}
+
+func newEntryReader(p PackedLabels, r int32) *entryReader {
+	return &entryReader{data: p.Data[p.Offsets[r]:p.Offsets[r+1]], rank: r, hub: r}
+}
+
+// next decodes the next entry and reports whether there was one.
+func (e *entryReader) next() bool {
+	if len(e.data) == 0 || e.err != nil {
+		return false
+	}
+	var values [3]uint64
+	for i := range values {
+		value, n := binary.Uvarint(e.data)
+		if n <= 0 {
+			e.err = ErrCorruptLabel
+			return false
+		}
+		values[i] = value
+		e.data = e.data[n:]
+	}
+	e.hub += int32(values[0])
+	e.dist = int(values[1])
+	e.parent = e.rank + int32(values[2])
+	return true
+}
@@ -0,0 +1,290 @@
+// Package hublabel implements hub labeling on top of a contraction hierarchy.
+// Every vertex gets a forward label with the distances to a set of hubs and a
+// backward label with the distances from a set of hubs, such that every
+// shortest path passes a hub of both labels. A query only intersects two
+// sorted labels and does not search the graph at all.
+package hublabel
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var ErrUnknownVertex = errors.New("vertex has no hub label")
+
+// Label is the forward or backward label of a single vertex. The hubs are
+// given by their rank in the contraction order and sorted in ascending order,
+// so the first entry is the vertex itself with distance zero.
+type Label struct {
+	Hubs  []int32
+	Dists []int
+	// Parents holds the next vertex on the upward path from the vertex towards
+	// each hub, the vertex itself for its own entry.
+	Parents []graph.VertexId
+}
+
+// Len returns the number of hubs in the label.
+func (l Label) Len() int {
+	return len(l.Hubs)
+}
+
+// Labels holds the forward and backward labels of all vertices, indexed by
+// their rank in the contraction order.
+type Labels struct {
+	Order    []graph.VertexId
+	Rank     map[graph.VertexId]int32
+	Forward  []Label
+	Backward []Label
+}
+
+// Build computes hub labels from a preprocessed contraction hierarchy. The
+// vertices are processed from the highest rank down: the label of a vertex is
+// obtained by extending the labels of its upward neighbors by one edge, which
+// is equivalent to an upward search. Entries whose distance is not the
+// shortest distance to the hub, as witnessed by the labels computed so far,
+// are pruned. A core CH is rejected with ch.ErrHasCore, since its core
+// vertices have no rank.
+func Build(c *ch.ContractionHierarchies) (*Labels, error) {
+	if err := c.RequireNoCore(); err != nil {
+		return nil, err
+	}
+	n := len(c.ContractionOrder)
+	l := newLabels(c.ContractionOrder)
+	b := newBuilder(n)
+
+	for r := n - 1; r >= 0; r-- {
+		v := c.ContractionOrder[r]
+		for w, edge := range c.UpwardsGraph.Edges[v] {
+			if edge.Weight == graph.InfWeight {
+				continue
+			}
+			b.relax(l.Forward[l.Rank[w]], edge.Weight, w)
+		}
+		forward := b.label(int32(r), v)
+		l.Forward[r] = l.prune(forward, true)
+
+		for w := range c.UpwardsGraph.Edges[v] {
+			edge, ok := c.DownwardsGraph.Edges[w][v]
+			if !ok || edge.Weight == graph.InfWeight {
+				continue
+			}
+			b.relax(l.Backward[l.Rank[w]], edge.Weight, w)
+		}
+		backward := b.label(int32(r), v)
+		l.Backward[r] = l.prune(backward, false)
+	}
+
+	return l, nil
+}
+
+func newLabels(order []graph.VertexId) *Labels {
+	l := &Labels{
+		Order:    slices.Clone(order),
+		Rank:     make(map[graph.VertexId]int32, len(order)),
+		Forward:  make([]Label, len(order)),
+		Backward: make([]Label, len(order)),
+	}
+	for r, v := range order {
+		l.Rank[v] = int32(r)
+	}
+	return l
+}
+
+// prune drops every entry of candidate whose distance can be undercut through
+// another hub. Forward entries are checked against the backward label of the
+// hub and vice versa; the hub's labels are complete since it has a higher rank.
+func (l *Labels) prune(candidate Label, forward bool) Label {
+	var pruned Label
+	for i, hub := range candidate.Hubs {
+		dist := candidate.Dists[i]
+		if i > 0 {
+			var d int
+			var ok bool
+			if forward {
+				d, _, ok = intersect(candidate, l.Backward[hub])
+			} else {
+				d, _, ok = intersect(l.Forward[hub], candidate)
+			}
+			if ok && d < dist {
+				continue
+			}
+		}
+		pruned.Hubs = append(pruned.Hubs, hub)
+		pruned.Dists = append(pruned.Dists, dist)
+		pruned.Parents = append(pruned.Parents, candidate.Parents[i])
+	}
+	return pruned
+}
+
+// intersect returns the shortest distance over the common hubs of a forward
+// and a backward label and the rank of the hub attaining it.
+func intersect(forward, backward Label) (int, int32, bool) {
+	best, bestHub, found := 0, int32(-1), false
+	i, j := 0, 0
+	for i < len(forward.Hubs) && j < len(backward.Hubs) {
+		switch {
+		case forward.Hubs[i] < backward.Hubs[j]:
+			i++
+		case forward.Hubs[i] > backward.Hubs[j]:
+			j++
+		default:
+			if d := forward.Dists[i] + backward.Dists[j]; !found || d < best {
+				best, bestHub, found = d, forward.Hubs[i], true
+			}
+			i++
+			j++
+		}
+	}
+	return best, bestHub, found
+}
+
+// Distance returns the shortest path distance from source to target.
+func (l *Labels) Distance(source, target graph.VertexId) (float64, error) {
+	forward, backward, err := l.labels(source, target)
+	if err != nil {
+		return 0, err
+	}
+	d, _, ok := intersect(forward, backward)
+	if !ok {
+		return 0, pathfinding.ErrTargetNotReachable
+	}
+	return float64(d), nil
+}
+
+// Path returns a shortest path from source to target on the upward and
+// downward graphs of the contraction hierarchy the labels were built from,
+// i.e. the path may contain shortcuts, together with its distance.
+func (l *Labels) Path(source, target graph.VertexId) ([]graph.VertexId, float64, error) {
+	forward, backward, err := l.labels(source, target)
+	if err != nil {
+		return nil, 0, err
+	}
+	d, hub, ok := intersect(forward, backward)
+	if !ok {
+		return nil, 0, pathfinding.ErrTargetNotReachable
+	}
+
+	path, err := l.walk(l.Forward, source, hub)
+	if err != nil {
+		return nil, 0, err
+	}
+	down, err := l.walk(l.Backward, target, hub)
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.Reverse(down)
+	path = append(path, down[1:]...)
+
+	return path, float64(d), nil
+}
+
+// walk follows the parents stored in labels from v up to the hub.
+func (l *Labels) walk(labels []Label, v graph.VertexId, hub int32) ([]graph.VertexId, error) {
+	path := []graph.VertexId{v}
+	for l.Rank[v] != hub {
+		label := labels[l.Rank[v]]
+		i, ok := slices.BinarySearch(label.Hubs, hub)
+		if !ok {
+			return nil, fmt.Errorf("hub %d missing in label of vertex %d", l.Order[hub], v)
+		}
+		v = label.Parents[i]
+		path = append(path, v)
+	}
+	return path, nil
+}
+
+func (l *Labels) labels(source, target graph.VertexId) (Label, Label, error) {
+	s, ok := l.Rank[source]
+	if !ok {
+		return Label{}, Label{}, fmt.Errorf("%w: %d", ErrUnknownVertex, source)
+	}
+	t, ok := l.Rank[target]
+	if !ok {
+		return Label{}, Label{}, fmt.Errorf("%w: %d", ErrUnknownVertex, target)
+	}
+	return l.Forward[s], l.Backward[t], nil
+}
+
+// SearchSpace returns the number of label entries scanned by a query from
+// source to target, the counterpart of the nodes popped by a search.
+func (l *Labels) SearchSpace(source, target graph.VertexId) int {
+	forward, backward, err := l.labels(source, target)
+	if err != nil {
+		return 0
+	}
+	return forward.Len() + backward.Len()
+}
+
+// AverageLabelSize returns the average number of hubs per forward and
+// backward label.
+func (l *Labels) AverageLabelSize() float64 {
+	if len(l.Order) == 0 {
+		return 0
+	}
+	return float64(l.Entries()) / float64(2*len(l.Order))
+}
+
+// Entries returns the total number of hubs over all labels.
+func (l *Labels) Entries() int {
+	entries := 0
+	for r := range l.Forward {
+		entries += l.Forward[r].Len() + l.Backward[r].Len()
+	}
+	return entries
+}
+
+// builder merges the labels of the upward neighbors of a vertex using dense
+// scratch arrays indexed by hub rank.
+type builder struct {
+	dist    []int
+	parent  []graph.VertexId
+	touched []int32
+}
+
+func newBuilder(n int) *builder {
+	b := &builder{dist: make([]int, n), parent: make([]graph.VertexId, n)}
+	for i := range b.dist {
+		b.dist[i] = -1
+	}
+	return b
+}
+
+// relax offers every entry of a neighbor's label, extended by the edge weight.
+func (b *builder) relax(label Label, weight int, via graph.VertexId) {
+	for i, hub := range label.Hubs {
+		d := graph.AddWeights(label.Dists[i], weight)
+		if b.dist[hub] == -1 {
+			b.touched = append(b.touched, hub)
+		} else if d >= b.dist[hub] {
+			continue
+		}
+		b.dist[hub] = d
+		b.parent[hub] = via
+	}
+}
+
+// label returns the merged label of vertex v with rank r and resets the builder.
+func (b *builder) label(r int32, v graph.VertexId) Label {
+	slices.Sort(b.touched)
+	label := Label{
+		Hubs:    make([]int32, 0, len(b.touched)+1),
+		Dists:   make([]int, 0, len(b.touched)+1),
+		Parents: make([]graph.VertexId, 0, len(b.touched)+1),
+	}
+	label.Hubs = append(label.Hubs, r)
+	label.Dists = append(label.Dists, 0)
+	label.Parents = append(label.Parents, v)
+	for _, hub := range b.touched {
+		label.Hubs = append(label.Hubs, hub)
+		label.Dists = append(label.Dists, b.dist[hub])
+		label.Parents = append(label.Parents, b.parent[hub])
+		b.dist[hub] = -1
+	}
+	b.touched = b.touched[:0]
+	return label
+}
@@ -0,0 +1,193 @@
+package hublabel
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func createTestGraph() *graph.Graph {
+	g := graph.NewGraph()
+	for i := 0; i <= 8; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	edges := []struct {
+		from, to graph.VertexId
+		weight   int
+	}{
+		{0, 1, 2}, {0, 2, 1}, {1, 2, 4}, {1, 3, 10}, {1, 4, 3},
+		{1, 5, 5}, {4, 6, 6}, {4, 7, 9}, {5, 6, 2},
+	}
+	for _, e := range edges {
+		g.AddEdge(e.from, e.to, e.weight, false, -1)
+		g.AddEdge(e.to, e.from, e.weight, false, -1)
+	}
+	// Vertex 8 is isolated.
+	return g
+}
+
+func buildLabels(t *testing.T, g *graph.Graph) (*ch.ContractionHierarchies, *Labels) {
+	t.Helper()
+	c := ch.NewContractionHierarchies()
+	c.Preprocess(g.Clone())
+	l, err := Build(c)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return c, l
+}
+
+// checkQuery compares the labels against Dijkstra and checks that the path
+// unpacks to a path of the returned cost.
+func checkQuery(t *testing.T, g *graph.Graph, c *ch.ContractionHierarchies, l *Labels, compressed *CompressedLabels, s, d graph.VertexId) {
+	t.Helper()
+	_, want, _, err := pathfinding.DijkstraShortestPath(g, s, d, math.Inf(1))
+	if errors.Is(err, pathfinding.ErrTargetNotReachable) {
+		if _, err := l.Distance(s, d); !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+			t.Errorf("Distance(%d, %d) error = %v, want ErrTargetNotReachable", s, d, err)
+		}
+		if _, err := compressed.Distance(s, d); !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+			t.Errorf("compressed Distance(%d, %d) error = %v, want ErrTargetNotReachable", s, d, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Dijkstra failed: %v", err)
+	}
+
+	got, err := l.Distance(s, d)
+	if err != nil || got != want {
+		t.Errorf("Distance(%d, %d) = %v, %v, want %v", s, d, got, err, want)
+	}
+	got, err = compressed.Distance(s, d)
+	if err != nil || got != want {
+		t.Errorf("compressed Distance(%d, %d) = %v, %v, want %v", s, d, got, err, want)
+	}
+
+	packed, cost, err := l.Path(s, d)
+	if err != nil || cost != want {
+		t.Fatalf("Path(%d, %d) cost = %v, %v, want %v", s, d, cost, err, want)
+	}
+	path, err := c.UnpackPath(packed)
+	if err != nil {
+		t.Fatalf("UnpackPath(%v) failed: %v", packed, err)
+	}
+	if path[0] != s || path[len(path)-1] != d {
+		t.Fatalf("Path(%d, %d) = %v has wrong end points", s, d, path)
+	}
+	sum := 0
+	for i := 0; i+1 < len(path); i++ {
+		edge, ok := g.Edges[path[i]][path[i+1]]
+		if !ok {
+			t.Fatalf("Path(%d, %d) = %v uses missing edge %d -> %d", s, d, path, path[i], path[i+1])
+		}
+		sum += edge.Weight
+	}
+	if float64(sum) != want {
+		t.Errorf("Path(%d, %d) = %v has weight %d, want %v", s, d, path, sum, want)
+	}
+}
+
+func TestLabelsAllPairs(t *testing.T) {
+	g := createTestGraph()
+	c, l := buildLabels(t, g)
+	compressed := l.Compress()
+
+	for s := range g.Vertices {
+		for d := range g.Vertices {
+			checkQuery(t, g, c, l, compressed, s, d)
+		}
+	}
+}
+
+func TestLabelsOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+	c, l := buildLabels(t, g)
+	compressed := l.Compress()
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		checkQuery(t, g, c, l, compressed, vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))])
+	}
+
+	if l.AverageLabelSize() >= float64(len(vertices)) {
+		t.Errorf("AverageLabelSize() = %v, labels were not pruned", l.AverageLabelSize())
+	}
+}
+
+func TestLabelsSorted(t *testing.T) {
+	_, l := buildLabels(t, createTestGraph())
+	for r := range l.Order {
+		for _, label := range []Label{l.Forward[r], l.Backward[r]} {
+			if label.Len() == 0 || label.Hubs[0] != int32(r) || label.Dists[0] != 0 {
+				t.Fatalf("label of rank %d does not start with the vertex itself: %+v", r, label)
+			}
+			for i := 1; i < label.Len(); i++ {
+				if label.Hubs[i] <= label.Hubs[i-1] {
+					t.Fatalf("label of rank %d is not sorted: %v", r, label.Hubs)
+				}
+			}
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	_, l := buildLabels(t, createTestGraph())
+	decompressed, err := l.Compress().Decompress()
+	if err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+	for r := range l.Order {
+		if !labelsEqual(l.Forward[r], decompressed.Forward[r]) || !labelsEqual(l.Backward[r], decompressed.Backward[r]) {
+			t.Errorf("labels of rank %d differ after decompression", r)
+		}
+	}
+}
+
+func TestUnknownVertex(t *testing.T) {
+	_, l := buildLabels(t, createTestGraph())
+	if _, err := l.Distance(0, 42); !errors.Is(err, ErrUnknownVertex) {
+		t.Errorf("Distance error = %v, want ErrUnknownVertex", err)
+	}
+	if _, err := l.Compress().Distance(42, 0); !errors.Is(err, ErrUnknownVertex) {
+		t.Errorf("compressed Distance error = %v, want ErrUnknownVertex", err)
+	}
+}
+
+func TestBuildCoreCH(t *testing.T) {
+	c := ch.NewContractionHierarchies(ch.WithCore(4, 0))
+	c.Preprocess(createTestGraph())
+	if !c.HasCore() {
+		t.Fatal("preprocessing left no core")
+	}
+	if _, err := Build(c); !errors.Is(err, ch.ErrHasCore) {
+		t.Errorf("Build error = %v, want ch.ErrHasCore", err)
+	}
+}
+
+func labelsEqual(a, b Label) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := range a.Hubs {
+		if a.Hubs[i] != b.Hubs[i] || a.Dists[i] != b.Dists[i] || a.Parents[i] != b.Parents[i] {
+			return false
+		}
+	}
+	return true
+}
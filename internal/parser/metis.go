@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
 
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
@@ -102,3 +104,42 @@ func ToMetis(g *graph.Graph, w io.Writer) error {
 
 	return nil
 }
+
+// ReadMetisPartition reads a partition of g computed by METIS or KaHIP from a
+// file written by ToMetis, e.g. with kaffpa --k=16 --output_filename. Line i
+// holds the block of the i-th vertex in the order used by ToMetis, i.e. by
+// ascending vertex id. The result maps every vertex to its block.
+func ReadMetisPartition(g *graph.Graph, r io.Reader) (map[graph.VertexId]int, error) {
+	nodeIDs := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		return nodeIDs[i] < nodeIDs[j]
+	})
+
+	regions := make(map[graph.VertexId]int, len(nodeIDs))
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(regions) == len(nodeIDs) {
+			return nil, fmt.Errorf("line %d: more blocks than the %d vertices of the graph", lineNumber, len(nodeIDs))
+		}
+		block, err := strconv.Atoi(line)
+		if err != nil || block < 0 {
+			return nil, fmt.Errorf("line %d: invalid block %q", lineNumber, line)
+		}
+		regions[nodeIDs[len(regions)]] = block
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read partition: %w", err)
+	}
+	if len(regions) != len(nodeIDs) {
+		return nil, fmt.Errorf("partition has %d blocks, graph has %d vertices", len(regions), len(nodeIDs))
+	}
+
+	return regions, nil
+}
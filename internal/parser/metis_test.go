@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
@@ -109,3 +111,32 @@ func TestToMetisFromFile(t *testing.T) {
 		t.Errorf("ToMetis output mismatch.\nExpected:\n%q\nActual:\n%q", expectedMetisOutput, actualMetisOutput)
 	}
 }
+
+func TestReadMetisPartition(t *testing.T) {
+	g := graph.NewGraph()
+	for _, id := range []graph.VertexId{7, 2, 5} {
+		g.AddVertex(graph.Vertex{Id: id})
+	}
+
+	regions, err := ReadMetisPartition(g, strings.NewReader("1\n0\n1\n"))
+	if err != nil {
+		t.Fatalf("ReadMetisPartition failed: %v", err)
+	}
+	want := map[graph.VertexId]int{2: 1, 5: 0, 7: 1}
+	if !reflect.DeepEqual(regions, want) {
+		t.Errorf("got %v, want %v", regions, want)
+	}
+
+	for name, input := range map[string]string{
+		"too few blocks":  "0\n1\n",
+		"too many blocks": "0\n1\n0\n1\n",
+		"invalid block":   "0\nx\n1\n",
+		"negative block":  "0\n-1\n1\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadMetisPartition(g, strings.NewReader(input)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ReadOrdering reads a node ordering of g, e.g. a nested dissection order
+// computed by KaHIP for a file written by ToMetis, or one written by
+// WriteOrdering. Every line holds a rank and a 1-based METIS id, i.e. the
+// position of a vertex among all vertices by ascending id; other lines are
+// ignored. The result lists the vertices by ascending rank, which is the
+// order in which they are contracted.
+func ReadOrdering(g *graph.Graph, r io.Reader) ([]graph.VertexId, error) {
+	nodeIDs := sortedVertexIds(g)
+
+	metisIdToGraphId := make(map[int]graph.VertexId, len(nodeIDs))
+	for i, id := range nodeIDs {
+		metisIdToGraphId[i+1] = id
+	}
+
+	type metisPair struct {
+		id   int
+		rank int
+	}
+	pairs := []metisPair{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 2 {
+			rank, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid rank in ordering file: %w", err)
+			}
+			id, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid METIS ID in ordering file: %w", err)
+			}
+			pairs = append(pairs, metisPair{id, rank})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ordering file: %w", err)
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].rank < pairs[j].rank
+	})
+
+	if len(pairs) != len(g.Vertices) {
+		return nil, fmt.Errorf("mismatch in node count: graph has %d nodes, but ordering file has %d entries",
+			len(g.Vertices), len(pairs))
+	}
+
+	order := make([]graph.VertexId, len(pairs))
+	for i, p := range pairs {
+		originalID, ok := metisIdToGraphId[p.id]
+		if !ok {
+			return nil, fmt.Errorf("METIS ID %d from file not found in graph mapping", p.id)
+		}
+		order[i] = originalID
+	}
+
+	return order, nil
+}
+
+// WriteOrdering writes order, e.g. the contraction order of a contraction
+// hierarchy, in the format read by ReadOrdering: the number of vertices,
+// then one line per vertex with its 1-based rank and METIS id.
+func WriteOrdering(w io.Writer, g *graph.Graph, order []graph.VertexId) error {
+	nodeIDs := sortedVertexIds(g)
+
+	graphIdToMetisId := make(map[graph.VertexId]int, len(nodeIDs))
+	for i, id := range nodeIDs {
+		graphIdToMetisId[id] = i + 1
+	}
+
+	bufferedWriter := bufio.NewWriter(w)
+	if _, err := fmt.Fprintln(bufferedWriter, len(order)); err != nil {
+		return err
+	}
+	for rank, id := range order {
+		metisID, ok := graphIdToMetisId[id]
+		if !ok {
+			return fmt.Errorf("vertex %d of the order is not part of the graph", id)
+		}
+		if _, err := fmt.Fprintf(bufferedWriter, "%d %d\n", rank+1, metisID); err != nil {
+			return err
+		}
+	}
+
+	return bufferedWriter.Flush()
+}
+
+// sortedVertexIds returns the ids of the vertices of g in ascending order,
+// the order of the METIS ids.
+func sortedVertexIds(g *graph.Graph) []graph.VertexId {
+	nodeIDs := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		return nodeIDs[i] < nodeIDs[j]
+	})
+	return nodeIDs
+}
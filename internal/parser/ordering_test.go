@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestOrderingRoundTrip(t *testing.T) {
+	g := graph.NewGraph()
+	for _, id := range []graph.VertexId{3, 10, 42} {
+		g.AddVertex(graph.Vertex{Id: id})
+	}
+	order := []graph.VertexId{42, 3, 10}
+
+	var buf bytes.Buffer
+	if err := WriteOrdering(&buf, g, order); err != nil {
+		t.Fatalf("WriteOrdering failed: %v", err)
+	}
+	if want := "3\n1 3\n2 1\n3 2\n"; buf.String() != want {
+		t.Errorf("WriteOrdering wrote %q, want %q", buf.String(), want)
+	}
+
+	got, err := ReadOrdering(g, &buf)
+	if err != nil {
+		t.Fatalf("ReadOrdering failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Errorf("ReadOrdering = %v, want %v", got, order)
+	}
+
+	if err := WriteOrdering(&buf, g, []graph.VertexId{7}); err == nil {
+		t.Error("WriteOrdering accepted a vertex that is not part of the graph")
+	}
+}
+
+func TestReadOrderingErrors(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0})
+	g.AddVertex(graph.Vertex{Id: 1})
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"too few entries", "1 1\n", "mismatch in node count"},
+		{"invalid rank", "x 1\n2 2\n", "invalid rank"},
+		{"invalid id", "1 x\n2 2\n", "invalid METIS ID"},
+		{"unknown id", "1 1\n2 3\n", "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadOrdering(g, strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadOrdering error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+type osmFile struct {
+	Nodes     []osmNode     `xml:"node"`
+	Ways      []osmWay      `xml:"way"`
+	Relations []osmRelation `xml:"relation"`
+}
+
+type osmNode struct {
+	Id  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type osmWay struct {
+	Id    int64    `xml:"id,attr"`
+	Nodes []osmRef `xml:"nd"`
+	Tags  []osmTag `xml:"tag"`
+}
+
+type osmRef struct {
+	Ref int64 `xml:"ref,attr"`
+}
+
+type osmRelation struct {
+	Id      int64       `xml:"id,attr"`
+	Members []osmMember `xml:"member"`
+	Tags    []osmTag    `xml:"tag"`
+}
+
+type osmMember struct {
+	Type string `xml:"type,attr"`
+	Ref  int64  `xml:"ref,attr"`
+	Role string `xml:"role,attr"`
+}
+
+type osmTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+// accessTags lists the OSM access keys from the most general to the most
+// specific one together with the vehicle types they apply to.
+var accessTags = []struct {
+	key      string
+	vehicles graph.Access
+}{
+	{"access", graph.AccessAll},
+	{"vehicle", graph.AccessCar | graph.AccessTruck | graph.AccessBicycle},
+	{"motor_vehicle", graph.AccessCar | graph.AccessTruck},
+	{"motorcar", graph.AccessCar},
+	{"hgv", graph.AccessTruck},
+	{"bicycle", graph.AccessBicycle},
+	{"foot", graph.AccessPedestrian},
+}
+
+// ImportOSM builds a road network from the highways of an OSM XML file. The
+// vertices are the nodes used by highways, numbered from 0 in the order of
+// their OSM ids; the returned map translates OSM node ids into vertex ids.
+// Every pair of consecutive nodes of a way becomes an edge in both directions
+// whose weight is its length in meters, at least 1. The attributes of the
+// edges are taken from the highway, maxspeed, access and oneway tags. The
+// opposite direction of a one-way road stays in the graph but is restricted
+// to pedestrians, so the topology is symmetric as required by CCH.
+func ImportOSM(r io.Reader) (graph.RoadNetwork, map[int64]graph.VertexId, error) {
+	var file osmFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return graph.RoadNetwork{}, nil, fmt.Errorf("failed to decode OSM XML: %w", err)
+	}
+	network, idMap := importOSM(file)
+	return network, idMap, nil
+}
+
+// ImportOSMWithRestrictions imports a road network like ImportOSM and also
+// returns the turn restrictions of the restriction relations in the same
+// file, as read by ParseOSMRestrictions. Restrictions whose turns do not
+// follow edges of the network, e.g. because a from-way is not a highway, are
+// left out.
+func ImportOSMWithRestrictions(r io.Reader) (graph.RoadNetwork, *turns.Table, map[int64]graph.VertexId, error) {
+	var file osmFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return graph.RoadNetwork{}, nil, nil, fmt.Errorf("failed to decode OSM XML: %w", err)
+	}
+	network, idMap := importOSM(file)
+
+	table := turns.NewTable()
+	for _, restriction := range osmRestrictions(file, idMap) {
+		if err := table.AddRestriction(network.Network, restriction); err != nil && !errors.Is(err, turns.ErrInvalidTurn) {
+			return graph.RoadNetwork{}, nil, nil, err
+		}
+	}
+	return network, table, idMap, nil
+}
+
+func importOSM(file osmFile) (graph.RoadNetwork, map[int64]graph.VertexId) {
+	nodes := make(map[int64]osmNode, len(file.Nodes))
+	for _, node := range file.Nodes {
+		nodes[node.Id] = node
+	}
+
+	type roadWay struct {
+		nodes      []int64
+		attributes graph.Attributes
+		oneway     int
+	}
+	var ways []roadWay
+	used := make(map[int64]bool)
+	for _, way := range file.Ways {
+		tags := make(map[string]string, len(way.Tags))
+		for _, tag := range way.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		roadClass, ok := graph.ParseRoadClass(tags["highway"])
+		if !ok || tags["highway"] == "" {
+			continue
+		}
+
+		var wayNodes []int64
+		for _, nd := range way.Nodes {
+			if _, ok := nodes[nd.Ref]; ok {
+				wayNodes = append(wayNodes, nd.Ref)
+				used[nd.Ref] = true
+			}
+		}
+		ways = append(ways, roadWay{
+			nodes: wayNodes,
+			attributes: graph.Attributes{
+				RoadClass:  roadClass,
+				MaxSpeed:   parseMaxSpeed(tags["maxspeed"]),
+				Restricted: restrictions(roadClass, tags),
+			},
+			oneway: onewayDirection(roadClass, tags),
+		})
+	}
+
+	osmIds := make([]int64, 0, len(used))
+	for id := range used {
+		osmIds = append(osmIds, id)
+	}
+	sort.Slice(osmIds, func(i, j int) bool { return osmIds[i] < osmIds[j] })
+
+	g := graph.NewGraph()
+	idMap := make(map[int64]graph.VertexId, len(osmIds))
+	for i, osmId := range osmIds {
+		node := nodes[osmId]
+		id := graph.VertexId(i)
+		g.AddVertex(graph.Vertex{Id: id, Lat: node.Lat, Lon: node.Lon})
+		idMap[osmId] = id
+	}
+
+	numEdges := 0
+	for _, way := range ways {
+		for i := 0; i+1 < len(way.nodes); i++ {
+			from, to := idMap[way.nodes[i]], idMap[way.nodes[i+1]]
+			if from == to {
+				continue
+			}
+			if _, exists := g.Edges[from][to]; exists {
+				continue
+			}
+
+			forward := way.attributes
+			forward.Length = graph.Distance(g.Vertices[from], g.Vertices[to])
+			backward := forward
+			// Vehicles may only drive in the direction of a one-way road.
+			oneway := graph.AccessCar | graph.AccessTruck | graph.AccessBicycle
+			switch way.oneway {
+			case 1:
+				backward.Restricted |= oneway
+			case -1:
+				forward.Restricted |= oneway
+			}
+
+			weight := max(int(math.Round(forward.Length)), 1)
+			g.AddEdge(from, to, weight, false, -1)
+			g.AddEdge(to, from, weight, false, -1)
+			g.SetAttributes(from, to, forward)
+			g.SetAttributes(to, from, backward)
+			numEdges++
+		}
+	}
+
+	return graph.RoadNetwork{NumNodes: len(g.Vertices), NumEdges: numEdges, Network: g}, idMap
+}
+
+// restrictions returns the vehicle types that may not use a way with the
+// given road class and tags.
+func restrictions(roadClass graph.RoadClass, tags map[string]string) graph.Access {
+	restricted := graph.DefaultRestrictions(roadClass)
+	for _, tag := range accessTags {
+		switch tags[tag.key] {
+		case "no", "private":
+			restricted |= tag.vehicles
+		case "yes", "designated", "permissive", "destination":
+			restricted &^= tag.vehicles
+		}
+	}
+	return restricted
+}
+
+// onewayDirection returns 1 for ways that may only be used in the direction
+// of their nodes, -1 for ways that may only be used against it and 0 for ways
+// open in both directions.
+func onewayDirection(roadClass graph.RoadClass, tags map[string]string) int {
+	switch tags["oneway"] {
+	case "yes", "true", "1":
+		return 1
+	case "-1", "reverse":
+		return -1
+	case "no", "false", "0":
+		return 0
+	}
+	if roadClass == graph.RoadClassMotorway || tags["junction"] == "roundabout" {
+		return 1
+	}
+	return 0
+}
+
+// parseMaxSpeed parses an OSM maxspeed tag into km/h. Unknown values and
+// "none" yield 0.
+func parseMaxSpeed(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	speed, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || speed <= 0 {
+		return 0
+	}
+	if len(fields) > 1 && fields[1] == "mph" {
+		speed *= 1.609344
+	}
+	return int(math.Round(speed))
+}
+
+// WriteNetwork writes g in the text format described in
+// data/RoadNetworks/README.md. Every pair of adjacent vertices is written as
+// one edge; edges with a known road class include their class and speed
+// limit. Weights, lengths and one-way restrictions are not part of the format.
+func WriteNetwork(w io.Writer, g *graph.Graph) error {
+	ids := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var lines []string
+	for _, from := range ids {
+		targets := make([]graph.VertexId, 0, len(g.Edges[from]))
+		for to := range g.Edges[from] {
+			if _, reverse := g.Edges[to][from]; to > from || !reverse {
+				targets = append(targets, to)
+			}
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+
+		for _, to := range targets {
+			attributes := g.Edges[from][to].Attributes
+			if attributes.RoadClass == graph.RoadClassUnknown && attributes.MaxSpeed == 0 {
+				lines = append(lines, fmt.Sprintf("%d %d", from, to))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%d %d %s %d", from, to, attributes.RoadClass, attributes.MaxSpeed))
+		}
+	}
+
+	bufferedWriter := bufio.NewWriter(w)
+	fmt.Fprintf(bufferedWriter, "%d\n%d\n", len(ids), len(lines))
+	for _, id := range ids {
+		v := g.Vertices[id]
+		fmt.Fprintf(bufferedWriter, "%d %s %s\n", id,
+			strconv.FormatFloat(v.Lat, 'f', -1, 64), strconv.FormatFloat(v.Lon, 'f', -1, 64))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(bufferedWriter, line)
+	}
+	return bufferedWriter.Flush()
+}
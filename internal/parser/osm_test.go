@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+const exampleHighways = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="30" lat="48.0000" lon="9.0000"/>
+  <node id="10" lat="48.0010" lon="9.0000"/>
+  <node id="20" lat="48.0010" lon="9.0010"/>
+  <node id="40" lat="48.0020" lon="9.0010"/>
+  <node id="50" lat="48.0030" lon="9.0010"/>
+  <way id="1">
+    <nd ref="30"/>
+    <nd ref="10"/>
+    <tag k="highway" v="primary"/>
+    <tag k="maxspeed" v="30 mph"/>
+  </way>
+  <way id="2">
+    <nd ref="10"/>
+    <nd ref="20"/>
+    <tag k="highway" v="residential"/>
+    <tag k="oneway" v="yes"/>
+  </way>
+  <way id="3">
+    <nd ref="20"/>
+    <nd ref="40"/>
+    <tag k="highway" v="footway"/>
+    <tag k="bicycle" v="designated"/>
+  </way>
+  <way id="4">
+    <nd ref="40"/>
+    <nd ref="50"/>
+    <tag k="railway" v="rail"/>
+  </way>
+</osm>`
+
+func TestImportOSM(t *testing.T) {
+	network, idMap, err := ImportOSM(strings.NewReader(exampleHighways))
+	assertError(t, err, nil)
+
+	if network.NumNodes != 4 || network.NumEdges != 3 {
+		t.Fatalf("got %d nodes and %d edges, want 4 and 3", network.NumNodes, network.NumEdges)
+	}
+	if _, ok := idMap[50]; ok {
+		t.Error("node 50 is only part of a railway and should not be imported")
+	}
+	for osmId, want := range map[int64]graph.VertexId{10: 0, 20: 1, 30: 2, 40: 3} {
+		if got := idMap[osmId]; got != want {
+			t.Errorf("OSM node %d: got vertex %d, want %d", osmId, got, want)
+		}
+	}
+
+	g := network.Network
+	primary := g.Edges[2][0]
+	if primary.Attributes.RoadClass != graph.RoadClassPrimary || primary.Attributes.MaxSpeed != 48 {
+		t.Errorf("got attributes %+v, want a primary road limited to 48 km/h", primary.Attributes)
+	}
+	if primary.Weight != 111 {
+		t.Errorf("got weight %d, want the length in meters 111", primary.Weight)
+	}
+
+	if !g.Edges[0][1].Attributes.Allows(graph.AccessCar) {
+		t.Error("cars should be allowed in the direction of the one-way road")
+	}
+	backward := g.Edges[1][0].Attributes
+	if backward.Allows(graph.AccessCar) || !backward.Allows(graph.AccessPedestrian) {
+		t.Errorf("against the one-way road only pedestrians should be allowed, got %+v", backward)
+	}
+
+	footway := g.Edges[1][3].Attributes
+	if footway.Allows(graph.AccessCar) || !footway.Allows(graph.AccessBicycle) {
+		t.Errorf("the footway should be closed to cars and open to bicycles, got %+v", footway)
+	}
+}
+
+func TestImportOSMInvalid(t *testing.T) {
+	if _, _, err := ImportOSM(strings.NewReader("<osm><node")); err == nil {
+		t.Error("expected an error for malformed XML")
+	}
+}
+
+func TestParseMaxSpeed(t *testing.T) {
+	for value, want := range map[string]int{
+		"50":     50,
+		"30 mph": 48,
+		"none":   0,
+		"":       0,
+		"-10":    0,
+	} {
+		if got := parseMaxSpeed(value); got != want {
+			t.Errorf("parseMaxSpeed(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestWriteNetwork(t *testing.T) {
+	network, _, err := ImportOSM(strings.NewReader(exampleHighways))
+	assertError(t, err, nil)
+
+	var buffer bytes.Buffer
+	assertError(t, WriteNetwork(&buffer, network.Network), nil)
+
+	fs := fstest.MapFS{"network.txt": {Data: buffer.Bytes()}}
+	got, err := NewNetworkFromFS(fs, "network.txt")
+	assertError(t, err, nil)
+
+	if got.NumNodes != network.NumNodes || got.NumEdges != network.NumEdges {
+		t.Fatalf("got %d nodes and %d edges, want %d and %d",
+			got.NumNodes, got.NumEdges, network.NumNodes, network.NumEdges)
+	}
+	for from, targets := range network.Network.Edges {
+		for to, edge := range targets {
+			attributes := got.Network.Edges[from][to].Attributes
+			if attributes.RoadClass != edge.Attributes.RoadClass || attributes.MaxSpeed != edge.Attributes.MaxSpeed {
+				t.Errorf("edge %d->%d: got %+v, want class and speed of %+v", from, to, attributes, edge.Attributes)
+			}
+		}
+	}
+}
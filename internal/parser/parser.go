@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"io/fs"
 	"strconv"
@@ -10,16 +11,11 @@ import (
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
 func NewNetworkFromFS(fileSystem fs.FS, name string) (graph.RoadNetwork, error) {
 	file, err := fileSystem.Open(name)
-
-	check(err)
+	if err != nil {
+		return graph.RoadNetwork{}, fmt.Errorf("failed to open network file: %w", err)
+	}
 
 	defer file.Close()
 	return newNetwork(file)
@@ -31,11 +27,15 @@ func newNetwork(networkFile io.Reader) (graph.RoadNetwork, error) {
 
 	scanner.Scan()
 	numNodes, err := strconv.Atoi(scanner.Text())
-	check(err)
+	if err != nil {
+		return graph.RoadNetwork{}, fmt.Errorf("invalid node count: %w", err)
+	}
 
 	scanner.Scan()
 	numEdges, err := strconv.Atoi(scanner.Text())
-	check(err)
+	if err != nil {
+		return graph.RoadNetwork{}, fmt.Errorf("invalid edge count: %w", err)
+	}
 
 	for scanner.Scan() {
 		properties := strings.Fields(scanner.Text())
@@ -46,7 +46,7 @@ func newNetwork(networkFile io.Reader) (graph.RoadNetwork, error) {
 			vertex := graph.Vertex{Id: graph.VertexId(id), Lat: lat, Lon: lon}
 			g.AddVertex(vertex)
 		}
-		if len(properties) == 2 {
+		if len(properties) == 2 || len(properties) == 4 {
 			sourceId, _ := strconv.Atoi(properties[0])
 			targetId, _ := strconv.Atoi(properties[1])
 			source, _ := g.Vertex(graph.VertexId(sourceId))
@@ -55,9 +55,38 @@ func newNetwork(networkFile io.Reader) (graph.RoadNetwork, error) {
 			g.AddEdge(source.Id, target.Id, 1, false, -1)
 			// as it is undirected also the reverse
 			g.AddEdge(target.Id, source.Id, 1, false, -1)
+
+			if len(properties) == 4 {
+				attributes, err := parseEdgeAttributes(properties[2], properties[3], source, target)
+				if err != nil {
+					return graph.RoadNetwork{}, fmt.Errorf("invalid edge %d %d: %w", sourceId, targetId, err)
+				}
+				g.SetAttributes(source.Id, target.Id, attributes)
+				g.SetAttributes(target.Id, source.Id, attributes)
+			}
 		}
 	}
 
 	network := graph.RoadNetwork{NumNodes: numNodes, NumEdges: numEdges, Network: g}
 	return network, nil
 }
+
+// parseEdgeAttributes parses the optional road class and speed limit of an
+// edge line. The road class is an OSM highway tag, the speed limit is given in
+// km/h with 0 for unknown. The length is the distance between the end points.
+func parseEdgeAttributes(highway, maxSpeed string, source, target graph.Vertex) (graph.Attributes, error) {
+	roadClass, ok := graph.ParseRoadClass(highway)
+	if !ok {
+		return graph.Attributes{}, fmt.Errorf("unknown road class %q", highway)
+	}
+	speed, err := strconv.Atoi(maxSpeed)
+	if err != nil || speed < 0 {
+		return graph.Attributes{}, fmt.Errorf("invalid speed limit %q", maxSpeed)
+	}
+	return graph.Attributes{
+		RoadClass:  roadClass,
+		MaxSpeed:   speed,
+		Length:     graph.Distance(source, target),
+		Restricted: graph.DefaultRestrictions(roadClass),
+	}, nil
+}
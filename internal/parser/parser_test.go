@@ -70,3 +70,20 @@ func assertRoadNetwork(t *testing.T, got graph.RoadNetwork, want graph.RoadNetwo
 		t.Errorf("RoadNetwork mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestNewRoadNetworkErrors(t *testing.T) {
+	fs := fstest.MapFS{
+		"invalid.txt": {Data: []byte("four\n3\n")},
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := NewNetworkFromFS(fs, "missing.txt"); err == nil {
+			t.Error("expected an error for a missing file")
+		}
+	})
+	t.Run("invalid header", func(t *testing.T) {
+		if _, err := NewNetworkFromFS(fs, "invalid.txt"); err == nil {
+			t.Error("expected an error for an invalid node count")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/ttf"
+)
+
+// NewProfilesFromFS reads travel time profiles sampled at a fixed interval.
+// The first line that is neither empty nor a comment starting with '#' holds
+// the interval, every following line the samples of one directed edge:
+//
+//	900
+//	from to d0 d1 ... dn-1
+//
+// Sample i is the travel time when departing at i * interval. All edges need
+// the same number of samples n, which makes the period n * interval; a week
+// in 15 minute steps has 672 samples.
+func NewProfilesFromFS(fileSystem fs.FS, name string) (*ttf.Profiles, error) {
+	file, err := fileSystem.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile file: %w", err)
+	}
+
+	defer file.Close()
+	return newProfiles(file)
+}
+
+func newProfiles(r io.Reader) (*ttf.Profiles, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var profiles *ttf.Profiles
+	interval := 0.0
+	numSamples := 0
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if interval == 0 {
+			value, err := strconv.ParseFloat(line, 64)
+			if err != nil || value <= 0 {
+				return nil, fmt.Errorf("line %d: invalid interval %q", lineNumber, line)
+			}
+			interval = value
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected an edge and at least one sample", lineNumber)
+		}
+		from, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid vertex id: %w", lineNumber, err)
+		}
+		to, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid vertex id: %w", lineNumber, err)
+		}
+
+		samples := make([]float64, 0, len(fields)-2)
+		for _, field := range fields[2:] {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid sample: %w", lineNumber, err)
+			}
+			samples = append(samples, value)
+		}
+		if numSamples == 0 {
+			numSamples = len(samples)
+			profiles = ttf.NewProfiles(float64(numSamples) * interval)
+		}
+		if len(samples) != numSamples {
+			return nil, fmt.Errorf("line %d: got %d samples, want %d", lineNumber, len(samples), numSamples)
+		}
+
+		f, err := ttf.FromSamples(interval, samples)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if err := profiles.Set(graph.VertexId(from), graph.VertexId(to), f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading profile file: %w", err)
+	}
+	if profiles == nil {
+		return nil, fmt.Errorf("profile file contains no edges")
+	}
+	return profiles, nil
+}
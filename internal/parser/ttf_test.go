@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+const exampleProfiles = `# 15 minute samples
+900
+0 1 60 60 120 90
+1 0 30 30 30 30`
+
+func TestNewProfiles(t *testing.T) {
+	fs := fstest.MapFS{"profiles.txt": {Data: []byte(exampleProfiles)}}
+	profiles, err := NewProfilesFromFS(fs, "profiles.txt")
+	assertError(t, err, nil)
+
+	if profiles.Period() != 3600 {
+		t.Errorf("got period %f, want 3600", profiles.Period())
+	}
+	if profiles.Len() != 2 {
+		t.Errorf("got %d profiles, want 2", profiles.Len())
+	}
+	f, ok := profiles.Get(0, 1)
+	if !ok {
+		t.Fatal("missing profile for 0 -> 1")
+	}
+	if got := f.Eval(1350); got != 90 {
+		t.Errorf("got travel time %f at 1350, want 90", got)
+	}
+	if got := f.Eval(3150); got != 75 {
+		t.Errorf("got travel time %f at 3150, want 75", got)
+	}
+}
+
+func TestNewProfilesErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no edges":         "900",
+		"invalid interval": "fast\n0 1 5",
+		"invalid vertex":   "900\na 1 5",
+		"invalid sample":   "900\n0 1 slow",
+		"sample count":     "900\n0 1 5 5\n1 0 5",
+		"not FIFO":         "1\n0 1 100 1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			fs := fstest.MapFS{"profiles.txt": {Data: []byte(content)}}
+			if _, err := NewProfilesFromFS(fs, "profiles.txt"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
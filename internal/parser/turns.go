@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// NewTurnTableFromFS reads a turn table. Every non-empty line that does not
+// start with '#' has the form
+//
+//	from via to cost
+//	from via to forbidden
+//
+// where cost is a non-negative integer added to every route taking the turn.
+func NewTurnTableFromFS(fileSystem fs.FS, name string) (*turns.Table, error) {
+	file, err := fileSystem.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open turn table: %w", err)
+	}
+
+	defer file.Close()
+	return newTurnTable(file)
+}
+
+func newTurnTable(r io.Reader) (*turns.Table, error) {
+	table := turns.NewTable()
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected 4 fields, got %d", lineNumber, len(fields))
+		}
+		var ids [3]graph.VertexId
+		for i := range ids {
+			id, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid vertex id: %w", lineNumber, err)
+			}
+			ids[i] = graph.VertexId(id)
+		}
+
+		if fields[3] == "forbidden" {
+			table.Forbid(ids[0], ids[1], ids[2])
+			continue
+		}
+		cost, err := strconv.Atoi(fields[3])
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("line %d: invalid turn cost %q", lineNumber, fields[3])
+		}
+		table.SetCost(ids[0], ids[1], ids[2], cost)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading turn table: %w", err)
+	}
+	return table, nil
+}
+
+// WriteTurnTable writes the turns of table on the edges of g in the format
+// read by NewTurnTableFromFS, one turn per line ordered by from, via and to.
+// only_* restrictions and U-turn defaults are written as the single turns
+// they forbid or charge.
+func WriteTurnTable(w io.Writer, g *graph.Graph, table *turns.Table) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range table.Entries(g) {
+		cost := strconv.Itoa(entry.Cost)
+		if entry.Forbidden {
+			cost = "forbidden"
+		}
+		if _, err := fmt.Fprintf(bw, "%d %d %d %s\n", entry.From, entry.Via, entry.To, cost); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ParseOSMRestrictions reads the restriction relations of an OSM XML file.
+// Only relations with a single via node are supported; relations with a via
+// way are skipped. A from-way is reduced to the node before the via node on
+// its segment ending there, so it must start or end at the via node. A to-way
+// passing through the via node yields a restriction for both directions; for
+// only_* relations turns.Table allows both. OSM node ids are translated with
+// idMap. Restrictions that reference nodes missing from idMap are skipped, so
+// a file covering a larger area than the road network can be used.
+func ParseOSMRestrictions(r io.Reader, idMap map[int64]graph.VertexId) ([]turns.Restriction, error) {
+	var file osmFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode OSM XML: %w", err)
+	}
+	return osmRestrictions(file, idMap), nil
+}
+
+func osmRestrictions(file osmFile, idMap map[int64]graph.VertexId) []turns.Restriction {
+	ways := make(map[int64][]int64, len(file.Ways))
+	for _, way := range file.Ways {
+		nodes := make([]int64, 0, len(way.Nodes))
+		for _, nd := range way.Nodes {
+			nodes = append(nodes, nd.Ref)
+		}
+		ways[way.Id] = nodes
+	}
+
+	var restrictions []turns.Restriction
+	for _, relation := range file.Relations {
+		kind, ok := restrictionKind(relation.Tags)
+		if !ok {
+			continue
+		}
+		only := strings.HasPrefix(kind, "only_")
+		if !only && !strings.HasPrefix(kind, "no_") {
+			continue
+		}
+
+		var fromWays, toWays []int64
+		via, viaCount := int64(0), 0
+		for _, member := range relation.Members {
+			switch {
+			case member.Role == "from" && member.Type == "way":
+				fromWays = append(fromWays, member.Ref)
+			case member.Role == "to" && member.Type == "way":
+				toWays = append(toWays, member.Ref)
+			case member.Role == "via" && member.Type == "node":
+				via = member.Ref
+				viaCount++
+			case member.Role == "via":
+				viaCount = -1
+			}
+		}
+		if viaCount != 1 || len(fromWays) == 0 || len(toWays) == 0 {
+			continue
+		}
+
+		viaId, ok := idMap[via]
+		if !ok {
+			continue
+		}
+		for _, fromWay := range fromWays {
+			from, ok := fromNeighbor(ways[fromWay], via)
+			if !ok {
+				continue
+			}
+			fromId, ok := idMap[from]
+			if !ok {
+				continue
+			}
+			for _, toWay := range toWays {
+				for _, to := range neighborsInWay(ways[toWay], via) {
+					toId, ok := idMap[to]
+					if !ok {
+						continue
+					}
+					restrictions = append(restrictions, turns.Restriction{
+						Turn: turns.Turn{From: fromId, Via: viaId, To: toId},
+						Only: only,
+					})
+				}
+			}
+		}
+	}
+
+	return restrictions
+}
+
+// restrictionKind returns the value of the restriction tag of a relation of
+// type restriction.
+func restrictionKind(tags []osmTag) (string, bool) {
+	isRestriction := false
+	kind := ""
+	for _, tag := range tags {
+		switch tag.Key {
+		case "type":
+			isRestriction = tag.Value == "restriction"
+		case "restriction":
+			kind = tag.Value
+		}
+	}
+	return kind, isRestriction && kind != ""
+}
+
+// fromNeighbor returns the node before node on a from-way, which is the
+// second to last node if the way ends at node and the second node if it
+// starts there. A from-way passing through node is ambiguous and yields no
+// node.
+func fromNeighbor(nodes []int64, node int64) (int64, bool) {
+	switch {
+	case len(nodes) < 2:
+		return 0, false
+	case nodes[len(nodes)-1] == node:
+		return nodes[len(nodes)-2], true
+	case nodes[0] == node:
+		return nodes[1], true
+	}
+	return 0, false
+}
+
+// neighborsInWay returns the nodes next to node in the node list of a way.
+func neighborsInWay(nodes []int64, node int64) []int64 {
+	var neighbors []int64
+	for i, n := range nodes {
+		if n != node {
+			continue
+		}
+		if i > 0 {
+			neighbors = append(neighbors, nodes[i-1])
+		}
+		if i < len(nodes)-1 {
+			neighbors = append(neighbors, nodes[i+1])
+		}
+	}
+	return neighbors
+}
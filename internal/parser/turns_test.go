@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+const exampleTurnTable = `# from via to cost
+1 0 2 forbidden
+1 0 3 5
+
+2 0 2 10`
+
+func TestNewTurnTable(t *testing.T) {
+	fs := fstest.MapFS{"turns.txt": {Data: []byte(exampleTurnTable)}}
+	table, err := NewTurnTableFromFS(fs, "turns.txt")
+	assertError(t, err, nil)
+
+	testCases := []struct {
+		turn        turns.Turn
+		wantCost    int
+		wantAllowed bool
+	}{
+		{turns.Turn{From: 1, Via: 0, To: 2}, 0, false},
+		{turns.Turn{From: 1, Via: 0, To: 3}, 5, true},
+		{turns.Turn{From: 2, Via: 0, To: 2}, 10, true},
+		{turns.Turn{From: 3, Via: 0, To: 1}, 0, true},
+	}
+	for _, tc := range testCases {
+		cost, allowed := table.Cost(tc.turn.From, tc.turn.Via, tc.turn.To)
+		if cost != tc.wantCost || allowed != tc.wantAllowed {
+			t.Errorf("turn %v: got (%d, %t), want (%d, %t)", tc.turn, cost, allowed, tc.wantCost, tc.wantAllowed)
+		}
+	}
+}
+
+func TestNewTurnTableErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"too few fields": "1 0 2",
+		"invalid vertex": "a 0 2 3",
+		"invalid cost":   "1 0 2 expensive",
+		"negative cost":  "1 0 2 -1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			fs := fstest.MapFS{"turns.txt": {Data: []byte(content)}}
+			if _, err := NewTurnTableFromFS(fs, "turns.txt"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := NewTurnTableFromFS(fstest.MapFS{}, "missing.txt"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+const exampleOSM = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="100" lat="48.0" lon="9.0"/>
+  <way id="10">
+    <nd ref="101"/>
+    <nd ref="100"/>
+  </way>
+  <way id="11">
+    <nd ref="100"/>
+    <nd ref="102"/>
+  </way>
+  <way id="12">
+    <nd ref="103"/>
+    <nd ref="100"/>
+    <nd ref="104"/>
+  </way>
+  <relation id="1">
+    <member type="way" ref="10" role="from"/>
+    <member type="node" ref="100" role="via"/>
+    <member type="way" ref="11" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="no_left_turn"/>
+  </relation>
+  <relation id="2">
+    <member type="way" ref="11" role="from"/>
+    <member type="node" ref="100" role="via"/>
+    <member type="way" ref="12" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="only_right_turn"/>
+  </relation>
+  <relation id="3">
+    <member type="way" ref="10" role="from"/>
+    <member type="way" ref="12" role="via"/>
+    <member type="way" ref="11" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="no_u_turn"/>
+  </relation>
+  <relation id="4">
+    <member type="way" ref="10" role="from"/>
+    <member type="node" ref="100" role="via"/>
+    <member type="way" ref="11" role="to"/>
+    <tag k="type" v="route"/>
+  </relation>
+</osm>`
+
+func TestParseOSMRestrictions(t *testing.T) {
+	idMap := map[int64]graph.VertexId{100: 0, 101: 1, 102: 2, 103: 3}
+
+	got, err := ParseOSMRestrictions(strings.NewReader(exampleOSM), idMap)
+	assertError(t, err, nil)
+
+	// Node 104 is not part of the network, so the second relation only yields
+	// the restriction towards 103; the via way and the route relation are skipped.
+	want := []turns.Restriction{
+		{Turn: turns.Turn{From: 1, Via: 0, To: 2}},
+		{Turn: turns.Turn{From: 2, Via: 0, To: 3}, Only: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := ParseOSMRestrictions(strings.NewReader("<osm"), idMap); err == nil {
+		t.Error("expected an error for malformed XML")
+	}
+}
+
+// throughWayOSM has a junction at node 200 where way 21 passes through. The
+// only_straight_on from way 20 therefore allows both directions of way 21.
+// Way 22 also passes through the junction and is skipped as a from-way.
+const throughWayOSM = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <way id="20">
+    <nd ref="201"/>
+    <nd ref="200"/>
+  </way>
+  <way id="21">
+    <nd ref="202"/>
+    <nd ref="200"/>
+    <nd ref="203"/>
+  </way>
+  <way id="22">
+    <nd ref="204"/>
+    <nd ref="200"/>
+    <nd ref="205"/>
+  </way>
+  <relation id="5">
+    <member type="way" ref="20" role="from"/>
+    <member type="node" ref="200" role="via"/>
+    <member type="way" ref="21" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="only_straight_on"/>
+  </relation>
+  <relation id="6">
+    <member type="way" ref="22" role="from"/>
+    <member type="node" ref="200" role="via"/>
+    <member type="way" ref="20" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="no_right_turn"/>
+  </relation>
+</osm>`
+
+func TestParseOSMRestrictionsThroughWay(t *testing.T) {
+	idMap := map[int64]graph.VertexId{200: 0, 201: 1, 202: 2, 203: 3, 204: 4, 205: 5}
+
+	restrictions, err := ParseOSMRestrictions(strings.NewReader(throughWayOSM), idMap)
+	assertError(t, err, nil)
+	want := []turns.Restriction{
+		{Turn: turns.Turn{From: 1, Via: 0, To: 2}, Only: true},
+		{Turn: turns.Turn{From: 1, Via: 0, To: 3}, Only: true},
+	}
+	if !reflect.DeepEqual(restrictions, want) {
+		t.Fatalf("got %v, want %v", restrictions, want)
+	}
+
+	g := graph.NewGraph()
+	for id := range 6 {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(id)})
+	}
+	for arm := graph.VertexId(1); arm <= 5; arm++ {
+		g.AddEdge(0, arm, 1, false, -1)
+		g.AddEdge(arm, 0, 1, false, -1)
+	}
+	table := turns.NewTable()
+	for _, r := range restrictions {
+		assertError(t, table.AddRestriction(g, r), nil)
+	}
+	for to, allowed := range map[graph.VertexId]bool{1: false, 2: true, 3: true, 4: false, 5: false} {
+		if _, got := table.Cost(1, 0, to); got != allowed {
+			t.Errorf("turn 1 -> 0 -> %d: got allowed %t, want %t", to, got, allowed)
+		}
+	}
+}
+
+// restrictedJunction is a T junction at node 1 with a no_left_turn from way
+// 10 onto way 12. The relation onto the railway is left out on import.
+const restrictedJunction = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="1" lat="48.0000" lon="9.0000"/>
+  <node id="2" lat="47.9990" lon="9.0000"/>
+  <node id="3" lat="48.0000" lon="9.0010"/>
+  <node id="4" lat="48.0000" lon="8.9990"/>
+  <node id="5" lat="48.0010" lon="9.0000"/>
+  <way id="10">
+    <nd ref="2"/>
+    <nd ref="1"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="11">
+    <nd ref="3"/>
+    <nd ref="1"/>
+    <nd ref="4"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="12">
+    <nd ref="1"/>
+    <nd ref="4"/>
+  </way>
+  <way id="13">
+    <nd ref="1"/>
+    <nd ref="5"/>
+    <tag k="railway" v="rail"/>
+  </way>
+  <relation id="1">
+    <member type="way" ref="10" role="from"/>
+    <member type="node" ref="1" role="via"/>
+    <member type="way" ref="12" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="no_left_turn"/>
+  </relation>
+  <relation id="2">
+    <member type="way" ref="10" role="from"/>
+    <member type="node" ref="1" role="via"/>
+    <member type="way" ref="13" role="to"/>
+    <tag k="type" v="restriction"/>
+    <tag k="restriction" v="only_straight_on"/>
+  </relation>
+</osm>`
+
+func TestImportOSMWithRestrictions(t *testing.T) {
+	network, table, idMap, err := ImportOSMWithRestrictions(strings.NewReader(restrictedJunction))
+	assertError(t, err, nil)
+	g := network.Network
+	if network.NumNodes != 4 {
+		t.Fatalf("got %d nodes, want 4", network.NumNodes)
+	}
+
+	from, via, left, right := idMap[2], idMap[1], idMap[4], idMap[3]
+	if _, allowed := table.Cost(from, via, left); allowed {
+		t.Error("expected the left turn to be forbidden")
+	}
+	if _, allowed := table.Cost(from, via, right); !allowed {
+		t.Error("expected the right turn to stay allowed")
+	}
+
+	var buffer strings.Builder
+	assertError(t, WriteTurnTable(&buffer, g, table), nil)
+	if got, want := buffer.String(), "1 0 3 forbidden\n"; got != want {
+		t.Errorf("got turn table %q, want %q", got, want)
+	}
+	read, err := NewTurnTableFromFS(fstest.MapFS{"turns.txt": {Data: []byte(buffer.String())}}, "turns.txt")
+	assertError(t, err, nil)
+	if !reflect.DeepEqual(read.Entries(g), table.Entries(g)) {
+		t.Errorf("read back %v, want %v", read.Entries(g), table.Entries(g))
+	}
+
+	if _, _, _, err := ImportOSMWithRestrictions(strings.NewReader("<osm")); err == nil {
+		t.Error("expected an error for malformed XML")
+	}
+}
@@ -0,0 +1,146 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"slices"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+// ArcFlags assigns every vertex to a region and marks for every edge the
+// regions it leads into on some shortest path.
+type ArcFlags interface {
+	Region(v graph.VertexId) (int, bool)
+	HasFlag(from, to graph.VertexId, region int) bool
+}
+
+// ArcFlagDijkstraShortestPath finds the shortest path from source to target
+// with a Dijkstra search that only relaxes edges flagged for the region of
+// the target. If the target has no region, no edge is pruned.
+func ArcFlagDijkstraShortestPath(g *graph.Graph, flags ArcFlags, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	region, pruned := flags.Region(target)
+
+	distances := map[graph.VertexId]float64{source: 0}
+	bestPredecessors := make(map[graph.VertexId]graph.VertexId)
+	queue := collection.NewPriorityQueue[graph.VertexId]()
+	queue.PushWithPriority(source, 0)
+	visited := make(map[graph.VertexId]bool)
+	nodesPopped := 0
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
+		nodesPopped++
+		vertex := queue.GetValue(item)
+		cost := queue.GetPriority(item)
+		if visited[vertex] {
+			continue
+		}
+		visited[vertex] = true
+
+		if vertex == target {
+			path, err := buildPath(bestPredecessors, source, target)
+			if err != nil {
+				return nil, 0, nodesPopped, err
+			}
+			return path, cost, nodesPopped, nil
+		}
+
+		for adjacent, edge := range g.Edges[vertex] {
+			if visited[adjacent] || edge.Weight == graph.InfWeight {
+				continue
+			}
+			if pruned && !flags.HasFlag(vertex, adjacent, region) {
+				continue
+			}
+			newWeight := cost + float64(edge.Weight)
+			if oldDist, exists := distances[adjacent]; !exists || newWeight < oldDist {
+				distances[adjacent] = newWeight
+				bestPredecessors[adjacent] = vertex
+				queue.PushWithPriority(adjacent, newWeight)
+			}
+		}
+	}
+
+	return nil, 0, nodesPopped, ErrTargetNotReachable
+}
+
+// Phases of an up-down path on a contraction hierarchy.
+const (
+	phaseUp = iota
+	phaseDown
+)
+
+// phasedVertex is a vertex together with the phase of the up-down path that
+// reached it.
+type phasedVertex struct {
+	vertex graph.VertexId
+	phase  int
+}
+
+// CHArcFlagShortestPath finds the shortest path from source to target on a
+// contraction hierarchy with a unidirectional search for an up-down path: it
+// first follows upward edges and, once it took a downward edge, only downward
+// edges. upGraph and downGraph follow the conventions of
+// BiDirectionalDijkstraShortestPath, i.e. downGraph.Edges[v][w] is the edge
+// from v down to the lower ranked w. Only edges flagged for the region of the
+// target are relaxed. The returned path may contain shortcuts.
+func CHArcFlagShortestPath(upGraph, downGraph *graph.Graph, flags ArcFlags, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	region, pruned := flags.Region(target)
+
+	start := phasedVertex{source, phaseUp}
+	distances := map[phasedVertex]float64{start: 0}
+	bestPredecessors := make(map[phasedVertex]phasedVertex)
+	queue := collection.NewPriorityQueue[phasedVertex]()
+	queue.PushWithPriority(start, 0)
+	visited := make(map[phasedVertex]bool)
+	nodesPopped := 0
+
+	relax := func(from phasedVertex, cost float64, to graph.VertexId, phase int, weight int) {
+		next := phasedVertex{to, phase}
+		if visited[next] || weight == graph.InfWeight {
+			return
+		}
+		if pruned && !flags.HasFlag(from.vertex, to, region) {
+			return
+		}
+		newWeight := cost + float64(weight)
+		if oldDist, exists := distances[next]; !exists || newWeight < oldDist {
+			distances[next] = newWeight
+			bestPredecessors[next] = from
+			queue.PushWithPriority(next, newWeight)
+		}
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[phasedVertex])
+		nodesPopped++
+		current := queue.GetValue(item)
+		cost := queue.GetPriority(item)
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		if current.vertex == target {
+			path := []graph.VertexId{current.vertex}
+			for current != start {
+				current = bestPredecessors[current]
+				path = append(path, current.vertex)
+			}
+			slices.Reverse(path)
+			return path, cost, nodesPopped, nil
+		}
+
+		if current.phase == phaseUp {
+			for adjacent, edge := range upGraph.Edges[current.vertex] {
+				relax(current, cost, adjacent, phaseUp, edge.Weight)
+			}
+		}
+		for adjacent, edge := range downGraph.Edges[current.vertex] {
+			relax(current, cost, adjacent, phaseDown, edge.Weight)
+		}
+	}
+
+	return nil, 0, nodesPopped, ErrTargetNotReachable
+}
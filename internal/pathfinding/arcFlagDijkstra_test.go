@@ -0,0 +1,78 @@
+package pathfinding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// testFlags puts every vertex in region 0 and flags all edges except the
+// blocked ones.
+type testFlags struct {
+	blocked map[[2]graph.VertexId]bool
+}
+
+func (f testFlags) Region(v graph.VertexId) (int, bool) {
+	return 0, true
+}
+
+func (f testFlags) HasFlag(from, to graph.VertexId, region int) bool {
+	return !f.blocked[[2]graph.VertexId{from, to}]
+}
+
+func TestArcFlagDijkstraShortestPath(t *testing.T) {
+	g := createTestGraph()
+
+	path, weight, _, err := ArcFlagDijkstraShortestPath(g, testFlags{}, 0, 3)
+	if err != nil || weight != 5 || !reflect.DeepEqual(path, []graph.VertexId{0, 1, 3}) {
+		t.Errorf("got %v, %v, %v, want [0 1 3], 5", path, weight, err)
+	}
+
+	// Without the flag on 1 -> 3 the search must take the detour over 2.
+	flags := testFlags{blocked: map[[2]graph.VertexId]bool{{1, 3}: true}}
+	path, weight, _, err = ArcFlagDijkstraShortestPath(g, flags, 0, 3)
+	if err != nil || weight != 8 || !reflect.DeepEqual(path, []graph.VertexId{0, 2, 3}) {
+		t.Errorf("got %v, %v, %v, want [0 2 3], 8", path, weight, err)
+	}
+
+	flags.blocked[[2]graph.VertexId{2, 3}] = true
+	if _, _, _, err := ArcFlagDijkstraShortestPath(g, flags, 0, 3); !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+}
+
+func TestCHArcFlagShortestPath(t *testing.T) {
+	// Ranks 0 < 1 < 2 < 3 by vertex id: 0 -> 3 goes up to 3 and 3 -> 1 down.
+	up := graph.NewGraph()
+	down := graph.NewGraph()
+	for i := 0; i < 4; i++ {
+		up.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+		down.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	up.AddEdge(0, 3, 1, false, -1)
+	up.AddEdge(0, 2, 1, false, -1)
+	up.AddEdge(1, 3, 1, false, -1)
+	down.AddEdge(3, 1, 1, false, -1)
+	down.AddEdge(2, 1, 5, false, -1)
+	down.AddEdge(3, 0, 1, false, -1)
+	down.AddEdge(2, 0, 1, false, -1)
+
+	path, weight, _, err := CHArcFlagShortestPath(up, down, testFlags{}, 0, 1)
+	if err != nil || weight != 2 || !reflect.DeepEqual(path, []graph.VertexId{0, 3, 1}) {
+		t.Errorf("got %v, %v, %v, want [0 3 1], 2", path, weight, err)
+	}
+
+	flags := testFlags{blocked: map[[2]graph.VertexId]bool{{3, 1}: true}}
+	path, weight, _, err = CHArcFlagShortestPath(up, down, flags, 0, 1)
+	if err != nil || weight != 6 || !reflect.DeepEqual(path, []graph.VertexId{0, 2, 1}) {
+		t.Errorf("got %v, %v, %v, want [0 2 1], 6", path, weight, err)
+	}
+
+	// Once the path went down it cannot go up again: 0 -> 2 -> 0 -> 3 is no up-down path.
+	flags.blocked[[2]graph.VertexId{2, 1}] = true
+	if _, _, _, err := CHArcFlagShortestPath(up, down, flags, 0, 1); !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+}
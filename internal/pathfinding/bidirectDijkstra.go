@@ -1,54 +1,39 @@
 package pathfinding
 
 import (
-	"container/heap"
 	"math"
 
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
-	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
 )
 
-// searchContext holds the state for a single direction of a Dijkstra search.
-// It includes the graph being traversed, a priority queue for nodes to visit,
-// maps for distances from the source, and predecessors to reconstruct the path.
-type searchContext struct {
-	g     *graph.Graph
-	pq    *collection.PriorityQueue[graph.VertexId]
-	dists map[graph.VertexId]float64
-	preds map[graph.VertexId]graph.VertexId
-}
-
-// newSearchContext initializes and returns a new search context for a given graph and start node.
-// It sets up the distance map with infinity for all nodes except the start node (which is set to 0)
-// and prepares the priority queue.
-func newSearchContext(g *graph.Graph, startNode graph.VertexId) *searchContext {
-	dists := initializeWeights(g, startNode)
-	pq := initializePriorityQueue(dists)
-	return &searchContext{
-		g:     g,
-		pq:    pq,
-		dists: dists,
-		preds: make(map[graph.VertexId]graph.VertexId),
-	}
-}
-
-// processNextNode extracts the highest-priority node from the queue, relaxes its edges,
-// and checks for a potential meeting point with the opposite search direction.
-// It updates the shortest path length and the meeting node if a shorter path is found.
-func (sc *searchContext) processNextNode(
-	oppositeDists map[graph.VertexId]float64,
+// relax pops the closest vertex of sc, checks whether it was reached by the
+// opposite search and relaxes its edges on g. If reverse is set, an edge (v, w)
+// of g is weighted with the edge (w, v) of reverse, which lets a backward search
+// follow the upward edges with the weights of the downward edges. Vertices of
+// core, which may be nil, relax their edges of core instead. It updates the
+// shortest path length and the meeting node if a shorter path is found.
+func relax(
+	sc, opposite *searchSpace,
+	g, reverse, core *graph.Graph,
 	shortestPathLength *float64,
 	meetNode *graph.VertexId,
 	nodesPopped *int,
 ) {
-	item := heap.Pop(sc.pq).(*collection.Item[graph.VertexId])
+	vertex, cost, _ := sc.pop()
 	(*nodesPopped)++
-	vertex := sc.pq.GetValue(item)
-	cost := sc.pq.GetPriority(item)
+
+	if core != nil {
+		if _, ok := core.Vertices[vertex]; ok {
+			g = core
+			if reverse != nil {
+				reverse = core
+			}
+		}
+	}
 
 	// Check if the current node has been reached by the other search.
 	// If so, a potential path has been found.
-	if oppositeDist, found := oppositeDists[vertex]; found && !math.IsInf(oppositeDist, 1) {
+	if oppositeDist := opposite.dist(vertex); !math.IsInf(oppositeDist, 1) {
 		if potentialPathLength := cost + oppositeDist; potentialPathLength < *shortestPathLength {
 			*shortestPathLength = potentialPathLength
 			*meetNode = vertex
@@ -56,12 +41,20 @@ func (sc *searchContext) processNextNode(
 	}
 
 	// Relax outgoing edges.
-	for adjacent, edge := range sc.g.Edges[vertex] {
-		newWeight := cost + float64(edge.Weight)
-		if newWeight < sc.dists[adjacent] {
-			sc.dists[adjacent] = newWeight
-			sc.preds[adjacent] = vertex
-			sc.pq.UpdatePriority(adjacent, newWeight)
+	for adjacent, edge := range g.Edges[vertex] {
+		weight := edge.Weight
+		if reverse != nil {
+			reverseEdge, ok := reverse.Edges[adjacent][vertex]
+			if !ok {
+				continue
+			}
+			weight = reverseEdge.Weight
+		}
+		if weight == graph.InfWeight {
+			continue
+		}
+		if newWeight := cost + float64(weight); newWeight < sc.dist(adjacent) {
+			sc.reach(adjacent, newWeight, vertex)
 		}
 	}
 }
@@ -70,28 +63,45 @@ func (sc *searchContext) processNextNode(
 // in a graph using a bidirectional Dijkstra's algorithm. It runs two searches simultaneously:
 // one forward from the source on upGraph and one backward from the target on downGraph.
 // For Contraction Hierarchies, upGraph contains only upward edges, and downGraph contains only
-// downward edges. The search terminates when the sum of minimum distances from both search
+// downward edges. The backward search follows the upward edges of upGraph but weighs them with
+// the corresponding edges of downGraph, so directed metrics are supported. The search terminates when the sum of minimum distances from both search
 // frontiers exceeds the length of the best path found so far.
 // It returns the path as a slice of vertex IDs, the total path weight, and an error if no
 // path is found.
 func BiDirectionalDijkstraShortestPath(upGraph *graph.Graph, downGraph *graph.Graph, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
-	if source == target {
-		if _, ok := upGraph.Edges[source]; ok {
-			return []graph.VertexId{source}, 0, 0, nil
-		}
+	return (*Workspace)(nil).BiDirectionalDijkstraShortestPath(upGraph, downGraph, source, target)
+}
+
+// BiDirectionalDijkstraShortestPath runs BiDirectionalDijkstraShortestPath
+// with the search state kept in w.
+func (w *Workspace) BiDirectionalDijkstraShortestPath(upGraph *graph.Graph, downGraph *graph.Graph, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	_, sourceExists := upGraph.Vertices[source]
+	_, targetExists := upGraph.Vertices[target]
+	if !sourceExists || !targetExists {
 		return nil, 0, 0, ErrTargetNotReachable
 	}
+	if source == target {
+		return []graph.VertexId{source}, 0, 0, nil
+	}
 
-	fwdSearch := newSearchContext(upGraph, source)
-	bwdSearch := newSearchContext(upGraph, target)
+	w, pooled := w.acquire()
+	defer w.release(pooled)
+	fwdSearch, bwdSearch := &w.forward, &w.backward
+	fwdSearch.reset()
+	bwdSearch.reset()
+	fwdSearch.reach(source, 0, source)
+	bwdSearch.reach(target, 0, target)
 
 	currentShortestPath := math.Inf(1)
 	var meetNode graph.VertexId
 	nodesPopped := 0
 
-	for fwdSearch.pq.Len() > 0 && bwdSearch.pq.Len() > 0 {
-		fwdMinDist := fwdSearch.pq.GetPriority(fwdSearch.pq.Peek())
-		bwdMinDist := bwdSearch.pq.GetPriority(bwdSearch.pq.Peek())
+	for {
+		fwdMinDist, fwdOk := fwdSearch.min()
+		bwdMinDist, bwdOk := bwdSearch.min()
+		if !fwdOk || !bwdOk {
+			break
+		}
 
 		// Termination condition: if the sum of the smallest distances in both queues
 		// is greater than or equal to the current shortest path, no shorter path can be found.
@@ -102,31 +112,89 @@ func BiDirectionalDijkstraShortestPath(upGraph *graph.Graph, downGraph *graph.Gr
 
 		// Process the node from the search direction with the smaller minimum distance.
 		if fwdMinDist <= bwdMinDist {
-			fwdSearch.processNextNode(bwdSearch.dists, &currentShortestPath, &meetNode, &nodesPopped)
+			relax(fwdSearch, bwdSearch, upGraph, nil, nil, &currentShortestPath, &meetNode, &nodesPopped)
 		} else {
-			bwdSearch.processNextNode(fwdSearch.dists, &currentShortestPath, &meetNode, &nodesPopped)
+			relax(bwdSearch, fwdSearch, upGraph, downGraph, nil, &currentShortestPath, &meetNode, &nodesPopped)
 		}
 	}
 
 	// One of the searches may be exhausted. Continue with the other until its priority queue
 	// is empty or the minimum distance is greater than the current shortest path.
-	for fwdSearch.pq.Len() > 0 && (math.IsInf(currentShortestPath, 1) || fwdSearch.pq.GetPriority(fwdSearch.pq.Peek()) < currentShortestPath) {
-		fwdSearch.processNextNode(bwdSearch.dists, &currentShortestPath, &meetNode, &nodesPopped)
+	for minDist, ok := fwdSearch.min(); ok && minDist < currentShortestPath; minDist, ok = fwdSearch.min() {
+		relax(fwdSearch, bwdSearch, upGraph, nil, nil, &currentShortestPath, &meetNode, &nodesPopped)
 	}
-	for bwdSearch.pq.Len() > 0 && (math.IsInf(currentShortestPath, 1) || bwdSearch.pq.GetPriority(bwdSearch.pq.Peek()) < currentShortestPath) {
-		bwdSearch.processNextNode(fwdSearch.dists, &currentShortestPath, &meetNode, &nodesPopped)
+	for minDist, ok := bwdSearch.min(); ok && minDist < currentShortestPath; minDist, ok = bwdSearch.min() {
+		relax(bwdSearch, fwdSearch, upGraph, downGraph, nil, &currentShortestPath, &meetNode, &nodesPopped)
 	}
 
+	return joinPaths(fwdSearch, bwdSearch, source, target, meetNode, currentShortestPath, nodesPopped)
+}
+
+// CoreShortestPath finds the shortest path in a contraction hierarchy whose
+// vertices in core were left uncontracted. Both searches go upward as in
+// BiDirectionalDijkstraShortestPath until they reach the core and follow the
+// edges of core from there, the backward search weighing them in reverse. As
+// the core is not ordered by rank, each search only stops when its smallest
+// distance reaches the length of the best path found so far.
+func CoreShortestPath(upGraph, downGraph, core *graph.Graph, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	return (*Workspace)(nil).CoreShortestPath(upGraph, downGraph, core, source, target)
+}
+
+// CoreShortestPath runs CoreShortestPath with the search state kept in w.
+func (w *Workspace) CoreShortestPath(upGraph, downGraph, core *graph.Graph, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	_, sourceExists := upGraph.Vertices[source]
+	_, targetExists := upGraph.Vertices[target]
+	if !sourceExists || !targetExists {
+		return nil, 0, 0, ErrTargetNotReachable
+	}
+	if source == target {
+		return []graph.VertexId{source}, 0, 0, nil
+	}
+
+	w, pooled := w.acquire()
+	defer w.release(pooled)
+	fwdSearch, bwdSearch := &w.forward, &w.backward
+	fwdSearch.reset()
+	bwdSearch.reset()
+	fwdSearch.reach(source, 0, source)
+	bwdSearch.reach(target, 0, target)
+
+	currentShortestPath := math.Inf(1)
+	var meetNode graph.VertexId
+	nodesPopped := 0
+
+	for {
+		fwdMinDist, fwdOk := fwdSearch.min()
+		bwdMinDist, bwdOk := bwdSearch.min()
+		fwdOk = fwdOk && fwdMinDist < currentShortestPath
+		bwdOk = bwdOk && bwdMinDist < currentShortestPath
+		if !fwdOk && !bwdOk {
+			break
+		}
+
+		if fwdOk && (!bwdOk || fwdMinDist <= bwdMinDist) {
+			relax(fwdSearch, bwdSearch, upGraph, nil, core, &currentShortestPath, &meetNode, &nodesPopped)
+		} else {
+			relax(bwdSearch, fwdSearch, upGraph, downGraph, core, &currentShortestPath, &meetNode, &nodesPopped)
+		}
+	}
+
+	return joinPaths(fwdSearch, bwdSearch, source, target, meetNode, currentShortestPath, nodesPopped)
+}
+
+// joinPaths returns the path from source to target through meetNode found by
+// the forward and backward search.
+func joinPaths(fwdSearch, bwdSearch *searchSpace, source, target, meetNode graph.VertexId, currentShortestPath float64, nodesPopped int) ([]graph.VertexId, float64, int, error) {
 	if math.IsInf(currentShortestPath, 1) {
 		return nil, 0, nodesPopped, ErrTargetNotReachable
 	}
 
-	pathFwd, errFwd := buildPath(fwdSearch.preds, source, meetNode)
+	pathFwd, errFwd := fwdSearch.path(source, meetNode)
 	if errFwd != nil {
 		return nil, 0, nodesPopped, errFwd
 	}
 
-	pathBwdReversed, errBwd := buildPath(bwdSearch.preds, target, meetNode)
+	pathBwdReversed, errBwd := bwdSearch.path(target, meetNode)
 	if errBwd != nil {
 		return nil, 0, nodesPopped, errBwd
 	}
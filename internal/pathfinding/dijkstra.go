@@ -1,47 +1,52 @@
 package pathfinding
 
 import (
-	"container/heap"
 	"errors"
-	"math"
 
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
-	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
 )
 
 var ErrTargetNotReachable = errors.New("target vertex not reachable from source")
 
+// DijkstraShortestPath finds the shortest path from source to target that is
+// cheaper than bound. If ignoredNode is given, the search does not pass
+// through that vertex.
 func DijkstraShortestPath(g *graph.Graph, source, target graph.VertexId, bound float64, ignoredNode ...graph.VertexId) ([]graph.VertexId, float64, int, error) {
-	// Only track distances for visited nodes - huge optimization
-	distances := make(map[graph.VertexId]float64)
-	distances[source] = 0
-	bestPredecessors := make(map[graph.VertexId]graph.VertexId)
-
-	// Only add source to queue initially - major optimization
-	queue := collection.NewPriorityQueue[graph.VertexId]()
-	queue.PushWithPriority(source, 0)
+	var excluded *Exclusions
+	if len(ignoredNode) > 0 {
+		excluded = NewExclusions()
+		excluded.ExcludeVertex(ignoredNode[0])
+	}
+	return DijkstraShortestPathExcluding(g, source, target, bound, excluded)
+}
 
-	// Track visited nodes to avoid re-processing
-	visited := make(map[graph.VertexId]bool)
-	nodesPopped := 0
+// DijkstraShortestPathExcluding finds the shortest path from source to target
+// that is cheaper than bound and uses none of the excluded vertices and edges.
+// A nil excluded excludes nothing.
+func DijkstraShortestPathExcluding(g *graph.Graph, source, target graph.VertexId, bound float64, excluded *Exclusions) ([]graph.VertexId, float64, int, error) {
+	return (*Workspace)(nil).DijkstraShortestPathExcluding(g, source, target, bound, excluded)
+}
 
-	var nodeToIgnore graph.VertexId
-	ignoreIsSet := len(ignoredNode) > 0
-	if ignoreIsSet {
-		nodeToIgnore = ignoredNode[0]
+// DijkstraShortestPathExcluding runs DijkstraShortestPathExcluding with the
+// search state kept in w.
+func (w *Workspace) DijkstraShortestPathExcluding(g *graph.Graph, source, target graph.VertexId, bound float64, excluded *Exclusions) ([]graph.VertexId, float64, int, error) {
+	if source < 0 {
+		return nil, 0, 0, ErrTargetNotReachable
 	}
 
-	for queue.Len() > 0 {
-		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
-		nodesPopped++
-		vertex := queue.GetValue(item)
-		cost := queue.GetPriority(item)
+	w, pooled := w.acquire()
+	defer w.release(pooled)
+	search := &w.forward
+	search.reset()
+	search.reach(source, 0, source)
+	nodesPopped := 0
 
-		// If already visited with better cost, skip
-		if visited[vertex] {
-			continue
+	for {
+		vertex, cost, ok := search.pop()
+		if !ok {
+			break
 		}
-		visited[vertex] = true
+		nodesPopped++
 
 		// If the shortest distance to the current node already exceeds the bound,
 		// we know we can't find a path to the target within the limit.
@@ -49,24 +54,24 @@ func DijkstraShortestPath(g *graph.Graph, source, target graph.VertexId, bound f
 			break
 		}
 
-		if ignoreIsSet && vertex == nodeToIgnore {
+		if excluded.ExcludesVertex(vertex) {
 			continue
 		}
 
 		if vertex == target {
-			path, err := buildPath(bestPredecessors, source, target)
+			path, err := search.path(source, target)
 			if err != nil {
 				return nil, 0, nodesPopped, err
 			}
-			return path, distances[target], nodesPopped, nil
+			return path, cost, nodesPopped, nil
 		}
 
 		for adjacent, edge := range g.Edges[vertex] {
-			if ignoreIsSet && adjacent == nodeToIgnore {
+			if excluded.ExcludesVertex(adjacent) || excluded.ExcludesEdge(vertex, adjacent) {
 				continue
 			}
 
-			if visited[adjacent] {
+			if edge.Weight == graph.InfWeight {
 				continue
 			}
 
@@ -77,10 +82,8 @@ func DijkstraShortestPath(g *graph.Graph, source, target graph.VertexId, bound f
 				continue
 			}
 
-			if oldDist, exists := distances[adjacent]; !exists || newWeight < oldDist {
-				distances[adjacent] = newWeight
-				bestPredecessors[adjacent] = vertex
-				queue.PushWithPriority(adjacent, newWeight)
+			if newWeight < search.dist(adjacent) {
+				search.reach(adjacent, newWeight, vertex)
 			}
 		}
 	}
@@ -88,26 +91,48 @@ func DijkstraShortestPath(g *graph.Graph, source, target graph.VertexId, bound f
 	return nil, 0, nodesPopped, ErrTargetNotReachable
 }
 
+// WitnessLimits bounds the work of a witness search. A search that hits a
+// limit reports that there is no witness, which only costs a superfluous
+// shortcut. Zero values mean no limit.
+type WitnessLimits struct {
+	// MaxSettled is the number of vertices the search settles at most.
+	MaxSettled int
+	// MaxHops is the number of edges a witness has at most.
+	MaxHops int
+}
+
 // WitnessSearch is an optimized version for contraction hierarchies
 // Returns true if a witness path exists (path not using ignored node within bound)
 func WitnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64, ignoredNode graph.VertexId) bool {
-	distances := make(map[graph.VertexId]float64)
-	distances[source] = 0
-
-	queue := collection.NewPriorityQueue[graph.VertexId]()
-	queue.PushWithPriority(source, 0)
+	return (*Workspace)(nil).LimitedWitnessSearch(g, source, target, bound, ignoredNode, WitnessLimits{})
+}
 
-	visited := make(map[graph.VertexId]bool)
+// WitnessSearch runs WitnessSearch with the search state kept in w.
+func (w *Workspace) WitnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64, ignoredNode graph.VertexId) bool {
+	return w.LimitedWitnessSearch(g, source, target, bound, ignoredNode, WitnessLimits{})
+}
 
-	for queue.Len() > 0 {
-		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
-		vertex := queue.GetValue(item)
-		cost := queue.GetPriority(item)
+// LimitedWitnessSearch runs WitnessSearch within the given limits. The hop
+// limit applies to the shortest paths found by the search, so a longer path
+// with fewer hops is not considered.
+func (w *Workspace) LimitedWitnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64, ignoredNode graph.VertexId, limits WitnessLimits) bool {
+	if source < 0 {
+		return false
+	}
 
-		if visited[vertex] {
-			continue
+	w, pooled := w.acquire()
+	defer w.release(pooled)
+	search := &w.forward
+	search.reset()
+	search.reach(source, 0, source)
+	search.hops[source] = 0
+	settled := 0
+
+	for {
+		vertex, cost, ok := search.pop()
+		if !ok {
+			return false // No witness path found
 		}
-		visited[vertex] = true
 
 		// Stop if we exceed bound
 		if cost >= bound {
@@ -119,13 +144,18 @@ func WitnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64,
 			return true
 		}
 
-		for adjacent, edge := range g.Edges[vertex] {
-			// Skip ignored node
-			if adjacent == ignoredNode {
-				continue
-			}
+		settled++
+		if limits.MaxSettled > 0 && settled >= limits.MaxSettled {
+			return false
+		}
+		hops := search.hops[vertex] + 1
+		if limits.MaxHops > 0 && int(hops) > limits.MaxHops {
+			continue
+		}
 
-			if visited[adjacent] {
+		for adjacent, edge := range g.Edges[vertex] {
+			// Skip ignored node and closed edges
+			if adjacent == ignoredNode || edge.Weight == graph.InfWeight {
 				continue
 			}
 
@@ -136,34 +166,12 @@ func WitnessSearch(g *graph.Graph, source, target graph.VertexId, bound float64,
 				continue
 			}
 
-			if oldDist, exists := distances[adjacent]; !exists || newWeight < oldDist {
-				distances[adjacent] = newWeight
-				queue.PushWithPriority(adjacent, newWeight)
+			if newWeight < search.dist(adjacent) {
+				search.reach(adjacent, newWeight, vertex)
+				search.hops[adjacent] = hops
 			}
 		}
 	}
-
-	return false // No witness path found
-}
-
-func initializeWeights(g *graph.Graph, source graph.VertexId) map[graph.VertexId]float64 {
-	weights := make(map[graph.VertexId]float64)
-	for vertex := range g.Vertices {
-		if vertex == source {
-			weights[vertex] = 0
-		} else {
-			weights[vertex] = math.Inf(1)
-		}
-	}
-	return weights
-}
-
-func initializePriorityQueue(weights map[graph.VertexId]float64) *collection.PriorityQueue[graph.VertexId] {
-	queue := collection.NewPriorityQueue[graph.VertexId]()
-	for vertex, weight := range weights {
-		queue.PushWithPriority(vertex, weight)
-	}
-	return queue
 }
 
 func buildPath(predecessors map[graph.VertexId]graph.VertexId, source, target graph.VertexId) ([]graph.VertexId, error) {
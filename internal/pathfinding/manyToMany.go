@@ -0,0 +1,143 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"math"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+// bucketEntry stores the distance from a vertex to the target with the given index.
+type bucketEntry struct {
+	target   int
+	distance float64
+}
+
+// ManyToMany computes the shortest path distances from every source to every
+// target on a contraction hierarchy with the bucket algorithm. upGraph and
+// downGraph follow the conventions of BiDirectionalDijkstraShortestPath. A
+// complete backward search from every target stores its distances in buckets
+// at the visited vertices, then a complete forward search from every source
+// scans the buckets of the vertices it visits. Unreachable pairs have the
+// distance +Inf. The result is indexed by source, then by target.
+func ManyToMany(upGraph, downGraph *graph.Graph, sources, targets []graph.VertexId) [][]float64 {
+	buckets := make(map[graph.VertexId][]bucketEntry)
+	for i, target := range targets {
+		for v, distance := range UpwardSearch(upGraph, downGraph, target, nil) {
+			buckets[v] = append(buckets[v], bucketEntry{target: i, distance: distance})
+		}
+	}
+
+	matrix := make([][]float64, len(sources))
+	for i, source := range sources {
+		row := make([]float64, len(targets))
+		for j := range row {
+			row[j] = math.Inf(1)
+		}
+		for v, distance := range UpwardSearch(upGraph, nil, source, nil) {
+			for _, entry := range buckets[v] {
+				row[entry.target] = math.Min(row[entry.target], distance+entry.distance)
+			}
+		}
+		matrix[i] = row
+	}
+	return matrix
+}
+
+// UpwardSearch runs a Dijkstra search from start on g and returns the
+// distances of all reached vertices. If reverse is set, an edge (v, w) of g is
+// weighted with the edge (w, v) of reverse, as in searchContext. The edges of
+// settled vertices for which stop returns true are not relaxed; a nil stop
+// runs a complete search.
+func UpwardSearch(g, reverse *graph.Graph, start graph.VertexId, stop func(graph.VertexId) bool) map[graph.VertexId]float64 {
+	distances := make(map[graph.VertexId]float64)
+	if _, ok := g.Vertices[start]; !ok {
+		return distances
+	}
+	distances[start] = 0
+
+	queue := collection.NewPriorityQueue[graph.VertexId]()
+	queue.PushWithPriority(start, 0)
+	settled := make(map[graph.VertexId]bool)
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
+		vertex := queue.GetValue(item)
+		if settled[vertex] {
+			continue
+		}
+		settled[vertex] = true
+		cost := queue.GetPriority(item)
+		if stop != nil && stop(vertex) {
+			continue
+		}
+
+		for adjacent, edge := range g.Edges[vertex] {
+			weight := edge.Weight
+			if reverse != nil {
+				reverseEdge, ok := reverse.Edges[adjacent][vertex]
+				if !ok {
+					continue
+				}
+				weight = reverseEdge.Weight
+			}
+			if weight == graph.InfWeight || settled[adjacent] {
+				continue
+			}
+			newDistance := cost + float64(weight)
+			if oldDistance, ok := distances[adjacent]; !ok || newDistance < oldDistance {
+				distances[adjacent] = newDistance
+				queue.PushWithPriority(adjacent, newDistance)
+			}
+		}
+	}
+	return distances
+}
+
+// OneToMany computes the shortest path distances from source to every target
+// with a single Dijkstra search on g that stops once all targets are settled.
+// Unreachable targets have the distance +Inf.
+func OneToMany(g *graph.Graph, source graph.VertexId, targets []graph.VertexId) []float64 {
+	row := make([]float64, len(targets))
+	remaining := make(map[graph.VertexId][]int, len(targets))
+	for i, target := range targets {
+		row[i] = math.Inf(1)
+		remaining[target] = append(remaining[target], i)
+	}
+	if _, ok := g.Vertices[source]; !ok {
+		return row
+	}
+
+	distances := map[graph.VertexId]float64{source: 0}
+	queue := collection.NewPriorityQueue[graph.VertexId]()
+	queue.PushWithPriority(source, 0)
+	settled := make(map[graph.VertexId]bool)
+
+	for queue.Len() > 0 && len(remaining) > 0 {
+		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
+		vertex := queue.GetValue(item)
+		if settled[vertex] {
+			continue
+		}
+		settled[vertex] = true
+		cost := queue.GetPriority(item)
+
+		for _, i := range remaining[vertex] {
+			row[i] = cost
+		}
+		delete(remaining, vertex)
+
+		for adjacent, edge := range g.Edges[vertex] {
+			if edge.Weight == graph.InfWeight || settled[adjacent] {
+				continue
+			}
+			newDistance := cost + float64(edge.Weight)
+			if oldDistance, ok := distances[adjacent]; !ok || newDistance < oldDistance {
+				distances[adjacent] = newDistance
+				queue.PushWithPriority(adjacent, newDistance)
+			}
+		}
+	}
+	return row
+}
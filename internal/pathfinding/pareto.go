@@ -0,0 +1,127 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/criteria"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+// ErrTooManyLabels is returned when a Pareto search exceeds its label limit.
+var ErrTooManyLabels = errors.New("pareto search exceeded the label limit")
+
+// ParetoRoute is a route of a Pareto set together with its cost vector.
+type ParetoRoute struct {
+	Path  []graph.VertexId
+	Costs criteria.Vector
+}
+
+// label is a partial route ending at vertex. pred is the index of the label
+// it was extended from, or -1 for the label at the source.
+type label struct {
+	vertex    graph.VertexId
+	costs     criteria.Vector
+	pred      int
+	dominated bool
+}
+
+// ParetoShortestPaths returns all Pareto-optimal routes from source to target
+// with respect to the cost vectors of costs: no returned route is at least as
+// good as another one in every criterion. Routes with equal cost vectors are
+// reported once. The routes are sorted lexicographically by their costs.
+//
+// The search is a multi-criteria label-setting Dijkstra. Labels are settled in
+// the order of the sum of their costs, which is never smaller for a label than
+// for any label dominating it. maxLabels bounds the number of labels created;
+// zero means no limit. It returns the routes and the number of settled labels.
+func ParetoShortestPaths(g *graph.Graph, costs *criteria.Costs, source, target graph.VertexId, maxLabels int) ([]ParetoRoute, int, error) {
+	if _, ok := g.Vertices[source]; !ok {
+		return nil, 0, graph.ErrVertexNotFound
+	}
+
+	labels := []label{{vertex: source, costs: make(criteria.Vector, costs.Dim()), pred: -1}}
+	bags := map[graph.VertexId][]int{source: {0}}
+
+	queue := collection.NewPriorityQueue[int]()
+	queue.PushWithPriority(0, 0)
+	nodesPopped := 0
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[int])
+		current := queue.GetValue(item)
+		if labels[current].dominated {
+			continue
+		}
+		nodesPopped++
+
+		vertex := labels[current].vertex
+		if vertex == target {
+			continue
+		}
+
+		for adjacent := range g.Edges[vertex] {
+			edgeCosts, ok := costs.Vector(g, vertex, adjacent)
+			if !ok {
+				continue
+			}
+			candidate := labels[current].costs.Add(edgeCosts)
+
+			// Routes that are not better than a known route to the target
+			// in some criterion can be discarded right away.
+			if dominatedByBag(labels, bags[target], candidate) || dominatedByBag(labels, bags[adjacent], candidate) {
+				continue
+			}
+
+			bag := bags[adjacent][:0]
+			for _, other := range bags[adjacent] {
+				if candidate.Dominates(labels[other].costs) {
+					labels[other].dominated = true
+					continue
+				}
+				bag = append(bag, other)
+			}
+
+			if maxLabels > 0 && len(labels) >= maxLabels {
+				return nil, nodesPopped, ErrTooManyLabels
+			}
+			labels = append(labels, label{vertex: adjacent, costs: candidate, pred: current})
+			index := len(labels) - 1
+			bags[adjacent] = append(bag, index)
+			queue.PushWithPriority(index, float64(candidate.Sum()))
+		}
+	}
+
+	if source == target {
+		return []ParetoRoute{{Path: []graph.VertexId{source}, Costs: labels[0].costs}}, nodesPopped, nil
+	}
+	if len(bags[target]) == 0 {
+		return nil, nodesPopped, ErrTargetNotReachable
+	}
+
+	routes := make([]ParetoRoute, 0, len(bags[target]))
+	for _, index := range bags[target] {
+		var path []graph.VertexId
+		for i := index; i >= 0; i = labels[i].pred {
+			path = append(path, labels[i].vertex)
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		routes = append(routes, ParetoRoute{Path: path, Costs: labels[index].costs})
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Costs.Less(routes[j].Costs) })
+	return routes, nodesPopped, nil
+}
+
+// dominatedByBag reports whether one of the labels in bag dominates costs.
+func dominatedByBag(labels []label, bag []int, costs criteria.Vector) bool {
+	for _, index := range bag {
+		if labels[index].costs.Dominates(costs) {
+			return true
+		}
+	}
+	return false
+}
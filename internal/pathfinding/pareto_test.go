@@ -0,0 +1,107 @@
+package pathfinding
+
+import (
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/criteria"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createParetoGraph builds four routes from 0 to 3 with (time, distance) costs:
+// a fast one through 1, a short one through 2, a dominated one through 4 and
+// mixed ones using the connection between 1 and 2.
+func createParetoGraph(t *testing.T) (*graph.Graph, *criteria.Costs) {
+	t.Helper()
+	g := graph.NewGraph()
+	costs := criteria.NewCosts("time", "distance")
+	for i := 0; i <= 4; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	edges := [][4]int{{0, 1, 1, 5}, {1, 3, 1, 5}, {0, 2, 2, 2}, {2, 3, 2, 2}, {0, 4, 3, 6}, {4, 3, 2, 6}, {1, 2, 1, 1}}
+	for _, e := range edges {
+		for _, dir := range [][2]int{{e[0], e[1]}, {e[1], e[0]}} {
+			from, to := graph.VertexId(dir[0]), graph.VertexId(dir[1])
+			g.AddEdge(from, to, e[2], false, -1)
+			if err := costs.Set(from, to, criteria.Vector{e[2], e[3]}); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+		}
+	}
+	return g, costs
+}
+
+func TestParetoShortestPaths(t *testing.T) {
+	g, costs := createParetoGraph(t)
+
+	routes, _, err := ParetoShortestPaths(g, costs, 0, 3, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ParetoRoute{
+		{Path: []graph.VertexId{0, 1, 3}, Costs: criteria.Vector{2, 10}},
+		{Path: []graph.VertexId{0, 2, 3}, Costs: criteria.Vector{4, 4}},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("got %v, want %v", routes, want)
+	}
+
+	routes, _, err = ParetoShortestPaths(g, costs, 2, 2, 0)
+	if err != nil || len(routes) != 1 || routes[0].Costs.Sum() != 0 {
+		t.Errorf("got %v, %v for a query to the source itself", routes, err)
+	}
+
+	if _, _, err := ParetoShortestPaths(g, costs, 0, 3, 2); !errors.Is(err, ErrTooManyLabels) {
+		t.Errorf("expected ErrTooManyLabels, got %v", err)
+	}
+
+	g.AddVertex(graph.Vertex{Id: 5})
+	if _, _, err := ParetoShortestPaths(g, costs, 0, 5, 0); !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+}
+
+func TestParetoShortestPathsOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+	costs := criteria.DistanceCosts(g)
+	distances, err := costs.WeightedSum(g, []float64{0, 1})
+	if err != nil {
+		t.Fatalf("WeightedSum failed: %v", err)
+	}
+
+	for _, pair := range [][2]graph.VertexId{{1, 5}, {0, 42}, {17, 120}} {
+		routes, _, err := ParetoShortestPaths(g, costs, pair[0], pair[1], 0)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", pair, err)
+		}
+
+		for i, a := range routes {
+			if got := pathCost(g, a.Path); got != float64(a.Costs[0]) {
+				t.Errorf("%v: route %d costs %f, reported %d", pair, i, got, a.Costs[0])
+			}
+			for j, b := range routes {
+				if i != j && a.Costs.Dominates(b.Costs) {
+					t.Errorf("%v: route %v dominates route %v", pair, a.Costs, b.Costs)
+				}
+			}
+		}
+
+		// The extreme points of the Pareto set are the optima of the single criteria.
+		_, fastest, _, _ := DijkstraShortestPath(g, pair[0], pair[1], math.Inf(1))
+		_, shortest, _, _ := DijkstraShortestPath(distances, pair[0], pair[1], math.Inf(1))
+		if got := routes[0].Costs[0]; float64(got) != fastest {
+			t.Errorf("%v: fastest route in the Pareto set takes %d, want %f", pair, got, fastest)
+		}
+		if got := routes[len(routes)-1].Costs[1]; float64(got) != shortest {
+			t.Errorf("%v: shortest route in the Pareto set is %d long, want %f", pair, got, shortest)
+		}
+	}
+}
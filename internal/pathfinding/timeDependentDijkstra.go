@@ -0,0 +1,74 @@
+package pathfinding
+
+import (
+	"container/heap"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/ttf"
+)
+
+// TimeDependentDijkstraShortestPath computes the earliest arrival at target
+// when leaving source at departure. Edges with a profile in profiles are
+// traversed according to their travel time function, all other edges take
+// their static weight. It returns the path and the arrival time; the travel
+// time is the arrival time minus departure.
+//
+// The search is a plain Dijkstra on arrival times, which is exact because all
+// travel time functions satisfy the FIFO property.
+func TimeDependentDijkstraShortestPath(g *graph.Graph, profiles *ttf.Profiles, source, target graph.VertexId, departure float64) ([]graph.VertexId, float64, int, error) {
+	arrivals := make(map[graph.VertexId]float64)
+	arrivals[source] = departure
+	bestPredecessors := make(map[graph.VertexId]graph.VertexId)
+
+	queue := collection.NewPriorityQueue[graph.VertexId]()
+	queue.PushWithPriority(source, departure)
+
+	visited := make(map[graph.VertexId]bool)
+	nodesPopped := 0
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[graph.VertexId])
+		nodesPopped++
+		vertex := queue.GetValue(item)
+		arrival := queue.GetPriority(item)
+
+		if visited[vertex] {
+			continue
+		}
+		visited[vertex] = true
+
+		if vertex == target {
+			path, err := buildPath(bestPredecessors, source, target)
+			if err != nil {
+				return nil, 0, nodesPopped, err
+			}
+			return path, arrival, nodesPopped, nil
+		}
+
+		for adjacent, edge := range g.Edges[vertex] {
+			if visited[adjacent] || edge.Weight == graph.InfWeight {
+				continue
+			}
+
+			newArrival := arrival + TravelTime(profiles, vertex, adjacent, edge.Weight, arrival)
+			if oldArrival, exists := arrivals[adjacent]; !exists || newArrival < oldArrival {
+				arrivals[adjacent] = newArrival
+				bestPredecessors[adjacent] = vertex
+				queue.PushWithPriority(adjacent, newArrival)
+			}
+		}
+	}
+
+	return nil, 0, nodesPopped, ErrTargetNotReachable
+}
+
+// TravelTime returns the time it takes to traverse the edge from -> to when
+// entering it at departure: the value of its profile, or weight if the edge
+// has none.
+func TravelTime(profiles *ttf.Profiles, from, to graph.VertexId, weight int, departure float64) float64 {
+	if f, ok := profiles.Get(from, to); ok {
+		return f.Eval(departure)
+	}
+	return float64(weight)
+}
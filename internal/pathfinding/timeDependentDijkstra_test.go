@@ -0,0 +1,75 @@
+package pathfinding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/ttf"
+)
+
+// createRushHourNetwork builds a highway 0 -> 1 -> 2 that is congested
+// between 100 and 200 and a side road 0 -> 3 -> 2 with constant travel times.
+func createRushHourNetwork(t *testing.T) (*graph.Graph, *ttf.Profiles) {
+	t.Helper()
+	g := graph.NewGraph()
+	for i := 0; i < 4; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	edges := [][3]int{{0, 1, 10}, {1, 2, 10}, {0, 3, 15}, {3, 2, 15}}
+	for _, e := range edges {
+		g.AddEdge(graph.VertexId(e[0]), graph.VertexId(e[1]), e[2], false, -1)
+		g.AddEdge(graph.VertexId(e[1]), graph.VertexId(e[0]), e[2], false, -1)
+	}
+
+	rushHour, err := ttf.New(400, []ttf.Point{{At: 0, Duration: 10}, {At: 90, Duration: 10}, {At: 100, Duration: 60}, {At: 190, Duration: 60}, {At: 250, Duration: 10}})
+	if err != nil {
+		t.Fatalf("ttf.New failed: %v", err)
+	}
+	profiles := ttf.NewProfiles(400)
+	if err := profiles.Set(0, 1, rushHour); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	return g, profiles
+}
+
+func TestTimeDependentDijkstraShortestPath(t *testing.T) {
+	g, profiles := createRushHourNetwork(t)
+
+	testCases := []struct {
+		departure   float64
+		wantPath    []graph.VertexId
+		wantArrival float64
+	}{
+		{0, []graph.VertexId{0, 1, 2}, 20},
+		{85, []graph.VertexId{0, 1, 2}, 105},
+		{95, []graph.VertexId{0, 3, 2}, 125},
+		{150, []graph.VertexId{0, 3, 2}, 180},
+		// The profile repeats every 400.
+		{400, []graph.VertexId{0, 1, 2}, 420},
+	}
+	for _, tc := range testCases {
+		path, arrival, _, err := TimeDependentDijkstraShortestPath(g, profiles, 0, 2, tc.departure)
+		if err != nil {
+			t.Fatalf("departure %f: unexpected error: %v", tc.departure, err)
+		}
+		if arrival != tc.wantArrival {
+			t.Errorf("departure %f: got arrival %f, want %f", tc.departure, arrival, tc.wantArrival)
+		}
+		if !reflect.DeepEqual(path, tc.wantPath) {
+			t.Errorf("departure %f: got path %v, want %v", tc.departure, path, tc.wantPath)
+		}
+	}
+
+	// Without profiles all edges keep their static weight.
+	_, arrival, _, err := TimeDependentDijkstraShortestPath(g, nil, 0, 2, 150)
+	if err != nil || arrival != 170 {
+		t.Errorf("got arrival %f and error %v without profiles, want 170", arrival, err)
+	}
+
+	g.AddVertex(graph.Vertex{Id: 4})
+	if _, _, _, err := TimeDependentDijkstraShortestPath(g, profiles, 0, 4, 0); !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package pathfinding
+
+import (
+	"container/heap"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+// TurnCostFunc returns the cost of turning from the edge from -> via onto the
+// edge via -> to and whether that turn is allowed. turns.Table.Cost has this signature.
+type TurnCostFunc func(from, via, to graph.VertexId) (int, bool)
+
+// arc is a directed edge and the search state of the turn aware Dijkstra.
+type arc struct {
+	from graph.VertexId
+	to   graph.VertexId
+}
+
+// TurnAwareDijkstraShortestPath finds the shortest path from source to target
+// that respects the turn costs and forbidden turns of turnCost. The search runs
+// on the edges of g instead of its vertices, so a vertex may be passed more
+// than once, e.g. to replace a forbidden left turn by three right turns.
+func TurnAwareDijkstraShortestPath(g *graph.Graph, turnCost TurnCostFunc, source, target graph.VertexId) ([]graph.VertexId, float64, int, error) {
+	if source == target {
+		return []graph.VertexId{source}, 0, 0, nil
+	}
+
+	distances := make(map[arc]float64)
+	predecessors := make(map[arc]arc)
+	visited := make(map[arc]bool)
+	queue := collection.NewPriorityQueue[arc]()
+	nodesPopped := 0
+
+	for next, edge := range g.Edges[source] {
+		if edge.Weight == graph.InfWeight {
+			continue
+		}
+		start := arc{source, next}
+		distances[start] = float64(edge.Weight)
+		queue.PushWithPriority(start, float64(edge.Weight))
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*collection.Item[arc])
+		nodesPopped++
+		current := queue.GetValue(item)
+		cost := queue.GetPriority(item)
+
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		if current.to == target {
+			return buildArcPath(predecessors, current), cost, nodesPopped, nil
+		}
+
+		for next, edge := range g.Edges[current.to] {
+			if edge.Weight == graph.InfWeight {
+				continue
+			}
+			following := arc{current.to, next}
+			if visited[following] {
+				continue
+			}
+			penalty, allowed := turnCost(current.from, current.to, next)
+			if !allowed {
+				continue
+			}
+
+			newWeight := cost + float64(edge.Weight) + float64(penalty)
+			if oldDist, exists := distances[following]; !exists || newWeight < oldDist {
+				distances[following] = newWeight
+				predecessors[following] = current
+				queue.PushWithPriority(following, newWeight)
+			}
+		}
+	}
+
+	return nil, 0, nodesPopped, ErrTargetNotReachable
+}
+
+// buildArcPath follows the predecessors from last back to an arc leaving the
+// source and returns the visited vertices in order.
+func buildArcPath(predecessors map[arc]arc, last arc) []graph.VertexId {
+	path := []graph.VertexId{last.to}
+	current := last
+	for {
+		path = append(path, current.from)
+		prev, exists := predecessors[current]
+		if !exists {
+			break
+		}
+		current = prev
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
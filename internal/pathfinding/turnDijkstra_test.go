@@ -0,0 +1,104 @@
+package pathfinding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createIntersectionGraph builds a crossing at vertex 4 with the arms 1 (north),
+// 3 (west), 5 (east) and 7 (south). The north and west arms are also connected
+// by a slow road through 0.
+//
+//	0 --3-- 1
+//	|       |
+//	3       2
+//	|       |
+//	3 --1-- 4 --1-- 5
+//	        |
+//	        1
+//	        |
+//	        7
+func createIntersectionGraph() *graph.Graph {
+	g := graph.NewGraph()
+	for _, id := range []graph.VertexId{0, 1, 3, 4, 5, 7} {
+		g.AddVertex(graph.Vertex{Id: id})
+	}
+	edges := [][3]int{{0, 1, 3}, {0, 3, 3}, {1, 4, 2}, {3, 4, 1}, {4, 5, 1}, {4, 7, 1}}
+	for _, e := range edges {
+		g.AddEdge(graph.VertexId(e[0]), graph.VertexId(e[1]), e[2], false, -1)
+		g.AddEdge(graph.VertexId(e[1]), graph.VertexId(e[0]), e[2], false, -1)
+	}
+	return g
+}
+
+// turnRules is a minimal TurnCostFunc for the tests of this package.
+type turnRules struct {
+	forbidden    map[[3]graph.VertexId]bool
+	costs        map[[3]graph.VertexId]int
+	uTurnCost    int
+	forbidUTurns bool
+}
+
+func (r turnRules) cost(from, via, to graph.VertexId) (int, bool) {
+	turn := [3]graph.VertexId{from, via, to}
+	if r.forbidden[turn] || (from == to && r.forbidUTurns) {
+		return 0, false
+	}
+	if from == to {
+		return r.uTurnCost, true
+	}
+	return r.costs[turn], true
+}
+
+func TestTurnAwareDijkstraShortestPath(t *testing.T) {
+	noLeftTurn := map[[3]graph.VertexId]bool{{7, 4, 3}: true}
+
+	testCases := []struct {
+		name     string
+		rules    turnRules
+		wantPath []graph.VertexId
+		wantCost float64
+	}{
+		{"no restrictions", turnRules{}, []graph.VertexId{7, 4, 3}, 2},
+		{"turn cost", turnRules{costs: map[[3]graph.VertexId]int{{7, 4, 3}: 5}}, []graph.VertexId{7, 4, 5, 4, 3}, 4},
+		{"no left turn", turnRules{forbidden: noLeftTurn}, []graph.VertexId{7, 4, 5, 4, 3}, 4},
+		{"no left turn with U-turn cost", turnRules{forbidden: noLeftTurn, uTurnCost: 2}, []graph.VertexId{7, 4, 5, 4, 3}, 6},
+		{"no left turn and no U-turns", turnRules{forbidden: noLeftTurn, forbidUTurns: true}, []graph.VertexId{7, 4, 1, 0, 3}, 9},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, cost, _, err := TurnAwareDijkstraShortestPath(createIntersectionGraph(), tc.rules.cost, 7, 3)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cost != tc.wantCost {
+				t.Errorf("got cost %f, want %f", cost, tc.wantCost)
+			}
+			if !reflect.DeepEqual(path, tc.wantPath) {
+				t.Errorf("got path %v, want %v", path, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestTurnAwareDijkstraUnreachable(t *testing.T) {
+	// Leaving the dead end 5 requires a U-turn at 4 or a turn onto another arm;
+	// forbidding all of them makes every other vertex unreachable.
+	rules := turnRules{
+		forbidden:    map[[3]graph.VertexId]bool{{5, 4, 1}: true, {5, 4, 3}: true, {5, 4, 7}: true},
+		forbidUTurns: true,
+	}
+	_, _, _, err := TurnAwareDijkstraShortestPath(createIntersectionGraph(), rules.cost, 5, 3)
+	if !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+
+	path, cost, _, err := TurnAwareDijkstraShortestPath(createIntersectionGraph(), rules.cost, 5, 5)
+	if err != nil || cost != 0 || !reflect.DeepEqual(path, []graph.VertexId{5}) {
+		t.Errorf("got %v, %f, %v for a query to the source itself", path, cost, err)
+	}
+}
@@ -0,0 +1,180 @@
+package pathfinding
+
+import (
+	"math"
+	"slices"
+	"sync"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
+)
+
+// Workspace holds the state of a query in dense arrays indexed by vertex id,
+// so it can be reused across queries without reinitializing anything. Every
+// query starts a new round, and an entry only counts if it was written in the
+// current round, which resets the arrays in constant time. The arrays grow to
+// the largest vertex id seen, so a query costs time in the size of its search
+// space only. The priority queue of the searches is picked when the workspace
+// is created. A Workspace must not be used by two goroutines at once; a nil
+// Workspace takes one from a shared pool for the duration of the query.
+type Workspace struct {
+	forward  searchSpace
+	backward searchSpace
+}
+
+// NewWorkspace creates a workspace for graphs with vertex ids below
+// numVertices whose searches use queues of the given kind. Larger ids are
+// handled by growing the arrays. The radix heap and the bucket queue require
+// integer edge weights.
+func NewWorkspace(numVertices int, kind queue.Kind) *Workspace {
+	w := &Workspace{
+		forward:  searchSpace{queue: queue.New(kind)},
+		backward: searchSpace{queue: queue.New(kind)},
+	}
+	if numVertices > 0 {
+		w.forward.grow(graph.VertexId(numVertices - 1))
+		w.backward.grow(graph.VertexId(numVertices - 1))
+	}
+	return w
+}
+
+// WorkspacePool hands out workspaces to concurrent queries, e.g. of HTTP
+// handlers.
+type WorkspacePool struct {
+	pool sync.Pool
+}
+
+// NewWorkspacePool creates a pool of workspaces as created by NewWorkspace.
+func NewWorkspacePool(numVertices int, kind queue.Kind) *WorkspacePool {
+	return &WorkspacePool{pool: sync.Pool{New: func() any { return NewWorkspace(numVertices, kind) }}}
+}
+
+// Get takes a workspace from the pool, creating one if the pool is empty.
+func (p *WorkspacePool) Get() *Workspace {
+	return p.pool.Get().(*Workspace)
+}
+
+// Put returns a workspace to the pool. It must not be used afterwards.
+func (p *WorkspacePool) Put(w *Workspace) {
+	p.pool.Put(w)
+}
+
+// sharedPools holds one pool per queue kind for the whole program.
+var sharedPools = func() map[queue.Kind]*WorkspacePool {
+	pools := make(map[queue.Kind]*WorkspacePool)
+	for _, kind := range queue.Kinds() {
+		pools[kind] = NewWorkspacePool(0, kind)
+	}
+	return pools
+}()
+
+// SharedWorkspacePool returns a pool of workspaces with queues of the given
+// kind that is shared by all callers.
+func SharedWorkspacePool(kind queue.Kind) *WorkspacePool {
+	if pool, ok := sharedPools[kind]; ok {
+		return pool
+	}
+	return sharedPools[queue.FourAry]
+}
+
+// workspaces backs the query functions that take no workspace.
+var workspaces = SharedWorkspacePool(queue.FourAry)
+
+// acquire returns w, or a workspace from the shared pool if w is nil. pooled
+// reports whether the workspace has to be returned with release.
+func (w *Workspace) acquire() (acquired *Workspace, pooled bool) {
+	if w != nil {
+		return w, false
+	}
+	return workspaces.Get(), true
+}
+
+func (w *Workspace) release(pooled bool) {
+	if pooled {
+		workspaces.Put(w)
+	}
+}
+
+// searchSpace is the state of one search direction. The queue has no
+// decrease-key: a vertex is pushed again whenever its distance improves and
+// stale entries are skipped when they reach the top.
+type searchSpace struct {
+	dists  []float64
+	preds  []graph.VertexId
+	hops   []int32
+	stamps []uint32
+	round  uint32
+	queue  queue.Queue
+}
+
+// reset starts a new round, which forgets all distances and empties the queue.
+func (s *searchSpace) reset() {
+	s.round++
+	if s.round == 0 {
+		clear(s.stamps)
+		s.round = 1
+	}
+	s.queue.Reset()
+}
+
+func (s *searchSpace) grow(v graph.VertexId) {
+	if int(v) < len(s.stamps) {
+		return
+	}
+	n := max(int(v)+1, 2*len(s.stamps))
+	s.dists = append(s.dists, make([]float64, n-len(s.dists))...)
+	s.preds = append(s.preds, make([]graph.VertexId, n-len(s.preds))...)
+	s.hops = append(s.hops, make([]int32, n-len(s.hops))...)
+	s.stamps = append(s.stamps, make([]uint32, n-len(s.stamps))...)
+}
+
+// dist returns the tentative distance of v in the current round.
+func (s *searchSpace) dist(v graph.VertexId) float64 {
+	if v < 0 || int(v) >= len(s.stamps) || s.stamps[v] != s.round {
+		return math.Inf(1)
+	}
+	return s.dists[v]
+}
+
+// reach records the tentative distance d of v over pred and queues v.
+func (s *searchSpace) reach(v graph.VertexId, d float64, pred graph.VertexId) {
+	s.grow(v)
+	s.stamps[v] = s.round
+	s.dists[v] = d
+	s.preds[v] = pred
+	s.queue.Push(int(v), d)
+}
+
+// min returns the smallest distance in the queue, dropping stale entries.
+func (s *searchSpace) min() (float64, bool) {
+	for s.queue.Len() > 0 {
+		if v, d := s.queue.Min(); d == s.dists[v] {
+			return d, true
+		}
+		s.queue.Pop()
+	}
+	return math.Inf(1), false
+}
+
+// pop removes and returns the closest queued vertex.
+func (s *searchSpace) pop() (graph.VertexId, float64, bool) {
+	if _, ok := s.min(); !ok {
+		return 0, 0, false
+	}
+	v, d := s.queue.Pop()
+	return graph.VertexId(v), d, true
+}
+
+// path follows the predecessors from target back to source.
+func (s *searchSpace) path(source, target graph.VertexId) ([]graph.VertexId, error) {
+	path := []graph.VertexId{target}
+	for current := target; current != source; {
+		if s.dist(current) == math.Inf(1) {
+			return nil, ErrTargetNotReachable
+		}
+		current = s.preds[current]
+		path = append(path, current)
+	}
+	slices.Reverse(path)
+	return path, nil
+}
@@ -0,0 +1,128 @@
+package pathfinding
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
+)
+
+func TestWorkspaceReuse(t *testing.T) {
+	g := createTestGraph()
+	for _, kind := range queue.Kinds() {
+		t.Run(kind.String(), func(t *testing.T) {
+			w := NewWorkspace(2, kind)
+
+			// Distances of an earlier query must not leak into the next one.
+			for i := 0; i < 3; i++ {
+				path, weight, _, err := w.DijkstraShortestPathExcluding(g, 0, 3, math.Inf(1), nil)
+				if err != nil || weight != 5 || !reflect.DeepEqual(path, []graph.VertexId{0, 1, 3}) {
+					t.Errorf("Dijkstra 0 -> 3 = %v, %v, %v, want [0 1 3], 5", path, weight, err)
+				}
+				path, weight, _, err = w.BiDirectionalDijkstraShortestPath(g, g, 3, 2)
+				if err != nil || weight != 6 || !reflect.DeepEqual(path, []graph.VertexId{3, 1, 0, 2}) {
+					t.Errorf("bidirectional 3 -> 2 = %v, %v, %v, want [3 1 0 2], 6", path, weight, err)
+				}
+				if _, _, _, err := w.DijkstraShortestPathExcluding(g, 0, 99, math.Inf(1), nil); !errors.Is(err, ErrTargetNotReachable) {
+					t.Errorf("expected ErrTargetNotReachable, got %v", err)
+				}
+				if !w.WitnessSearch(g, 0, 3, 6, 2) || w.WitnessSearch(g, 0, 3, 5, 2) {
+					t.Errorf("witness 0 -> 3 avoiding 2 has length 5")
+				}
+			}
+		})
+	}
+}
+
+func TestWorkspaceRoundOverflow(t *testing.T) {
+	g := createTestGraph()
+	w := NewWorkspace(len(g.Vertices), queue.FourAry)
+	w.DijkstraShortestPathExcluding(g, 0, 3, math.Inf(1), nil)
+
+	// Entries written right before the counter wraps must not count as
+	// written in the first round after it.
+	w.forward.round = math.MaxUint32 - 1
+	w.DijkstraShortestPathExcluding(g, 0, 3, math.Inf(1), nil)
+	_, weight, _, err := w.DijkstraShortestPathExcluding(g, 1, 2, math.Inf(1), nil)
+	if err != nil || weight != 3 {
+		t.Errorf("Dijkstra 1 -> 2 = %v, %v, want 3", weight, err)
+	}
+	if w.forward.round != 1 {
+		t.Errorf("round = %d after overflow, want 1", w.forward.round)
+	}
+}
+
+func TestWorkspaceAllocations(t *testing.T) {
+	g := createTestGraph()
+	pool := NewWorkspacePool(len(g.Vertices), queue.FourAry)
+	w := pool.Get()
+	defer pool.Put(w)
+
+	// Warm up the queue so only the returned path is allocated.
+	w.BiDirectionalDijkstraShortestPath(g, g, 0, 3)
+	allocs := testing.AllocsPerRun(100, func() {
+		w.WitnessSearch(g, 0, 3, math.Inf(1), 2)
+	})
+	if allocs != 0 {
+		t.Errorf("WitnessSearch allocated %v times per run, want 0", allocs)
+	}
+}
+
+// BenchmarkDijkstraQueues compares the queues on random Dijkstra queries on
+// the road networks.
+func BenchmarkDijkstraQueues(b *testing.B) {
+	for _, name := range []string{"osm1.txt", "osm3.txt", "osm5.txt"} {
+		network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), name)
+		if err != nil {
+			b.Skipf("failed to load %s: %v", name, err)
+		}
+		g := network.Network
+		vertices := make([]graph.VertexId, 0, len(g.Vertices))
+		for v := range g.Vertices {
+			vertices = append(vertices, v)
+		}
+		slices.Sort(vertices)
+
+		for _, kind := range queue.Kinds() {
+			b.Run(strings.TrimSuffix(name, ".txt")+"/"+kind.String(), func(b *testing.B) {
+				w := NewWorkspace(len(vertices), kind)
+				r := rand.New(rand.NewSource(1))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					s, t := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+					w.DijkstraShortestPathExcluding(g, s, t, math.Inf(1), nil)
+				}
+			})
+		}
+	}
+}
+
+func TestLimitedWitnessSearch(t *testing.T) {
+	g := createTestGraph()
+	w := NewWorkspace(len(g.Vertices), queue.FourAry)
+
+	// The only witness 0 -> 3 avoiding 2 is 0 -> 1 -> 3 with two hops.
+	tests := []struct {
+		limits WitnessLimits
+		want   bool
+	}{
+		{WitnessLimits{}, true},
+		{WitnessLimits{MaxHops: 1}, false},
+		{WitnessLimits{MaxHops: 2}, true},
+		{WitnessLimits{MaxSettled: 1}, false},
+		{WitnessLimits{MaxSettled: 3}, true},
+	}
+	for _, tt := range tests {
+		if got := w.LimitedWitnessSearch(g, 0, 3, 6, 2, tt.limits); got != tt.want {
+			t.Errorf("LimitedWitnessSearch with %+v = %v, want %v", tt.limits, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package pathfinding
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sort"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrInvalidK is returned by KShortestPaths for k < 1.
+var ErrInvalidK = errors.New("k must be at least 1")
+
+// Exclusions is a set of vertices and directed edges a search must not use.
+// All methods can be called on a nil *Exclusions, which excludes nothing.
+type Exclusions struct {
+	vertices map[graph.VertexId]bool
+	edges    map[graph.VertexId]map[graph.VertexId]bool
+}
+
+// NewExclusions returns an empty set of exclusions.
+func NewExclusions() *Exclusions {
+	return &Exclusions{
+		vertices: make(map[graph.VertexId]bool),
+		edges:    make(map[graph.VertexId]map[graph.VertexId]bool),
+	}
+}
+
+// ExcludeVertex excludes v and therefore all edges incident to it.
+func (e *Exclusions) ExcludeVertex(v graph.VertexId) {
+	e.vertices[v] = true
+}
+
+// ExcludeEdge excludes the directed edge from -> to.
+func (e *Exclusions) ExcludeEdge(from, to graph.VertexId) {
+	if e.edges[from] == nil {
+		e.edges[from] = make(map[graph.VertexId]bool)
+	}
+	e.edges[from][to] = true
+}
+
+// ExcludesVertex reports whether v is excluded.
+func (e *Exclusions) ExcludesVertex(v graph.VertexId) bool {
+	return e != nil && e.vertices[v]
+}
+
+// ExcludesEdge reports whether the edge from -> to is excluded, either itself
+// or through one of its end points.
+func (e *Exclusions) ExcludesEdge(from, to graph.VertexId) bool {
+	return e != nil && (e.edges[from][to] || e.vertices[from] || e.vertices[to])
+}
+
+// Allows reports whether path uses none of the excluded vertices and edges.
+func (e *Exclusions) Allows(path []graph.VertexId) bool {
+	for i, v := range path {
+		if e.ExcludesVertex(v) || (i > 0 && e.ExcludesEdge(path[i-1], v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// SpurOracle computes a shortest path from source to target that avoids the
+// excluded vertices and edges. It returns ErrTargetNotReachable if there is none.
+// Its costs must be measured with the edge weights of the graph passed to
+// KShortestPaths.
+type SpurOracle func(source, target graph.VertexId, excluded *Exclusions) ([]graph.VertexId, float64, error)
+
+// DijkstraOracle answers spur queries with Dijkstra on g.
+func DijkstraOracle(g *graph.Graph) SpurOracle {
+	return func(source, target graph.VertexId, excluded *Exclusions) ([]graph.VertexId, float64, error) {
+		path, cost, _, err := DijkstraShortestPathExcluding(g, source, target, math.Inf(1), excluded)
+		return path, cost, err
+	}
+}
+
+// WeightedPath is a path together with its total weight.
+type WeightedPath struct {
+	Path []graph.VertexId
+	Cost float64
+}
+
+// KShortestPaths returns up to k shortest loopless paths from source to target
+// in g in order of increasing cost, using Yen's algorithm. Every spur path is
+// computed by oracle, which defaults to DijkstraOracle(g) if nil. The cost of
+// a candidate is the weight of its root path in g plus the spur cost of the
+// oracle, so the oracle must use the weights of g. Fewer than k paths are
+// returned if there are no more loopless paths.
+func KShortestPaths(g *graph.Graph, source, target graph.VertexId, k int, oracle SpurOracle) ([]WeightedPath, error) {
+	if k < 1 {
+		return nil, ErrInvalidK
+	}
+	if oracle == nil {
+		oracle = DijkstraOracle(g)
+	}
+
+	path, cost, err := oracle(source, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	accepted := []WeightedPath{{Path: path, Cost: cost}}
+	var candidates []WeightedPath
+	seen := map[string]bool{pathKey(path): true}
+
+	for len(accepted) < k {
+		previous := accepted[len(accepted)-1].Path
+		rootCost := 0.0
+		for j := 0; j < len(previous)-1; j++ {
+			spurNode, rootPath := previous[j], previous[:j+1]
+
+			excluded := NewExclusions()
+			for _, p := range accepted {
+				if len(p.Path) > j+1 && slices.Equal(p.Path[:j+1], rootPath) {
+					excluded.ExcludeEdge(p.Path[j], p.Path[j+1])
+				}
+			}
+			for _, v := range rootPath[:j] {
+				excluded.ExcludeVertex(v)
+			}
+
+			spurPath, spurCost, err := oracle(spurNode, target, excluded)
+			if err == nil {
+				candidate := append(slices.Clone(rootPath), spurPath[1:]...)
+				if key := pathKey(candidate); !seen[key] {
+					seen[key] = true
+					candidates = append(candidates, WeightedPath{Path: candidate, Cost: rootCost + spurCost})
+				}
+			} else if !errors.Is(err, ErrTargetNotReachable) {
+				return nil, err
+			}
+
+			rootCost += float64(g.Edges[previous[j]][previous[j+1]].Weight)
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].Cost < candidates[b].Cost })
+		accepted = append(accepted, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return accepted, nil
+}
+
+// pathKey returns a string identifying a path, used to skip duplicate candidates.
+func pathKey(path []graph.VertexId) string {
+	key := make([]byte, 0, 4*len(path))
+	for _, v := range path {
+		key = append(key, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+	}
+	return string(key)
+}
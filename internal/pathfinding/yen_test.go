@@ -0,0 +1,139 @@
+package pathfinding
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createYenGraph builds the directed example graph of Yen's algorithm with
+// the vertices C=0, D=1, E=2, F=3, G=4 and H=5.
+func createYenGraph() *graph.Graph {
+	g := graph.NewGraph()
+	for i := 0; i < 6; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	edges := [][3]int{{0, 1, 3}, {0, 2, 2}, {1, 3, 4}, {2, 1, 1}, {2, 3, 2}, {2, 4, 3}, {3, 4, 2}, {3, 5, 1}, {4, 5, 2}}
+	for _, e := range edges {
+		g.AddEdge(graph.VertexId(e[0]), graph.VertexId(e[1]), e[2], false, -1)
+	}
+	return g
+}
+
+// allSimplePathCosts enumerates the costs of all loopless paths from source to target.
+func allSimplePathCosts(g *graph.Graph, source, target graph.VertexId) []float64 {
+	var costs []float64
+	onPath := map[graph.VertexId]bool{source: true}
+	var visit func(v graph.VertexId, cost float64)
+	visit = func(v graph.VertexId, cost float64) {
+		if v == target {
+			costs = append(costs, cost)
+			return
+		}
+		for w, edge := range g.Edges[v] {
+			if !onPath[w] {
+				onPath[w] = true
+				visit(w, cost+float64(edge.Weight))
+				onPath[w] = false
+			}
+		}
+	}
+	visit(source, 0)
+	sort.Float64s(costs)
+	return costs
+}
+
+func assertLooplessPaths(t *testing.T, g *graph.Graph, paths []WeightedPath, source, target graph.VertexId) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if p.Path[0] != source || p.Path[len(p.Path)-1] != target {
+			t.Errorf("path %v does not lead from %d to %d", p.Path, source, target)
+		}
+		visited := make(map[graph.VertexId]bool)
+		cost := 0.0
+		for i, v := range p.Path {
+			if visited[v] {
+				t.Errorf("path %v visits %d twice", p.Path, v)
+			}
+			visited[v] = true
+			if i > 0 {
+				cost += float64(g.Edges[p.Path[i-1]][v].Weight)
+			}
+		}
+		if cost != p.Cost {
+			t.Errorf("path %v has cost %f, reported %f", p.Path, cost, p.Cost)
+		}
+		if seen[pathKey(p.Path)] {
+			t.Errorf("path %v is returned twice", p.Path)
+		}
+		seen[pathKey(p.Path)] = true
+	}
+}
+
+func TestKShortestPaths(t *testing.T) {
+	g := createYenGraph()
+
+	paths, err := KShortestPaths(g, 0, 5, 3, nil)
+	if err != nil {
+		t.Fatalf("KShortestPaths failed: %v", err)
+	}
+	assertLooplessPaths(t, g, paths, 0, 5)
+	if want := []graph.VertexId{0, 2, 3, 5}; !reflect.DeepEqual(paths[0].Path, want) {
+		t.Errorf("got shortest path %v want %v", paths[0].Path, want)
+	}
+	if want := []graph.VertexId{0, 2, 4, 5}; !reflect.DeepEqual(paths[1].Path, want) {
+		t.Errorf("got second path %v want %v", paths[1].Path, want)
+	}
+
+	// Asking for more paths than exist returns all loopless paths.
+	paths, err = KShortestPaths(g, 0, 5, 100, nil)
+	if err != nil {
+		t.Fatalf("KShortestPaths failed: %v", err)
+	}
+	assertLooplessPaths(t, g, paths, 0, 5)
+	costs := make([]float64, len(paths))
+	for i, p := range paths {
+		costs[i] = p.Cost
+	}
+	if want := allSimplePathCosts(g, 0, 5); !reflect.DeepEqual(costs, want) {
+		t.Errorf("got costs %v want %v", costs, want)
+	}
+}
+
+func TestKShortestPathsErrors(t *testing.T) {
+	g := createYenGraph()
+	if _, err := KShortestPaths(g, 0, 5, 0, nil); !errors.Is(err, ErrInvalidK) {
+		t.Errorf("got %v want %v", err, ErrInvalidK)
+	}
+	if _, err := KShortestPaths(g, 5, 0, 2, nil); !errors.Is(err, ErrTargetNotReachable) {
+		t.Errorf("got %v want %v", err, ErrTargetNotReachable)
+	}
+}
+
+func TestDijkstraShortestPathExcluding(t *testing.T) {
+	g := createYenGraph()
+
+	excluded := NewExclusions()
+	excluded.ExcludeEdge(2, 3)
+	excluded.ExcludeVertex(4)
+	path, cost, _, err := DijkstraShortestPathExcluding(g, 0, 5, math.Inf(1), excluded)
+	if err != nil {
+		t.Fatalf("DijkstraShortestPathExcluding failed: %v", err)
+	}
+	if want := []graph.VertexId{0, 1, 3, 5}; !reflect.DeepEqual(path, want) || cost != 8 {
+		t.Errorf("got %v with cost %f, want %v with cost 8", path, cost, want)
+	}
+	if !excluded.Allows(path) || excluded.Allows([]graph.VertexId{0, 2, 3}) {
+		t.Error("Allows does not match the exclusions")
+	}
+
+	var none *Exclusions
+	if none.ExcludesVertex(0) || none.ExcludesEdge(0, 1) || !none.Allows([]graph.VertexId{0, 1}) {
+		t.Error("a nil *Exclusions should exclude nothing")
+	}
+}
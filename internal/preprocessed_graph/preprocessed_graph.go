@@ -1,12 +1,15 @@
 package preprocessed_graph
 
 import (
+	"cmp"
 	"encoding/gob"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
 	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
 
@@ -165,6 +168,7 @@ type PreprocessedCHFile struct {
 	ContractionOrder []int64
 	UpwardsGraph     *GraphData
 	DownwardsGraph   *GraphData
+	Core             *GraphData // Uncontracted vertices of a core CH, nil otherwise
 }
 
 // FromCH converts a ch.ContractionHierarchies object into a serializable PreprocessedCHFile struct.
@@ -176,14 +180,24 @@ func FromCH(ch *ch.ContractionHierarchies) *PreprocessedCHFile {
 		p.ContractionOrder = append(p.ContractionOrder, int64(id))
 	}
 
-	// Upwards Graph
-	ug := &GraphData{}
-	for _, v := range ch.UpwardsGraph.Vertices {
-		ug.Vertices = append(ug.Vertices, ParquetVertex{ID: int64(v.Id), Lat: v.Lat, Lon: v.Lon})
+	p.UpwardsGraph = newGraphData(ch.UpwardsGraph)
+	p.DownwardsGraph = newGraphData(ch.DownwardsGraph)
+	if ch.Core != nil {
+		p.Core = newGraphData(ch.Core)
 	}
-	for u, edges := range ch.UpwardsGraph.Edges {
+
+	return p
+}
+
+// newGraphData converts g into its serializable representation.
+func newGraphData(g *graph.Graph) *GraphData {
+	d := &GraphData{}
+	for _, v := range g.Vertices {
+		d.Vertices = append(d.Vertices, ParquetVertex{ID: int64(v.Id), Lat: v.Lat, Lon: v.Lon})
+	}
+	for u, edges := range g.Edges {
 		for v, edge := range edges {
-			ug.Edges = append(ug.Edges, ParquetEdge{
+			d.Edges = append(d.Edges, ParquetEdge{
 				Source: int64(u),
 				Target: int64(v),
 				Weight: int64(edge.Weight),
@@ -191,26 +205,27 @@ func FromCH(ch *ch.ContractionHierarchies) *PreprocessedCHFile {
 			})
 		}
 	}
-	p.UpwardsGraph = ug
+	sortGraphData(d)
+	return d
+}
 
-	// Downwards Graph
-	dg := &GraphData{}
-	for _, v := range ch.DownwardsGraph.Vertices {
-		dg.Vertices = append(dg.Vertices, ParquetVertex{ID: int64(v.Id), Lat: v.Lat, Lon: v.Lon})
+// addTo adds the vertices and edges of d to g.
+func (d *GraphData) addTo(g *graph.Graph) {
+	for _, pv := range d.Vertices {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(pv.ID), Lat: pv.Lat, Lon: pv.Lon})
 	}
-	for u, edges := range ch.DownwardsGraph.Edges {
-		for v, edge := range edges {
-			dg.Edges = append(dg.Edges, ParquetEdge{
-				Source: int64(u),
-				Target: int64(v),
-				Weight: int64(edge.Weight),
-				Via:    int64(edge.Via),
-			})
-		}
+	for _, pe := range d.Edges {
+		g.AddEdge(graph.VertexId(pe.Source), graph.VertexId(pe.Target), int(pe.Weight), pe.Via != -1, graph.VertexId(pe.Via))
 	}
-	p.DownwardsGraph = dg
+}
 
-	return p
+// sortGraphData orders the vertices by id and the edges by source and target,
+// so equal graphs are written to identical files.
+func sortGraphData(d *GraphData) {
+	slices.SortFunc(d.Vertices, func(a, b ParquetVertex) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(d.Edges, func(a, b ParquetEdge) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Target, b.Target))
+	})
 }
 
 // ToCH converts a PreprocessedCHFile struct back into a ch.ContractionHierarchies object.
@@ -222,20 +237,11 @@ func (p *PreprocessedCHFile) ToCH() *ch.ContractionHierarchies {
 		ch.ContractionOrder = append(ch.ContractionOrder, graph.VertexId(id))
 	}
 
-	// Upwards Graph
-	for _, pv := range p.UpwardsGraph.Vertices {
-		ch.UpwardsGraph.AddVertex(graph.Vertex{Id: graph.VertexId(pv.ID), Lat: pv.Lat, Lon: pv.Lon})
-	}
-	for _, pe := range p.UpwardsGraph.Edges {
-		ch.UpwardsGraph.AddEdge(graph.VertexId(pe.Source), graph.VertexId(pe.Target), int(pe.Weight), pe.Via != -1, graph.VertexId(pe.Via))
-	}
-
-	// Downwards Graph
-	for _, pv := range p.DownwardsGraph.Vertices {
-		ch.DownwardsGraph.AddVertex(graph.Vertex{Id: graph.VertexId(pv.ID), Lat: pv.Lat, Lon: pv.Lon})
-	}
-	for _, pe := range p.DownwardsGraph.Edges {
-		ch.DownwardsGraph.AddEdge(graph.VertexId(pe.Source), graph.VertexId(pe.Target), int(pe.Weight), pe.Via != -1, graph.VertexId(pe.Via))
+	p.UpwardsGraph.addTo(ch.UpwardsGraph)
+	p.DownwardsGraph.addTo(ch.DownwardsGraph)
+	if p.Core != nil {
+		ch.Core = graph.NewGraph()
+		p.Core.addTo(ch.Core)
 	}
 
 	return ch
@@ -272,4 +278,83 @@ func ReadCHFile(path string) (*PreprocessedCHFile, error) {
 	}
 
 	return &p, nil
-}
\ No newline at end of file
+}
+
+// PreprocessedHubLabelsFile holds hub labels in their compressed encoding in a
+// format that can be written to a gob file.
+type PreprocessedHubLabelsFile struct {
+	Order           []int64
+	ForwardData     []byte
+	ForwardOffsets  []uint32
+	BackwardData    []byte
+	BackwardOffsets []uint32
+}
+
+// FromHubLabels compresses hub labels into a serializable PreprocessedHubLabelsFile struct.
+func FromHubLabels(labels *hublabel.Labels) *PreprocessedHubLabelsFile {
+	compressed := labels.Compress()
+	p := &PreprocessedHubLabelsFile{
+		ForwardData:     compressed.Forward.Data,
+		ForwardOffsets:  compressed.Forward.Offsets,
+		BackwardData:    compressed.Backward.Data,
+		BackwardOffsets: compressed.Backward.Offsets,
+	}
+	for _, id := range compressed.Order {
+		p.Order = append(p.Order, int64(id))
+	}
+	return p
+}
+
+// ToCompressedHubLabels converts a PreprocessedHubLabelsFile struct back into
+// compressed hub labels, which answer distance queries without decompression.
+func (p *PreprocessedHubLabelsFile) ToCompressedHubLabels() (*hublabel.CompressedLabels, error) {
+	order := make([]graph.VertexId, 0, len(p.Order))
+	for _, id := range p.Order {
+		order = append(order, graph.VertexId(id))
+	}
+	return hublabel.NewCompressedLabels(order,
+		hublabel.PackedLabels{Data: p.ForwardData, Offsets: p.ForwardOffsets},
+		hublabel.PackedLabels{Data: p.BackwardData, Offsets: p.BackwardOffsets})
+}
+
+// ToHubLabels converts a PreprocessedHubLabelsFile struct back into hub labels.
+func (p *PreprocessedHubLabelsFile) ToHubLabels() (*hublabel.Labels, error) {
+	compressed, err := p.ToCompressedHubLabels()
+	if err != nil {
+		return nil, err
+	}
+	return compressed.Decompress()
+}
+
+// WriteHubLabels saves the PreprocessedHubLabelsFile to a gob file.
+func (p *PreprocessedHubLabelsFile) WriteHubLabels(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := gob.NewEncoder(file)
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	return nil
+}
+
+// ReadHubLabelsFile reads a PreprocessedHubLabelsFile from a gob file.
+func ReadHubLabelsFile(path string) (*PreprocessedHubLabelsFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var p PreprocessedHubLabelsFile
+	decoder := gob.NewDecoder(file)
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to decode data: %w", err)
+	}
+
+	return &p, nil
+}
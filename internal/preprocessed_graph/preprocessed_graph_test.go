@@ -1,6 +1,7 @@
 package preprocessed_graph
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
 	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
 	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
 	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
 )
@@ -121,6 +123,53 @@ func TestWriteAndReadCH(t *testing.T) {
 	}
 }
 
+func TestWriteAndReadHubLabels(t *testing.T) {
+	fs := os.DirFS("../..")
+	net, err := parser.NewNetworkFromFS(fs, "data/RoadNetworks/example.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+
+	chInstance := ch.NewContractionHierarchies()
+	chInstance.Preprocess(net.Network.Clone())
+	labelsOriginal, err := hublabel.Build(chInstance)
+	if err != nil {
+		t.Fatalf("Failed to build hub labels: %v", err)
+	}
+
+	gobPath := filepath.Join(t.TempDir(), "test_hl.gob")
+	if err := FromHubLabels(labelsOriginal).WriteHubLabels(gobPath); err != nil {
+		t.Fatalf("Failed to write gob file: %v", err)
+	}
+
+	readData, err := ReadHubLabelsFile(gobPath)
+	if err != nil {
+		t.Fatalf("Failed to read gob data: %v", err)
+	}
+	labelsReconstructed, err := readData.ToHubLabels()
+	if err != nil {
+		t.Fatalf("Failed to decompress hub labels: %v", err)
+	}
+
+	if !reflect.DeepEqual(labelsOriginal, labelsReconstructed) {
+		t.Error("hub labels are not equal")
+	}
+
+	compressed, err := readData.ToCompressedHubLabels()
+	if err != nil {
+		t.Fatalf("Failed to read compressed hub labels: %v", err)
+	}
+	for s := range net.Network.Vertices {
+		for d := range net.Network.Vertices {
+			want, wantErr := labelsOriginal.Distance(s, d)
+			got, err := compressed.Distance(s, d)
+			if got != want || (err == nil) != (wantErr == nil) {
+				t.Errorf("Distance(%d, %d) = %v, %v, want %v, %v", s, d, got, err, want, wantErr)
+			}
+		}
+	}
+}
+
 // graphsAreEqual is a helper to compare two graph.Graph objects.
 func graphsAreEqual(g1, g2 *graph.Graph) bool {
 	if len(g1.Vertices) != len(g2.Vertices) {
@@ -150,3 +199,67 @@ func graphsAreEqual(g1, g2 *graph.Graph) bool {
 	}
 	return true
 }
+
+func TestDeterministicCHFile(t *testing.T) {
+	net, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+
+	// Runs with different numbers of workers must write identical files.
+	var files [][]byte
+	for _, workers := range []int{1, 4, 4, 0} {
+		c := ch.NewContractionHierarchies()
+		c.Deterministic = true
+		c.Workers = workers
+		c.Preprocess(net.Network.Clone())
+
+		path := filepath.Join(t.TempDir(), "ch.gob")
+		if err := FromCH(c).WriteCH(path); err != nil {
+			t.Fatalf("WriteCH failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		files = append(files, data)
+	}
+
+	for i, data := range files[1:] {
+		if !bytes.Equal(data, files[0]) {
+			t.Errorf("run %d wrote a different file than run 0", i+1)
+		}
+	}
+}
+
+func TestWriteAndReadCoreCH(t *testing.T) {
+	net, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	chOriginal := ch.NewContractionHierarchies(ch.WithCore(100, 0))
+	chOriginal.Preprocess(net.Network.Clone())
+
+	path := filepath.Join(t.TempDir(), "core_ch.gob")
+	if err := FromCH(chOriginal).WriteCH(path); err != nil {
+		t.Fatalf("WriteCH failed: %v", err)
+	}
+	readData, err := ReadCHFile(path)
+	if err != nil {
+		t.Fatalf("ReadCHFile failed: %v", err)
+	}
+	chReconstructed := readData.ToCH()
+
+	if !chReconstructed.HasCore() || !graphsAreEqual(chOriginal.Core, chReconstructed.Core) {
+		t.Fatal("Core is not equal")
+	}
+	for _, source := range chOriginal.ContractionOrder[:10] {
+		for target := range chOriginal.Core.Vertices {
+			_, want, _, wantErr := chOriginal.Query(source, target)
+			_, got, _, err := chReconstructed.Query(source, target)
+			if got != want || (err == nil) != (wantErr == nil) {
+				t.Errorf("query %d -> %d = %v, %v, want %v, %v", source, target, got, err, want, wantErr)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrMetricMismatch is returned by the oracle of SpurOracle if the router
+// measures a route with other weights than the graph, e.g. a CCH customized
+// with a vehicle profile.
+var ErrMetricMismatch = errors.New("router costs differ from the edge weights of the graph")
+
+// SpurOracle returns a spur path oracle for pathfinding.KShortestPaths that
+// asks r first. Excluding vertices and edges only removes paths, so if the
+// unrestricted shortest path of r avoids all exclusions it is also the
+// shortest restricted path. Otherwise the oracle falls back to Dijkstra on g,
+// which must be the graph r answers queries for. KShortestPaths adds the
+// weights of the root paths in g to the spur costs, so r must use the weights
+// of g as well; every route of r is checked against them and a difference
+// makes the oracle fail with ErrMetricMismatch.
+func SpurOracle(r Router, g *graph.Graph) pathfinding.SpurOracle {
+	fallback := pathfinding.DijkstraOracle(g)
+	return func(source, target graph.VertexId, excluded *pathfinding.Exclusions) ([]graph.VertexId, float64, error) {
+		route, err := r.Route(source, target, Options{})
+		if errors.Is(err, pathfinding.ErrTargetNotReachable) {
+			return nil, 0, err
+		}
+		if err == nil && excluded.Allows(route.Path) {
+			if cost, ok := pathCost(g, route.Path); !ok || cost != route.Cost {
+				return nil, 0, fmt.Errorf("%w: route %d -> %d costs %v, but its edges weigh %v", ErrMetricMismatch, source, target, route.Cost, cost)
+			}
+			return route.Path, route.Cost, nil
+		}
+		return fallback(source, target, excluded)
+	}
+}
+
+// pathCost returns the sum of the weights of the edges of path in g, and false
+// if an edge is missing.
+func pathCost(g *graph.Graph, path []graph.VertexId) (float64, bool) {
+	cost := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		edge, ok := g.Edges[path[i]][path[i+1]]
+		if !ok {
+			return cost, false
+		}
+		cost += float64(edge.Weight)
+	}
+	return cost, true
+}
@@ -0,0 +1,74 @@
+package routing
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestSpurOracle(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+	c := cch.NewCCH()
+	if err := c.Preprocess(g, "../../data/KaHIP/osm1.ordering"); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+	oracle := SpurOracle(NewCCHRouter(c), g)
+
+	for _, pair := range [][2]graph.VertexId{{0, 50}, {3, 120}, {10, 11}} {
+		want, err := pathfinding.KShortestPaths(g, pair[0], pair[1], 5, nil)
+		if err != nil {
+			t.Fatalf("KShortestPaths failed: %v", err)
+		}
+		got, err := pathfinding.KShortestPaths(g, pair[0], pair[1], 5, oracle)
+		if err != nil {
+			t.Fatalf("KShortestPaths with CCH oracle failed: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%v: got %d paths want %d", pair, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Cost != want[i].Cost {
+				t.Errorf("%v: path %d has cost %f with the CCH oracle, want %f", pair, i, got[i].Cost, want[i].Cost)
+			}
+		}
+	}
+}
+
+func TestSpurOracleMetricMismatch(t *testing.T) {
+	// The CCH is customized with doubled weights, so its costs cannot be added
+	// to the weights of root paths in g.
+	g := createTestGraph()
+	doubled := createTestGraph()
+	for from, edges := range doubled.Edges {
+		for to, edge := range edges {
+			edge.Weight *= 2
+			doubled.Edges[from][to] = edge
+		}
+	}
+	c := cch.NewCCH()
+	if err := c.PreprocessWithOrder(doubled, []graph.VertexId{0, 2, 1, 3}); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	if err := c.Customize(doubled); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+
+	if _, err := pathfinding.KShortestPaths(g, 0, 3, 3, SpurOracle(NewCCHRouter(c), g)); !errors.Is(err, ErrMetricMismatch) {
+		t.Errorf("expected ErrMetricMismatch, got %v", err)
+	}
+	if _, err := pathfinding.KShortestPaths(doubled, 0, 3, 3, SpurOracle(NewCCHRouter(c), doubled)); err != nil {
+		t.Errorf("KShortestPaths with matching weights failed: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"errors"
+	"math"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// DistanceMatrix returns the shortest path distances from every source to
+// every target, indexed by source, then by target. Unreachable pairs have the
+// distance +Inf. CH and CCH routers use the bucket many-to-many algorithm,
+// Dijkstra routers one search per source and hub label routers intersect the
+// labels of every pair; other routers answer one query per
+// pair without unpacking.
+func DistanceMatrix(r Router, sources, targets []graph.VertexId) ([][]float64, error) {
+	switch router := r.(type) {
+	case *CHRouter:
+		return pathfinding.ManyToMany(router.CH.UpwardsGraph, router.CH.DownwardsGraph, sources, targets), nil
+	case *CCHRouter:
+		return pathfinding.ManyToMany(router.CCH.UpwardsGraph, router.CCH.DownwardsGraph, sources, targets), nil
+	case *DijkstraRouter:
+		matrix := make([][]float64, len(sources))
+		for i, source := range sources {
+			matrix[i] = pathfinding.OneToMany(router.Graph, source, targets)
+		}
+		return matrix, nil
+	case *HubLabelRouter:
+		return labelMatrix(router.Labels, sources, targets)
+	}
+
+	matrix := make([][]float64, len(sources))
+	for i, source := range sources {
+		matrix[i] = make([]float64, len(targets))
+		for j, target := range targets {
+			route, err := r.Route(source, target, Options{NoUnpack: true})
+			if errors.Is(err, pathfinding.ErrTargetNotReachable) {
+				matrix[i][j] = math.Inf(1)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			matrix[i][j] = route.Cost
+		}
+	}
+	return matrix, nil
+}
+
+func labelMatrix(labels *hublabel.Labels, sources, targets []graph.VertexId) ([][]float64, error) {
+	matrix := make([][]float64, len(sources))
+	for i, source := range sources {
+		matrix[i] = make([]float64, len(targets))
+		for j, target := range targets {
+			d, err := labels.Distance(source, target)
+			if errors.Is(err, pathfinding.ErrTargetNotReachable) || errors.Is(err, hublabel.ErrUnknownVertex) {
+				d = math.Inf(1)
+			} else if err != nil {
+				return nil, err
+			}
+			matrix[i][j] = d
+		}
+	}
+	return matrix, nil
+}
@@ -0,0 +1,69 @@
+package routing
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestDistanceMatrix(t *testing.T) {
+	sources := []graph.VertexId{0, 3, 7}
+	targets := []graph.VertexId{3, 2, 0}
+	inf := math.Inf(1)
+	want := [][]float64{
+		{5, 1, 0},
+		{0, 6, 5},
+		{inf, inf, inf},
+	}
+
+	for name, router := range newTestRouters(t) {
+		t.Run(name, func(t *testing.T) {
+			got, err := DistanceMatrix(router, sources, targets)
+			if err != nil {
+				t.Fatalf("DistanceMatrix failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDistanceMatrixOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+	c := cch.NewCCH()
+	if err := c.Preprocess(g, "../../data/KaHIP/osm1.ordering"); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+
+	vertices := make([]graph.VertexId, 0, 20)
+	for id := graph.VertexId(0); len(vertices) < 20 && int(id) < len(g.Vertices)*2; id += 7 {
+		if _, ok := g.Vertices[id]; ok {
+			vertices = append(vertices, id)
+		}
+	}
+
+	want, err := DistanceMatrix(NewDijkstraRouter(g), vertices, vertices)
+	if err != nil {
+		t.Fatalf("Dijkstra matrix failed: %v", err)
+	}
+	got, err := DistanceMatrix(NewCCHRouter(c), vertices, vertices)
+	if err != nil {
+		t.Fatalf("CCH matrix failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CCH matrix differs from Dijkstra:\ngot  %v\nwant %v", got, want)
+	}
+}
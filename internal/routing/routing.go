@@ -0,0 +1,313 @@
+// Package routing provides a common interface for the shortest path engines of
+// this project, so callers can answer queries without knowing whether Dijkstra,
+// CH, CCH, arc flags or hub labels are used underneath.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/arcflags"
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var ErrEdgeNotFound = errors.New("route edge not found in search graph")
+
+// Router answers shortest path queries between two vertices.
+type Router interface {
+	Route(source, target graph.VertexId, opts Options) (Route, error)
+}
+
+// Options controls a single query. The zero value runs an unbounded query and
+// unpacks all shortcuts.
+type Options struct {
+	// NoUnpack returns the path on the search graph, i.e. shortcuts are kept.
+	// It has no effect on routers without shortcuts.
+	NoUnpack bool
+	// Bound limits the search to paths cheaper than the bound. Zero means unbounded.
+	// It is only honored by Dijkstra, hierarchical searches always run to completion.
+	Bound float64
+	// Workspace holds the search state of Dijkstra, CH and CCH queries. It must
+	// not be shared between goroutines. Nil takes one from a shared pool.
+	Workspace *pathfinding.Workspace
+}
+
+// RouteEdge is a single edge of a route with the weight it had during the query.
+type RouteEdge struct {
+	From       graph.VertexId
+	To         graph.VertexId
+	Weight     int
+	IsShortcut bool
+}
+
+// Stats holds search statistics of a single query.
+type Stats struct {
+	NodesPopped int
+	Duration    time.Duration
+}
+
+// Route is the result of a shortest path query.
+type Route struct {
+	Source graph.VertexId
+	Target graph.VertexId
+	Path   []graph.VertexId
+	Edges  []RouteEdge
+	Cost   float64
+	Stats  Stats
+}
+
+// DijkstraRouter answers queries with a plain Dijkstra search on the network.
+type DijkstraRouter struct {
+	Graph *graph.Graph
+}
+
+// NewDijkstraRouter creates a router running Dijkstra on g.
+func NewDijkstraRouter(g *graph.Graph) *DijkstraRouter {
+	return &DijkstraRouter{Graph: g}
+}
+
+// Route implements Router.
+func (d *DijkstraRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	bound := opts.Bound
+	if bound == 0 {
+		bound = math.Inf(1)
+	}
+
+	start := time.Now()
+	path, cost, nodesPopped, err := opts.Workspace.DijkstraShortestPathExcluding(d.Graph, source, target, bound, nil)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, d.Graph)
+}
+
+// CHRouter answers queries with a preprocessed contraction hierarchy.
+type CHRouter struct {
+	CH *ch.ContractionHierarchies
+}
+
+// NewCHRouter creates a router querying c.
+func NewCHRouter(c *ch.ContractionHierarchies) *CHRouter {
+	return &CHRouter{CH: c}
+}
+
+// Route implements Router.
+func (r *CHRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	query := r.CH.QueryWith
+	if opts.NoUnpack {
+		query = r.CH.QueryNoUnpackWith
+	}
+
+	start := time.Now()
+	path, cost, nodesPopped, err := query(opts.Workspace, source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, r.CH.UpwardsGraph, r.CH.DownwardsGraph)
+}
+
+// CCHRouter answers queries with a customized CCH.
+type CCHRouter struct {
+	CCH *cch.CCH
+}
+
+// NewCCHRouter creates a router querying c. The CCH must be customized.
+func NewCCHRouter(c *cch.CCH) *CCHRouter {
+	return &CCHRouter{CCH: c}
+}
+
+// Route implements Router.
+func (r *CCHRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	query := r.CCH.QueryWith
+	if opts.NoUnpack {
+		query = r.CCH.QueryNoUnpackWith
+	}
+
+	start := time.Now()
+	path, cost, nodesPopped, err := query(opts.Workspace, source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, r.CCH.UpwardsGraph, r.CCH.DownwardsGraph)
+}
+
+// ArcFlagRouter answers queries with a Dijkstra search pruned by arc flags.
+type ArcFlagRouter struct {
+	Graph *graph.Graph
+	Flags *arcflags.Flags
+}
+
+// NewArcFlagRouter creates a router running an arc-flag Dijkstra on g with
+// flags computed for g.
+func NewArcFlagRouter(g *graph.Graph, flags *arcflags.Flags) *ArcFlagRouter {
+	return &ArcFlagRouter{Graph: g, Flags: flags}
+}
+
+// Route implements Router.
+func (r *ArcFlagRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	start := time.Now()
+	path, cost, nodesPopped, err := pathfinding.ArcFlagDijkstraShortestPath(r.Graph, r.Flags, source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, r.Graph)
+}
+
+// CHArcFlagRouter answers queries with the arc-flag pruned up-down search on
+// a contraction hierarchy.
+type CHArcFlagRouter struct {
+	CHArcFlags *arcflags.CHArcFlags
+}
+
+// NewCHArcFlagRouter creates a router querying c.
+func NewCHArcFlagRouter(c *arcflags.CHArcFlags) *CHArcFlagRouter {
+	return &CHArcFlagRouter{CHArcFlags: c}
+}
+
+// Route implements Router.
+func (r *CHArcFlagRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	query := r.CHArcFlags.Query
+	if opts.NoUnpack {
+		query = r.CHArcFlags.QueryNoUnpack
+	}
+
+	start := time.Now()
+	path, cost, nodesPopped, err := query(source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	c := r.CHArcFlags.CH
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, c.UpwardsGraph, c.DownwardsGraph)
+}
+
+// HubLabelRouter answers queries by intersecting the hub labels of source and
+// target. Paths are unpacked with the contraction hierarchy the labels were
+// built from. NodesPopped counts the label entries scanned by the query.
+type HubLabelRouter struct {
+	Labels *hublabel.Labels
+	CH     *ch.ContractionHierarchies
+}
+
+// NewHubLabelRouter creates a router querying labels built from c.
+func NewHubLabelRouter(labels *hublabel.Labels, c *ch.ContractionHierarchies) *HubLabelRouter {
+	return &HubLabelRouter{Labels: labels, CH: c}
+}
+
+// Route implements Router.
+func (r *HubLabelRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	start := time.Now()
+	path, cost, err := r.Labels.Path(source, target)
+	if err == nil && !opts.NoUnpack {
+		path, err = r.CH.UnpackPath(path)
+	}
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{r.Labels.SearchSpace(source, target), duration}, r.CH.UpwardsGraph, r.CH.DownwardsGraph)
+}
+
+// TurnDijkstraRouter answers queries with a turn aware Dijkstra search that
+// respects the turn costs and restrictions of Turns. Route costs include the
+// turn costs, so they can exceed the sum of the edge weights.
+type TurnDijkstraRouter struct {
+	Graph *graph.Graph
+	Turns *turns.Table
+}
+
+// NewTurnDijkstraRouter creates a router running a turn aware Dijkstra on g.
+// A nil table allows all turns.
+func NewTurnDijkstraRouter(g *graph.Graph, table *turns.Table) *TurnDijkstraRouter {
+	return &TurnDijkstraRouter{Graph: g, Turns: table}
+}
+
+// Route implements Router.
+func (r *TurnDijkstraRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	start := time.Now()
+	path, cost, nodesPopped, err := pathfinding.TurnAwareDijkstraShortestPath(r.Graph, r.Turns.Cost, source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, r.Graph)
+}
+
+// TurnCCHRouter answers queries with a customized turn aware CCH. Paths are
+// given in vertices of Graph, the network the CCH was customized with.
+type TurnCCHRouter struct {
+	CCH   *cch.TurnCCH
+	Graph *graph.Graph
+}
+
+// NewTurnCCHRouter creates a router querying c, which must be customized
+// with g.
+func NewTurnCCHRouter(c *cch.TurnCCH, g *graph.Graph) *TurnCCHRouter {
+	return &TurnCCHRouter{CCH: c, Graph: g}
+}
+
+// Route implements Router.
+func (r *TurnCCHRouter) Route(source, target graph.VertexId, opts Options) (Route, error) {
+	// Unknown vertices are unreachable, as for the other routers.
+	for _, v := range []graph.VertexId{source, target} {
+		if _, ok := r.Graph.Vertices[v]; !ok {
+			return Route{}, pathfinding.ErrTargetNotReachable
+		}
+	}
+
+	start := time.Now()
+	path, cost, nodesPopped, err := r.CCH.Query(source, target)
+	duration := time.Since(start)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return newRoute(source, target, path, cost, Stats{nodesPopped, duration}, r.Graph)
+}
+
+// newRoute assembles a route and looks up every edge of the path in the given
+// graphs, in order.
+func newRoute(source, target graph.VertexId, path []graph.VertexId, cost float64, stats Stats, graphs ...*graph.Graph) (Route, error) {
+	route := Route{Source: source, Target: target, Path: path, Cost: cost, Stats: stats}
+	if len(path) > 1 {
+		route.Edges = make([]RouteEdge, 0, len(path)-1)
+	}
+
+	for i := 0; i < len(path)-1; i++ {
+		u, v := path[i], path[i+1]
+		edge, ok := lookupEdge(u, v, graphs)
+		if !ok {
+			return Route{}, fmt.Errorf("%w: %d -> %d", ErrEdgeNotFound, u, v)
+		}
+		route.Edges = append(route.Edges, RouteEdge{From: u, To: v, Weight: edge.Weight, IsShortcut: edge.IsShortcut})
+	}
+
+	return route, nil
+}
+
+func lookupEdge(u, v graph.VertexId, graphs []*graph.Graph) (graph.Edge, bool) {
+	for _, g := range graphs {
+		if edge, ok := g.Edges[u][v]; ok {
+			return edge, true
+		}
+	}
+	return graph.Edge{}, false
+}
@@ -0,0 +1,188 @@
+package routing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/arcflags"
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/hublabel"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/turns"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createTestGraph builds the small graph
+//
+//	0 --2-- 1 --3-- 3
+//	 \      |      /
+//	  1     4     7
+//	   \    |    /
+//	    `-- 2 --'
+func createTestGraph() *graph.Graph {
+	g := graph.NewGraph()
+	for i := 0; i < 4; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	edges := [][3]int{{0, 1, 2}, {1, 2, 4}, {0, 2, 1}, {2, 3, 7}, {1, 3, 3}}
+	for _, e := range edges {
+		g.AddEdge(graph.VertexId(e[0]), graph.VertexId(e[1]), e[2], false, -1)
+		g.AddEdge(graph.VertexId(e[1]), graph.VertexId(e[0]), e[2], false, -1)
+	}
+	return g
+}
+
+func newTestRouters(t *testing.T) map[string]Router {
+	t.Helper()
+
+	chInst := ch.NewContractionHierarchies()
+	chInst.Preprocess(createTestGraph())
+
+	orderingFile := filepath.Join(t.TempDir(), "ordering.txt")
+	if err := os.WriteFile(orderingFile, []byte("4\n1 1\n2 3\n3 2\n4 4\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ordering file: %v", err)
+	}
+	g := createTestGraph()
+	cchInst := cch.NewCCH()
+	if err := cchInst.Preprocess(g, orderingFile); err != nil {
+		t.Fatalf("CCH.Preprocess failed: %v", err)
+	}
+	if err := cchInst.Customize(g); err != nil {
+		t.Fatalf("CCH.Customize failed: %v", err)
+	}
+
+	partition, err := arcflags.KDPartition(createTestGraph(), 2)
+	if err != nil {
+		t.Fatalf("KDPartition failed: %v", err)
+	}
+	labels, err := hublabel.Build(chInst)
+	if err != nil {
+		t.Fatalf("hublabel.Build failed: %v", err)
+	}
+	chFlags, err := arcflags.NewCHArcFlags(chInst, partition)
+	if err != nil {
+		t.Fatalf("NewCHArcFlags failed: %v", err)
+	}
+
+	turnCCH, err := cch.NewTurnCCH(g, cchInst.ContractionOrder)
+	if err != nil {
+		t.Fatalf("NewTurnCCH failed: %v", err)
+	}
+	if err := turnCCH.Customize(g, nil); err != nil {
+		t.Fatalf("TurnCCH.Customize failed: %v", err)
+	}
+
+	return map[string]Router{
+		"dijkstra":    NewDijkstraRouter(createTestGraph()),
+		"ch":          NewCHRouter(chInst),
+		"cch":         NewCCHRouter(cchInst),
+		"hublabel":    NewHubLabelRouter(labels, chInst),
+		"arcflags":    NewArcFlagRouter(createTestGraph(), arcflags.Compute(createTestGraph(), partition)),
+		"ch_arcflags": NewCHArcFlagRouter(chFlags),
+		"turns":       NewTurnDijkstraRouter(createTestGraph(), turns.NewTable()),
+		"turn_cch":    NewTurnCCHRouter(turnCCH, g),
+	}
+}
+
+func TestRoute(t *testing.T) {
+	for name, router := range newTestRouters(t) {
+		t.Run(name, func(t *testing.T) {
+			route, err := router.Route(0, 3, Options{})
+			if err != nil {
+				t.Fatalf("Route failed: %v", err)
+			}
+
+			if route.Cost != 5 {
+				t.Errorf("got cost %f, want 5", route.Cost)
+			}
+			if want := []graph.VertexId{0, 1, 3}; !reflect.DeepEqual(route.Path, want) {
+				t.Errorf("got path %v, want %v", route.Path, want)
+			}
+			if len(route.Edges) != len(route.Path)-1 {
+				t.Fatalf("got %d edges for a path of %d vertices", len(route.Edges), len(route.Path))
+			}
+
+			sum := 0
+			for i, edge := range route.Edges {
+				if edge.From != route.Path[i] || edge.To != route.Path[i+1] {
+					t.Errorf("edge %d is %d->%d, want %d->%d", i, edge.From, edge.To, route.Path[i], route.Path[i+1])
+				}
+				if edge.IsShortcut {
+					t.Errorf("unpacked route contains shortcut %d->%d", edge.From, edge.To)
+				}
+				sum += edge.Weight
+			}
+			if float64(sum) != route.Cost {
+				t.Errorf("edge weights sum to %d, want %f", sum, route.Cost)
+			}
+			if route.Stats.NodesPopped == 0 {
+				t.Error("expected search statistics to be recorded")
+			}
+		})
+	}
+}
+
+func TestTurnRouters(t *testing.T) {
+	// Forbidding the turn 0 -> 1 -> 3 makes both detours via 2 shortest.
+	g := createTestGraph()
+	table := turns.NewTable()
+	table.Forbid(0, 1, 3)
+	turnCCH, err := cch.NewTurnCCH(g, []graph.VertexId{0, 2, 1, 3})
+	if err != nil {
+		t.Fatalf("NewTurnCCH failed: %v", err)
+	}
+	if err := turnCCH.Customize(g, table); err != nil {
+		t.Fatalf("TurnCCH.Customize failed: %v", err)
+	}
+
+	for name, router := range map[string]Router{
+		"turns":    NewTurnDijkstraRouter(g, table),
+		"turn_cch": NewTurnCCHRouter(turnCCH, g),
+	} {
+		route, err := router.Route(0, 3, Options{})
+		if err != nil {
+			t.Fatalf("%s: Route failed: %v", name, err)
+		}
+		if route.Cost != 8 || route.Path[1] != 2 {
+			t.Errorf("%s: got path %v with cost %f, want a path via 2 with cost 8", name, route.Path, route.Cost)
+		}
+	}
+}
+
+func TestRouteNoUnpack(t *testing.T) {
+	for name, router := range newTestRouters(t) {
+		t.Run(name, func(t *testing.T) {
+			route, err := router.Route(2, 3, Options{NoUnpack: true})
+			if err != nil {
+				t.Fatalf("Route failed: %v", err)
+			}
+			if route.Cost != 6 {
+				t.Errorf("got cost %f, want 6", route.Cost)
+			}
+			if route.Path[0] != 2 || route.Path[len(route.Path)-1] != 3 {
+				t.Errorf("got path %v, want a path from 2 to 3", route.Path)
+			}
+		})
+	}
+}
+
+func TestRouteUnreachable(t *testing.T) {
+	g := createTestGraph()
+	g.AddVertex(graph.Vertex{Id: 4})
+
+	_, err := NewDijkstraRouter(g).Route(0, 4, Options{})
+	if !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable, got %v", err)
+	}
+}
+
+func TestRouteBound(t *testing.T) {
+	_, err := NewDijkstraRouter(createTestGraph()).Route(0, 3, Options{Bound: 4})
+	if !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+		t.Errorf("expected ErrTargetNotReachable for a bound below the distance, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package tnr implements transit node routing on top of a contraction
+// hierarchy. The highest ranked vertices of the contraction order are transit
+// nodes; every vertex stores the transit nodes its upward searches reach
+// first, its access nodes, and a table holds the distances between all
+// transit nodes. Long-distance queries combine access nodes and table entries
+// without any search, short queries fall back to the CH.
+package tnr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var (
+	ErrInvalidTransitCount = errors.New("number of transit nodes out of range")
+	ErrUnknownVertex       = errors.New("vertex not in transit node routing")
+)
+
+// AccessNode is a transit node, given by its index into TransitNodes, together
+// with the distance between a vertex and the transit node.
+type AccessNode struct {
+	Node int
+	Dist float64
+}
+
+// box is the bounding box of the coordinates of a search space.
+type box struct {
+	minLat, minLon, maxLat, maxLon float64
+	empty                          bool
+}
+
+func (b *box) add(v graph.Vertex) {
+	if b.empty {
+		*b = box{minLat: v.Lat, minLon: v.Lon, maxLat: v.Lat, maxLon: v.Lon}
+		return
+	}
+	b.minLat, b.maxLat = math.Min(b.minLat, v.Lat), math.Max(b.maxLat, v.Lat)
+	b.minLon, b.maxLon = math.Min(b.minLon, v.Lon), math.Max(b.maxLon, v.Lon)
+}
+
+func (b box) intersects(o box) bool {
+	return !b.empty && !o.empty &&
+		b.minLat <= o.maxLat && o.minLat <= b.maxLat &&
+		b.minLon <= o.maxLon && o.minLon <= b.maxLon
+}
+
+// TransitNodeRouting answers distance queries with transit nodes selected from
+// the top of a CH order.
+type TransitNodeRouting struct {
+	CH           *ch.ContractionHierarchies
+	TransitNodes []graph.VertexId
+	// Table holds the distance from every transit node to every other one,
+	// indexed like TransitNodes.
+	Table    [][]float64
+	Forward  map[graph.VertexId][]AccessNode
+	Backward map[graph.VertexId][]AccessNode
+
+	transitIndex  map[graph.VertexId]int
+	forwardSpace  map[graph.VertexId]box
+	backwardSpace map[graph.VertexId]box
+}
+
+// Build selects the numTransit highest ranked vertices of c as transit nodes,
+// computes their distance table with the CH many-to-many algorithm and the
+// access nodes of every vertex with an upward search that stops at transit
+// nodes. Access nodes that are reached more cheaply through another access
+// node are pruned.
+//
+// The locality filter stores the bounding box of the non-transit vertices of
+// both upward searches. If the shortest path between s and t does not pass a
+// transit node, the highest vertex on it is in the forward search space of s
+// and the backward search space of t, so disjoint boxes prove that the
+// transit node distance is exact.
+//
+// A core CH is rejected with ch.ErrHasCore, since the transit nodes are taken
+// from the contraction order.
+func Build(c *ch.ContractionHierarchies, numTransit int) (*TransitNodeRouting, error) {
+	if err := c.RequireNoCore(); err != nil {
+		return nil, err
+	}
+	n := len(c.ContractionOrder)
+	if numTransit < 1 || numTransit > n {
+		return nil, fmt.Errorf("%w: %d of %d vertices", ErrInvalidTransitCount, numTransit, n)
+	}
+
+	t := &TransitNodeRouting{
+		CH:            c,
+		TransitNodes:  make([]graph.VertexId, numTransit),
+		Forward:       make(map[graph.VertexId][]AccessNode, n),
+		Backward:      make(map[graph.VertexId][]AccessNode, n),
+		transitIndex:  make(map[graph.VertexId]int, numTransit),
+		forwardSpace:  make(map[graph.VertexId]box, n),
+		backwardSpace: make(map[graph.VertexId]box, n),
+	}
+	for i := range numTransit {
+		v := c.ContractionOrder[n-numTransit+i]
+		t.TransitNodes[i] = v
+		t.transitIndex[v] = i
+	}
+	t.Table = pathfinding.ManyToMany(c.UpwardsGraph, c.DownwardsGraph, t.TransitNodes, t.TransitNodes)
+
+	for _, v := range c.ContractionOrder {
+		t.Forward[v], t.forwardSpace[v] = t.accessNodes(v, nil, true)
+		t.Backward[v], t.backwardSpace[v] = t.accessNodes(v, c.DownwardsGraph, false)
+	}
+
+	return t, nil
+}
+
+// accessNodes runs an upward search from v that stops at transit nodes and
+// returns the pruned access nodes and the bounding box of the search space.
+func (t *TransitNodeRouting) accessNodes(v graph.VertexId, reverse *graph.Graph, forward bool) ([]AccessNode, box) {
+	isTransit := func(w graph.VertexId) bool {
+		_, ok := t.transitIndex[w]
+		return ok
+	}
+
+	space := box{empty: true}
+	var candidates []AccessNode
+	for w, dist := range pathfinding.UpwardSearch(t.CH.UpwardsGraph, reverse, v, isTransit) {
+		if i, ok := t.transitIndex[w]; ok {
+			candidates = append(candidates, AccessNode{Node: i, Dist: dist})
+		} else {
+			space.add(t.CH.UpwardsGraph.Vertices[w])
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].Dist != candidates[b].Dist {
+			return candidates[a].Dist < candidates[b].Dist
+		}
+		return candidates[a].Node < candidates[b].Node
+	})
+
+	// A candidate is dominated if a closer access node reaches it, or is
+	// reached from it, at no additional cost.
+	var access []AccessNode
+	for _, candidate := range candidates {
+		dominated := false
+		for _, kept := range access {
+			via := t.Table[kept.Node][candidate.Node]
+			if !forward {
+				via = t.Table[candidate.Node][kept.Node]
+			}
+			if kept.Dist+via <= candidate.Dist {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			access = append(access, candidate)
+		}
+	}
+	return access, space
+}
+
+// IsLocal reports whether a query from source to target may not pass a
+// transit node and therefore has to be answered by the CH.
+func (t *TransitNodeRouting) IsLocal(source, target graph.VertexId) bool {
+	return t.forwardSpace[source].intersects(t.backwardSpace[target])
+}
+
+// Distance returns the shortest path distance from source to target and
+// whether the query was local and answered by the CH.
+func (t *TransitNodeRouting) Distance(source, target graph.VertexId) (float64, bool, error) {
+	forward, ok := t.Forward[source]
+	if !ok {
+		return 0, false, fmt.Errorf("%w: %d", ErrUnknownVertex, source)
+	}
+	backward, ok := t.Backward[target]
+	if !ok {
+		return 0, false, fmt.Errorf("%w: %d", ErrUnknownVertex, target)
+	}
+
+	if t.IsLocal(source, target) {
+		_, dist, _, err := t.CH.QueryNoUnpack(source, target)
+		if err != nil {
+			return 0, true, err
+		}
+		return dist, true, nil
+	}
+
+	best := math.Inf(1)
+	for _, a := range forward {
+		row := t.Table[a.Node]
+		for _, b := range backward {
+			best = math.Min(best, a.Dist+row[b.Node]+b.Dist)
+		}
+	}
+	if math.IsInf(best, 1) {
+		return 0, false, pathfinding.ErrTargetNotReachable
+	}
+	return best, false, nil
+}
+
+// AverageAccessNodes returns the average number of forward and backward
+// access nodes per vertex.
+func (t *TransitNodeRouting) AverageAccessNodes() float64 {
+	if len(t.Forward) == 0 {
+		return 0
+	}
+	total := 0
+	for v := range t.Forward {
+		total += len(t.Forward[v]) + len(t.Backward[v])
+	}
+	return float64(total) / float64(2*len(t.Forward))
+}
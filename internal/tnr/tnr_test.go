@@ -0,0 +1,113 @@
+package tnr
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func loadOsm1(t *testing.T) (*graph.Graph, *ch.ContractionHierarchies) {
+	t.Helper()
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	c := ch.NewContractionHierarchies()
+	c.Preprocess(network.Network.Clone())
+	return network.Network, c
+}
+
+func TestDistanceOsm1(t *testing.T) {
+	g, c := loadOsm1(t)
+	tnr, err := Build(c, 30)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	r := rand.New(rand.NewSource(1))
+	global := 0
+	for i := 0; i < 500; i++ {
+		s, d := vertices[r.Intn(len(vertices))], vertices[r.Intn(len(vertices))]
+		_, want, _, wantErr := pathfinding.DijkstraShortestPath(g, s, d, math.Inf(1))
+		got, local, err := tnr.Distance(s, d)
+		if wantErr != nil {
+			if !errors.Is(err, pathfinding.ErrTargetNotReachable) {
+				t.Errorf("Distance(%d, %d) error = %v, want ErrTargetNotReachable", s, d, err)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("Distance(%d, %d) = %v, %v (local %v), want %v", s, d, got, err, local, want)
+		}
+		if !local {
+			global++
+		}
+	}
+	if global == 0 {
+		t.Error("all queries were local, the transit node table was never used")
+	}
+}
+
+func TestTransitNodeAccessNodes(t *testing.T) {
+	_, c := loadOsm1(t)
+	tnr, err := Build(c, 10)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	for i, v := range tnr.TransitNodes {
+		if rank := len(c.ContractionOrder) - 10 + i; c.ContractionOrder[rank] != v {
+			t.Errorf("transit node %d is %d, want %d", i, v, c.ContractionOrder[rank])
+		}
+		access := tnr.Forward[v]
+		if len(access) != 1 || access[0].Node != i || access[0].Dist != 0 {
+			t.Errorf("forward access nodes of transit node %d = %v, want only itself", v, access)
+		}
+		if tnr.IsLocal(v, v) {
+			t.Errorf("query from transit node %d to itself is local", v)
+		}
+	}
+	if tnr.AverageAccessNodes() < 1 {
+		t.Errorf("AverageAccessNodes() = %v, want at least 1", tnr.AverageAccessNodes())
+	}
+}
+
+func TestBuildInvalidTransitCount(t *testing.T) {
+	_, c := loadOsm1(t)
+	for _, numTransit := range []int{0, len(c.ContractionOrder) + 1} {
+		if _, err := Build(c, numTransit); !errors.Is(err, ErrInvalidTransitCount) {
+			t.Errorf("Build(%d) error = %v, want ErrInvalidTransitCount", numTransit, err)
+		}
+	}
+}
+
+func TestBuildCoreCH(t *testing.T) {
+	g, _ := loadOsm1(t)
+	c := ch.NewContractionHierarchies(ch.WithCore(100, 0))
+	c.Preprocess(g.Clone())
+	if _, err := Build(c, 10); !errors.Is(err, ch.ErrHasCore) {
+		t.Errorf("Build error = %v, want ch.ErrHasCore", err)
+	}
+}
+
+func TestDistanceUnknownVertex(t *testing.T) {
+	_, c := loadOsm1(t)
+	tnr, err := Build(c, 10)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, _, err := tnr.Distance(-1, c.ContractionOrder[0]); !errors.Is(err, ErrUnknownVertex) {
+		t.Errorf("Distance error = %v, want ErrUnknownVertex", err)
+	}
+}
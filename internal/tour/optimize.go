@@ -0,0 +1,175 @@
+package tour
+
+import "math"
+
+// exactLimit is the largest number of intermediate waypoints OptimizeOrder
+// orders exactly. The dynamic program needs 2^k * k^2 steps for k of them.
+const exactLimit = 10
+
+// unreachableCost replaces infinite distances during the optimization, so
+// orders that use fewer unreachable legs are still preferred.
+const unreachableCost = 1e12
+
+// OptimizeOrder returns an order of the waypoints of matrix that starts with
+// the first and ends with the last waypoint and visits all others with a small
+// total cost. Up to exactLimit intermediate waypoints are ordered optimally
+// with the Held-Karp dynamic program. Larger tours are built with the nearest
+// neighbor heuristic and improved with 2-opt and relocate moves until no move
+// shortens the tour. The matrix may be asymmetric.
+func OptimizeOrder(matrix [][]float64) []int {
+	n := len(matrix)
+	if n <= 3 {
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+
+	costs := make([][]float64, n)
+	for i, row := range matrix {
+		costs[i] = make([]float64, n)
+		for j, c := range row {
+			costs[i][j] = c
+			if math.IsInf(c, 1) {
+				costs[i][j] = unreachableCost
+			}
+		}
+	}
+
+	if n-2 <= exactLimit {
+		return heldKarp(costs)
+	}
+	order := nearestNeighbor(costs)
+	improve(costs, order)
+	return order
+}
+
+// heldKarp returns the cheapest path from 0 to n-1 through all other vertices.
+func heldKarp(costs [][]float64) []int {
+	n := len(costs)
+	k := n - 2 // intermediate waypoints 1..n-2 are bit i-1 of a mask
+	full := 1<<k - 1
+
+	dp := make([][]float64, full+1)
+	parent := make([][]int, full+1)
+	for mask := range dp {
+		dp[mask] = make([]float64, k)
+		parent[mask] = make([]int, k)
+		for j := range dp[mask] {
+			dp[mask][j] = math.Inf(1)
+			parent[mask][j] = -1
+		}
+	}
+	for j := 0; j < k; j++ {
+		dp[1<<j][j] = costs[0][j+1]
+	}
+
+	for mask := 1; mask <= full; mask++ {
+		for last := 0; last < k; last++ {
+			if mask&(1<<last) == 0 || math.IsInf(dp[mask][last], 1) {
+				continue
+			}
+			for next := 0; next < k; next++ {
+				if mask&(1<<next) != 0 {
+					continue
+				}
+				nextMask := mask | 1<<next
+				if c := dp[mask][last] + costs[last+1][next+1]; c < dp[nextMask][next] {
+					dp[nextMask][next] = c
+					parent[nextMask][next] = last
+				}
+			}
+		}
+	}
+
+	last, best := 0, math.Inf(1)
+	for j := 0; j < k; j++ {
+		if c := dp[full][j] + costs[j+1][n-1]; c < best {
+			last, best = j, c
+		}
+	}
+
+	order := make([]int, n)
+	order[0], order[n-1] = 0, n-1
+	for mask, i := full, n-2; i >= 1; i-- {
+		order[i] = last + 1
+		mask, last = mask&^(1<<last), parent[mask][last]
+	}
+	return order
+}
+
+// nearestNeighbor starts at 0, always continues with the closest unvisited
+// intermediate waypoint and ends at n-1.
+func nearestNeighbor(costs [][]float64) []int {
+	n := len(costs)
+	visited := make([]bool, n)
+	order := []int{0}
+	visited[0], visited[n-1] = true, true
+
+	for current := 0; len(order) < n-1; {
+		next := -1
+		for candidate := 1; candidate < n-1; candidate++ {
+			if !visited[candidate] && (next == -1 || costs[current][candidate] < costs[current][next]) {
+				next = candidate
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+		current = next
+	}
+	return append(order, n-1)
+}
+
+// improve applies 2-opt and relocate moves to the intermediate waypoints of
+// order as long as one of them lowers the cost.
+func improve(costs [][]float64, order []int) {
+	n := len(order)
+	best := Cost(costs, order)
+	candidate := make([]int, n)
+
+	for improved := true; improved; {
+		improved = false
+
+		// 2-opt: reverse the segment order[i..j].
+		for i := 1; i < n-2; i++ {
+			for j := i + 1; j < n-1; j++ {
+				copy(candidate, order)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+				if c := Cost(costs, candidate); c < best {
+					copy(order, candidate)
+					best, improved = c, true
+				}
+			}
+		}
+
+		// Relocate: move the waypoint at position i to position j.
+		for i := 1; i < n-1; i++ {
+			for j := 1; j < n-1; j++ {
+				if i == j {
+					continue
+				}
+				moveWaypoint(candidate, order, i, j)
+				if c := Cost(costs, candidate); c < best {
+					copy(order, candidate)
+					best, improved = c, true
+				}
+			}
+		}
+	}
+}
+
+// moveWaypoint writes order to dst with the element at position i moved to
+// position j.
+func moveWaypoint(dst, order []int, i, j int) {
+	copy(dst, order)
+	moved := order[i]
+	if i < j {
+		copy(dst[i:j], order[i+1:j+1])
+	} else {
+		copy(dst[j+1:i+1], order[j:i])
+	}
+	dst[j] = moved
+}
@@ -0,0 +1,110 @@
+// Package tour plans routes through an ordered list of waypoints. Every leg
+// between two consecutive waypoints is answered by a routing.Router, and the
+// intermediate waypoints can be reordered to shorten the tour, which is a
+// small travelling salesman problem on the distance matrix of the waypoints.
+package tour
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var (
+	// ErrTooFewWaypoints is returned for tours with less than two waypoints.
+	ErrTooFewWaypoints = errors.New("a tour needs at least two waypoints")
+	// ErrUnreachable is returned if no order of the waypoints connects all of them.
+	ErrUnreachable = errors.New("waypoints are not connected")
+)
+
+// Leg is the route between two consecutive waypoints.
+type Leg struct {
+	From  graph.VertexId
+	To    graph.VertexId
+	Route routing.Route
+}
+
+// Tour is a route through all waypoints. Order holds the index of every
+// visited waypoint in the requested list, so it is the identity unless the
+// waypoints were reordered. Path and Edges are the concatenation of all legs.
+type Tour struct {
+	Waypoints []graph.VertexId
+	Order     []int
+	Legs      []Leg
+	Path      []graph.VertexId
+	Edges     []routing.RouteEdge
+	Cost      float64
+	Stats     routing.Stats
+}
+
+// Plan routes through the waypoints in the given order, answering one query
+// per leg with r.
+func Plan(r routing.Router, waypoints []graph.VertexId, opts routing.Options) (Tour, error) {
+	order := make([]int, len(waypoints))
+	for i := range order {
+		order[i] = i
+	}
+	return plan(r, waypoints, order, opts)
+}
+
+// PlanOptimized routes from the first to the last waypoint through all other
+// waypoints in the order that minimizes the total cost, as far as OptimizeOrder
+// finds it. Passing the same vertex as first and last waypoint plans a round
+// trip.
+func PlanOptimized(r routing.Router, waypoints []graph.VertexId, opts routing.Options) (Tour, error) {
+	if len(waypoints) < 2 {
+		return Tour{}, ErrTooFewWaypoints
+	}
+	matrix, err := routing.DistanceMatrix(r, waypoints, waypoints)
+	if err != nil {
+		return Tour{}, fmt.Errorf("failed to compute distance matrix: %w", err)
+	}
+	order := OptimizeOrder(matrix)
+	if math.IsInf(Cost(matrix, order), 1) {
+		return Tour{}, ErrUnreachable
+	}
+	return plan(r, waypoints, order, opts)
+}
+
+func plan(r routing.Router, waypoints []graph.VertexId, order []int, opts routing.Options) (Tour, error) {
+	if len(waypoints) < 2 {
+		return Tour{}, ErrTooFewWaypoints
+	}
+
+	t := Tour{Order: order, Waypoints: make([]graph.VertexId, len(order))}
+	for i, index := range order {
+		t.Waypoints[i] = waypoints[index]
+	}
+
+	for i := 0; i+1 < len(t.Waypoints); i++ {
+		from, to := t.Waypoints[i], t.Waypoints[i+1]
+		route, err := r.Route(from, to, opts)
+		if err != nil {
+			return Tour{}, fmt.Errorf("leg %d from %d to %d: %w", i, from, to, err)
+		}
+
+		t.Legs = append(t.Legs, Leg{From: from, To: to, Route: route})
+		if len(t.Path) == 0 {
+			t.Path = append(t.Path, route.Path...)
+		} else if len(route.Path) > 0 {
+			t.Path = append(t.Path, route.Path[1:]...)
+		}
+		t.Edges = append(t.Edges, route.Edges...)
+		t.Cost += route.Cost
+		t.Stats.NodesPopped += route.Stats.NodesPopped
+		t.Stats.Duration += route.Stats.Duration
+	}
+	return t, nil
+}
+
+// Cost returns the total cost of visiting the waypoints in the given order.
+func Cost(matrix [][]float64, order []int) float64 {
+	cost := 0.0
+	for i := 0; i+1 < len(order); i++ {
+		cost += matrix[order[i]][order[i+1]]
+	}
+	return cost
+}
@@ -0,0 +1,146 @@
+package tour
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createLineGraph builds the path 0 - 1 - 2 - 3 - 4 - 5 with unit weights.
+func createLineGraph() *graph.Graph {
+	g := graph.NewGraph()
+	for i := 0; i < 6; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	for i := 0; i < 5; i++ {
+		g.AddEdge(graph.VertexId(i), graph.VertexId(i+1), 1, false, -1)
+		g.AddEdge(graph.VertexId(i+1), graph.VertexId(i), 1, false, -1)
+	}
+	return g
+}
+
+func TestPlan(t *testing.T) {
+	r := routing.NewDijkstraRouter(createLineGraph())
+
+	tour, err := Plan(r, []graph.VertexId{0, 4, 2}, routing.Options{})
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if want := []graph.VertexId{0, 1, 2, 3, 4, 3, 2}; !reflect.DeepEqual(tour.Path, want) {
+		t.Errorf("got path %v want %v", tour.Path, want)
+	}
+	if tour.Cost != 6 || len(tour.Legs) != 2 || tour.Legs[0].Route.Cost != 4 || tour.Legs[1].Route.Cost != 2 {
+		t.Errorf("got cost %f with legs %+v, want 6 with legs of cost 4 and 2", tour.Cost, tour.Legs)
+	}
+	if len(tour.Edges) != len(tour.Path)-1 {
+		t.Errorf("got %d edges for a path of %d vertices", len(tour.Edges), len(tour.Path))
+	}
+
+	if _, err := Plan(r, []graph.VertexId{0}, routing.Options{}); !errors.Is(err, ErrTooFewWaypoints) {
+		t.Errorf("got %v want %v", err, ErrTooFewWaypoints)
+	}
+}
+
+func TestPlanOptimized(t *testing.T) {
+	r := routing.NewDijkstraRouter(createLineGraph())
+
+	tour, err := PlanOptimized(r, []graph.VertexId{0, 4, 2, 5, 1, 0}, routing.Options{})
+	if err != nil {
+		t.Fatalf("PlanOptimized failed: %v", err)
+	}
+	if tour.Cost != 10 {
+		t.Errorf("got cost %f for the round trip, want 10", tour.Cost)
+	}
+	if tour.Waypoints[0] != 0 || tour.Waypoints[len(tour.Waypoints)-1] != 0 {
+		t.Errorf("the first and last waypoint must stay in place, got %v", tour.Waypoints)
+	}
+
+	g := createLineGraph()
+	g.AddVertex(graph.Vertex{Id: 9})
+	if _, err := PlanOptimized(routing.NewDijkstraRouter(g), []graph.VertexId{0, 9, 5}, routing.Options{}); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("got %v want %v", err, ErrUnreachable)
+	}
+}
+
+// bruteForce returns the cost of the cheapest order with fixed end points.
+func bruteForce(matrix [][]float64) float64 {
+	n := len(matrix)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	best := math.Inf(1)
+	var permute func(k int)
+	permute = func(k int) {
+		if k == n-1 {
+			best = math.Min(best, Cost(matrix, order))
+			return
+		}
+		for i := k; i < n-1; i++ {
+			order[k], order[i] = order[i], order[k]
+			permute(k + 1)
+			order[k], order[i] = order[i], order[k]
+		}
+	}
+	permute(1)
+	return best
+}
+
+func randomMatrix(rng *rand.Rand, n int) [][]float64 {
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		for j := range matrix[i] {
+			if i != j {
+				matrix[i][j] = float64(rng.Intn(100) + 1)
+			}
+		}
+	}
+	return matrix
+}
+
+func assertValidOrder(t *testing.T, order []int, n int) {
+	t.Helper()
+	if len(order) != n || order[0] != 0 || order[n-1] != n-1 {
+		t.Fatalf("got order %v, want a permutation of %d waypoints with fixed end points", order, n)
+	}
+	seen := make(map[int]bool)
+	for _, i := range order {
+		if seen[i] {
+			t.Fatalf("waypoint %d is visited twice in %v", i, order)
+		}
+		seen[i] = true
+	}
+}
+
+func TestOptimizeOrderExact(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 2; n <= 8; n++ {
+		matrix := randomMatrix(rng, n)
+		order := OptimizeOrder(matrix)
+		assertValidOrder(t, order, n)
+		if got, want := Cost(matrix, order), bruteForce(matrix); got != want {
+			t.Errorf("n=%d: got cost %f want %f", n, got, want)
+		}
+	}
+}
+
+func TestOptimizeOrderHeuristic(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, n := range []int{15, 30} {
+		matrix := randomMatrix(rng, n)
+		order := OptimizeOrder(matrix)
+		assertValidOrder(t, order, n)
+
+		costs := make([][]float64, n)
+		copy(costs, matrix)
+		if got, start := Cost(matrix, order), Cost(matrix, nearestNeighbor(costs)); got > start {
+			t.Errorf("n=%d: local search made the tour worse: %f > %f", n, got, start)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package turns
+
+import (
+	"sort"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// Arc is a directed edge of the original graph.
+type Arc struct {
+	From graph.VertexId
+	To   graph.VertexId
+}
+
+// ExpandedGraph is the edge-based graph of an original graph. Every arc of the
+// original graph becomes a vertex, and every turn u -> v -> w becomes an edge
+// from the vertex of (u, v) to the vertex of (v, w) that costs the weight of
+// (v, w) plus the turn cost. Forbidden turns are kept with an infinite weight,
+// so the topology only depends on the original graph and not on the Table.
+//
+// Routes start and end at vertices of the original graph, not at arcs, so
+// every original vertex v also gets a source vertex with an edge to each arc
+// leaving v and a sink vertex with an edge from each arc entering v.
+type ExpandedGraph struct {
+	Graph   *graph.Graph
+	arcs    map[graph.VertexId]Arc
+	arcIds  map[Arc]graph.VertexId
+	sources map[graph.VertexId]graph.VertexId
+	sinks   map[graph.VertexId]graph.VertexId
+}
+
+// Expand builds the edge-based graph of g with the turn costs of table. A nil
+// table allows all turns at no cost. The vertex ids of the expansion only
+// depend on the topology of g, so expanding the same graph with different
+// weights or tables yields graphs that differ only in their edge weights.
+func Expand(g *graph.Graph, table *Table) *ExpandedGraph {
+	vertexIds := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		vertexIds = append(vertexIds, id)
+	}
+	sort.Slice(vertexIds, func(i, j int) bool { return vertexIds[i] < vertexIds[j] })
+
+	var arcs []Arc
+	for _, u := range vertexIds {
+		targets := make([]graph.VertexId, 0, len(g.Edges[u]))
+		for v := range g.Edges[u] {
+			targets = append(targets, v)
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+		for _, v := range targets {
+			arcs = append(arcs, Arc{u, v})
+		}
+	}
+
+	e := &ExpandedGraph{
+		Graph:   graph.NewGraph(),
+		arcs:    make(map[graph.VertexId]Arc, len(arcs)),
+		arcIds:  make(map[Arc]graph.VertexId, len(arcs)),
+		sources: make(map[graph.VertexId]graph.VertexId, len(vertexIds)),
+		sinks:   make(map[graph.VertexId]graph.VertexId, len(vertexIds)),
+	}
+
+	for i, arc := range arcs {
+		id := graph.VertexId(i)
+		head := g.Vertices[arc.To]
+		e.Graph.AddVertex(graph.Vertex{Id: id, Lat: head.Lat, Lon: head.Lon})
+		e.arcs[id] = arc
+		e.arcIds[arc] = id
+	}
+	for i, v := range vertexIds {
+		vertex := g.Vertices[v]
+		source := graph.VertexId(len(arcs) + 2*i)
+		sink := source + 1
+		e.Graph.AddVertex(graph.Vertex{Id: source, Lat: vertex.Lat, Lon: vertex.Lon})
+		e.Graph.AddVertex(graph.Vertex{Id: sink, Lat: vertex.Lat, Lon: vertex.Lon})
+		e.sources[v] = source
+		e.sinks[v] = sink
+	}
+
+	for _, arc := range arcs {
+		id := e.arcIds[arc]
+		weight := g.Edges[arc.From][arc.To].Weight
+
+		e.Graph.AddEdge(e.sources[arc.From], id, weight, false, -1)
+		e.Graph.AddEdge(id, e.sinks[arc.To], 0, false, -1)
+
+		// Every arc leaving the head of this arc is a possible turn.
+		for next, nextEdge := range g.Edges[arc.To] {
+			turnWeight := graph.InfWeight
+			if cost, allowed := table.Cost(arc.From, arc.To, next); allowed {
+				turnWeight = graph.AddWeights(nextEdge.Weight, cost)
+			}
+			e.Graph.AddEdge(id, e.arcIds[Arc{arc.To, next}], turnWeight, false, -1)
+		}
+	}
+
+	return e
+}
+
+// Arc returns the original arc represented by the expanded vertex id.
+func (e *ExpandedGraph) Arc(id graph.VertexId) (Arc, bool) {
+	arc, ok := e.arcs[id]
+	return arc, ok
+}
+
+// Source returns the expanded vertex routes starting at v begin at.
+func (e *ExpandedGraph) Source(v graph.VertexId) (graph.VertexId, bool) {
+	id, ok := e.sources[v]
+	return id, ok
+}
+
+// Sink returns the expanded vertex routes ending at v end at.
+func (e *ExpandedGraph) Sink(v graph.VertexId) (graph.VertexId, bool) {
+	id, ok := e.sinks[v]
+	return id, ok
+}
+
+// OriginalPath translates a path from a source to a sink of the expansion
+// back into the vertices of the original graph.
+func (e *ExpandedGraph) OriginalPath(path []graph.VertexId) []graph.VertexId {
+	var original []graph.VertexId
+	for _, id := range path {
+		arc, ok := e.arcs[id]
+		if !ok {
+			continue
+		}
+		if len(original) == 0 {
+			original = append(original, arc.From)
+		}
+		original = append(original, arc.To)
+	}
+	return original
+}
+
+// Order derives a contraction order of the expansion from a contraction order
+// of the original graph, listed from the lowest to the highest rank. Source
+// and sink vertices are contracted first. Arcs follow ordered by the higher
+// rank of their two endpoints, so arcs at separator vertices of the original
+// order end up at the top of the hierarchy of the expansion as well.
+func (e *ExpandedGraph) Order(order []graph.VertexId) []graph.VertexId {
+	rank := make(map[graph.VertexId]int, len(order))
+	for i, v := range order {
+		rank[v] = i
+	}
+
+	result := make([]graph.VertexId, 0, len(e.Graph.Vertices))
+	for _, v := range order {
+		result = append(result, e.sources[v], e.sinks[v])
+	}
+
+	arcIds := make([]graph.VertexId, 0, len(e.arcs))
+	for id := range e.arcs {
+		arcIds = append(arcIds, id)
+	}
+	key := func(id graph.VertexId) (int, int) {
+		arc := e.arcs[id]
+		lo, hi := rank[arc.From], rank[arc.To]
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		return hi, lo
+	}
+	sort.Slice(arcIds, func(i, j int) bool {
+		hiI, loI := key(arcIds[i])
+		hiJ, loJ := key(arcIds[j])
+		if hiI != hiJ {
+			return hiI < hiJ
+		}
+		if loI != loJ {
+			return loI < loJ
+		}
+		return arcIds[i] < arcIds[j]
+	})
+
+	return append(result, arcIds...)
+}
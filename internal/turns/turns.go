@@ -0,0 +1,162 @@
+// Package turns models turn costs and turn restrictions. A Table stores the
+// cost of every turn that differs from the default of zero and the turns that
+// are forbidden, for example by a no-left-turn sign. Expand turns a graph and
+// a Table into an edge-based graph on which every ordinary shortest path
+// algorithm respects the turns.
+package turns
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrInvalidTurn is returned when a turn does not consist of two edges of the graph.
+var ErrInvalidTurn = errors.New("turn does not follow edges of the graph")
+
+// Turn is the transition from the edge From -> Via onto the edge Via -> To.
+// A turn with From == To is a U-turn.
+type Turn struct {
+	From graph.VertexId
+	Via  graph.VertexId
+	To   graph.VertexId
+}
+
+// IsUTurn reports whether the turn leads back to the vertex it came from.
+func (t Turn) IsUTurn() bool {
+	return t.From == t.To
+}
+
+func (t Turn) String() string {
+	return fmt.Sprintf("%d -> %d -> %d", t.From, t.Via, t.To)
+}
+
+// Restriction is a turn restriction as found in OSM restriction relations.
+// If Only is false the turn is forbidden (no_left_turn, no_u_turn, ...). If
+// Only is true it is the only turn allowed when arriving from From at Via
+// (only_straight_on, ...), so all other turns are forbidden.
+type Restriction struct {
+	Turn
+	Only bool
+}
+
+// Table holds turn costs and forbidden turns. The zero cost applies to every
+// turn without an entry. A nil *Table allows all turns at no cost.
+type Table struct {
+	costs     map[Turn]int
+	forbidden map[Turn]bool
+	// onlyExits holds the exits allowed by only_* restrictions for every
+	// entry edge from -> via that has any.
+	onlyExits    map[[2]graph.VertexId]map[graph.VertexId]bool
+	uTurnCost    int
+	forbidUTurns bool
+}
+
+// NewTable returns an empty table that allows all turns at no cost.
+func NewTable() *Table {
+	return &Table{
+		costs:     make(map[Turn]int),
+		forbidden: make(map[Turn]bool),
+		onlyExits: make(map[[2]graph.VertexId]map[graph.VertexId]bool),
+	}
+}
+
+// Forbid forbids the turn from -> via -> to.
+func (t *Table) Forbid(from, via, to graph.VertexId) {
+	t.forbidden[Turn{from, via, to}] = true
+}
+
+// SetCost sets the cost of the turn from -> via -> to. It overrides the
+// default U-turn cost for that turn.
+func (t *Table) SetCost(from, via, to graph.VertexId, cost int) {
+	t.costs[Turn{from, via, to}] = cost
+}
+
+// SetUTurnCost sets the cost of every U-turn without an explicit cost.
+func (t *Table) SetUTurnCost(cost int) {
+	t.uTurnCost = cost
+}
+
+// ForbidUTurns forbids all U-turns.
+func (t *Table) ForbidUTurns() {
+	t.forbidUTurns = true
+}
+
+// AddRestriction adds a turn restriction after checking that its edges are
+// part of g. An only_* restriction forbids every turn from the edge from ->
+// via except those allowed by the only_* restrictions of that edge, so an
+// only_straight_on whose to-way passes through via allows both directions of
+// the way.
+func (t *Table) AddRestriction(g *graph.Graph, r Restriction) error {
+	if _, ok := g.Edges[r.From][r.Via]; !ok {
+		return fmt.Errorf("%w: no edge %d -> %d in restriction %v", ErrInvalidTurn, r.From, r.Via, r.Turn)
+	}
+	if _, ok := g.Edges[r.Via][r.To]; !ok {
+		return fmt.Errorf("%w: no edge %d -> %d in restriction %v", ErrInvalidTurn, r.Via, r.To, r.Turn)
+	}
+
+	if !r.Only {
+		t.Forbid(r.From, r.Via, r.To)
+		return nil
+	}
+	entry := [2]graph.VertexId{r.From, r.Via}
+	if t.onlyExits[entry] == nil {
+		t.onlyExits[entry] = make(map[graph.VertexId]bool)
+	}
+	t.onlyExits[entry][r.To] = true
+	return nil
+}
+
+// Cost returns the cost of the turn from -> via -> to and whether the turn is
+// allowed at all. Its signature matches pathfinding.TurnCostFunc.
+func (t *Table) Cost(from, via, to graph.VertexId) (int, bool) {
+	if t == nil {
+		return 0, true
+	}
+
+	turn := Turn{from, via, to}
+	if t.forbidden[turn] || (turn.IsUTurn() && t.forbidUTurns) {
+		return 0, false
+	}
+	if exits, ok := t.onlyExits[[2]graph.VertexId{from, via}]; ok && !exits[to] {
+		return 0, false
+	}
+	if cost, ok := t.costs[turn]; ok {
+		return cost, true
+	}
+	if turn.IsUTurn() {
+		return t.uTurnCost, true
+	}
+	return 0, true
+}
+
+// Entry is a turn whose cost differs from zero or that is forbidden.
+type Entry struct {
+	Turn
+	Cost      int
+	Forbidden bool
+}
+
+// Entries returns every turn between two edges of g that is forbidden or has
+// a cost, ordered by from, via and to. It resolves only_* restrictions and
+// U-turn defaults into single turns, e.g. to write the table to a file.
+func (t *Table) Entries(g *graph.Graph) []Entry {
+	var entries []Entry
+	for from, edges := range g.Edges {
+		for via := range edges {
+			for to := range g.Edges[via] {
+				cost, allowed := t.Cost(from, via, to)
+				if cost != 0 || !allowed {
+					entries = append(entries, Entry{Turn: Turn{from, via, to}, Cost: cost, Forbidden: !allowed})
+				}
+			}
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.Via, b.Via), cmp.Compare(a.To, b.To))
+	})
+	return entries
+}
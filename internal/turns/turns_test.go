@@ -0,0 +1,199 @@
+package turns
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// createCrossing builds a four way crossing at vertex 0 with the arms 1 to 4.
+func createCrossing() *graph.Graph {
+	g := graph.NewGraph()
+	for i := 0; i <= 4; i++ {
+		g.AddVertex(graph.Vertex{Id: graph.VertexId(i)})
+	}
+	for i := 1; i <= 4; i++ {
+		g.AddEdge(0, graph.VertexId(i), i, false, -1)
+		g.AddEdge(graph.VertexId(i), 0, i, false, -1)
+	}
+	return g
+}
+
+func TestTableCost(t *testing.T) {
+	table := NewTable()
+	table.Forbid(1, 0, 2)
+	table.SetCost(1, 0, 3, 7)
+	table.SetUTurnCost(4)
+	table.SetCost(2, 0, 2, 1)
+
+	testCases := []struct {
+		turn        Turn
+		wantCost    int
+		wantAllowed bool
+	}{
+		{Turn{1, 0, 2}, 0, false},
+		{Turn{1, 0, 3}, 7, true},
+		{Turn{1, 0, 4}, 0, true},
+		{Turn{1, 0, 1}, 4, true},
+		{Turn{2, 0, 2}, 1, true},
+	}
+	for _, tc := range testCases {
+		cost, allowed := table.Cost(tc.turn.From, tc.turn.Via, tc.turn.To)
+		if cost != tc.wantCost || allowed != tc.wantAllowed {
+			t.Errorf("turn %v: got (%d, %t), want (%d, %t)", tc.turn, cost, allowed, tc.wantCost, tc.wantAllowed)
+		}
+	}
+
+	table.ForbidUTurns()
+	if _, allowed := table.Cost(2, 0, 2); allowed {
+		t.Error("expected U-turns to be forbidden")
+	}
+
+	var empty *Table
+	if cost, allowed := empty.Cost(1, 0, 2); cost != 0 || !allowed {
+		t.Errorf("nil table: got (%d, %t), want (0, true)", cost, allowed)
+	}
+}
+
+func TestAddRestriction(t *testing.T) {
+	g := createCrossing()
+	table := NewTable()
+
+	if err := table.AddRestriction(g, Restriction{Turn: Turn{1, 0, 3}, Only: true}); err != nil {
+		t.Fatalf("AddRestriction failed: %v", err)
+	}
+	for to, want := range map[graph.VertexId]bool{1: false, 2: false, 3: true, 4: false} {
+		if _, allowed := table.Cost(1, 0, to); allowed != want {
+			t.Errorf("turn 1 -> 0 -> %d: got allowed %t, want %t", to, allowed, want)
+		}
+	}
+
+	if err := table.AddRestriction(g, Restriction{Turn: Turn{2, 0, 4}}); err != nil {
+		t.Fatalf("AddRestriction failed: %v", err)
+	}
+	if _, allowed := table.Cost(2, 0, 4); allowed {
+		t.Error("expected turn 2 -> 0 -> 4 to be forbidden")
+	}
+	if _, allowed := table.Cost(2, 0, 3); !allowed {
+		t.Error("expected turn 2 -> 0 -> 3 to stay allowed")
+	}
+
+	err := table.AddRestriction(g, Restriction{Turn: Turn{1, 2, 0}})
+	if !errors.Is(err, ErrInvalidTurn) {
+		t.Errorf("expected ErrInvalidTurn, got %v", err)
+	}
+}
+
+func TestAddRestrictionOnlyTwoExits(t *testing.T) {
+	// An only_straight_on whose to-way passes through the via vertex allows
+	// both of its directions, in whichever order they are added.
+	g := createCrossing()
+	table := NewTable()
+	for _, to := range []graph.VertexId{3, 4} {
+		if err := table.AddRestriction(g, Restriction{Turn: Turn{1, 0, to}, Only: true}); err != nil {
+			t.Fatalf("AddRestriction failed: %v", err)
+		}
+	}
+	for to, want := range map[graph.VertexId]bool{1: false, 2: false, 3: true, 4: true} {
+		if _, allowed := table.Cost(1, 0, to); allowed != want {
+			t.Errorf("turn 1 -> 0 -> %d: got allowed %t, want %t", to, allowed, want)
+		}
+	}
+	if _, allowed := table.Cost(2, 0, 1); !allowed {
+		t.Error("expected turns from another edge to stay allowed")
+	}
+}
+
+func TestEntries(t *testing.T) {
+	g := createCrossing()
+	table := NewTable()
+	table.SetCost(2, 0, 3, 5)
+	if err := table.AddRestriction(g, Restriction{Turn: Turn{1, 0, 3}, Only: true}); err != nil {
+		t.Fatalf("AddRestriction failed: %v", err)
+	}
+
+	want := []Entry{
+		{Turn: Turn{1, 0, 1}, Forbidden: true},
+		{Turn: Turn{1, 0, 2}, Forbidden: true},
+		{Turn: Turn{1, 0, 4}, Forbidden: true},
+		{Turn: Turn{2, 0, 3}, Cost: 5},
+	}
+	if got := table.Entries(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	g := createCrossing()
+	table := NewTable()
+	table.Forbid(1, 0, 2)
+	table.SetCost(1, 0, 3, 5)
+
+	e := Expand(g, table)
+
+	// 8 arcs plus a source and a sink for each of the 5 vertices.
+	if got := len(e.Graph.Vertices); got != 18 {
+		t.Fatalf("got %d vertices, want 18", got)
+	}
+
+	arcId := func(from, to graph.VertexId) graph.VertexId {
+		t.Helper()
+		id, ok := e.arcIds[Arc{from, to}]
+		if !ok {
+			t.Fatalf("missing arc %d -> %d", from, to)
+		}
+		return id
+	}
+	in := arcId(1, 0)
+	if w := e.Graph.Edges[in][arcId(0, 2)].Weight; w != graph.InfWeight {
+		t.Errorf("forbidden turn has weight %d, want infinity", w)
+	}
+	if w := e.Graph.Edges[in][arcId(0, 3)].Weight; w != 8 {
+		t.Errorf("turn with cost has weight %d, want 8", w)
+	}
+	if w := e.Graph.Edges[in][arcId(0, 4)].Weight; w != 4 {
+		t.Errorf("free turn has weight %d, want 4", w)
+	}
+
+	source, _ := e.Source(1)
+	sink, _ := e.Sink(3)
+	if _, ok := e.Graph.Edges[source][in]; !ok {
+		t.Error("missing edge from the source of 1 to arc 1 -> 0")
+	}
+	if _, ok := e.Graph.Edges[arcId(0, 3)][sink]; !ok {
+		t.Error("missing edge from arc 0 -> 3 to the sink of 3")
+	}
+
+	path := []graph.VertexId{source, in, arcId(0, 4), arcId(4, 0), arcId(0, 3), sink}
+	if got, want := e.OriginalPath(path), []graph.VertexId{1, 0, 4, 0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got original path %v, want %v", got, want)
+	}
+
+	// Expanding the same topology again must yield the same vertex ids.
+	if again := Expand(g, nil); !reflect.DeepEqual(again.arcIds, e.arcIds) {
+		t.Error("expansion is not deterministic")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	e := Expand(createCrossing(), nil)
+	order := e.Order([]graph.VertexId{1, 2, 3, 4, 0})
+
+	if len(order) != len(e.Graph.Vertices) {
+		t.Fatalf("order has %d entries, want %d", len(order), len(e.Graph.Vertices))
+	}
+	seen := make(map[graph.VertexId]bool)
+	for _, id := range order {
+		if seen[id] {
+			t.Fatalf("vertex %d appears twice in the order", id)
+		}
+		seen[id] = true
+	}
+	for _, id := range order[:10] {
+		if _, isArc := e.Arc(id); isArc {
+			t.Errorf("arc %d is ordered before a source or sink vertex", id)
+		}
+	}
+}
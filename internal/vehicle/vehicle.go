@@ -0,0 +1,193 @@
+// Package vehicle defines vehicle profiles. A profile derives a travel time
+// metric from the attributes of the edges of a road network and excludes the
+// edges the vehicle may not use, so the same CCH can be customized for cars,
+// trucks, bicycles and pedestrians.
+package vehicle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrUnknownProfile is returned by ByName for names without a profile.
+var ErrUnknownProfile = errors.New("unknown vehicle profile")
+
+// Profile describes how a type of vehicle uses the road network. Speeds are
+// given in km/h. Roads whose class has no speed are not used at all.
+type Profile struct {
+	Name string
+	// Access is the vehicle type checked against the restrictions of an edge.
+	Access graph.Access
+	// Speeds is the typical speed on each road class.
+	Speeds map[graph.RoadClass]float64
+	// MaxSpeed caps the speed of the vehicle, e.g. at 80 km/h for trucks.
+	MaxSpeed float64
+	// RespectSpeedLimits lowers the speed to the speed limit of a road.
+	RespectSpeedLimits bool
+}
+
+var (
+	Car = Profile{
+		Name:   "car",
+		Access: graph.AccessCar,
+		Speeds: map[graph.RoadClass]float64{
+			graph.RoadClassMotorway:     120,
+			graph.RoadClassTrunk:        100,
+			graph.RoadClassPrimary:      80,
+			graph.RoadClassSecondary:    70,
+			graph.RoadClassTertiary:     60,
+			graph.RoadClassUnclassified: 50,
+			graph.RoadClassResidential:  30,
+			graph.RoadClassLivingStreet: 7,
+			graph.RoadClassService:      20,
+			graph.RoadClassUnknown:      50,
+		},
+		MaxSpeed:           180,
+		RespectSpeedLimits: true,
+	}
+
+	Truck = Profile{
+		Name:   "truck",
+		Access: graph.AccessTruck,
+		Speeds: map[graph.RoadClass]float64{
+			graph.RoadClassMotorway:     80,
+			graph.RoadClassTrunk:        80,
+			graph.RoadClassPrimary:      70,
+			graph.RoadClassSecondary:    60,
+			graph.RoadClassTertiary:     50,
+			graph.RoadClassUnclassified: 40,
+			graph.RoadClassResidential:  25,
+			graph.RoadClassLivingStreet: 7,
+			graph.RoadClassService:      15,
+			graph.RoadClassUnknown:      40,
+		},
+		MaxSpeed:           80,
+		RespectSpeedLimits: true,
+	}
+
+	Bicycle = Profile{
+		Name:   "bicycle",
+		Access: graph.AccessBicycle,
+		Speeds: map[graph.RoadClass]float64{
+			graph.RoadClassPrimary:      18,
+			graph.RoadClassSecondary:    18,
+			graph.RoadClassTertiary:     18,
+			graph.RoadClassUnclassified: 18,
+			graph.RoadClassResidential:  18,
+			graph.RoadClassLivingStreet: 10,
+			graph.RoadClassService:      15,
+			graph.RoadClassTrack:        12,
+			graph.RoadClassCycleway:     20,
+			graph.RoadClassFootway:      6,
+			graph.RoadClassUnknown:      15,
+		},
+		MaxSpeed: 25,
+	}
+
+	Pedestrian = Profile{
+		Name:   "pedestrian",
+		Access: graph.AccessPedestrian,
+		Speeds: map[graph.RoadClass]float64{
+			graph.RoadClassPrimary:      5,
+			graph.RoadClassSecondary:    5,
+			graph.RoadClassTertiary:     5,
+			graph.RoadClassUnclassified: 5,
+			graph.RoadClassResidential:  5,
+			graph.RoadClassLivingStreet: 5,
+			graph.RoadClassService:      5,
+			graph.RoadClassTrack:        5,
+			graph.RoadClassCycleway:     5,
+			graph.RoadClassFootway:      5,
+			graph.RoadClassUnknown:      5,
+		},
+		MaxSpeed: 5,
+	}
+)
+
+var profiles = map[string]Profile{
+	Car.Name:        Car,
+	Truck.Name:      Truck,
+	Bicycle.Name:    Bicycle,
+	Pedestrian.Name: Pedestrian,
+}
+
+// ByName returns the built-in profile with the given name.
+func ByName(name string) (Profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// Names returns the names of all built-in profiles in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Speed returns the speed in km/h the vehicle drives on a road with the given
+// attributes. The second result is false if the vehicle may not use the road.
+func (p Profile) Speed(a graph.Attributes) (float64, bool) {
+	if !a.Allows(p.Access) {
+		return 0, false
+	}
+	speed, ok := p.Speeds[a.RoadClass]
+	if !ok || speed <= 0 {
+		return 0, false
+	}
+	if p.RespectSpeedLimits && a.MaxSpeed > 0 {
+		speed = math.Min(speed, float64(a.MaxSpeed))
+	}
+	if p.MaxSpeed > 0 {
+		speed = math.Min(speed, p.MaxSpeed)
+	}
+	return speed, true
+}
+
+// TravelTime returns the time in milliseconds the vehicle needs for a road
+// with the given attributes, at least 1. The second result is false if the
+// vehicle may not use the road.
+func (p Profile) TravelTime(a graph.Attributes) (int, bool) {
+	speed, ok := p.Speed(a)
+	if !ok {
+		return 0, false
+	}
+	millis := int(math.Round(a.Length / (speed / 3.6) * 1000))
+	return max(millis, 1), true
+}
+
+// Metric returns a copy of g whose weights are the travel times of the
+// vehicle in milliseconds. Edges the vehicle may not use get graph.InfWeight,
+// so the topology stays the same and the result can be used to customize a
+// CCH built for g. Edges without a length use the distance between their end
+// points.
+func (p Profile) Metric(g *graph.Graph) *graph.Graph {
+	metric := g.Clone()
+	for from, targets := range metric.Edges {
+		for to, edge := range targets {
+			if edge.IsShortcut {
+				continue
+			}
+			attributes := edge.Attributes
+			if attributes.Length == 0 {
+				attributes.Length = graph.Distance(g.Vertices[from], g.Vertices[to])
+			}
+			if travelTime, ok := p.TravelTime(attributes); ok {
+				edge.Weight = travelTime
+			} else {
+				edge.Weight = graph.InfWeight
+			}
+			targets[to] = edge
+		}
+	}
+	return metric
+}
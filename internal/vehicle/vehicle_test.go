@@ -0,0 +1,85 @@
+package vehicle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func TestSpeed(t *testing.T) {
+	testCases := []struct {
+		name       string
+		profile    Profile
+		attributes graph.Attributes
+		want       float64
+		wantOk     bool
+	}{
+		{"class speed", Car, graph.Attributes{RoadClass: graph.RoadClassPrimary}, 80, true},
+		{"speed limit", Car, graph.Attributes{RoadClass: graph.RoadClassMotorway, MaxSpeed: 100}, 100, true},
+		{"vehicle cap", Truck, graph.Attributes{RoadClass: graph.RoadClassMotorway, MaxSpeed: 130}, 80, true},
+		{"no class speed", Car, graph.Attributes{RoadClass: graph.RoadClassFootway}, 0, false},
+		{"restricted", Bicycle, graph.Attributes{RoadClass: graph.RoadClassPrimary, Restricted: graph.AccessBicycle}, 0, false},
+		{"limit ignored", Bicycle, graph.Attributes{RoadClass: graph.RoadClassCycleway, MaxSpeed: 10}, 20, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.profile.Speed(tc.attributes)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("got (%f, %t) want (%f, %t)", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestTravelTime(t *testing.T) {
+	// 1 km at 60 km/h takes one minute.
+	got, ok := Car.TravelTime(graph.Attributes{RoadClass: graph.RoadClassTertiary, Length: 1000})
+	if !ok || got != 60000 {
+		t.Errorf("got (%d, %t) want (60000, true)", got, ok)
+	}
+	if got, _ := Car.TravelTime(graph.Attributes{RoadClass: graph.RoadClassTertiary}); got != 1 {
+		t.Errorf("got %d for an edge of length 0, want 1", got)
+	}
+}
+
+func TestMetric(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0, Lat: 48.0, Lon: 9.0})
+	g.AddVertex(graph.Vertex{Id: 1, Lat: 48.001, Lon: 9.0})
+	g.AddEdge(0, 1, 1, false, -1)
+	g.AddEdge(1, 0, 1, false, -1)
+	g.SetAttributes(0, 1, graph.Attributes{RoadClass: graph.RoadClassResidential, Length: 250})
+	g.SetAttributes(1, 0, graph.Attributes{RoadClass: graph.RoadClassFootway, Restricted: graph.AccessCar})
+
+	metric := Car.Metric(g)
+	if got := metric.Edges[0][1].Weight; got != 30000 {
+		t.Errorf("got %d for 250 m at 30 km/h, want 30000", got)
+	}
+	if got := metric.Edges[1][0].Weight; got != graph.InfWeight {
+		t.Errorf("got %d for a road closed to cars, want InfWeight", got)
+	}
+	if got := g.Edges[0][1].Weight; got != 1 {
+		t.Errorf("Metric changed the weight of the original graph to %d", got)
+	}
+
+	// Without a length the distance between the end points of about 111 m is used.
+	walking := Pedestrian.Metric(g)
+	if got := walking.Edges[1][0].Weight; got < 79000 || got > 81000 {
+		t.Errorf("got %d for about 111 m at 5 km/h, want about 80000", got)
+	}
+}
+
+func TestByName(t *testing.T) {
+	p, err := ByName("truck")
+	if err != nil || p.Name != "truck" {
+		t.Errorf("got (%v, %v) want the truck profile", p.Name, err)
+	}
+	if _, err := ByName("boat"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("got %v want %v", err, ErrUnknownProfile)
+	}
+	if got, want := Names(), []string{"bicycle", "car", "pedestrian", "truck"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
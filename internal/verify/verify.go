@@ -0,0 +1,366 @@
+// Package verify checks preprocessed contraction hierarchies. It tests the
+// structural invariants that CH and CCH share and compares their query
+// results with Dijkstra on the original graph.
+//
+// Both store an edge between u and w, where u has the lower rank, as the
+// upward edge (u, w) weighted with u -> w and the downward edge (w, u)
+// weighted with w -> u. A shortcut via x stands for the path over x, which
+// ranks below both of its endpoints.
+package verify
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+	"github.com/PaulMue0/efficient-routeplanning/pkg/collection/queue"
+)
+
+// Names of the structural checks.
+const (
+	CheckOrder          = "order"           // every vertex is ranked exactly once
+	CheckUpRank         = "up-rank"         // upward edges go up in rank
+	CheckDownRank       = "down-rank"       // downward edges go down in rank
+	CheckMirror         = "mirror"          // the downward graph mirrors the upward graph
+	CheckViaRank        = "via-rank"        // shortcuts skip a lower vertex
+	CheckShortcutWeight = "shortcut-weight" // shortcuts weigh as much as their halves
+)
+
+// maxExamples is the number of violations and mismatches a report lists per kind.
+const maxExamples = 20
+
+// Hierarchy is the view of a CH or CCH the checks need.
+type Hierarchy struct {
+	Name             string
+	UpwardsGraph     *graph.Graph
+	DownwardsGraph   *graph.Graph
+	ContractionOrder []graph.VertexId
+	Core             *graph.Graph // Uncontracted vertices of a core CH, may be nil
+	Query            func(source, target graph.VertexId) ([]graph.VertexId, float64, error)
+}
+
+// FromCH returns the hierarchy of a contraction hierarchy.
+func FromCH(c *ch.ContractionHierarchies) Hierarchy {
+	return Hierarchy{
+		Name:             "CH",
+		UpwardsGraph:     c.UpwardsGraph,
+		DownwardsGraph:   c.DownwardsGraph,
+		ContractionOrder: c.ContractionOrder,
+		Core:             c.Core,
+		Query: func(source, target graph.VertexId) ([]graph.VertexId, float64, error) {
+			path, dist, _, err := c.Query(source, target)
+			return path, dist, err
+		},
+	}
+}
+
+// FromCCH returns the hierarchy of a customized CCH.
+func FromCCH(c *cch.CCH) Hierarchy {
+	return Hierarchy{
+		Name:             "CCH",
+		UpwardsGraph:     c.UpwardsGraph,
+		DownwardsGraph:   c.DownwardsGraph,
+		ContractionOrder: c.ContractionOrder,
+		Query: func(source, target graph.VertexId) ([]graph.VertexId, float64, error) {
+			path, dist, _, err := c.Query(source, target)
+			return path, dist, err
+		},
+	}
+}
+
+// Violation is a broken structural invariant.
+type Violation struct {
+	Check   string
+	Message string
+}
+
+// Mismatch is a query whose result differs from Dijkstra.
+type Mismatch struct {
+	Source, Target graph.VertexId
+	Want, Got      float64
+	WantErr, Err   error
+	Reason         string
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	Hierarchy  string
+	Vertices   int
+	Edges      int // Upward, downward and core edges checked
+	Queries    int
+	AllPairs   bool
+	Violations []Violation
+	Mismatches []Mismatch
+}
+
+// OK reports whether no check failed.
+func (r *Report) OK() bool {
+	return len(r.Violations) == 0 && len(r.Mismatches) == 0
+}
+
+// Options configure Run.
+type Options struct {
+	Pairs         int   // Random source-target pairs
+	Seed          int64 // Seed of the random pairs
+	AllPairsLimit int   // Compare all pairs if the graph has at most this many vertices
+}
+
+// Run checks the structure of h and compares its distances with Dijkstra on
+// g, for all pairs if g is small enough and for random pairs otherwise.
+func Run(g *graph.Graph, h Hierarchy, opts Options) *Report {
+	r := &Report{Hierarchy: h.Name, Vertices: len(g.Vertices)}
+	r.Violations, r.Edges = Structure(g, h)
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for v := range g.Vertices {
+		vertices = append(vertices, v)
+	}
+	slices.Sort(vertices)
+
+	if len(vertices) <= opts.AllPairsLimit {
+		r.AllPairs = true
+		for _, source := range vertices {
+			dists := pathfinding.UpwardSearch(g, nil, source, nil)
+			for _, target := range vertices {
+				want, ok := dists[target]
+				var wantErr error
+				if !ok {
+					wantErr = pathfinding.ErrTargetNotReachable
+				}
+				r.compare(g, h, source, target, want, wantErr)
+			}
+		}
+		return r
+	}
+
+	if len(vertices) == 0 {
+		return r
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	w := pathfinding.NewWorkspace(len(vertices), queue.FourAry)
+	for range opts.Pairs {
+		source, target := vertices[rng.Intn(len(vertices))], vertices[rng.Intn(len(vertices))]
+		_, want, _, wantErr := w.DijkstraShortestPathExcluding(g, source, target, math.Inf(1), nil)
+		r.compare(g, h, source, target, want, wantErr)
+	}
+	return r
+}
+
+// compare runs the query from source to target on h and records a mismatch
+// if its distance or path disagrees with the Dijkstra result.
+func (r *Report) compare(g *graph.Graph, h Hierarchy, source, target graph.VertexId, want float64, wantErr error) {
+	r.Queries++
+	path, got, err := h.Query(source, target)
+	m := Mismatch{Source: source, Target: target, Want: want, Got: got, WantErr: wantErr, Err: err}
+
+	switch {
+	case wantErr != nil && err != nil:
+		return
+	case wantErr != nil:
+		m.Reason = "target is unreachable but the query found a path"
+	case err != nil:
+		m.Reason = "query failed"
+	case got != want:
+		m.Reason = "distance differs"
+	default:
+		length, err := pathLength(g, path, source, target)
+		if err != nil {
+			m.Reason = fmt.Sprintf("invalid path: %v", err)
+		} else if length != want {
+			m.Reason = fmt.Sprintf("path has length %v", length)
+		} else {
+			return
+		}
+	}
+	r.Mismatches = append(r.Mismatches, m)
+}
+
+// pathLength returns the length of path on g and checks that it leads from
+// source to target.
+func pathLength(g *graph.Graph, path []graph.VertexId, source, target graph.VertexId) (float64, error) {
+	if len(path) == 0 || path[0] != source || path[len(path)-1] != target {
+		return 0, fmt.Errorf("path does not lead from %d to %d", source, target)
+	}
+	length := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		edge, ok := g.Edges[path[i]][path[i+1]]
+		if !ok {
+			return 0, fmt.Errorf("no edge %d -> %d", path[i], path[i+1])
+		}
+		length += float64(edge.Weight)
+	}
+	return length, nil
+}
+
+// Structure checks the structural invariants of h and returns the violations
+// and the number of edges checked.
+func Structure(g *graph.Graph, h Hierarchy) ([]Violation, int) {
+	var violations []Violation
+	report := func(check, format string, args ...any) {
+		violations = append(violations, Violation{check, fmt.Sprintf(format, args...)})
+	}
+
+	// Core vertices rank above all contracted ones and among themselves equally.
+	rank := make(map[graph.VertexId]int, len(g.Vertices))
+	for i, v := range h.ContractionOrder {
+		if _, ok := rank[v]; ok {
+			report(CheckOrder, "vertex %d is ranked twice", v)
+		}
+		rank[v] = i
+	}
+	if h.Core != nil {
+		for v := range h.Core.Vertices {
+			if _, ok := rank[v]; ok {
+				report(CheckOrder, "core vertex %d is ranked", v)
+			}
+			rank[v] = len(h.ContractionOrder)
+		}
+	}
+	for v := range g.Vertices {
+		if _, ok := rank[v]; !ok {
+			report(CheckOrder, "vertex %d is not ranked", v)
+		}
+	}
+
+	// weight returns the weight of the edge from -> to of the hierarchy.
+	weight := func(from, to graph.VertexId) (int, bool) {
+		var edge graph.Edge
+		var ok bool
+		switch {
+		case h.Core != nil && rank[from] == len(h.ContractionOrder) && rank[to] == len(h.ContractionOrder):
+			edge, ok = h.Core.Edges[from][to]
+		case rank[from] < rank[to]:
+			edge, ok = h.UpwardsGraph.Edges[from][to]
+		default:
+			edge, ok = h.DownwardsGraph.Edges[from][to]
+		}
+		return edge.Weight, ok
+	}
+
+	// checkShortcut checks the via vertex and the weight of the shortcut from -> to.
+	checkShortcut := func(kind string, from, to graph.VertexId, edge graph.Edge) {
+		via, ok := rank[edge.Via]
+		if !ok {
+			report(CheckViaRank, "%s shortcut %d -> %d: via vertex %d is not ranked", kind, from, to, edge.Via)
+			return
+		}
+		if via >= rank[from] || via >= rank[to] {
+			report(CheckViaRank, "%s shortcut %d -> %d: via vertex %d has rank %d, endpoints %d and %d",
+				kind, from, to, edge.Via, via, rank[from], rank[to])
+			return
+		}
+		first, ok1 := weight(from, edge.Via)
+		second, ok2 := weight(edge.Via, to)
+		if !ok1 || !ok2 {
+			report(CheckShortcutWeight, "%s shortcut %d -> %d: missing half over %d", kind, from, to, edge.Via)
+			return
+		}
+		if sum := graph.AddWeights(first, second); sum != edge.Weight {
+			report(CheckShortcutWeight, "%s shortcut %d -> %d has weight %d, its halves over %d weigh %d + %d",
+				kind, from, to, edge.Weight, edge.Via, first, second)
+		}
+	}
+
+	edges := 0
+	for u, out := range h.UpwardsGraph.Edges {
+		for w, edge := range out {
+			edges++
+			if rank[u] >= rank[w] {
+				report(CheckUpRank, "upward edge %d -> %d goes from rank %d to %d", u, w, rank[u], rank[w])
+			}
+			if _, ok := h.DownwardsGraph.Edges[w][u]; !ok {
+				report(CheckMirror, "upward edge %d -> %d has no downward edge %d -> %d", u, w, w, u)
+			}
+			if edge.IsShortcut {
+				checkShortcut("upward", u, w, edge)
+			}
+		}
+	}
+	for w, out := range h.DownwardsGraph.Edges {
+		for u, edge := range out {
+			edges++
+			if rank[w] <= rank[u] {
+				report(CheckDownRank, "downward edge %d -> %d goes from rank %d to %d", w, u, rank[w], rank[u])
+			}
+			if _, ok := h.UpwardsGraph.Edges[u][w]; !ok {
+				report(CheckMirror, "downward edge %d -> %d has no upward edge %d -> %d", w, u, u, w)
+			}
+			if edge.IsShortcut {
+				checkShortcut("downward", w, u, edge)
+			}
+		}
+	}
+	if h.Core != nil {
+		for u, out := range h.Core.Edges {
+			for w, edge := range out {
+				edges++
+				if edge.IsShortcut {
+					checkShortcut("core", u, w, edge)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(violations, func(a, b Violation) int {
+		return cmp.Or(strings.Compare(a.Check, b.Check), strings.Compare(a.Message, b.Message))
+	})
+	return violations, edges
+}
+
+// Write writes a summary of r and the first violations and mismatches of
+// every kind to w.
+func (r *Report) Write(w io.Writer) error {
+	pairs := "random pairs"
+	if r.AllPairs {
+		pairs = "all pairs"
+	}
+	status := "OK"
+	if !r.OK() {
+		status = "FAILED"
+	}
+	if _, err := fmt.Fprintf(w, "%s verification %s: %d vertices, %d edges, %d queries (%s)\n",
+		r.Hierarchy, status, r.Vertices, r.Edges, r.Queries, pairs); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, v := range r.Violations {
+		counts[v.Check]++
+		if counts[v.Check] <= maxExamples {
+			if _, err := fmt.Fprintf(w, "  [%s] %s\n", v.Check, v.Message); err != nil {
+				return err
+			}
+		}
+	}
+	for _, check := range []string{CheckOrder, CheckUpRank, CheckDownRank, CheckMirror, CheckViaRank, CheckShortcutWeight} {
+		if counts[check] > 0 {
+			if _, err := fmt.Fprintf(w, "%s: %d violations\n", check, counts[check]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, m := range r.Mismatches {
+		if i == maxExamples {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "  [distance] %d -> %d: %s: got %v (%v), Dijkstra %v (%v)\n",
+			m.Source, m.Target, m.Reason, m.Got, m.Err, m.Want, m.WantErr); err != nil {
+			return err
+		}
+	}
+	if len(r.Mismatches) > 0 {
+		if _, err := fmt.Fprintf(w, "distance: %d of %d queries mismatched\n", len(r.Mismatches), r.Queries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
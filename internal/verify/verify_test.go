@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func loadNetwork(t *testing.T, name string) *graph.Graph {
+	t.Helper()
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), name)
+	if err != nil {
+		t.Skipf("failed to load %s: %v", name, err)
+	}
+	return network.Network
+}
+
+func TestRunCH(t *testing.T) {
+	g := loadNetwork(t, "osm1.txt")
+	for name, opts := range map[string][]ch.Option{
+		"full": nil,
+		"core": {ch.WithCore(50, 0)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := ch.NewContractionHierarchies(opts...)
+			c.Preprocess(g.Clone())
+
+			report := Run(g, FromCH(c), Options{Pairs: 200, Seed: 1})
+			if !report.OK() || report.AllPairs || report.Queries != 200 {
+				var buf bytes.Buffer
+				report.Write(&buf)
+				t.Errorf("verification failed:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRunCCHAllPairs(t *testing.T) {
+	g := loadNetwork(t, "example.txt")
+	c := cch.NewCCH()
+	if err := c.Preprocess(g, "../../data/KaHIP/example.ordering"); err != nil {
+		t.Skipf("CCH preprocessing failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+
+	report := Run(g, FromCCH(c), Options{AllPairsLimit: len(g.Vertices)})
+	if !report.OK() || !report.AllPairs || report.Queries != len(g.Vertices)*len(g.Vertices) {
+		var buf bytes.Buffer
+		report.Write(&buf)
+		t.Errorf("verification failed:\n%s", buf.String())
+	}
+}
+
+func TestRunDetectsCorruption(t *testing.T) {
+	g := loadNetwork(t, "osm1.txt")
+	c := ch.NewContractionHierarchies(ch.WithDeterminism())
+	c.Preprocess(g.Clone())
+
+	// Make a shortcut cheaper than its halves and drop the downward edge of an
+	// original upward edge.
+	var shortcut, original [2]graph.VertexId
+	foundShortcut, foundOriginal := false, false
+	for _, u := range c.ContractionOrder {
+		for w, edge := range c.UpwardsGraph.Edges[u] {
+			if edge.IsShortcut && !foundShortcut {
+				shortcut, foundShortcut = [2]graph.VertexId{u, w}, true
+			} else if !edge.IsShortcut && !foundOriginal {
+				original, foundOriginal = [2]graph.VertexId{u, w}, true
+			}
+		}
+	}
+	if !foundShortcut || !foundOriginal {
+		t.Fatal("hierarchy has no shortcut or original edge")
+	}
+	edge := c.UpwardsGraph.Edges[shortcut[0]][shortcut[1]]
+	c.UpwardsGraph.UpdateEdge(shortcut[0], shortcut[1], edge.Weight-1, true, edge.Via)
+	c.DownwardsGraph.RemoveEdge(original[1], original[0])
+
+	report := Run(g, FromCH(c), Options{Pairs: 20000, Seed: 1})
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"FAILED", CheckShortcutWeight + ": 1 violations", CheckMirror + ": 1 violations", "[distance]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+}
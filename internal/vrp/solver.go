@@ -0,0 +1,302 @@
+package vrp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// epsilon is the smallest cost reduction accepted as an improvement.
+const epsilon = 1e-9
+
+// Options controls the solver. A zero TimeLimit runs the local search until no
+// move improves the solution.
+type Options struct {
+	TimeLimit time.Duration
+}
+
+type solver struct {
+	problem  Problem
+	matrix   [][]float64
+	deadline time.Time
+	routes   [][]int
+	costs    []float64
+}
+
+// Solve assigns the stops of p to its vehicles. matrix holds the distances
+// between the locations of p, see Problem.Locations; unreachable pairs are
+// +Inf. Stops that no vehicle can serve within its capacity and time windows
+// are reported as unassigned.
+func Solve(p Problem, matrix [][]float64, opts Options) (Solution, error) {
+	s, err := newSolver(p, matrix, opts)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	unassigned := make([]int, len(p.Stops))
+	for i := range unassigned {
+		unassigned[i] = i
+	}
+	unassigned = s.insertAll(unassigned)
+
+	for improved := true; improved && !s.expired(); {
+		improved = s.twoOpt()
+		improved = s.relocate() || improved
+		improved = s.exchange() || improved
+		if len(unassigned) > 0 {
+			before := len(unassigned)
+			unassigned = s.insertAll(unassigned)
+			improved = improved || len(unassigned) < before
+		}
+	}
+
+	return s.solution(unassigned), nil
+}
+
+func newSolver(p Problem, matrix [][]float64, opts Options) (*solver, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	n := len(p.Vehicles) + len(p.Stops)
+	if len(matrix) != n {
+		return nil, fmt.Errorf("%w: %d rows for %d locations", ErrMatrixSize, len(matrix), n)
+	}
+	for i, row := range matrix {
+		if len(row) != n {
+			return nil, fmt.Errorf("%w: row %d has %d entries for %d locations", ErrMatrixSize, i, len(row), n)
+		}
+	}
+
+	s := &solver{
+		problem: p,
+		matrix:  matrix,
+		routes:  make([][]int, len(p.Vehicles)),
+		costs:   make([]float64, len(p.Vehicles)),
+	}
+	if opts.TimeLimit > 0 {
+		s.deadline = time.Now().Add(opts.TimeLimit)
+	}
+	return s, nil
+}
+
+func (s *solver) expired() bool {
+	return !s.deadline.IsZero() && time.Now().After(s.deadline)
+}
+
+func (s *solver) location(stop int) int {
+	return len(s.problem.Vehicles) + stop
+}
+
+// simulate drives the route of vehicle v through stops and returns the
+// arrival times at the stops and the travel cost. The third result is false
+// if the route violates the capacity, a time window or contains an
+// unreachable leg.
+func (s *solver) simulate(v int, stops []int) ([]float64, float64, bool) {
+	vehicle := s.problem.Vehicles[v]
+	load := 0
+	for _, stop := range stops {
+		load += s.problem.Stops[stop].Demand
+	}
+	if load > vehicle.Capacity {
+		return nil, 0, false
+	}
+
+	arrivals := make([]float64, len(stops))
+	now, cost, previous := vehicle.Shift.Earliest, 0.0, v
+	for i, stop := range stops {
+		next := s.location(stop)
+		distance := s.matrix[previous][next]
+		if math.IsInf(distance, 1) {
+			return nil, 0, false
+		}
+		cost += distance
+		now = math.Max(now+distance, s.problem.Stops[stop].Window.Earliest)
+		if !s.problem.Stops[stop].Window.allows(now) {
+			return nil, 0, false
+		}
+		arrivals[i] = now
+		now += s.problem.Stops[stop].Service
+		previous = next
+	}
+
+	distance := s.matrix[previous][v]
+	if math.IsInf(distance, 1) || !vehicle.Shift.allows(now+distance) {
+		return nil, 0, false
+	}
+	return arrivals, cost + distance, true
+}
+
+func (s *solver) evaluate(v int, stops []int) (float64, bool) {
+	_, cost, ok := s.simulate(v, stops)
+	return cost, ok
+}
+
+// insertAll inserts the given stops with cheapest insertion: it repeatedly
+// inserts the stop whose cheapest feasible insertion costs the least. It
+// returns the stops that cannot be inserted anywhere.
+func (s *solver) insertAll(stops []int) []int {
+	remaining := append([]int(nil), stops...)
+	for len(remaining) > 0 && !s.expired() {
+		bestIndex, bestVehicle, bestRoute := -1, -1, []int(nil)
+		bestDelta, bestCost := math.Inf(1), 0.0
+		for i, stop := range remaining {
+			for v, route := range s.routes {
+				for pos := 0; pos <= len(route); pos++ {
+					candidate := inserted(route, pos, stop)
+					cost, ok := s.evaluate(v, candidate)
+					if ok && cost-s.costs[v] < bestDelta {
+						bestIndex, bestVehicle, bestRoute = i, v, candidate
+						bestDelta, bestCost = cost-s.costs[v], cost
+					}
+				}
+			}
+		}
+		if bestIndex == -1 {
+			break
+		}
+		s.routes[bestVehicle], s.costs[bestVehicle] = bestRoute, bestCost
+		remaining = append(remaining[:bestIndex], remaining[bestIndex+1:]...)
+	}
+	return remaining
+}
+
+// twoOpt reverses segments of single routes.
+func (s *solver) twoOpt() bool {
+	improved := false
+	for v, route := range s.routes {
+		for i := 0; i < len(route)-1 && !s.expired(); i++ {
+			for j := i + 1; j < len(route); j++ {
+				candidate := reversed(s.routes[v], i, j)
+				if cost, ok := s.evaluate(v, candidate); ok && cost < s.costs[v]-epsilon {
+					s.routes[v], s.costs[v] = candidate, cost
+					improved = true
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// relocate moves a single stop to another position in the same or another route.
+func (s *solver) relocate() bool {
+	improved := false
+	for a := range s.routes {
+		for i := 0; i < len(s.routes[a]) && !s.expired(); i++ {
+			stop := s.routes[a][i]
+			shortened := without(s.routes[a], i)
+			shortenedCost, ok := s.evaluate(a, shortened)
+			if !ok {
+				continue
+			}
+			for b := range s.routes {
+				target := s.routes[b]
+				if a == b {
+					target = shortened
+				}
+				moved := false
+				for pos := 0; pos <= len(target); pos++ {
+					candidate := inserted(target, pos, stop)
+					cost, ok := s.evaluate(b, candidate)
+					if !ok {
+						continue
+					}
+					if a == b && cost < s.costs[a]-epsilon {
+						s.routes[a], s.costs[a] = candidate, cost
+						moved = true
+						break
+					}
+					if a != b && shortenedCost+cost < s.costs[a]+s.costs[b]-epsilon {
+						s.routes[a], s.costs[a] = shortened, shortenedCost
+						s.routes[b], s.costs[b] = candidate, cost
+						moved = true
+						break
+					}
+				}
+				if moved {
+					improved = true
+					break
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// exchange swaps two stops of the same or of different routes.
+func (s *solver) exchange() bool {
+	improved := false
+	for a := range s.routes {
+		for b := a; b < len(s.routes); b++ {
+			for i := 0; i < len(s.routes[a]) && !s.expired(); i++ {
+				start := 0
+				if a == b {
+					start = i + 1
+				}
+				for j := start; j < len(s.routes[b]); j++ {
+					if a == b {
+						candidate := append([]int(nil), s.routes[a]...)
+						candidate[i], candidate[j] = candidate[j], candidate[i]
+						if cost, ok := s.evaluate(a, candidate); ok && cost < s.costs[a]-epsilon {
+							s.routes[a], s.costs[a] = candidate, cost
+							improved = true
+						}
+						continue
+					}
+
+					routeA := append([]int(nil), s.routes[a]...)
+					routeB := append([]int(nil), s.routes[b]...)
+					routeA[i], routeB[j] = routeB[j], routeA[i]
+					costA, okA := s.evaluate(a, routeA)
+					costB, okB := s.evaluate(b, routeB)
+					if okA && okB && costA+costB < s.costs[a]+s.costs[b]-epsilon {
+						s.routes[a], s.costs[a] = routeA, costA
+						s.routes[b], s.costs[b] = routeB, costB
+						improved = true
+					}
+				}
+			}
+		}
+	}
+	return improved
+}
+
+func (s *solver) solution(unassigned []int) Solution {
+	unassigned = append([]int{}, unassigned...)
+	sort.Ints(unassigned)
+	solution := Solution{Routes: make([]Route, len(s.routes)), Unassigned: unassigned}
+	for v, stops := range s.routes {
+		arrivals, cost, _ := s.simulate(v, stops)
+		load := 0
+		for _, stop := range stops {
+			load += s.problem.Stops[stop].Demand
+		}
+		solution.Routes[v] = Route{Vehicle: v, Stops: append([]int{}, stops...), Arrivals: arrivals, Load: load, Cost: cost}
+		solution.Cost += cost
+	}
+	return solution
+}
+
+// inserted returns a copy of route with stop inserted at position pos.
+func inserted(route []int, pos, stop int) []int {
+	result := make([]int, 0, len(route)+1)
+	result = append(result, route[:pos]...)
+	result = append(result, stop)
+	return append(result, route[pos:]...)
+}
+
+// without returns a copy of route without the stop at position i.
+func without(route []int, i int) []int {
+	result := make([]int, 0, len(route)-1)
+	result = append(result, route[:i]...)
+	return append(result, route[i+1:]...)
+}
+
+// reversed returns a copy of route with the segment route[i..j] reversed.
+func reversed(route []int, i, j int) []int {
+	result := append([]int(nil), route...)
+	for ; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+// Package vrp solves vehicle routing problems with capacities and time
+// windows on top of a distance matrix. Every vehicle starts and ends at its
+// depot, and every stop is visited by at most one vehicle. The solver builds
+// an initial solution with cheapest insertion and improves it with 2-opt,
+// relocate and exchange moves until no move helps or the time limit is hit.
+package vrp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var (
+	// ErrInvalidProblem is returned for problems without vehicles or with
+	// negative demands, capacities or service times.
+	ErrInvalidProblem = errors.New("invalid vehicle routing problem")
+	// ErrMatrixSize is returned if the matrix does not match the locations of the problem.
+	ErrMatrixSize = errors.New("distance matrix does not match the problem")
+)
+
+// TimeWindow is an interval of time in the unit of the edge weights. A zero
+// Latest means that there is no deadline.
+type TimeWindow struct {
+	Earliest float64 `json:"earliest"`
+	Latest   float64 `json:"latest"`
+}
+
+// allows reports whether a vehicle may arrive at time t.
+func (w TimeWindow) allows(t float64) bool {
+	return w.Latest == 0 || t <= w.Latest
+}
+
+// Stop is a customer to visit. A vehicle arriving before the window opens
+// waits, arriving after it closes is not allowed. Service is the time spent
+// at the stop.
+type Stop struct {
+	Vertex  graph.VertexId `json:"vertex"`
+	Demand  int            `json:"demand"`
+	Service float64        `json:"service"`
+	Window  TimeWindow     `json:"window"`
+}
+
+// Vehicle leaves its depot at the start of its shift and must be back before
+// the shift ends.
+type Vehicle struct {
+	Depot    graph.VertexId `json:"depot"`
+	Capacity int            `json:"capacity"`
+	Shift    TimeWindow     `json:"shift"`
+}
+
+// Problem is a set of vehicles and the stops they have to serve.
+type Problem struct {
+	Vehicles []Vehicle `json:"vehicles"`
+	Stops    []Stop    `json:"stops"`
+}
+
+// Validate checks that the problem can be solved at all.
+func (p Problem) Validate() error {
+	if len(p.Vehicles) == 0 {
+		return fmt.Errorf("%w: no vehicles", ErrInvalidProblem)
+	}
+	for i, v := range p.Vehicles {
+		if v.Capacity < 0 {
+			return fmt.Errorf("%w: vehicle %d has a negative capacity", ErrInvalidProblem, i)
+		}
+	}
+	for i, s := range p.Stops {
+		if s.Demand < 0 || s.Service < 0 {
+			return fmt.Errorf("%w: stop %d has a negative demand or service time", ErrInvalidProblem, i)
+		}
+	}
+	return nil
+}
+
+// Locations returns the vertices the distance matrix of the problem is built
+// for: the depots of all vehicles followed by all stops.
+func (p Problem) Locations() []graph.VertexId {
+	locations := make([]graph.VertexId, 0, len(p.Vehicles)+len(p.Stops))
+	for _, v := range p.Vehicles {
+		locations = append(locations, v.Depot)
+	}
+	for _, s := range p.Stops {
+		locations = append(locations, s.Vertex)
+	}
+	return locations
+}
+
+// Matrix computes the distance matrix between the locations of the problem
+// with r.
+func (p Problem) Matrix(r routing.Router) ([][]float64, error) {
+	locations := p.Locations()
+	return routing.DistanceMatrix(r, locations, locations)
+}
+
+// Route is the tour of one vehicle. Stops holds indices into Problem.Stops in
+// visiting order and Arrivals the time the vehicle arrives at each of them.
+type Route struct {
+	Vehicle  int       `json:"vehicle"`
+	Stops    []int     `json:"stops"`
+	Arrivals []float64 `json:"arrivals"`
+	Load     int       `json:"load"`
+	Cost     float64   `json:"cost"`
+}
+
+// Solution assigns stops to vehicles. Routes has one entry per vehicle, stops
+// that cannot be served by any vehicle are listed in Unassigned. Cost is the
+// total travel cost of all routes.
+type Solution struct {
+	Routes     []Route `json:"routes"`
+	Unassigned []int   `json:"unassigned"`
+	Cost       float64 `json:"cost"`
+}
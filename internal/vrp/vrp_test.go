@@ -0,0 +1,171 @@
+package vrp
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// lineMatrix returns the distances between points on a line.
+func lineMatrix(positions []float64) [][]float64 {
+	matrix := make([][]float64, len(positions))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(positions))
+		for j := range matrix[i] {
+			matrix[i][j] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return matrix
+}
+
+// assertFeasible checks capacities, time windows and that every stop is
+// served at most once.
+func assertFeasible(t *testing.T, p Problem, solution Solution) {
+	t.Helper()
+	served := make(map[int]bool)
+	for _, stop := range solution.Unassigned {
+		served[stop] = true
+	}
+	for _, route := range solution.Routes {
+		if route.Load > p.Vehicles[route.Vehicle].Capacity {
+			t.Errorf("vehicle %d carries %d, capacity %d", route.Vehicle, route.Load, p.Vehicles[route.Vehicle].Capacity)
+		}
+		for i, stop := range route.Stops {
+			if served[stop] {
+				t.Errorf("stop %d is served twice", stop)
+			}
+			served[stop] = true
+			if !p.Stops[stop].Window.allows(route.Arrivals[i]) || route.Arrivals[i] < p.Stops[stop].Window.Earliest {
+				t.Errorf("vehicle %d serves stop %d at %f outside of %+v", route.Vehicle, stop, route.Arrivals[i], p.Stops[stop].Window)
+			}
+		}
+	}
+	if len(served) != len(p.Stops) {
+		t.Errorf("%d of %d stops are neither served nor unassigned", len(p.Stops)-len(served), len(p.Stops))
+	}
+}
+
+func TestSolveCapacities(t *testing.T) {
+	// Two vehicles at 0 and stops at -3, -2, 2 and 3. Each vehicle can carry
+	// two stops, so the best solution serves each side with one vehicle.
+	p := Problem{
+		Vehicles: []Vehicle{{Depot: 0, Capacity: 2}, {Depot: 0, Capacity: 2}},
+		Stops:    []Stop{{Vertex: 1, Demand: 1}, {Vertex: 2, Demand: 1}, {Vertex: 3, Demand: 1}, {Vertex: 4, Demand: 1}},
+	}
+	matrix := lineMatrix([]float64{0, 0, 2, -2, 3, -3})
+
+	solution, err := Solve(p, matrix, Options{})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	assertFeasible(t, p, solution)
+	if solution.Cost != 12 || len(solution.Unassigned) != 0 {
+		t.Errorf("got cost %f with unassigned %v, want 12 with every stop served", solution.Cost, solution.Unassigned)
+	}
+}
+
+func TestSolveTimeWindows(t *testing.T) {
+	// The stop at 5 must be served before 6 and the one at 1 after 8, so the
+	// vehicle has to pass the near stop first without serving it.
+	p := Problem{
+		Vehicles: []Vehicle{{Depot: 0, Capacity: 10, Shift: TimeWindow{Latest: 20}}},
+		Stops: []Stop{
+			{Vertex: 1, Demand: 1, Window: TimeWindow{Earliest: 8}},
+			{Vertex: 2, Demand: 1, Window: TimeWindow{Latest: 6}},
+			{Vertex: 3, Demand: 1, Window: TimeWindow{Latest: 1}},
+		},
+	}
+	matrix := lineMatrix([]float64{0, 1, 5, 10})
+
+	solution, err := Solve(p, matrix, Options{})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	assertFeasible(t, p, solution)
+	if want := []int{1, 0}; !reflect.DeepEqual(solution.Routes[0].Stops, want) {
+		t.Errorf("got stops %v want %v", solution.Routes[0].Stops, want)
+	}
+	if want := []int{2}; !reflect.DeepEqual(solution.Unassigned, want) {
+		t.Errorf("got unassigned %v want %v", solution.Unassigned, want)
+	}
+	if want := []float64{5, 9}; !reflect.DeepEqual(solution.Routes[0].Arrivals, want) {
+		t.Errorf("got arrivals %v want %v", solution.Routes[0].Arrivals, want)
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	if _, err := Solve(Problem{}, nil, Options{}); !errors.Is(err, ErrInvalidProblem) {
+		t.Errorf("got %v want %v", err, ErrInvalidProblem)
+	}
+	p := Problem{Vehicles: []Vehicle{{Capacity: 1}}, Stops: []Stop{{Demand: -1}}}
+	if _, err := Solve(p, lineMatrix([]float64{0, 0}), Options{}); !errors.Is(err, ErrInvalidProblem) {
+		t.Errorf("got %v want %v", err, ErrInvalidProblem)
+	}
+	p.Stops[0].Demand = 1
+	if _, err := Solve(p, lineMatrix([]float64{0}), Options{}); !errors.Is(err, ErrMatrixSize) {
+		t.Errorf("got %v want %v", err, ErrMatrixSize)
+	}
+}
+
+func TestSolveOsm1(t *testing.T) {
+	network, err := parser.NewNetworkFromFS(os.DirFS("../../data/RoadNetworks"), "osm1.txt")
+	if err != nil {
+		t.Fatalf("Failed to load graph: %v", err)
+	}
+	g := network.Network
+	c := cch.NewCCH()
+	if err := c.Preprocess(g, "../../data/KaHIP/osm1.ordering"); err != nil {
+		t.Fatalf("CCH preprocessing failed: %v", err)
+	}
+	if err := c.Customize(g); err != nil {
+		t.Fatalf("CCH customization failed: %v", err)
+	}
+
+	vertices := make([]graph.VertexId, 0, len(g.Vertices))
+	for id := range g.Vertices {
+		vertices = append(vertices, id)
+	}
+	rng := rand.New(rand.NewSource(1))
+	p := Problem{}
+	for i := 0; i < 3; i++ {
+		p.Vehicles = append(p.Vehicles, Vehicle{Depot: vertices[rng.Intn(len(vertices))], Capacity: 10})
+	}
+	for i := 0; i < 20; i++ {
+		p.Stops = append(p.Stops, Stop{Vertex: vertices[rng.Intn(len(vertices))], Demand: 1 + rng.Intn(2)})
+	}
+
+	matrix, err := p.Matrix(routing.NewCCHRouter(c))
+	if err != nil {
+		t.Fatalf("Matrix failed: %v", err)
+	}
+	solution, err := Solve(p, matrix, Options{TimeLimit: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	assertFeasible(t, p, solution)
+
+	// The local search never ends with a worse solution than the construction.
+	construction, err := newSolver(p, matrix, Options{})
+	if err != nil {
+		t.Fatalf("newSolver failed: %v", err)
+	}
+	unassigned := make([]int, len(p.Stops))
+	for i := range unassigned {
+		unassigned[i] = i
+	}
+	initial := construction.solution(construction.insertAll(unassigned))
+	if len(solution.Unassigned) > len(initial.Unassigned) ||
+		(len(solution.Unassigned) == len(initial.Unassigned) && solution.Cost > initial.Cost) {
+		t.Errorf("got cost %f with %d unassigned, construction had %f with %d",
+			solution.Cost, len(solution.Unassigned), initial.Cost, len(initial.Unassigned))
+	}
+}
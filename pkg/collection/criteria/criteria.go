@@ -0,0 +1,181 @@
+// Package criteria assigns a vector of costs to the edges of a graph, e.g.
+// travel time, distance and tolls, for multi-criteria routing. The first
+// criterion of every vector corresponds to the static weight of the edge.
+package criteria
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// ErrDimension is returned when a vector or a list of coefficients does not
+// have one entry per criterion.
+var ErrDimension = errors.New("dimension does not match the number of criteria")
+
+// Vector holds one non-negative cost per criterion.
+type Vector []int
+
+// Add returns the componentwise sum of v and w.
+func (v Vector) Add(w Vector) Vector {
+	sum := make(Vector, len(v))
+	for i := range v {
+		sum[i] = v[i] + w[i]
+	}
+	return sum
+}
+
+// Dominates reports whether v is at least as good as w in every criterion.
+// Equal vectors dominate each other.
+func (v Vector) Dominates(w Vector) bool {
+	for i := range v {
+		if v[i] > w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sum returns the sum of all components.
+func (v Vector) Sum() int {
+	sum := 0
+	for _, c := range v {
+		sum += c
+	}
+	return sum
+}
+
+// Less orders vectors lexicographically.
+func (v Vector) Less(w Vector) bool {
+	for i := range v {
+		if v[i] != w[i] {
+			return v[i] < w[i]
+		}
+	}
+	return false
+}
+
+// Costs stores the cost vectors of the edges of a graph. Edges without a
+// vector cost their static weight in the first criterion and nothing in all
+// others.
+type Costs struct {
+	names   []string
+	vectors map[graph.VertexId]map[graph.VertexId]Vector
+}
+
+// NewCosts returns an empty set of cost vectors for the given criteria.
+func NewCosts(names ...string) *Costs {
+	return &Costs{
+		names:   append([]string(nil), names...),
+		vectors: make(map[graph.VertexId]map[graph.VertexId]Vector),
+	}
+}
+
+// Names returns the names of the criteria.
+func (c *Costs) Names() []string {
+	return append([]string(nil), c.names...)
+}
+
+// Dim returns the number of criteria.
+func (c *Costs) Dim() int {
+	return len(c.names)
+}
+
+// Set assigns v to the edge from -> to.
+func (c *Costs) Set(from, to graph.VertexId, v Vector) error {
+	if len(v) != len(c.names) {
+		return fmt.Errorf("%w: got %d costs for edge %d -> %d, want %d", ErrDimension, len(v), from, to, len(c.names))
+	}
+	for _, cost := range v {
+		if cost < 0 {
+			return fmt.Errorf("negative cost %d for edge %d -> %d", cost, from, to)
+		}
+	}
+	if c.vectors[from] == nil {
+		c.vectors[from] = make(map[graph.VertexId]Vector)
+	}
+	c.vectors[from][to] = append(Vector(nil), v...)
+	return nil
+}
+
+// Vector returns the cost vector of the edge from -> to of g. The second
+// result is false if g has no such edge or the edge cannot be traversed.
+func (c *Costs) Vector(g *graph.Graph, from, to graph.VertexId) (Vector, bool) {
+	edge, ok := g.Edges[from][to]
+	if !ok || edge.Weight == graph.InfWeight {
+		return nil, false
+	}
+	if v, ok := c.vectors[from][to]; ok {
+		return v, true
+	}
+	v := make(Vector, len(c.names))
+	if len(v) > 0 {
+		v[0] = edge.Weight
+	}
+	return v, true
+}
+
+// WeightedSum returns a copy of g whose weights are the linear combination
+// of the cost vectors with the given coefficients, rounded to integers. The
+// result can be used to customize a CCH for a single trade-off between the
+// criteria.
+func (c *Costs) WeightedSum(g *graph.Graph, coefficients []float64) (*graph.Graph, error) {
+	if len(coefficients) != len(c.names) {
+		return nil, fmt.Errorf("%w: got %d coefficients, want %d", ErrDimension, len(coefficients), len(c.names))
+	}
+	for _, coefficient := range coefficients {
+		if coefficient < 0 {
+			return nil, fmt.Errorf("negative coefficient %f", coefficient)
+		}
+	}
+
+	weighted := g.Clone()
+	for from, targets := range weighted.Edges {
+		for to, edge := range targets {
+			v, ok := c.Vector(g, from, to)
+			if !ok {
+				continue
+			}
+			sum := 0.0
+			for i, cost := range v {
+				sum += coefficients[i] * float64(cost)
+			}
+			edge.Weight = int(math.Round(sum))
+			targets[to] = edge
+		}
+	}
+	return weighted, nil
+}
+
+// DistanceCosts returns costs with the criteria "time", the static weight of
+// each edge, and "distance", the length of the edge in meters computed from
+// the coordinates of its end points.
+func DistanceCosts(g *graph.Graph) *Costs {
+	c := NewCosts("time", "distance")
+	for from, targets := range g.Edges {
+		for to, edge := range targets {
+			if edge.Weight == graph.InfWeight {
+				continue
+			}
+			length := graph.Distance(g.Vertices[from], g.Vertices[to])
+			c.Set(from, to, Vector{edge.Weight, int(math.Round(length))})
+		}
+	}
+	return c
+}
+
+func (c *Costs) String() string {
+	return fmt.Sprintf("Costs(%s, %d edges)", strings.Join(c.names, ", "), c.Len())
+}
+
+// Len returns the number of edges with a cost vector.
+func (c *Costs) Len() int {
+	n := 0
+	for _, targets := range c.vectors {
+		n += len(targets)
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+package criteria
+
+import (
+	"errors"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func createTestGraph() *graph.Graph {
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0, Lat: 48.0, Lon: 9.0})
+	g.AddVertex(graph.Vertex{Id: 1, Lat: 48.001, Lon: 9.0})
+	g.AddVertex(graph.Vertex{Id: 2, Lat: 48.0, Lon: 9.001})
+	for _, e := range [][3]int{{0, 1, 3}, {0, 2, 8}} {
+		g.AddEdge(graph.VertexId(e[0]), graph.VertexId(e[1]), e[2], false, -1)
+		g.AddEdge(graph.VertexId(e[1]), graph.VertexId(e[0]), e[2], false, -1)
+	}
+	return g
+}
+
+func TestVector(t *testing.T) {
+	a := Vector{1, 5}
+	b := Vector{2, 5}
+	c := Vector{0, 7}
+
+	if !a.Dominates(b) || b.Dominates(a) {
+		t.Error("expected (1, 5) to dominate (2, 5) and not the other way round")
+	}
+	if a.Dominates(c) || c.Dominates(a) {
+		t.Error("expected (1, 5) and (0, 7) to be incomparable")
+	}
+	if !a.Dominates(a) {
+		t.Error("expected a vector to dominate itself")
+	}
+	if got := a.Add(c); got[0] != 1 || got[1] != 12 {
+		t.Errorf("got sum %v, want [1 12]", got)
+	}
+	if a.Sum() != 6 {
+		t.Errorf("got %d, want 6", a.Sum())
+	}
+	if !c.Less(a) || a.Less(c) || a.Less(a) {
+		t.Error("unexpected lexicographic order")
+	}
+}
+
+func TestCosts(t *testing.T) {
+	g := createTestGraph()
+	c := NewCosts("time", "distance", "tolls")
+
+	if err := c.Set(0, 1, Vector{1, 2}); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if err := c.Set(0, 1, Vector{1, -2, 0}); err == nil {
+		t.Error("expected an error for a negative cost")
+	}
+	if err := c.Set(0, 1, Vector{4, 100, 2}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if v, ok := c.Vector(g, 0, 1); !ok || v[2] != 2 {
+		t.Errorf("got %v, want the stored vector", v)
+	}
+	if v, ok := c.Vector(g, 0, 2); !ok || v[0] != 8 || v[1] != 0 || v[2] != 0 {
+		t.Errorf("got %v, want the static weight in the first criterion", v)
+	}
+	if _, ok := c.Vector(g, 1, 2); ok {
+		t.Error("expected no vector for a missing edge")
+	}
+
+	weighted, err := c.WeightedSum(g, []float64{1, 0.5, 10})
+	if err != nil {
+		t.Fatalf("WeightedSum failed: %v", err)
+	}
+	if w := weighted.Edges[0][1].Weight; w != 74 {
+		t.Errorf("got weight %d, want 4 + 50 + 20", w)
+	}
+	if w := g.Edges[0][1].Weight; w != 3 {
+		t.Errorf("WeightedSum modified the original graph: got weight %d", w)
+	}
+	if _, err := c.WeightedSum(g, []float64{1}); !errors.Is(err, ErrDimension) {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
+
+func TestDistanceCosts(t *testing.T) {
+	c := DistanceCosts(createTestGraph())
+	v, ok := c.Vector(createTestGraph(), 1, 0)
+	if !ok {
+		t.Fatal("missing vector for 1 -> 0")
+	}
+	// 0.001 degrees of latitude are about 111 meters.
+	if v[0] != 3 || v[1] < 110 || v[1] > 112 {
+		t.Errorf("got %v, want [3 111]", v)
+	}
+}
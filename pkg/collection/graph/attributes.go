@@ -0,0 +1,134 @@
+package collection
+
+import "fmt"
+
+// RoadClass classifies an edge by the kind of road it belongs to, following
+// the highway tag of OpenStreetMap.
+type RoadClass uint8
+
+const (
+	RoadClassUnknown RoadClass = iota
+	RoadClassMotorway
+	RoadClassTrunk
+	RoadClassPrimary
+	RoadClassSecondary
+	RoadClassTertiary
+	RoadClassUnclassified
+	RoadClassResidential
+	RoadClassLivingStreet
+	RoadClassService
+	RoadClassTrack
+	RoadClassCycleway
+	RoadClassFootway
+)
+
+var roadClassNames = map[RoadClass]string{
+	RoadClassUnknown:      "unknown",
+	RoadClassMotorway:     "motorway",
+	RoadClassTrunk:        "trunk",
+	RoadClassPrimary:      "primary",
+	RoadClassSecondary:    "secondary",
+	RoadClassTertiary:     "tertiary",
+	RoadClassUnclassified: "unclassified",
+	RoadClassResidential:  "residential",
+	RoadClassLivingStreet: "living_street",
+	RoadClassService:      "service",
+	RoadClassTrack:        "track",
+	RoadClassCycleway:     "cycleway",
+	RoadClassFootway:      "footway",
+}
+
+// highwayTags maps OSM highway tags that are not a class name themselves.
+var highwayTags = map[string]RoadClass{
+	"motorway_link":  RoadClassMotorway,
+	"trunk_link":     RoadClassTrunk,
+	"primary_link":   RoadClassPrimary,
+	"secondary_link": RoadClassSecondary,
+	"tertiary_link":  RoadClassTertiary,
+	"road":           RoadClassUnclassified,
+	"path":           RoadClassFootway,
+	"pedestrian":     RoadClassFootway,
+	"steps":          RoadClassFootway,
+	"bridleway":      RoadClassFootway,
+}
+
+func (c RoadClass) String() string {
+	if name, ok := roadClassNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("RoadClass(%d)", uint8(c))
+}
+
+// ParseRoadClass returns the road class of an OSM highway tag. The second
+// result is false for tags that do not describe a road, e.g. "platform".
+func ParseRoadClass(highway string) (RoadClass, bool) {
+	if c, ok := highwayTags[highway]; ok {
+		return c, true
+	}
+	for c, name := range roadClassNames {
+		if name == highway {
+			return c, true
+		}
+	}
+	return RoadClassUnknown, false
+}
+
+// Access is a set of vehicle types.
+type Access uint8
+
+const (
+	AccessCar Access = 1 << iota
+	AccessTruck
+	AccessBicycle
+	AccessPedestrian
+
+	AccessNone Access = 0
+	AccessAll         = AccessCar | AccessTruck | AccessBicycle | AccessPedestrian
+)
+
+// Contains reports whether all vehicle types of other are part of a.
+func (a Access) Contains(other Access) bool {
+	return a&other == other
+}
+
+// DefaultRestrictions returns the vehicle types that may not use a road of
+// class c if no access tags say otherwise.
+func DefaultRestrictions(c RoadClass) Access {
+	switch c {
+	case RoadClassMotorway, RoadClassTrunk:
+		return AccessBicycle | AccessPedestrian
+	case RoadClassTrack, RoadClassCycleway:
+		return AccessCar | AccessTruck
+	case RoadClassFootway:
+		return AccessCar | AccessTruck | AccessBicycle
+	default:
+		return AccessNone
+	}
+}
+
+// Attributes describe the road an edge belongs to. MaxSpeed is the legal
+// speed limit in km/h, or 0 if it is unknown, Length the length in meters, or
+// 0 if it is unknown. Restricted holds the vehicle types that may not use the
+// edge, so the zero value describes a road of unknown class open to everyone.
+type Attributes struct {
+	RoadClass  RoadClass
+	MaxSpeed   int
+	Length     float64
+	Restricted Access
+}
+
+// Allows reports whether vehicles of type vehicle may use the edge.
+func (a Attributes) Allows(vehicle Access) bool {
+	return a.Restricted&vehicle == 0
+}
+
+// SetAttributes sets the attributes of the edge x -> y.
+func (g *Graph) SetAttributes(x, y VertexId, attributes Attributes) error {
+	edge, exists := g.Edges[x][y]
+	if !exists {
+		return ErrEdgeNotFound
+	}
+	edge.Attributes = attributes
+	g.Edges[x][y] = edge
+	return nil
+}
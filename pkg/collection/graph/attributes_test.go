@@ -0,0 +1,62 @@
+package collection
+
+import "testing"
+
+func TestParseRoadClass(t *testing.T) {
+	testCases := []struct {
+		highway string
+		want    RoadClass
+		wantOk  bool
+	}{
+		{"motorway", RoadClassMotorway, true},
+		{"motorway_link", RoadClassMotorway, true},
+		{"living_street", RoadClassLivingStreet, true},
+		{"steps", RoadClassFootway, true},
+		{"platform", RoadClassUnknown, false},
+	}
+	for _, tc := range testCases {
+		got, ok := ParseRoadClass(tc.highway)
+		if got != tc.want || ok != tc.wantOk {
+			t.Errorf("ParseRoadClass(%q) = (%s, %t), want (%s, %t)", tc.highway, got, ok, tc.want, tc.wantOk)
+		}
+	}
+	if got := RoadClassResidential.String(); got != "residential" {
+		t.Errorf("got %q want %q", got, "residential")
+	}
+}
+
+func TestAllows(t *testing.T) {
+	if !(Attributes{}).Allows(AccessCar) {
+		t.Error("the zero attributes should allow every vehicle")
+	}
+	motorway := Attributes{RoadClass: RoadClassMotorway, Restricted: DefaultRestrictions(RoadClassMotorway)}
+	if !motorway.Allows(AccessTruck) || motorway.Allows(AccessBicycle) {
+		t.Errorf("motorways should allow trucks and forbid bicycles, got %+v", motorway)
+	}
+	if !AccessAll.Contains(AccessCar|AccessPedestrian) || AccessCar.Contains(AccessAll) {
+		t.Error("Contains does not match set inclusion")
+	}
+}
+
+func TestSetAttributes(t *testing.T) {
+	g := NewGraph()
+	g.AddVertex(Vertex{Id: 0})
+	g.AddVertex(Vertex{Id: 1})
+	g.AddEdge(0, 1, 5, false, -1)
+
+	attributes := Attributes{RoadClass: RoadClassPrimary, MaxSpeed: 50, Length: 120}
+	if err := g.SetAttributes(0, 1, attributes); err != nil {
+		t.Fatalf("SetAttributes failed: %v", err)
+	}
+	if err := g.SetAttributes(1, 0, attributes); err != ErrEdgeNotFound {
+		t.Errorf("got %v want %v", err, ErrEdgeNotFound)
+	}
+
+	// Changing the metric must not drop the description of the road.
+	if err := g.UpdateEdge(0, 1, 7, false, -1); err != nil {
+		t.Fatalf("UpdateEdge failed: %v", err)
+	}
+	if got := g.Edges[0][1]; got.Weight != 7 || got.Attributes != attributes {
+		t.Errorf("got %+v after UpdateEdge, want weight 7 and attributes %+v", got, attributes)
+	}
+}
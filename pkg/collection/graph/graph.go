@@ -3,6 +3,7 @@ package collection
 import (
 	"errors"
 	"fmt"
+	"math"
 )
 
 var (
@@ -14,6 +15,31 @@ var (
 	ErrVertexHasEdges      = errors.New("vertex has edges")
 )
 
+// InfWeight is the sentinel weight of an edge that cannot be traversed, e.g. a
+// CCH shortcut that has not been customized yet. Use AddWeights and LessWeight
+// instead of plain arithmetic when a weight may be InfWeight.
+const InfWeight = math.MaxInt
+
+// AddWeights returns a + b, or InfWeight if either of the two is InfWeight.
+func AddWeights(a, b int) int {
+	if a == InfWeight || b == InfWeight {
+		return InfWeight
+	}
+	return a + b
+}
+
+// LessWeight reports whether weight a is strictly smaller than weight b,
+// treating InfWeight as larger than every other weight.
+func LessWeight(a, b int) bool {
+	if a == InfWeight {
+		return false
+	}
+	if b == InfWeight {
+		return true
+	}
+	return a < b
+}
+
 type RoadNetwork struct {
 	NumNodes int
 	NumEdges int
@@ -36,11 +62,27 @@ func (v Vertex) String() string {
 	return fmt.Sprintf("%d", v.Id)
 }
 
+// earthRadius is the mean radius of the earth in meters.
+const earthRadius = 6371000.0
+
+// Distance returns the great-circle distance between two vertices in meters,
+// computed from their coordinates with the haversine formula.
+func Distance(a, b Vertex) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
 type Edge struct {
 	Target     VertexId
 	Weight     int
 	IsShortcut bool
 	Via        VertexId
+	Attributes Attributes
 }
 
 func (e Edge) String() string {
@@ -128,11 +170,13 @@ func (g *Graph) UpdateEdge(x, y VertexId, weight int, shortcut bool, via VertexI
 		return ErrEdgeNotFound
 	}
 
-	if _, exists := g.Edges[x][y]; !exists {
+	existing, exists := g.Edges[x][y]
+	if !exists {
 		return ErrEdgeNotFound // Cannot update an edge that does not exist.
 	}
 
-	g.Edges[x][y] = Edge{Target: y, Weight: weight, IsShortcut: shortcut, Via: via}
+	// The attributes describe the road and are kept when the metric changes.
+	g.Edges[x][y] = Edge{Target: y, Weight: weight, IsShortcut: shortcut, Via: via, Attributes: existing.Attributes}
 
 	return nil
 }
@@ -246,3 +290,22 @@ func (g *Graph) NumEdges() int {
 	}
 	return count
 }
+
+// Clone returns a deep copy of the graph. Modifying the clone does not affect
+// the original graph.
+func (g *Graph) Clone() *Graph {
+	c := &Graph{
+		Vertices: make(map[VertexId]Vertex, len(g.Vertices)),
+		Edges:    make(map[VertexId]map[VertexId]Edge, len(g.Edges)),
+	}
+	for id, v := range g.Vertices {
+		c.Vertices[id] = v
+	}
+	for src, targets := range g.Edges {
+		c.Edges[src] = make(map[VertexId]Edge, len(targets))
+		for tgt, edge := range targets {
+			c.Edges[src][tgt] = edge
+		}
+	}
+	return c
+}
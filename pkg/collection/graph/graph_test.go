@@ -320,6 +320,40 @@ func TestDegree(t *testing.T) {
 	})
 }
 
+func TestClone(t *testing.T) {
+	g := createGraphFromSlidedeck()
+	c := g.Clone()
+
+	assertInt(t, len(c.Vertices), len(g.Vertices))
+	assertInt(t, c.NumEdges(), g.NumEdges())
+
+	c.UpdateEdge(0, 1, 42, false, -1)
+	c.RemoveEdge(1, 2)
+
+	if g.Edges[0][1].Weight == 42 {
+		t.Error("updating an edge of the clone changed the original graph")
+	}
+	if _, ok := g.Edges[1][2]; !ok {
+		t.Error("removing an edge of the clone changed the original graph")
+	}
+}
+
+func TestWeightArithmetic(t *testing.T) {
+	assertInt(t, AddWeights(2, 3), 5)
+	assertInt(t, AddWeights(InfWeight, 3), InfWeight)
+	assertInt(t, AddWeights(2, InfWeight), InfWeight)
+
+	if !LessWeight(2, InfWeight) {
+		t.Error("expected finite weight to be less than InfWeight")
+	}
+	if LessWeight(InfWeight, 2) || LessWeight(InfWeight, InfWeight) {
+		t.Error("expected InfWeight to be larger than every weight")
+	}
+	if !LessWeight(2, 3) || LessWeight(3, 2) {
+		t.Error("expected finite weights to compare normally")
+	}
+}
+
 func assertError(t testing.TB, got error, want error) {
 	t.Helper()
 
@@ -348,3 +382,16 @@ func assertSlice[T Vertex | Edge](t testing.TB, got []T, want []T) {
 		t.Errorf("expected %q got %q", want, got)
 	}
 }
+
+func TestDistance(t *testing.T) {
+	stuttgart := Vertex{Id: 0, Lat: 48.7758, Lon: 9.1829}
+	munich := Vertex{Id: 1, Lat: 48.1351, Lon: 11.5820}
+
+	got := Distance(stuttgart, munich)
+	if got < 189000 || got > 192000 {
+		t.Errorf("got %f meters between Stuttgart and Munich, want about 190 km", got)
+	}
+	if d := Distance(stuttgart, stuttgart); d != 0 {
+		t.Errorf("got %f meters between a vertex and itself, want 0", d)
+	}
+}
@@ -0,0 +1,89 @@
+package queue
+
+// BucketQueue is Dial's monotone priority queue for non-negative integer
+// priorities. It keeps one bucket per priority in a ring that covers the
+// priorities from the last returned one on. Pops scan the ring for the next
+// non-empty bucket, so it is fastest if the pushed priorities exceed the last
+// returned one by little, as in a Dijkstra search with small edge weights. The
+// ring grows if a pushed priority does not fit. Push panics for a negative or
+// non-integer priority.
+type BucketQueue struct {
+	buckets [][]entry
+	cursor  uint64
+	size    int
+}
+
+// NewBucketQueue creates an empty bucket queue with a ring for priorities up
+// to maxGap above the last returned one.
+func NewBucketQueue(maxGap int) *BucketQueue {
+	q := &BucketQueue{}
+	q.resize(uint64(max(maxGap, 0)) + 1)
+	return q
+}
+
+// resize grows the ring to a power of two of at least n buckets.
+func (q *BucketQueue) resize(n uint64) {
+	size := uint64(1)
+	for size < n {
+		size *= 2
+	}
+	old := q.buckets
+	q.buckets = make([][]entry, size)
+	for _, bucket := range old {
+		for _, e := range bucket {
+			b := uint64(e.priority) & (size - 1)
+			q.buckets[b] = append(q.buckets[b], e)
+		}
+	}
+}
+
+// Push implements Queue. The priority must not be smaller than the last one
+// returned by Min or Pop.
+func (q *BucketQueue) Push(index int, priority float64) {
+	key := integerKey("BucketQueue", priority)
+	if gap := key - q.cursor; gap >= uint64(len(q.buckets)) {
+		q.resize(gap + 1)
+	}
+	b := key & uint64(len(q.buckets)-1)
+	q.buckets[b] = append(q.buckets[b], entry{index: index, priority: float64(key)})
+	q.size++
+}
+
+// advance moves the cursor to the next non-empty bucket.
+func (q *BucketQueue) advance() []entry {
+	mask := uint64(len(q.buckets) - 1)
+	for len(q.buckets[q.cursor&mask]) == 0 {
+		q.cursor++
+	}
+	return q.buckets[q.cursor&mask]
+}
+
+// Min implements Queue.
+func (q *BucketQueue) Min() (int, float64) {
+	bucket := q.advance()
+	top := bucket[len(bucket)-1]
+	return top.index, top.priority
+}
+
+// Pop implements Queue.
+func (q *BucketQueue) Pop() (int, float64) {
+	bucket := q.advance()
+	top := bucket[len(bucket)-1]
+	q.buckets[q.cursor&uint64(len(q.buckets)-1)] = bucket[:len(bucket)-1]
+	q.size--
+	return top.index, top.priority
+}
+
+// Len implements Queue.
+func (q *BucketQueue) Len() int {
+	return q.size
+}
+
+// Reset implements Queue.
+func (q *BucketQueue) Reset() {
+	for i := range q.buckets {
+		q.buckets[i] = q.buckets[i][:0]
+	}
+	q.cursor = 0
+	q.size = 0
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"container/heap"
+
+	collection "github.com/PaulMue0/efficient-routeplanning/pkg/collection/heap_gen"
+)
+
+const arity = 4
+
+// FourAryHeap is a heap in which every node has four children. It is flatter
+// than a binary heap, so pushes are cheaper and the children compared on a pop
+// share a cache line.
+type FourAryHeap struct {
+	entries []entry
+}
+
+// NewFourAryHeap creates an empty 4-ary heap.
+func NewFourAryHeap() *FourAryHeap {
+	return &FourAryHeap{}
+}
+
+// Push implements Queue.
+func (h *FourAryHeap) Push(index int, priority float64) {
+	h.entries = append(h.entries, entry{index: index, priority: priority})
+	i := len(h.entries) - 1
+	for i > 0 {
+		parent := (i - 1) / arity
+		if h.entries[parent].priority <= priority {
+			break
+		}
+		h.entries[i] = h.entries[parent]
+		i = parent
+	}
+	h.entries[i] = entry{index: index, priority: priority}
+}
+
+// Min implements Queue.
+func (h *FourAryHeap) Min() (int, float64) {
+	return h.entries[0].index, h.entries[0].priority
+}
+
+// Pop implements Queue.
+func (h *FourAryHeap) Pop() (int, float64) {
+	top := h.entries[0]
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	n := len(h.entries)
+	if n == 0 {
+		return top.index, top.priority
+	}
+
+	i := 0
+	for {
+		first := arity*i + 1
+		if first >= n {
+			break
+		}
+		smallest := first
+		for child := first + 1; child < min(first+arity, n); child++ {
+			if h.entries[child].priority < h.entries[smallest].priority {
+				smallest = child
+			}
+		}
+		if h.entries[smallest].priority >= last.priority {
+			break
+		}
+		h.entries[i] = h.entries[smallest]
+		i = smallest
+	}
+	h.entries[i] = last
+	return top.index, top.priority
+}
+
+// Len implements Queue.
+func (h *FourAryHeap) Len() int {
+	return len(h.entries)
+}
+
+// Reset implements Queue.
+func (h *FourAryHeap) Reset() {
+	h.entries = h.entries[:0]
+}
+
+// BinaryHeap adapts collection.PriorityQueue to Queue. The priority queue
+// keeps one entry per index, so pushing a queued index updates its priority
+// instead of adding a copy.
+type BinaryHeap struct {
+	pq *collection.PriorityQueue[int]
+}
+
+// NewBinaryHeap creates an empty binary heap.
+func NewBinaryHeap() *BinaryHeap {
+	return &BinaryHeap{pq: collection.NewPriorityQueue[int]()}
+}
+
+// Push implements Queue.
+func (h *BinaryHeap) Push(index int, priority float64) {
+	h.pq.UpdatePriority(index, priority)
+}
+
+// Min implements Queue.
+func (h *BinaryHeap) Min() (int, float64) {
+	item := h.pq.Peek()
+	return h.pq.GetValue(item), h.pq.GetPriority(item)
+}
+
+// Pop implements Queue.
+func (h *BinaryHeap) Pop() (int, float64) {
+	item := heap.Pop(h.pq).(*collection.Item[int])
+	return h.pq.GetValue(item), h.pq.GetPriority(item)
+}
+
+// Len implements Queue.
+func (h *BinaryHeap) Len() int {
+	return h.pq.Len()
+}
+
+// Reset implements Queue.
+func (h *BinaryHeap) Reset() {
+	for h.pq.Len() > 0 {
+		heap.Pop(h.pq)
+	}
+}
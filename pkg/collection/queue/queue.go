@@ -0,0 +1,105 @@
+// Package queue provides min-priority queues over dense non-negative indices,
+// e.g. vertex ids, for Dijkstra-like searches. All queues share the Queue
+// interface, so a search can pick the one that suits its weights: a 4-ary
+// heap for arbitrary priorities, a radix heap for monotone integer priorities
+// and a bucket queue for monotone integer priorities from a small range. The
+// binary heap of package heap_gen is wrapped for comparison.
+//
+// The queues do not support decrease-key. A search that lowers the priority
+// of an index pushes it again and skips the stale copies when it pops them.
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrUnknownKind = errors.New("unknown queue kind")
+
+// Queue is a min-priority queue of indices.
+type Queue interface {
+	// Push adds index with the given priority.
+	Push(index int, priority float64)
+	// Min returns an entry with the smallest priority without removing it.
+	// The queue must not be empty.
+	Min() (index int, priority float64)
+	// Pop removes and returns the entry Min would return.
+	Pop() (index int, priority float64)
+	// Len returns the number of entries, stale copies included.
+	Len() int
+	// Reset removes all entries but keeps the allocated memory.
+	Reset()
+}
+
+// Kind selects a Queue implementation. The zero value is FourAry.
+type Kind int
+
+const (
+	// FourAry is a 4-ary heap. It accepts any priorities.
+	FourAry Kind = iota
+	// Binary is the binary heap of package heap_gen.
+	Binary
+	// Radix is a radix heap. Priorities must be non-negative integers and a
+	// pushed priority must not be smaller than the last one returned by Min or
+	// Pop, as in a Dijkstra search.
+	Radix
+	// Buckets is a Dial bucket queue with the same restrictions as Radix. It
+	// needs as many buckets as the largest gap between the last returned and a
+	// pushed priority, i.e. the largest edge weight of a Dijkstra search.
+	Buckets
+)
+
+var kindNames = []string{"4-ary", "binary", "radix", "buckets"}
+
+// Kinds returns all queue kinds.
+func Kinds() []Kind {
+	return []Kind{FourAry, Binary, Radix, Buckets}
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// ParseKind returns the kind with the given name, as returned by String.
+func ParseKind(name string) (Kind, error) {
+	for _, kind := range Kinds() {
+		if kind.String() == name {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownKind, name)
+}
+
+// New creates an empty queue of the given kind.
+func New(kind Kind) Queue {
+	switch kind {
+	case Binary:
+		return NewBinaryHeap()
+	case Radix:
+		return NewRadixHeap()
+	case Buckets:
+		return NewBucketQueue(0)
+	default:
+		return NewFourAryHeap()
+	}
+}
+
+type entry struct {
+	index    int
+	priority float64
+}
+
+// integerKey returns priority as the key of a queue for integer priorities.
+// It panics for a negative or non-integer priority: truncating it would
+// return a different priority than the one pushed, and a search comparing
+// the two would drop the entry as stale.
+func integerKey(queue string, priority float64) uint64 {
+	if priority < 0 || priority != math.Trunc(priority) || math.IsInf(priority, 1) {
+		panic(fmt.Sprintf("queue: %s needs non-negative integer priorities, got %v", queue, priority))
+	}
+	return uint64(priority)
+}
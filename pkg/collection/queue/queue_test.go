@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestQueues_MonotonePops simulates the pushes of a Dijkstra search, i.e.
+// every pushed priority is at least the last popped one, and checks that all
+// queues pop the priorities in ascending order.
+func TestQueues_MonotonePops(t *testing.T) {
+	for _, kind := range Kinds() {
+		t.Run(kind.String(), func(t *testing.T) {
+			q := New(kind)
+			r := rand.New(rand.NewSource(1))
+			for round := 0; round < 3; round++ {
+				q.Reset()
+				var pushed []float64
+				last := 0
+				for i := 0; i < 50; i++ {
+					priority := float64(last + r.Intn(20))
+					q.Push(i, priority)
+					pushed = append(pushed, priority)
+				}
+
+				var popped []float64
+				for q.Len() > 0 {
+					index, priority := q.Min()
+					if gotIndex, gotPriority := q.Pop(); gotIndex != index || gotPriority != priority {
+						t.Fatalf("Pop() = %d, %v, Min() was %d, %v", gotIndex, gotPriority, index, priority)
+					}
+					if pushed[index] != priority {
+						t.Fatalf("index %d popped with priority %v, pushed with %v", index, priority, pushed[index])
+					}
+					popped = append(popped, priority)
+					last = int(priority)
+
+					// Push some more entries behind the popped one, up to a gap
+					// larger than the initial ring of the bucket queue.
+					if len(pushed) < 200 && r.Intn(2) == 0 {
+						priority := float64(last + r.Intn(3000))
+						q.Push(len(pushed), priority)
+						pushed = append(pushed, priority)
+					}
+				}
+				if len(popped) != len(pushed) {
+					t.Fatalf("popped %d entries, pushed %d", len(popped), len(pushed))
+				}
+				if !sort.Float64sAreSorted(popped) {
+					t.Errorf("priorities popped out of order: %v", popped)
+				}
+			}
+		})
+	}
+}
+
+func TestFourAryHeap_ArbitraryPriorities(t *testing.T) {
+	q := NewFourAryHeap()
+	priorities := []float64{2.5, -1, 7.25, 0.5, 3, -4.75, 2.5}
+	for i, p := range priorities {
+		q.Push(i, p)
+	}
+	sort.Float64s(priorities)
+	for _, want := range priorities {
+		if _, got := q.Pop(); got != want {
+			t.Errorf("Pop() priority = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestIntegerQueues_RejectFractional checks that the integer queues fail
+// loudly instead of truncating a priority, which a search would drop as stale.
+func TestIntegerQueues_RejectFractional(t *testing.T) {
+	for _, kind := range []Kind{Radix, Buckets} {
+		for _, priority := range []float64{0.5, 2.25, -1, math.Inf(1), math.NaN()} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: Push(%v) did not panic", kind, priority)
+					}
+				}()
+				New(kind).Push(0, priority)
+			}()
+		}
+
+		q := New(kind)
+		q.Push(0, 3)
+		if _, got := q.Pop(); got != 3 {
+			t.Errorf("%s: popped priority %v, want 3", kind, got)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, kind := range Kinds() {
+		if got, err := ParseKind(kind.String()); err != nil || got != kind {
+			t.Errorf("ParseKind(%q) = %v, %v, want %v", kind.String(), got, err, kind)
+		}
+	}
+	if _, err := ParseKind("fibonacci"); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("ParseKind(fibonacci) error = %v, want ErrUnknownKind", err)
+	}
+}
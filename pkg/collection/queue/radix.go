@@ -0,0 +1,84 @@
+package queue
+
+import "math/bits"
+
+// RadixHeap is a monotone priority queue for non-negative integer priorities.
+// An entry is kept in the bucket given by the highest bit in which its
+// priority differs from the last returned one. Popping from an empty bucket 0
+// redistributes the lowest non-empty bucket, and since an entry only ever
+// moves to lower buckets, every operation takes amortized O(log C) time for
+// priorities up to C. Push panics for a negative or non-integer priority.
+type RadixHeap struct {
+	buckets [65][]entry
+	last    uint64
+	size    int
+}
+
+// NewRadixHeap creates an empty radix heap.
+func NewRadixHeap() *RadixHeap {
+	return &RadixHeap{}
+}
+
+func (h *RadixHeap) bucket(key uint64) int {
+	return bits.Len64(key ^ h.last)
+}
+
+// Push implements Queue. The priority must not be smaller than the last one
+// returned by Min or Pop.
+func (h *RadixHeap) Push(index int, priority float64) {
+	key := integerKey("RadixHeap", priority)
+	b := h.bucket(key)
+	h.buckets[b] = append(h.buckets[b], entry{index: index, priority: float64(key)})
+	h.size++
+}
+
+// refill moves the entries with the smallest priority into bucket 0.
+func (h *RadixHeap) refill() {
+	if len(h.buckets[0]) > 0 {
+		return
+	}
+	i := 1
+	for len(h.buckets[i]) == 0 {
+		i++
+	}
+	smallest := h.buckets[i][0].priority
+	for _, e := range h.buckets[i][1:] {
+		smallest = min(smallest, e.priority)
+	}
+	h.last = uint64(smallest)
+	for _, e := range h.buckets[i] {
+		b := h.bucket(uint64(e.priority))
+		h.buckets[b] = append(h.buckets[b], e)
+	}
+	h.buckets[i] = h.buckets[i][:0]
+}
+
+// Min implements Queue.
+func (h *RadixHeap) Min() (int, float64) {
+	h.refill()
+	top := h.buckets[0][len(h.buckets[0])-1]
+	return top.index, top.priority
+}
+
+// Pop implements Queue.
+func (h *RadixHeap) Pop() (int, float64) {
+	h.refill()
+	top := h.buckets[0][len(h.buckets[0])-1]
+	h.buckets[0] = h.buckets[0][:len(h.buckets[0])-1]
+	h.size--
+	return top.index, top.priority
+}
+
+// Len implements Queue.
+func (h *RadixHeap) Len() int {
+	return h.size
+}
+
+// Reset implements Queue.
+func (h *RadixHeap) Reset() {
+	for i := range h.buckets {
+		h.buckets[i] = h.buckets[i][:0]
+	}
+	h.last = 0
+	h.size = 0
+}
@@ -0,0 +1,72 @@
+package ttf
+
+import (
+	"fmt"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+// Profiles assigns travel time functions to the edges of a graph. All
+// functions share the same period. Edges without a function keep their
+// static weight, which is then treated as a constant travel time.
+type Profiles struct {
+	period    float64
+	functions map[graph.VertexId]map[graph.VertexId]*TTF
+}
+
+// NewProfiles returns an empty set of profiles with the given period.
+func NewProfiles(period float64) *Profiles {
+	return &Profiles{
+		period:    period,
+		functions: make(map[graph.VertexId]map[graph.VertexId]*TTF),
+	}
+}
+
+// Period returns the period shared by all functions.
+func (p *Profiles) Period() float64 {
+	return p.period
+}
+
+// Set assigns f to the edge from -> to.
+func (p *Profiles) Set(from, to graph.VertexId, f *TTF) error {
+	if f.period != p.period {
+		return fmt.Errorf("function for edge %d -> %d has period %g, want %g", from, to, f.period, p.period)
+	}
+	if p.functions[from] == nil {
+		p.functions[from] = make(map[graph.VertexId]*TTF)
+	}
+	p.functions[from][to] = f
+	return nil
+}
+
+// Get returns the function assigned to the edge from -> to.
+func (p *Profiles) Get(from, to graph.VertexId) (*TTF, bool) {
+	if p == nil {
+		return nil, false
+	}
+	f, ok := p.functions[from][to]
+	return f, ok
+}
+
+// Function returns the function of the edge from -> to of g: its profile if
+// it has one and a constant function of its weight otherwise. The second
+// result is false if g has no such edge or the edge cannot be traversed.
+func (p *Profiles) Function(g *graph.Graph, from, to graph.VertexId) (*TTF, bool) {
+	edge, ok := g.Edges[from][to]
+	if !ok || edge.Weight == graph.InfWeight {
+		return nil, false
+	}
+	if f, ok := p.Get(from, to); ok {
+		return f, true
+	}
+	return Constant(p.period, float64(edge.Weight)), true
+}
+
+// Len returns the number of edges with a function.
+func (p *Profiles) Len() int {
+	n := 0
+	for _, targets := range p.functions {
+		n += len(targets)
+	}
+	return n
+}
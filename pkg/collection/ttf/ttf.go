@@ -0,0 +1,312 @@
+// Package ttf provides periodic piecewise-linear travel time functions (TTFs)
+// for time-dependent routing. A TTF maps the departure time at the start of an
+// edge to the time it takes to traverse the edge. All times are given in the
+// same unit, e.g. seconds, and repeat with the period of the function, e.g.
+// one day or one week.
+package ttf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// epsilon is the tolerance used when comparing times and travel times.
+const epsilon = 1e-9
+
+var (
+	// ErrNotFIFO is returned for functions that allow arriving earlier by departing later.
+	ErrNotFIFO = errors.New("travel time function violates the FIFO property")
+	// ErrInvalidPoints is returned for breakpoints that are unsorted, outside of the period or negative.
+	ErrInvalidPoints = errors.New("invalid breakpoints")
+)
+
+// Point is a breakpoint of a TTF: departing at At takes Duration.
+type Point struct {
+	At       float64
+	Duration float64
+}
+
+// TTF is a periodic piecewise-linear travel time function. Between two
+// breakpoints the travel time is interpolated linearly, and the last
+// breakpoint is connected to the first one of the next period.
+type TTF struct {
+	period float64
+	points []Point
+}
+
+// New returns the TTF with the given period and breakpoints. The breakpoints
+// must be sorted by At, lie within [0, period) and have non-negative
+// durations. The function must satisfy the FIFO property: departing later
+// never leads to an earlier arrival.
+func New(period float64, points []Point) (*TTF, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("%w: period %f is not positive", ErrInvalidPoints, period)
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w: no breakpoints", ErrInvalidPoints)
+	}
+	for i, p := range points {
+		if p.At < 0 || p.At >= period || p.Duration < 0 {
+			return nil, fmt.Errorf("%w: breakpoint %d (%f, %f)", ErrInvalidPoints, i, p.At, p.Duration)
+		}
+		if i > 0 && p.At <= points[i-1].At {
+			return nil, fmt.Errorf("%w: breakpoint %d is not after its predecessor", ErrInvalidPoints, i)
+		}
+	}
+
+	f := &TTF{period: period, points: append([]Point(nil), points...)}
+	for i := range f.points {
+		start, end := f.segment(i)
+		if end.Duration-start.Duration < -(end.At-start.At)-epsilon {
+			return nil, fmt.Errorf("%w: between %f and %f", ErrNotFIFO, start.At, end.At)
+		}
+	}
+	return f, nil
+}
+
+// Constant returns the TTF that always takes duration.
+func Constant(period, duration float64) *TTF {
+	return &TTF{period: period, points: []Point{{0, duration}}}
+}
+
+// FromSamples returns the TTF through values sampled every interval, starting
+// at time 0. The period is len(values) * interval, so 672 samples with an
+// interval of 900 seconds describe a week in 15 minute steps.
+func FromSamples(interval float64, values []float64) (*TTF, error) {
+	points := make([]Point, len(values))
+	for i, v := range values {
+		points[i] = Point{At: float64(i) * interval, Duration: v}
+	}
+	f, err := New(float64(len(values))*interval, points)
+	if err != nil {
+		return nil, err
+	}
+	return f.simplify(), nil
+}
+
+// Period returns the period of f.
+func (f *TTF) Period() float64 {
+	return f.period
+}
+
+// Points returns a copy of the breakpoints of f.
+func (f *TTF) Points() []Point {
+	return append([]Point(nil), f.points...)
+}
+
+// Eval returns the travel time when departing at time t. t may lie outside
+// of the first period.
+func (f *TTF) Eval(t float64) float64 {
+	t = f.normalize(t)
+	i := sort.Search(len(f.points), func(i int) bool { return f.points[i].At > t }) - 1
+	if i < 0 {
+		// t lies before the first breakpoint, on the segment wrapping around
+		// from the last breakpoint of the previous period.
+		i = len(f.points) - 1
+		t += f.period
+	}
+	start, end := f.segment(i)
+	return interpolate(start, end, t)
+}
+
+// Arrival returns the arrival time when departing at time t.
+func (f *TTF) Arrival(t float64) float64 {
+	return t + f.Eval(t)
+}
+
+// Min returns the smallest travel time of f.
+func (f *TTF) Min() float64 {
+	m := math.Inf(1)
+	for _, p := range f.points {
+		m = math.Min(m, p.Duration)
+	}
+	return m
+}
+
+// Max returns the largest travel time of f.
+func (f *TTF) Max() float64 {
+	m := math.Inf(-1)
+	for _, p := range f.points {
+		m = math.Max(m, p.Duration)
+	}
+	return m
+}
+
+// Link returns the TTF of traversing f and then g: departing at t takes
+// f(t) + g(t + f(t)). Both functions must have the same period.
+func Link(f, g *TTF) *TTF {
+	times := make([]float64, 0, len(f.points)+len(g.points))
+	for _, p := range f.points {
+		times = append(times, p.At)
+	}
+
+	// Between the breakpoints of f, the arrival time t + f(t) is linear and,
+	// because of FIFO, non-decreasing. Wherever it passes a breakpoint of g the
+	// linked function gets a breakpoint as well.
+	for i := range f.points {
+		start, end := f.segment(i)
+		arrivalStart := start.At + start.Duration
+		arrivalEnd := end.At + end.Duration
+		if arrivalEnd-arrivalStart <= epsilon {
+			continue
+		}
+		firstPeriod := math.Floor(arrivalStart / g.period)
+		for k := firstPeriod; k*g.period <= arrivalEnd; k++ {
+			for _, q := range g.points {
+				b := q.At + k*g.period
+				if b <= arrivalStart || b >= arrivalEnd {
+					continue
+				}
+				ratio := (b - arrivalStart) / (arrivalEnd - arrivalStart)
+				times = append(times, start.At+ratio*(end.At-start.At))
+			}
+		}
+	}
+
+	return sample(f.period, times, func(t float64) float64 {
+		d := f.Eval(t)
+		return d + g.Eval(t+d)
+	})
+}
+
+// Minimum returns the pointwise minimum of f and g. Both functions must have
+// the same period.
+func Minimum(f, g *TTF) *TTF {
+	times := make([]float64, 0, len(f.points)+len(g.points))
+	for _, p := range f.points {
+		times = append(times, p.At)
+	}
+	for _, p := range g.points {
+		times = append(times, p.At)
+	}
+	times = uniqueSorted(f.period, times)
+
+	// Between two breakpoints of either function both are linear, so they
+	// intersect at most once.
+	intersections := make([]float64, 0)
+	for i, t0 := range times {
+		t1 := times[0] + f.period
+		if i+1 < len(times) {
+			t1 = times[i+1]
+		}
+		d0 := f.Eval(t0) - g.Eval(t0)
+		d1 := f.Eval(t1) - g.Eval(t1)
+		if (d0 < -epsilon && d1 > epsilon) || (d0 > epsilon && d1 < -epsilon) {
+			intersections = append(intersections, t0+d0/(d0-d1)*(t1-t0))
+		}
+	}
+
+	return sample(f.period, append(times, intersections...), func(t float64) float64 {
+		return math.Min(f.Eval(t), g.Eval(t))
+	})
+}
+
+// Equal reports whether f and g describe the same function.
+func Equal(f, g *TTF) bool {
+	if f.period != g.period {
+		return false
+	}
+	for _, p := range f.points {
+		if math.Abs(g.Eval(p.At)-p.Duration) > 1e-6 {
+			return false
+		}
+	}
+	for _, p := range g.points {
+		if math.Abs(f.Eval(p.At)-p.Duration) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *TTF) String() string {
+	return fmt.Sprintf("TTF(period: %g, points: %v)", f.period, f.points)
+}
+
+// segment returns breakpoint i and its successor. The successor of the last
+// breakpoint is the first breakpoint shifted by one period.
+func (f *TTF) segment(i int) (Point, Point) {
+	start := f.points[i]
+	if i+1 < len(f.points) {
+		return start, f.points[i+1]
+	}
+	first := f.points[0]
+	return start, Point{At: first.At + f.period, Duration: first.Duration}
+}
+
+// normalize maps t into [0, period).
+func (f *TTF) normalize(t float64) float64 {
+	t = math.Mod(t, f.period)
+	if t < 0 {
+		t += f.period
+	}
+	return t
+}
+
+// simplify removes breakpoints that lie on the line through their neighbors.
+func (f *TTF) simplify() *TTF {
+	if len(f.points) < 3 {
+		return f
+	}
+	kept := make([]Point, 0, len(f.points))
+	for i, p := range f.points {
+		prev := f.points[(i+len(f.points)-1)%len(f.points)]
+		if i == 0 {
+			prev.At -= f.period
+		}
+		_, next := f.segment(i)
+		if math.Abs(interpolate(prev, next, p.At)-p.Duration) > epsilon {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		// All breakpoints are collinear, so the function is constant.
+		kept = f.points[:1]
+	}
+	return &TTF{period: f.period, points: kept}
+}
+
+// sample builds the TTF through eval evaluated at times. eval must be linear
+// between consecutive times.
+func sample(period float64, times []float64, eval func(float64) float64) *TTF {
+	times = uniqueSorted(period, times)
+	points := make([]Point, len(times))
+	for i, t := range times {
+		points[i] = Point{At: t, Duration: eval(t)}
+	}
+	return (&TTF{period: period, points: points}).simplify()
+}
+
+// uniqueSorted normalizes times into [0, period), sorts them and removes
+// duplicates.
+func uniqueSorted(period float64, times []float64) []float64 {
+	normalized := make([]float64, 0, len(times))
+	for _, t := range times {
+		t = math.Mod(t, period)
+		if t < 0 {
+			t += period
+		}
+		if period-t < epsilon {
+			t = 0
+		}
+		normalized = append(normalized, t)
+	}
+	sort.Float64s(normalized)
+
+	unique := normalized[:0]
+	for _, t := range normalized {
+		if len(unique) == 0 || t-unique[len(unique)-1] > epsilon {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+func interpolate(start, end Point, t float64) float64 {
+	if end.At == start.At {
+		return start.Duration
+	}
+	return start.Duration + (t-start.At)/(end.At-start.At)*(end.Duration-start.Duration)
+}
@@ -0,0 +1,148 @@
+package ttf
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+func assertClose(t *testing.T, got, want float64, msg string) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("%s: got %f, want %f", msg, got, want)
+	}
+}
+
+// randomTTF returns a FIFO function sampled every interval.
+func randomTTF(t *testing.T, rng *rand.Rand, samples int, interval float64) *TTF {
+	t.Helper()
+	values := make([]float64, samples)
+	for i := range values {
+		values[i] = 10 + rng.Float64()*0.8*interval
+	}
+	f, err := FromSamples(interval, values)
+	if err != nil {
+		t.Fatalf("FromSamples failed: %v", err)
+	}
+	return f
+}
+
+func TestEval(t *testing.T) {
+	f, err := New(100, []Point{{10, 5}, {50, 25}, {80, 10}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	testCases := []struct {
+		at   float64
+		want float64
+	}{
+		{10, 5},
+		{30, 15},
+		{50, 25},
+		{65, 17.5},
+		// Wrapping from 80 to 110 goes from 10 back to 5.
+		{95, 7.5},
+		{0, 20.0 / 3},
+		{110, 5},
+		{-70, 15},
+	}
+	for _, tc := range testCases {
+		assertClose(t, f.Eval(tc.at), tc.want, "Eval")
+	}
+	assertClose(t, f.Arrival(30), 45, "Arrival")
+	assertClose(t, f.Min(), 5, "Min")
+	assertClose(t, f.Max(), 25, "Max")
+
+	c := Constant(100, 7)
+	assertClose(t, c.Eval(42), 7, "constant Eval")
+}
+
+func TestNewErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		period float64
+		points []Point
+		want   error
+	}{
+		{"no points", 100, nil, ErrInvalidPoints},
+		{"zero period", 0, []Point{{0, 1}}, ErrInvalidPoints},
+		{"unsorted", 100, []Point{{50, 1}, {10, 1}}, ErrInvalidPoints},
+		{"outside period", 100, []Point{{100, 1}}, ErrInvalidPoints},
+		{"negative", 100, []Point{{0, -1}}, ErrInvalidPoints},
+		// Leaving at 10 arrives at 60, leaving at 20 arrives at 25.
+		{"overtaking", 100, []Point{{10, 50}, {20, 5}}, ErrNotFIFO},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(tc.period, tc.points); !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromSamplesSimplifies(t *testing.T) {
+	f, err := FromSamples(10, []float64{5, 10, 15, 10})
+	if err != nil {
+		t.Fatalf("FromSamples failed: %v", err)
+	}
+	if got := len(f.Points()); got != 2 {
+		t.Errorf("got %d breakpoints, want 2 after removing collinear ones: %v", got, f)
+	}
+	assertClose(t, f.Period(), 40, "Period")
+	assertClose(t, f.Eval(5), 7.5, "Eval")
+}
+
+func TestLinkAndMinimum(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		f := randomTTF(t, rng, 12, 100)
+		g := randomTTF(t, rng, 12, 100)
+
+		linked := Link(f, g)
+		minimum := Minimum(f, g)
+		for at := 0.0; at < 1200; at += 0.5 {
+			d := f.Eval(at)
+			assertClose(t, linked.Eval(at), d+g.Eval(at+d), "Link")
+			assertClose(t, minimum.Eval(at), math.Min(f.Eval(at), g.Eval(at)), "Minimum")
+		}
+		if !Equal(Minimum(f, f), f) {
+			t.Error("minimum of a function with itself differs from the function")
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddVertex(graph.Vertex{Id: 0})
+	g.AddVertex(graph.Vertex{Id: 1})
+	g.AddEdge(0, 1, 4, false, -1)
+	g.AddEdge(1, 0, 4, false, -1)
+
+	p := NewProfiles(100)
+	if err := p.Set(0, 1, Constant(50, 1)); err == nil {
+		t.Error("expected an error for a function with a different period")
+	}
+	if err := p.Set(0, 1, Constant(100, 9)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	f, ok := p.Function(g, 0, 1)
+	if !ok || f.Eval(0) != 9 {
+		t.Errorf("got %v, want the profile of 0 -> 1", f)
+	}
+	f, ok = p.Function(g, 1, 0)
+	if !ok || f.Eval(0) != 4 {
+		t.Errorf("got %v, want a constant function of the static weight", f)
+	}
+	if _, ok := p.Function(g, 0, 0); ok {
+		t.Error("expected no function for a missing edge")
+	}
+	if p.Len() != 1 {
+		t.Errorf("got %d profiles, want 1", p.Len())
+	}
+}
@@ -0,0 +1,254 @@
+// Package routing is the public Go API of this project. It wraps loading road
+// networks and preprocessing, customizing, persisting and querying Contraction
+// Hierarchies (CH) and Customizable Contraction Hierarchies (CCH), so other Go
+// programs can compute routes without running the HTTP server.
+//
+// A typical CCH workflow looks like this:
+//
+//	network, err := routing.LoadNetwork("data/RoadNetworks/osm1.txt")
+//	...
+//	c, err := routing.PreprocessCCH(network, "data/KaHIP/osm1.ordering")
+//	...
+//	if err := c.Customize(network); err != nil { ... }
+//	route, err := c.Query(1, 5)
+//
+// Network, CH and CCH all implement Router, so code answering queries does not
+// need to know which engine is used. Query errors can be inspected with
+// errors.Is against the errors declared in this package.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PaulMue0/efficient-routeplanning/internal/cch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/ch"
+	"github.com/PaulMue0/efficient-routeplanning/internal/parser"
+	"github.com/PaulMue0/efficient-routeplanning/internal/pathfinding"
+	"github.com/PaulMue0/efficient-routeplanning/internal/preprocessed_graph"
+	router "github.com/PaulMue0/efficient-routeplanning/internal/routing"
+	"github.com/PaulMue0/efficient-routeplanning/internal/vehicle"
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+var (
+	// ErrNoRoute is returned when the target cannot be reached from the source.
+	ErrNoRoute = errors.New("routing: target not reachable")
+	// ErrUnknownVertex is returned when a query references a vertex that is not part of the network.
+	ErrUnknownVertex = errors.New("routing: unknown vertex")
+	// ErrNotCustomized is returned when a CCH is queried before it was customized.
+	ErrNotCustomized = errors.New("routing: CCH is not customized")
+)
+
+// Router answers shortest path queries between two vertices.
+type Router interface {
+	Query(source, target graph.VertexId) (Route, error)
+}
+
+// Edge is a single edge of a route.
+type Edge struct {
+	From       graph.VertexId
+	To         graph.VertexId
+	Weight     int
+	IsShortcut bool
+}
+
+// Route is the result of a shortest path query. Path lists the vertices from
+// source to target, Edges the edges between consecutive vertices and Cost the
+// total weight of the route. NodesPopped and Duration describe the search.
+type Route struct {
+	Path        []graph.VertexId
+	Edges       []Edge
+	Cost        float64
+	NodesPopped int
+	Duration    time.Duration
+}
+
+// Network is a road network with integer edge weights. All edges are stored in
+// both directions.
+type Network struct {
+	g *graph.Graph
+}
+
+// LoadNetwork reads a network in the text format described in data/RoadNetworks/README.md.
+func LoadNetwork(path string) (*Network, error) {
+	roadNetwork, err := parser.NewNetworkFromFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("routing: failed to load network %s: %w", path, err)
+	}
+	return &Network{g: roadNetwork.Network}, nil
+}
+
+// NewNetwork wraps an existing graph. The graph must not be modified afterwards
+// except through the returned Network.
+func NewNetwork(g *graph.Graph) *Network {
+	return &Network{g: g}
+}
+
+// Graph returns the underlying graph.
+func (n *Network) Graph() *graph.Graph {
+	return n.g
+}
+
+// NumVertices returns the number of vertices of the network.
+func (n *Network) NumVertices() int {
+	return len(n.g.Vertices)
+}
+
+// SetWeight changes the weight of the edge between from and to in both
+// directions. A CCH picks up the change on its next customization, a CH has to
+// be preprocessed again.
+func (n *Network) SetWeight(from, to graph.VertexId, weight int) error {
+	if err := n.g.UpdateEdge(from, to, weight, false, -1); err != nil {
+		return fmt.Errorf("routing: failed to set weight of %d -> %d: %w", from, to, err)
+	}
+	if err := n.g.UpdateEdge(to, from, weight, false, -1); err != nil {
+		return fmt.Errorf("routing: failed to set weight of %d -> %d: %w", to, from, err)
+	}
+	return nil
+}
+
+// Query runs a plain Dijkstra search on the network.
+func (n *Network) Query(source, target graph.VertexId) (Route, error) {
+	return query(n.g, router.NewDijkstraRouter(n.g), source, target)
+}
+
+// CH is a preprocessed contraction hierarchy. It is immutable; a change of the
+// network requires a new preprocessing.
+type CH struct {
+	ch *ch.ContractionHierarchies
+}
+
+// PreprocessCH builds a contraction hierarchy for the network. The network
+// itself is not modified.
+func PreprocessCH(n *Network) *CH {
+	c := ch.NewContractionHierarchies()
+	c.Preprocess(n.g.Clone())
+	return &CH{ch: c}
+}
+
+// LoadCH reads a CH written by CH.Save.
+func LoadCH(path string) (*CH, error) {
+	file, err := preprocessed_graph.ReadCHFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("routing: failed to load CH %s: %w", path, err)
+	}
+	return &CH{ch: file.ToCH()}, nil
+}
+
+// Save writes the CH to path.
+func (c *CH) Save(path string) error {
+	if err := preprocessed_graph.FromCH(c.ch).WriteCH(path); err != nil {
+		return fmt.Errorf("routing: failed to save CH %s: %w", path, err)
+	}
+	return nil
+}
+
+// Query computes the shortest route between source and target.
+func (c *CH) Query(source, target graph.VertexId) (Route, error) {
+	return query(c.ch.UpwardsGraph, router.NewCHRouter(c.ch), source, target)
+}
+
+// CCH is a customizable contraction hierarchy. Its topology is computed once
+// from a nested dissection order, after which it can be customized with the
+// current weights of the network any number of times.
+type CCH struct {
+	cch        *cch.CCH
+	customized bool
+}
+
+// PreprocessCCH runs the metric independent preprocessing of a CCH using the
+// vertex order in orderingPath, as written by KaHIP (see data/KaHIP). The
+// returned CCH must be customized before it can be queried.
+func PreprocessCCH(n *Network, orderingPath string) (*CCH, error) {
+	c := cch.NewCCH()
+	if err := c.Preprocess(n.g, orderingPath); err != nil {
+		return nil, fmt.Errorf("routing: failed to preprocess CCH: %w", err)
+	}
+	return &CCH{cch: c}, nil
+}
+
+// LoadCCH reads a CCH written by CCH.Save. The returned CCH must be customized
+// before it can be queried.
+func LoadCCH(path string) (*CCH, error) {
+	file, err := preprocessed_graph.ReadCCH(path)
+	if err != nil {
+		return nil, fmt.Errorf("routing: failed to load CCH %s: %w", path, err)
+	}
+	return &CCH{cch: file.ToCCH()}, nil
+}
+
+// Save writes the CCH topology to path.
+func (c *CCH) Save(path string) error {
+	if err := preprocessed_graph.FromCCH(c.cch).Write(path); err != nil {
+		return fmt.Errorf("routing: failed to save CCH %s: %w", path, err)
+	}
+	return nil
+}
+
+// Customize applies the current weights of the network to the CCH. The network
+// must be the one the CCH was preprocessed for.
+func (c *CCH) Customize(n *Network) error {
+	if err := c.cch.Customize(n.g); err != nil {
+		return fmt.Errorf("routing: failed to customize CCH: %w", err)
+	}
+	c.customized = true
+	return nil
+}
+
+// CustomizeProfile customizes the CCH with the travel times in milliseconds of
+// the built-in vehicle profile with the given name, e.g. "car" or "bicycle".
+// The travel times are derived from the road attributes of the network.
+func (c *CCH) CustomizeProfile(n *Network, profile string) error {
+	p, err := vehicle.ByName(profile)
+	if err != nil {
+		return fmt.Errorf("routing: %w", err)
+	}
+	if err := c.cch.CustomizeProfile(n.g, p); err != nil {
+		return fmt.Errorf("routing: failed to customize CCH for %s: %w", profile, err)
+	}
+	c.customized = true
+	return nil
+}
+
+// Query computes the shortest route between source and target with the
+// weights of the last customization.
+func (c *CCH) Query(source, target graph.VertexId) (Route, error) {
+	if !c.customized {
+		return Route{}, ErrNotCustomized
+	}
+	return query(c.cch.UpwardsGraph, router.NewCCHRouter(c.cch), source, target)
+}
+
+// query checks that both vertices exist in g and translates the result of r
+// into the public types.
+func query(g *graph.Graph, r router.Router, source, target graph.VertexId) (Route, error) {
+	for _, v := range []graph.VertexId{source, target} {
+		if _, ok := g.Vertices[v]; !ok {
+			return Route{}, fmt.Errorf("%w: %d", ErrUnknownVertex, v)
+		}
+	}
+
+	route, err := r.Route(source, target, router.Options{})
+	if errors.Is(err, pathfinding.ErrTargetNotReachable) {
+		return Route{}, fmt.Errorf("%w: %d -> %d", ErrNoRoute, source, target)
+	}
+	if err != nil {
+		return Route{}, fmt.Errorf("routing: query failed: %w", err)
+	}
+
+	edges := make([]Edge, 0, len(route.Edges))
+	for _, e := range route.Edges {
+		edges = append(edges, Edge{From: e.From, To: e.To, Weight: e.Weight, IsShortcut: e.IsShortcut})
+	}
+	return Route{
+		Path:        route.Path,
+		Edges:       edges,
+		Cost:        route.Cost,
+		NodesPopped: route.Stats.NodesPopped,
+		Duration:    route.Stats.Duration,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package routing
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	graph "github.com/PaulMue0/efficient-routeplanning/pkg/collection/graph"
+)
+
+const (
+	networkPath  = "../../data/RoadNetworks/osm1.txt"
+	orderingPath = "../../data/KaHIP/osm1.ordering"
+)
+
+func loadTestNetwork(t *testing.T) *Network {
+	t.Helper()
+	network, err := LoadNetwork(networkPath)
+	if err != nil {
+		t.Fatalf("LoadNetwork failed: %v", err)
+	}
+	return network
+}
+
+func TestLoadNetwork(t *testing.T) {
+	network := loadTestNetwork(t)
+	if got := network.NumVertices(); got != 500 {
+		t.Errorf("got %d vertices, want 500", got)
+	}
+
+	if _, err := LoadNetwork("does/not/exist.txt"); err == nil {
+		t.Error("expected an error for a missing network file")
+	}
+}
+
+func TestRoutersAgree(t *testing.T) {
+	network := loadTestNetwork(t)
+
+	chRouter := PreprocessCH(network)
+	if network.NumVertices() != 500 {
+		t.Fatal("PreprocessCH modified the network")
+	}
+
+	cchRouter, err := PreprocessCCH(network, orderingPath)
+	if err != nil {
+		t.Fatalf("PreprocessCCH failed: %v", err)
+	}
+	if _, err := cchRouter.Query(1, 5); !errors.Is(err, ErrNotCustomized) {
+		t.Errorf("expected ErrNotCustomized before customization, got %v", err)
+	}
+	if err := cchRouter.Customize(network); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	pairs := [][2]int{{1, 5}, {0, 499}, {42, 250}, {17, 17}}
+	for _, pair := range pairs {
+		want, err := network.Query(graph.VertexId(pair[0]), graph.VertexId(pair[1]))
+		if err != nil {
+			t.Fatalf("Dijkstra query %v failed: %v", pair, err)
+		}
+		for name, r := range map[string]Router{"ch": chRouter, "cch": cchRouter} {
+			got, err := r.Query(graph.VertexId(pair[0]), graph.VertexId(pair[1]))
+			if err != nil {
+				t.Errorf("%s query %v failed: %v", name, pair, err)
+				continue
+			}
+			if got.Cost != want.Cost {
+				t.Errorf("%s query %v: got cost %f, want %f", name, pair, got.Cost, want.Cost)
+			}
+			if len(got.Edges) != len(got.Path)-1 {
+				t.Errorf("%s query %v: got %d edges for %d vertices", name, pair, len(got.Edges), len(got.Path))
+			}
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	network := loadTestNetwork(t)
+
+	if _, err := network.Query(1, 100000); !errors.Is(err, ErrUnknownVertex) {
+		t.Errorf("expected ErrUnknownVertex, got %v", err)
+	}
+}
+
+func TestSetWeightAndCustomize(t *testing.T) {
+	network := loadTestNetwork(t)
+	cchRouter, err := PreprocessCCH(network, orderingPath)
+	if err != nil {
+		t.Fatalf("PreprocessCCH failed: %v", err)
+	}
+	if err := cchRouter.Customize(network); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	before, err := cchRouter.Query(1, 5)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for _, edge := range before.Edges {
+		if err := network.SetWeight(edge.From, edge.To, 1000); err != nil {
+			t.Fatalf("SetWeight failed: %v", err)
+		}
+	}
+	if err := cchRouter.Customize(network); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	after, err := cchRouter.Query(1, 5)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want, _ := network.Query(1, 5)
+	if after.Cost != want.Cost {
+		t.Errorf("got cost %f after customization, want %f", after.Cost, want.Cost)
+	}
+	if after.Cost <= before.Cost {
+		t.Errorf("expected the route to get more expensive, got %f before and %f after", before.Cost, after.Cost)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	network := loadTestNetwork(t)
+	dir := t.TempDir()
+
+	chPath := filepath.Join(dir, "ch.gob")
+	if err := PreprocessCH(network).Save(chPath); err != nil {
+		t.Fatalf("CH.Save failed: %v", err)
+	}
+	loadedCH, err := LoadCH(chPath)
+	if err != nil {
+		t.Fatalf("LoadCH failed: %v", err)
+	}
+
+	cchRouter, err := PreprocessCCH(network, orderingPath)
+	if err != nil {
+		t.Fatalf("PreprocessCCH failed: %v", err)
+	}
+	cchPath := filepath.Join(dir, "cch.gob")
+	if err := cchRouter.Save(cchPath); err != nil {
+		t.Fatalf("CCH.Save failed: %v", err)
+	}
+	loadedCCH, err := LoadCCH(cchPath)
+	if err != nil {
+		t.Fatalf("LoadCCH failed: %v", err)
+	}
+	if err := loadedCCH.Customize(network); err != nil {
+		t.Fatalf("Customize failed: %v", err)
+	}
+
+	want, _ := network.Query(0, 499)
+	for name, r := range map[string]Router{"ch": loadedCH, "cch": loadedCCH} {
+		got, err := r.Query(0, 499)
+		if err != nil {
+			t.Fatalf("%s query failed: %v", name, err)
+		}
+		if got.Cost != want.Cost {
+			t.Errorf("%s: got cost %f, want %f", name, got.Cost, want.Cost)
+		}
+	}
+}
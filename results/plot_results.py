@@ -139,6 +139,82 @@ plt.tight_layout()
 plt.savefig(os.path.join(output_dir, 'ch_nodes_popped.pdf'))
 plt.close()
 
+# --- Arc-Flags Experiment Results ---
+# The arc-flags experiment is optional, its plots are only created once its results exist.
+arcflags_path = os.path.join(output_dir, 'arcflags_experiment_results.csv')
+df_arcflags = pd.read_csv(arcflags_path) if os.path.exists(arcflags_path) else None
+
+if df_arcflags is not None:
+    plt.figure(figsize=(12, 7))
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgDijkstraTime(ms)', marker='o', label='Avg Dijkstra Time')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgArcFlagTime(ms)', marker='o', label='Avg Arc-Flags Time')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgCHTime(ms)', marker='o', label='Avg CH Time')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgCHArcFlagTime(ms)', marker='o', label='Avg CH+Arc-Flags Time')
+    plt.title('Arc-Flags Query Time vs Dijkstra and CH')
+    plt.xlabel('Graph')
+    plt.ylabel('Time (ms)')
+    plt.yscale('log')
+    plt.xticks(rotation=45, ha='right')
+    plt.legend()
+    plt.grid(True, which="both", ls="--", c='0.7')
+    plt.tight_layout()
+    plt.savefig(os.path.join(output_dir, 'arcflags_query_time.pdf'))
+    plt.close()
+
+    plt.figure(figsize=(12, 7))
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgDijkstraNodesPopped', marker='o', label='Avg Dijkstra Nodes Popped')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgArcFlagNodesPopped', marker='o', label='Avg Arc-Flags Nodes Popped')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgCHNodesPopped', marker='o', label='Avg CH Nodes Popped')
+    sns.lineplot(data=df_arcflags, x='Graph', y='AvgCHArcFlagNodesPopped', marker='o', label='Avg CH+Arc-Flags Nodes Popped')
+    plt.title('Arc-Flags Nodes Popped vs Dijkstra and CH')
+    plt.xlabel('Graph')
+    plt.ylabel('Nodes Popped')
+    plt.yscale('log')
+    plt.xticks(rotation=45, ha='right')
+    plt.legend()
+    plt.grid(True, which="both", ls="--", c='0.7')
+    plt.tight_layout()
+    plt.savefig(os.path.join(output_dir, 'arcflags_nodes_popped.pdf'))
+    plt.close()
+
+# --- Benchmark Experiment Results ---
+# The benchmark experiment is optional, its plots are only created once its results exist.
+benchmark_path = os.path.join(output_dir, 'benchmark_experiment_results.csv')
+df_benchmark = pd.read_csv(benchmark_path) if os.path.exists(benchmark_path) else None
+
+if df_benchmark is not None:
+    # Percentiles over the random queries, one line per router and percentile
+    df_random = df_benchmark[(df_benchmark['QuerySet'] == 'random') & (df_benchmark['Rank'] == -1)]
+    df_percentiles = df_random.melt(id_vars=['Graph', 'Router'], value_vars=['P50Time(ms)', 'P95Time(ms)', 'P99Time(ms)'],
+                                    var_name='Percentile', value_name='Time (ms)')
+    plt.figure(figsize=(12, 7))
+    sns.lineplot(data=df_percentiles, x='Graph', y='Time (ms)', hue='Router', style='Percentile', marker='o')
+    plt.title('Query Time Percentiles of Random Queries')
+    plt.xlabel('Graph')
+    plt.ylabel('Time (ms)')
+    plt.yscale('log')
+    plt.xticks(rotation=45, ha='right')
+    plt.grid(True, which="both", ls="--", c='0.7')
+    plt.tight_layout()
+    plt.savefig(os.path.join(output_dir, 'benchmark_percentiles.pdf'))
+    plt.close()
+
+    # Median query time by Dijkstra rank on the largest graph
+    df_rank = df_benchmark[(df_benchmark['QuerySet'] == 'rank') & (df_benchmark['Rank'] >= 0)].copy()
+    if not df_rank.empty:
+        df_rank['GraphNum'] = df_rank['Graph'].apply(extract_graph_num)
+        df_rank = df_rank[df_rank['GraphNum'] == df_rank['GraphNum'].max()]
+        plt.figure(figsize=(12, 7))
+        sns.lineplot(data=df_rank, x='Rank', y='P50Time(ms)', hue='Router', marker='o')
+        plt.title(f"Median Query Time by Dijkstra Rank ({df_rank['Graph'].iloc[0]})")
+        plt.xlabel('Dijkstra Rank (log2)')
+        plt.ylabel('Time (ms)')
+        plt.yscale('log')
+        plt.grid(True, which="both", ls="--", c='0.7')
+        plt.tight_layout()
+        plt.savefig(os.path.join(output_dir, 'benchmark_rank_times.pdf'))
+        plt.close()
+
 # --- Combined Query Time Plot (Dijkstra vs CCH vs CH) ---
 # Merge the two query dataframes
 # Rename columns to avoid conflicts and for clarity in the combined plot
@@ -152,6 +228,11 @@ df_ch_query_renamed.rename(columns={'AvgCHDijkstraTime(ms)': 'CH Query Time (ms)
 # Use outer merge to keep all graphs from both datasets
 combined_query_df = pd.merge(df_cch_query_renamed, df_ch_query_renamed, on='Graph', how='outer')
 
+if df_arcflags is not None:
+    df_arcflags_renamed = df_arcflags[['Graph', 'AvgArcFlagTime(ms)', 'AvgCHArcFlagTime(ms)']].copy()
+    df_arcflags_renamed.rename(columns={'AvgArcFlagTime(ms)': 'Arc-Flags Query Time (ms)', 'AvgCHArcFlagTime(ms)': 'CH+Arc-Flags Query Time (ms)'}, inplace=True)
+    combined_query_df = pd.merge(combined_query_df, df_arcflags_renamed, on='Graph', how='outer')
+
 # Melt the DataFrame for easier plotting with seaborn
 combined_query_melted = combined_query_df.melt(id_vars=['Graph'], var_name='Algorithm', value_name='Time (ms)')
 
@@ -17,4 +17,19 @@ go run cmd/ch_experiment/main.go --experiment cch_customization
 echo "\n--- Running CCH Query Experiment ---"
 go run cmd/ch_experiment/main.go --experiment cch_query
 
+echo "\n--- Running Hub Label Experiment ---"
+go run cmd/ch_experiment/main.go --experiment hub_labels
+
+echo "\n--- Running Transit Node Routing Experiment ---"
+go run cmd/ch_experiment/main.go --experiment tnr
+
+echo "\n--- Running Arc-Flags Experiment ---"
+go run cmd/ch_experiment/main.go --experiment arcflags
+
+echo "\n--- Running CH Order Experiment ---"
+go run cmd/ch_experiment/main.go --experiment ch_order
+
+echo "\n--- Running Benchmark Experiment ---"
+go run cmd/ch_experiment/main.go --experiment benchmark
+
 echo "\n--- All experiments completed ---"